@@ -1,8 +1,11 @@
 package cache
 
 import (
+	"container/list"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"sync"
 
 	"log"
@@ -16,25 +19,208 @@ const (
 	updateEvent = "update"
 	addEvent    = "add"
 	deleteEvent = "delete"
+	syncedEvent = "synced"
 	bufferSize  = 65536
 )
 
 // RowCache is a collections of Models hashed by UUID
+// If it is bounded (see NewLRURowCache), it only holds the most recently read or written rows
 type RowCache struct {
 	cache map[string]model.Model
 	mutex sync.RWMutex
+	// maxEntries is the maximum number of rows of a bounded cache
+	maxEntries int
+	// lru holds the UUIDs of the rows of a bounded cache, most recently used first
+	lru      *list.List
+	elements map[string]*list.Element
+	// metadata holds the user metadata attached to the rows, if any
+	metadata map[string]interface{}
+	// mapper maps the rows of the table, if its schema is known. When it is, the rows are
+	// indexed by the values of the indexes the schema defines
+	mapper *mapper.Mapper
+	table  string
+	// indexes holds the UUIDs of the rows holding every index key, per index. A consistent
+	// cache has a single row per key
+	indexes map[string]map[string]map[string]bool
+	// indexKeys holds the index keys of every indexed row
+	indexKeys map[string][]mapper.IndexKey
 }
 
 // Row returns one model from the cache by UUID
 func (r *RowCache) Row(uuid string) model.Model {
-	r.mutex.RLock()
-	defer r.mutex.RUnlock()
+	if r.lru != nil {
+		// Reading a row of a bounded cache updates its recency
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		if element, ok := r.elements[uuid]; ok {
+			r.lru.MoveToFront(element)
+		}
+	} else {
+		r.mutex.RLock()
+		defer r.mutex.RUnlock()
+	}
 	if row, ok := r.cache[uuid]; ok {
 		return row.(model.Model)
 	}
 	return nil
 }
 
+// set writes a row, evicting the least recently used rows of a bounded cache if needed
+// The caller must hold the write lock
+func (r *RowCache) set(uuid string, m model.Model) {
+	r.cache[uuid] = m
+	if err := r.index(uuid, m); err != nil {
+		log.Printf("unable to index row %s: %v", uuid, err)
+	}
+	if r.lru == nil {
+		return
+	}
+	if element, ok := r.elements[uuid]; ok {
+		r.lru.MoveToFront(element)
+		return
+	}
+	r.elements[uuid] = r.lru.PushFront(uuid)
+	for r.lru.Len() > r.maxEntries {
+		r.remove(r.lru.Back().Value.(string))
+	}
+}
+
+// remove deletes a row and its metadata. The caller must hold the write lock
+func (r *RowCache) remove(uuid string) {
+	delete(r.cache, uuid)
+	delete(r.metadata, uuid)
+	r.unindex(uuid)
+	if r.lru == nil {
+		return
+	}
+	if element, ok := r.elements[uuid]; ok {
+		r.lru.Remove(element)
+		delete(r.elements, uuid)
+	}
+}
+
+// indexName returns the name used to hold the keys of an index
+func indexName(columns []string) string {
+	return strings.Join(columns, ",")
+}
+
+// index replaces the index keys of a row. The caller must hold the write lock
+func (r *RowCache) index(uuid string, m model.Model) error {
+	if r.mapper == nil {
+		return nil
+	}
+	r.unindex(uuid)
+	info, err := r.mapper.NewMapperInfo(r.table, m)
+	if err != nil {
+		return err
+	}
+	keys, err := info.IndexKeys()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		name := indexName(key.Columns)
+		if r.indexes[name] == nil {
+			r.indexes[name] = make(map[string]map[string]bool)
+		}
+		if r.indexes[name][key.Key] == nil {
+			r.indexes[name][key.Key] = make(map[string]bool)
+		}
+		r.indexes[name][key.Key][uuid] = true
+	}
+	r.indexKeys[uuid] = keys
+	return nil
+}
+
+// unindex removes the index keys of a row. The caller must hold the write lock
+func (r *RowCache) unindex(uuid string) {
+	for _, key := range r.indexKeys[uuid] {
+		name := indexName(key.Columns)
+		delete(r.indexes[name][key.Key], uuid)
+		if len(r.indexes[name][key.Key]) == 0 {
+			delete(r.indexes[name], key.Key)
+		}
+	}
+	delete(r.indexKeys, uuid)
+}
+
+// setMapper sets the mapper of the schema used to index the rows of the table and drops their
+// index keys. The rows are not indexed if the schema does not define the table
+// The caller must hold the write lock
+func (r *RowCache) setMapper(m *mapper.Mapper, table string) {
+	if m != nil && m.Schema.Table(table) == nil {
+		m = nil
+	}
+	r.mapper = m
+	r.table = table
+	r.indexes = make(map[string]map[string]map[string]bool)
+	r.indexKeys = make(map[string][]mapper.IndexKey)
+}
+
+// reindex recomputes the index keys of all the rows. The caller must hold the write lock
+func (r *RowCache) reindex(m *mapper.Mapper, table string) error {
+	r.setMapper(m, table)
+	for uuid, m := range r.cache {
+		if err := r.index(uuid, m); err != nil {
+			return fmt.Errorf("row %s: %w", uuid, err)
+		}
+	}
+	return nil
+}
+
+// RowByIndex returns the cached row that holds the same values as the provided model in any of
+// the indexes of the table (the _uuid column and the indexes defined in the schema), or nil if
+// there is none. An error is returned if the rows of the cache are not indexed, i.e: if it is
+// not the cache of a table of the schema of a TableCache
+func (r *RowCache) RowByIndex(m model.Model) (model.Model, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if r.mapper == nil {
+		return nil, fmt.Errorf("the rows of the cache are not indexed")
+	}
+	info, err := r.mapper.NewMapperInfo(r.table, m)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := info.IndexKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if uuids := r.indexedRows(key); len(uuids) > 0 {
+			return r.cache[uuids[0]], nil
+		}
+	}
+	return nil, nil
+}
+
+// RowsByIndexKey returns the UUIDs of the cached rows that hold the provided index key (see
+// mapper.MapperInfo's IndexKeys), sorted. An error is returned if the rows of the cache are not
+// indexed, see RowByIndex
+func (r *RowCache) RowsByIndexKey(key mapper.IndexKey) ([]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if r.mapper == nil {
+		return nil, fmt.Errorf("the rows of the cache are not indexed")
+	}
+	return r.indexedRows(key), nil
+}
+
+// indexedRows returns the UUIDs of the rows that hold an index key, sorted
+// The caller must hold the read lock
+func (r *RowCache) indexedRows(key mapper.IndexKey) []string {
+	rows := r.indexes[indexName(key.Columns)][key.Key]
+	if len(rows) == 0 {
+		return nil
+	}
+	uuids := make([]string, 0, len(rows))
+	for uuid := range rows {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+	return uuids
+}
+
 // Set writes the provided content to the cache
 // WARNING: Do not use Set outside of testing
 // as it may case cache corruption if, for example,
@@ -43,7 +229,7 @@ func (r *RowCache) Row(uuid string) model.Model {
 func (r *RowCache) Set(uuid string, m model.Model) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	r.cache[uuid] = m
+	r.set(uuid, m)
 }
 
 // Rows returns a list of row UUIDs as strings
@@ -57,6 +243,13 @@ func (r *RowCache) Rows() []string {
 	return result
 }
 
+// RowsSorted returns a list of row UUIDs as strings, sorted, so the output is stable
+func (r *RowCache) RowsSorted() []string {
+	result := r.Rows()
+	sort.Strings(result)
+	return result
+}
+
 // Len returns the length of the cache
 func (r *RowCache) Len() int {
 	r.mutex.Lock()
@@ -76,6 +269,19 @@ func NewRowCache(data map[string]model.Model) *RowCache {
 	}
 }
 
+// NewLRURowCache creates a new, empty, row cache that holds at most maxEntries rows
+// When it is full, writing a new row evicts the least recently read or written one. Evictions
+// do not generate events, so reads from a bounded cache may miss rows that exist in the database
+func NewLRURowCache(maxEntries int) *RowCache {
+	return &RowCache{
+		cache:      make(map[string]model.Model),
+		mutex:      sync.RWMutex{},
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		elements:   make(map[string]*list.Element),
+	}
+}
+
 // EventHandler can handle events when the contents of the cache changes
 type EventHandler interface {
 	OnAdd(table string, model model.Model)
@@ -83,12 +289,28 @@ type EventHandler interface {
 	OnDelete(table string, model model.Model)
 }
 
+// ChangedColumnsEventHandler can be implemented by an EventHandler that needs to know
+// which columns were changed by an update. If implemented, OnUpdateColumns is called
+// instead of OnUpdate
+type ChangedColumnsEventHandler interface {
+	OnUpdateColumns(table string, old model.Model, new model.Model, changedColumns []string)
+}
+
+// InitialSyncEventHandler can be implemented by an EventHandler that needs to know when the
+// initial contents of the monitored tables have been fully delivered to the cache, see
+// StartInitialSync. OnInitialSyncComplete is called after the events of all their rows
+type InitialSyncEventHandler interface {
+	OnInitialSyncComplete()
+}
+
 // EventHandlerFuncs is a wrapper for the EventHandler interface
 // It allows a caller to only implement the functions they need
 type EventHandlerFuncs struct {
-	AddFunc    func(table string, model model.Model)
-	UpdateFunc func(table string, old model.Model, new model.Model)
-	DeleteFunc func(table string, model model.Model)
+	AddFunc                 func(table string, model model.Model)
+	UpdateFunc              func(table string, old model.Model, new model.Model)
+	UpdateColumnsFunc       func(table string, old model.Model, new model.Model, changedColumns []string)
+	DeleteFunc              func(table string, model model.Model)
+	InitialSyncCompleteFunc func()
 }
 
 // OnAdd calls AddFunc if it is not nil
@@ -105,6 +327,16 @@ func (e *EventHandlerFuncs) OnUpdate(table string, old, new model.Model) {
 	}
 }
 
+// OnUpdateColumns calls UpdateColumnsFunc if it is not nil
+// Otherwise, it falls back to OnUpdate
+func (e *EventHandlerFuncs) OnUpdateColumns(table string, old, new model.Model, changedColumns []string) {
+	if e.UpdateColumnsFunc != nil {
+		e.UpdateColumnsFunc(table, old, new, changedColumns)
+		return
+	}
+	e.OnUpdate(table, old, new)
+}
+
 // OnDelete calls DeleteFunc if it is not nil
 func (e *EventHandlerFuncs) OnDelete(table string, row model.Model) {
 	if e.DeleteFunc != nil {
@@ -112,6 +344,13 @@ func (e *EventHandlerFuncs) OnDelete(table string, row model.Model) {
 	}
 }
 
+// OnInitialSyncComplete calls InitialSyncCompleteFunc if it is not nil
+func (e *EventHandlerFuncs) OnInitialSyncComplete() {
+	if e.InitialSyncCompleteFunc != nil {
+		e.InitialSyncCompleteFunc()
+	}
+}
+
 // TableCache contains a collection of RowCaches, hashed by name,
 // and an array of EventHandlers that respond to cache updates
 type TableCache struct {
@@ -120,6 +359,18 @@ type TableCache struct {
 	eventProcessor *eventProcessor
 	mapper         *mapper.Mapper
 	dbModel        *model.DBModel
+	// normalizers are kept apart from the cache as they are invoked while it is being populated
+	normalizers      map[string]func(model.Model)
+	normalizersMutex sync.RWMutex
+	// weakRefCleanup is protected by cacheMutex
+	weakRefCleanup bool
+	// optimistic holds, by table and uuid, the model the rows patched by ApplyOptimistic had
+	// before, or nil if they did not exist, until the server updates them. It is protected
+	// by cacheMutex
+	optimistic map[string]map[string]model.Model
+	// syncing is the number of initial syncs in progress, see StartInitialSync. It is protected
+	// by cacheMutex
+	syncing int
 }
 
 // NewTableCache creates a new TableCache
@@ -156,18 +407,172 @@ func (t *TableCache) Table(name string) *RowCache {
 	return nil
 }
 
+// newRowCache creates an empty RowCache for a table that indexes its rows
+func (t *TableCache) newRowCache(table string) *RowCache {
+	rc := NewRowCache(nil)
+	rc.setMapper(t.mapper, table)
+	return rc
+}
+
 // Set write the provided RowCache to the provided table name in the cache
 // if the provided cache is nil, we'll initialize a new one
+// The rows of the provided cache are indexed
 // WARNING: Do not use Set outside of testing
 func (t *TableCache) Set(name string, rc *RowCache) {
 	if rc == nil {
 		rc = NewRowCache(nil)
 	}
+	rc.mutex.Lock()
+	if err := rc.reindex(t.mapper, name); err != nil {
+		log.Printf("unable to index table %s: %v", name, err)
+	}
+	rc.mutex.Unlock()
 	t.cacheMutex.Lock()
 	defer t.cacheMutex.Unlock()
 	t.cache[name] = rc
 }
 
+// ReindexTable recomputes the indexes of the cached rows of a table from scratch, e.g: to recover
+// from an inconsistent state.
+// The indexes are the _uuid column and those defined in the schema, see RowCache.RowByIndex
+func (t *TableCache) ReindexTable(table string) error {
+	if _, ok := t.dbModel.Types()[table]; !ok {
+		return fmt.Errorf("table %s not found in the database model", table)
+	}
+	if t.mapper.Schema.Table(table) == nil {
+		return fmt.Errorf("table %s not found in schema", table)
+	}
+	t.cacheMutex.RLock()
+	defer t.cacheMutex.RUnlock()
+	tCache, ok := t.cache[table]
+	if !ok {
+		return nil
+	}
+	tCache.mutex.Lock()
+	defer tCache.mutex.Unlock()
+	if err := tCache.reindex(t.mapper, table); err != nil {
+		return fmt.Errorf("table %s: %w", table, err)
+	}
+	return nil
+}
+
+// Reindex recomputes the indexes of the cached rows of all the tables from scratch, see ReindexTable
+func (t *TableCache) Reindex() error {
+	tables := t.Tables()
+	sort.Strings(tables)
+	for _, table := range tables {
+		if err := t.ReindexTable(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetLRU makes the cache of the provided table hold at most maxEntries rows, evicting the least
+// recently read or written ones, e.g: for memory-constrained clients that only populate the cache
+// with the results of their own queries. Evictions do not generate events and reads of the table
+// may miss rows that exist in the database, so callers must be ready to fall back to the server.
+// The rows already cached are kept, up to maxEntries
+func (t *TableCache) SetLRU(table string, maxEntries int) error {
+	if _, ok := t.dbModel.Types()[table]; !ok {
+		return fmt.Errorf("table %s not found in the database model", table)
+	}
+	if maxEntries <= 0 {
+		return fmt.Errorf("invalid maximum number of entries %d", maxEntries)
+	}
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+	lru := NewLRURowCache(maxEntries)
+	lru.setMapper(t.mapper, table)
+	if existing, ok := t.cache[table]; ok {
+		existing.mutex.RLock()
+		for uuid, m := range existing.cache {
+			lru.set(uuid, m)
+		}
+		existing.mutex.RUnlock()
+	}
+	t.cache[table] = lru
+	return nil
+}
+
+// SetNormalizer registers a function that is invoked on every model of the provided table right
+// after it is decoded from the wire and before it is stored, e.g: to lowercase a name or sort a set
+// so that the comparisons made by conditions and on updates are stable. Updates that only differ in
+// what the normalizer changes do not generate events. The normalizer must be deterministic and must
+// not have side effects beyond modifying the model it receives, as it may be invoked more than once
+// on the same data. The rows already cached are not normalized. A nil function removes the table's
+// normalizer
+func (t *TableCache) SetNormalizer(table string, fn func(model.Model)) {
+	t.normalizersMutex.Lock()
+	defer t.normalizersMutex.Unlock()
+	if fn == nil {
+		delete(t.normalizers, table)
+		return
+	}
+	if t.normalizers == nil {
+		t.normalizers = make(map[string]func(model.Model))
+	}
+	t.normalizers[table] = fn
+}
+
+// normalize invokes the normalizer of a table, if any, on a model
+func (t *TableCache) normalize(table string, m model.Model) {
+	t.normalizersMutex.RLock()
+	fn, ok := t.normalizers[table]
+	t.normalizersMutex.RUnlock()
+	if ok {
+		fn(m)
+	}
+}
+
+// SetWeakReferenceCleanup makes the cache remove, as the server does, the weak references to the
+// rows it deletes from the sets and maps of the rows referencing them, so the cache is consistent
+// before the server sends the updates of the referencing rows. It applies to the rows deleted by
+// Populate, Populate2 and PurgeWhere, and an update event is generated for each changed row. The
+// references are removed once the whole update is applied, so updates of the referencing rows
+// received along with the deletion are not applied twice
+func (t *TableCache) SetWeakReferenceCleanup(enabled bool) {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+	t.weakRefCleanup = enabled
+}
+
+// SetMetadata attaches arbitrary user metadata (e.g: state derived from the row) to a cached row,
+// replacing any previous one. The metadata is cleared when the row is removed from the cache, so it
+// shares its lifecycle. An error is returned if the row is not in the cache
+func (t *TableCache) SetMetadata(table, uuid string, value interface{}) error {
+	t.cacheMutex.RLock()
+	defer t.cacheMutex.RUnlock()
+	tCache, ok := t.cache[table]
+	if !ok {
+		return fmt.Errorf("row %s not found in table %s", uuid, table)
+	}
+	tCache.mutex.Lock()
+	defer tCache.mutex.Unlock()
+	if _, ok := tCache.cache[uuid]; !ok {
+		return fmt.Errorf("row %s not found in table %s", uuid, table)
+	}
+	if tCache.metadata == nil {
+		tCache.metadata = make(map[string]interface{})
+	}
+	tCache.metadata[uuid] = value
+	return nil
+}
+
+// GetMetadata returns the user metadata attached to a cached row and whether there is any
+func (t *TableCache) GetMetadata(table, uuid string) (interface{}, bool) {
+	t.cacheMutex.RLock()
+	defer t.cacheMutex.RUnlock()
+	tCache, ok := t.cache[table]
+	if !ok {
+		return nil, false
+	}
+	tCache.mutex.RLock()
+	defer tCache.mutex.RUnlock()
+	value, ok := tCache.metadata[uuid]
+	return value, ok
+}
+
 // Tables returns a list of table names that are in the cache
 func (t *TableCache) Tables() []string {
 	t.cacheMutex.RLock()
@@ -179,6 +584,101 @@ func (t *TableCache) Tables() []string {
 	return result
 }
 
+// Dump returns a human-readable representation of the cached rows of a table,
+// ordered by UUID, with their columns ordered by name
+func (t *TableCache) Dump(table string) (string, error) {
+	var b strings.Builder
+	if err := t.dump(&b, table); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// DumpAll returns a human-readable representation of all the cached tables, ordered by name
+func (t *TableCache) DumpAll() (string, error) {
+	tables := t.Tables()
+	sort.Strings(tables)
+	var b strings.Builder
+	for _, table := range tables {
+		if err := t.dump(&b, table); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+func (t *TableCache) dump(b *strings.Builder, table string) error {
+	tableSchema := t.mapper.Schema.Table(table)
+	if tableSchema == nil {
+		return fmt.Errorf("table %s not found in schema", table)
+	}
+	columns := make([]string, 0, len(tableSchema.Columns))
+	for column := range tableSchema.Columns {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	fmt.Fprintf(b, "%s:\n", table)
+	rowCache := t.Table(table)
+	if rowCache == nil {
+		return nil
+	}
+	rowCache.mutex.RLock()
+	defer rowCache.mutex.RUnlock()
+	uuids := make([]string, 0, len(rowCache.cache))
+	for uuid := range rowCache.cache {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+	for _, uuid := range uuids {
+		info, err := mapper.NewMapperInfo(tableSchema, rowCache.cache[uuid])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "  %s:\n", uuid)
+		for _, column := range columns {
+			value, err := info.FieldByColumn(column)
+			if err != nil {
+				// The model does not map this column
+				continue
+			}
+			fmt.Fprintf(b, "    %s: %v\n", column, value)
+		}
+	}
+	return nil
+}
+
+// StartInitialSync marks the cache as syncing until CompleteInitialSync is called, e.g: while the
+// initial contents of the monitored tables are delivered to it in successive calls to Populate
+// or Populate2. Several syncs may be in progress at once
+func (t *TableCache) StartInitialSync() {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+	t.syncing++
+}
+
+// CompleteInitialSync ends a sync started with StartInitialSync. Once none is in progress, the
+// InitialSyncEventHandlers are notified, after the events of the rows delivered until then
+func (t *TableCache) CompleteInitialSync() {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+	if t.syncing == 0 {
+		return
+	}
+	t.syncing--
+	if t.syncing == 0 {
+		t.eventProcessor.addEvent(event{eventType: syncedEvent})
+	}
+}
+
+// Syncing returns whether an initial sync started with StartInitialSync is in progress, in which
+// case the cache may not hold all the rows of the monitored tables yet
+func (t *TableCache) Syncing() bool {
+	t.cacheMutex.RLock()
+	defer t.cacheMutex.RUnlock()
+	return t.syncing > 0
+}
+
 // Update implements the update method of the NotificationHandler interface
 // this populates the cache with new updates
 func (t *TableCache) Update(context interface{}, tableUpdates ovsdb.TableUpdates) {
@@ -188,6 +688,15 @@ func (t *TableCache) Update(context interface{}, tableUpdates ovsdb.TableUpdates
 	t.Populate(tableUpdates)
 }
 
+// Update2 implements the Update2NotificationHandler interface
+// this populates the cache with new updates
+func (t *TableCache) Update2(context interface{}, tableUpdates ovsdb.TableUpdates2) {
+	if len(tableUpdates) == 0 {
+		return
+	}
+	t.Populate2(tableUpdates)
+}
+
 // Locked implements the locked method of the NotificationHandler interface
 func (t *TableCache) Locked([]interface{}) {
 }
@@ -208,6 +717,7 @@ func (t *TableCache) Disconnected() {
 func (t *TableCache) Populate(tableUpdates ovsdb.TableUpdates) {
 	t.cacheMutex.Lock()
 	defer t.cacheMutex.Unlock()
+	deleted := make(map[string]map[string]bool)
 	for table := range t.dbModel.Types() {
 		updates, ok := tableUpdates[table]
 		if !ok {
@@ -215,51 +725,491 @@ func (t *TableCache) Populate(tableUpdates ovsdb.TableUpdates) {
 		}
 		var tCache *RowCache
 		if tCache, ok = t.cache[table]; !ok {
-			t.cache[table] = NewRowCache(nil)
+			t.cache[table] = t.newRowCache(table)
 			tCache = t.cache[table]
 		}
 		tCache.mutex.Lock()
 		for uuid, row := range updates {
+			// The full rows of update notifications supersede the optimistic ones
+			delete(t.optimistic[table], uuid)
 			if row.New != nil {
-				newModel, err := t.CreateModel(table, row.New, uuid)
+				newModel, err := t.decodeModel(table, row.New, uuid)
 				if err != nil {
 					panic(err)
 				}
 				if existing, ok := tCache.cache[uuid]; ok {
 					if !reflect.DeepEqual(newModel, existing) {
-						tCache.cache[uuid] = newModel
-						oldModel, err := t.CreateModel(table, row.Old, uuid)
+						tCache.set(uuid, newModel)
+						oldModel, err := t.decodeModel(table, row.Old, uuid)
 						if err != nil {
 							panic(err)
 						}
-						t.eventProcessor.AddEvent(updateEvent, table, oldModel, newModel)
+						changedColumns, err := t.mapper.ChangedColumns(table, existing, newModel)
+						if err != nil {
+							panic(err)
+						}
+						t.eventProcessor.AddUpdateEvent(table, oldModel, newModel, changedColumns)
 					}
 					// no diff
 					continue
 				}
-				tCache.cache[uuid] = newModel
+				tCache.set(uuid, newModel)
 				t.eventProcessor.AddEvent(addEvent, table, nil, newModel)
 				continue
 			} else {
-				oldModel, err := t.CreateModel(table, row.Old, uuid)
+				oldModel, err := t.decodeModel(table, row.Old, uuid)
 				if err != nil {
 					panic(err)
 				}
 				// delete from cache
-				delete(tCache.cache, uuid)
+				tCache.remove(uuid)
 				t.eventProcessor.AddEvent(deleteEvent, table, oldModel, nil)
+				addDeleted(deleted, table, uuid)
+				continue
+			}
+		}
+		tCache.mutex.Unlock()
+	}
+	t.cleanupWeakReferences(deleted)
+}
+
+// Purge removes all the rows of a table from the cache without any interaction with the server,
+// e.g: to drop rows that are no longer monitored. A delete event is generated for each removed row
+func (t *TableCache) Purge(table string) {
+	t.PurgeWhere(table, func(model.Model) bool { return true })
+}
+
+// PurgeWhere removes the rows of a table that match the predicate from the cache without any
+// interaction with the server, e.g: to drop rows that are logically gone but for which the server
+// will never send a delete. A delete event is generated for each removed row
+func (t *TableCache) PurgeWhere(table string, predicate func(model.Model) bool) {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+	tCache, ok := t.cache[table]
+	if !ok {
+		return
+	}
+	deleted := make(map[string]map[string]bool)
+	tCache.mutex.Lock()
+	for uuid, row := range tCache.cache {
+		if predicate(row) {
+			tCache.remove(uuid)
+			delete(t.optimistic[table], uuid)
+			t.eventProcessor.AddEvent(deleteEvent, table, row, nil)
+			addDeleted(deleted, table, uuid)
+		}
+	}
+	tCache.mutex.Unlock()
+	t.cleanupWeakReferences(deleted)
+}
+
+// Populate2 adds data from update2 notifications to the cache and places an event on the channel
+// Modify updates only contain the changed columns and, for sets and maps, the difference between
+// the old and the new values, so they are applied on top of the cached row: the columns absent from
+// them are left unchanged. Rows patched by ApplyOptimistic are reconciled with the update, which is
+// applied on top of the row they had before
+func (t *TableCache) Populate2(tableUpdates ovsdb.TableUpdates2) {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+	deleted := make(map[string]map[string]bool)
+	for table := range t.dbModel.Types() {
+		updates, ok := tableUpdates[table]
+		if !ok {
+			continue
+		}
+		var tCache *RowCache
+		if tCache, ok = t.cache[table]; !ok {
+			t.cache[table] = t.newRowCache(table)
+			tCache = t.cache[table]
+		}
+		tCache.mutex.Lock()
+		for uuid, row := range updates {
+			existing, exists := tCache.cache[uuid]
+			base := existing
+			if authoritative, ok := t.optimistic[table][uuid]; ok {
+				base = authoritative
+				delete(t.optimistic[table], uuid)
+			}
+			var newModel model.Model
+			var err error
+			switch {
+			case row.Initial != nil:
+				newModel, err = t.decodeModel(table, row.Initial, uuid)
+			case row.Insert != nil:
+				newModel, err = t.decodeModel(table, row.Insert, uuid)
+			case row.Modify != nil:
+				if base == nil {
+					log.Printf("ignoring modify update of unknown row %s in table %s", uuid, table)
+					continue
+				}
+				newModel, err = t.applyModify(table, uuid, base, row.Modify)
+			case row.Delete != nil:
+				if exists {
+					tCache.remove(uuid)
+					t.eventProcessor.AddEvent(deleteEvent, table, existing, nil)
+				}
+				if exists || base != nil {
+					// Rows removed by ApplyOptimistic still have their weak references cleaned up
+					addDeleted(deleted, table, uuid)
+				}
+				continue
+			default:
+				continue
+			}
+			if err != nil {
+				panic(err)
+			}
+			if !exists {
+				tCache.set(uuid, newModel)
+				t.eventProcessor.AddEvent(addEvent, table, nil, newModel)
+				continue
+			}
+			if !reflect.DeepEqual(newModel, existing) {
+				tCache.set(uuid, newModel)
+				changedColumns, err := t.mapper.ChangedColumns(table, existing, newModel)
+				if err != nil {
+					panic(err)
+				}
+				t.eventProcessor.AddUpdateEvent(table, existing, newModel, changedColumns)
+			}
+		}
+		tCache.mutex.Unlock()
+	}
+	t.cleanupWeakReferences(deleted)
+}
+
+// addDeleted records the uuid of a row deleted from a table
+func addDeleted(deleted map[string]map[string]bool, table, uuid string) {
+	if deleted[table] == nil {
+		deleted[table] = make(map[string]bool)
+	}
+	deleted[table][uuid] = true
+}
+
+// ApplyOptimistic sets a row of the cache to the given model, or removes it if the model is nil,
+// ahead of the update the server sends for it, e.g: once a transaction changing the row is
+// committed. The row is marked as optimistic until that update is received by Populate2, which
+// then applies it on top of the row as it was before, so the cache ends up holding the view of
+// the server even if it differs from the given model. The events of the change are generated
+// as usual
+func (t *TableCache) ApplyOptimistic(table, uuid string, m model.Model) error {
+	if _, ok := t.dbModel.Types()[table]; !ok {
+		return fmt.Errorf("table %s not found in the database model", table)
+	}
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+	tCache, ok := t.cache[table]
+	if !ok {
+		tCache = t.newRowCache(table)
+		t.cache[table] = tCache
+	}
+	tCache.mutex.Lock()
+	defer tCache.mutex.Unlock()
+	if t.optimistic == nil {
+		t.optimistic = make(map[string]map[string]model.Model)
+	}
+	if t.optimistic[table] == nil {
+		t.optimistic[table] = make(map[string]model.Model)
+	}
+	if _, ok := t.optimistic[table][uuid]; !ok {
+		// Only the first patch records the row of the server
+		t.optimistic[table][uuid] = tCache.cache[uuid]
+	}
+	t.setRow(tCache, table, uuid, m)
+	return nil
+}
+
+// IsOptimistic returns whether a row was patched by ApplyOptimistic and is yet to be updated
+// by the server
+func (t *TableCache) IsOptimistic(table, uuid string) bool {
+	t.cacheMutex.RLock()
+	defer t.cacheMutex.RUnlock()
+	_, ok := t.optimistic[table][uuid]
+	return ok
+}
+
+// RevertOptimistic restores the rows patched by ApplyOptimistic that are yet to be updated by
+// the server to the state they had before, e.g: when the server will never send an update for
+// them because its view of the transaction differs from the one of the client
+func (t *TableCache) RevertOptimistic() {
+	t.cacheMutex.Lock()
+	defer t.cacheMutex.Unlock()
+	for table, rows := range t.optimistic {
+		tCache, ok := t.cache[table]
+		if !ok {
+			continue
+		}
+		tCache.mutex.Lock()
+		for uuid, authoritative := range rows {
+			t.setRow(tCache, table, uuid, authoritative)
+		}
+		tCache.mutex.Unlock()
+	}
+	t.optimistic = nil
+}
+
+// setRow sets a row of a row cache to the given model, or removes it if the model is nil, and
+// generates the event of the change, if any. The caller must hold the row cache's write lock
+func (t *TableCache) setRow(tCache *RowCache, table, uuid string, m model.Model) {
+	existing, exists := tCache.cache[uuid]
+	switch {
+	case m == nil:
+		if exists {
+			tCache.remove(uuid)
+			t.eventProcessor.AddEvent(deleteEvent, table, existing, nil)
+		}
+	case !exists:
+		tCache.set(uuid, m)
+		t.eventProcessor.AddEvent(addEvent, table, nil, m)
+	case !reflect.DeepEqual(existing, m):
+		tCache.set(uuid, m)
+		changedColumns, err := t.mapper.ChangedColumns(table, existing, m)
+		if err != nil {
+			panic(err)
+		}
+		t.eventProcessor.AddUpdateEvent(table, existing, m, changedColumns)
+	}
+}
+
+// cleanupWeakReferences removes the weak references to the deleted rows, given by table, from
+// the cached rows if SetWeakReferenceCleanup enabled it. The caller must hold cacheMutex and
+// none of the row caches' locks
+func (t *TableCache) cleanupWeakReferences(deleted map[string]map[string]bool) {
+	if !t.weakRefCleanup || len(deleted) == 0 {
+		return
+	}
+	for tableName, tCache := range t.cache {
+		table := t.mapper.Schema.Table(tableName)
+		if table == nil {
+			continue
+		}
+		columns := make(map[string]*ovsdb.ColumnSchema)
+		for name, column := range table.Columns {
+			if referencesDeleted(column, deleted) {
+				columns[name] = column
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		tCache.mutex.Lock()
+		for uuid, existing := range tCache.cache {
+			newModel, err := t.removeWeakReferences(tableName, uuid, existing, columns, deleted)
+			if err != nil {
+				log.Printf("unable to remove weak references from row %s in table %s: %v", uuid, tableName, err)
 				continue
 			}
+			if newModel == nil {
+				continue
+			}
+			tCache.set(uuid, newModel)
+			changedColumns, err := t.mapper.ChangedColumns(tableName, existing, newModel)
+			if err != nil {
+				panic(err)
+			}
+			t.eventProcessor.AddUpdateEvent(tableName, existing, newModel, changedColumns)
 		}
 		tCache.mutex.Unlock()
 	}
 }
 
+// referencesDeleted returns whether the keys or the values of a set or map column are weak
+// references to a table with deleted rows
+func referencesDeleted(column *ovsdb.ColumnSchema, deleted map[string]map[string]bool) bool {
+	if column.Type != ovsdb.TypeSet && column.Type != ovsdb.TypeMap {
+		return false
+	}
+	return len(weakReferenceTargets(column.TypeObj.Key, deleted)) > 0 ||
+		len(weakReferenceTargets(column.TypeObj.Value, deleted)) > 0
+}
+
+// weakReferenceTargets returns the deleted rows of the table a base type weakly refers to, if any
+func weakReferenceTargets(baseType *ovsdb.BaseType, deleted map[string]map[string]bool) map[string]bool {
+	if baseType == nil || baseType.Type != ovsdb.TypeUUID {
+		return nil
+	}
+	refTable, _ := baseType.RefTable()
+	refType, _ := baseType.RefType()
+	if refType != ovsdb.Weak {
+		return nil
+	}
+	return deleted[refTable]
+}
+
+// removeWeakReferences returns a new model resulting from removing the references to the deleted
+// rows from the given columns of an existing model, or nil if it does not reference any of them
+func (t *TableCache) removeWeakReferences(tableName, uuid string, existing model.Model, columns map[string]*ovsdb.ColumnSchema, deleted map[string]map[string]bool) (model.Model, error) {
+	row, err := t.mapper.NewRow(tableName, existing)
+	if err != nil {
+		return nil, err
+	}
+	changed := false
+	for name, column := range columns {
+		ovsElem, ok := row[name]
+		if !ok {
+			continue
+		}
+		current, err := ovsdb.OvsToNative(column, ovsElem)
+		if err != nil {
+			return nil, err
+		}
+		keys := weakReferenceTargets(column.TypeObj.Key, deleted)
+		values := weakReferenceTargets(column.TypeObj.Value, deleted)
+		result, removed := removeReferences(column, current, keys, values)
+		if !removed {
+			continue
+		}
+		if ovsElem, err = ovsdb.NativeToOvs(column, result); err != nil {
+			return nil, err
+		}
+		row[name] = ovsElem
+		changed = true
+	}
+	if !changed {
+		return nil, nil
+	}
+	return t.CreateModel(tableName, &row, uuid)
+}
+
+// removeReferences returns the native value of a set or map column without the elements, or the
+// pairs, whose key is in keys or whose value is in values, and whether any was removed
+func removeReferences(column *ovsdb.ColumnSchema, current interface{}, keys, values map[string]bool) (interface{}, bool) {
+	currentVal := reflect.ValueOf(current)
+	removed := false
+	switch column.Type {
+	case ovsdb.TypeSet:
+		result := reflect.MakeSlice(ovsdb.NativeType(column), 0, currentVal.Len())
+		for i := 0; i < currentVal.Len(); i++ {
+			if keys[currentVal.Index(i).String()] {
+				removed = true
+				continue
+			}
+			result = reflect.Append(result, currentVal.Index(i))
+		}
+		return result.Interface(), removed
+	case ovsdb.TypeMap:
+		result := reflect.MakeMap(ovsdb.NativeType(column))
+		iter := currentVal.MapRange()
+		for iter.Next() {
+			if (keys != nil && keys[iter.Key().String()]) || (values != nil && values[iter.Value().String()]) {
+				removed = true
+				continue
+			}
+			result.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return result.Interface(), removed
+	default:
+		return current, false
+	}
+}
+
+// applyModify returns a new model resulting from applying the columns of an update2 modify
+// row to an existing model
+func (t *TableCache) applyModify(tableName, uuid string, existing model.Model, modify *ovsdb.Row) (model.Model, error) {
+	table := t.mapper.Schema.Table(tableName)
+	if table == nil {
+		return nil, fmt.Errorf("table %s not found", tableName)
+	}
+	row, err := t.mapper.NewRow(tableName, existing)
+	if err != nil {
+		return nil, err
+	}
+	for name, delta := range *modify {
+		column := table.Column(name)
+		if column == nil {
+			return nil, fmt.Errorf("table %s, column %s: column not found", tableName, name)
+		}
+		nativeDelta, err := ovsdb.OvsToNative(column, delta)
+		if err != nil {
+			return nil, err
+		}
+		current := reflect.Zero(ovsdb.NativeType(column)).Interface()
+		if ovsElem, ok := row[name]; ok {
+			if current, err = ovsdb.OvsToNative(column, ovsElem); err != nil {
+				return nil, err
+			}
+		}
+		ovsElem, err := ovsdb.NativeToOvs(column, applyModifyDelta(column, current, nativeDelta))
+		if err != nil {
+			return nil, err
+		}
+		row[name] = ovsElem
+	}
+	return t.decodeModel(tableName, &row, uuid)
+}
+
+// applyModifyDelta returns the native value of a column after applying the difference
+// received in an update2 modify row:
+// For sets, the elements present in the difference are toggled (added if absent, removed if present)
+// For maps, keys that are absent are added, keys with the same value are removed and keys
+// with a different value are updated
+// For other types, the difference is the new value
+func applyModifyDelta(column *ovsdb.ColumnSchema, current, delta interface{}) interface{} {
+	switch column.Type {
+	case ovsdb.TypeSet:
+		currentVal := reflect.ValueOf(current)
+		deltaVal := reflect.ValueOf(delta)
+		result := reflect.MakeSlice(ovsdb.NativeType(column), 0, currentVal.Len()+deltaVal.Len())
+		for i := 0; i < currentVal.Len(); i++ {
+			if !sliceContains(deltaVal, currentVal.Index(i)) {
+				result = reflect.Append(result, currentVal.Index(i))
+			}
+		}
+		for i := 0; i < deltaVal.Len(); i++ {
+			if !sliceContains(currentVal, deltaVal.Index(i)) {
+				result = reflect.Append(result, deltaVal.Index(i))
+			}
+		}
+		return result.Interface()
+	case ovsdb.TypeMap:
+		currentVal := reflect.ValueOf(current)
+		deltaVal := reflect.ValueOf(delta)
+		result := reflect.MakeMap(ovsdb.NativeType(column))
+		iter := currentVal.MapRange()
+		for iter.Next() {
+			result.SetMapIndex(iter.Key(), iter.Value())
+		}
+		iter = deltaVal.MapRange()
+		for iter.Next() {
+			if value := result.MapIndex(iter.Key()); value.IsValid() && value.Interface() == iter.Value().Interface() {
+				// A zero Value deletes the key
+				result.SetMapIndex(iter.Key(), reflect.Value{})
+			} else {
+				result.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+		return result.Interface()
+	default:
+		return delta
+	}
+}
+
+func sliceContains(slice reflect.Value, elem reflect.Value) bool {
+	for i := 0; i < slice.Len(); i++ {
+		if slice.Index(i).Interface() == elem.Interface() {
+			return true
+		}
+	}
+	return false
+}
+
 // AddEventHandler registers the supplied EventHandler to recieve cache events
 func (t *TableCache) AddEventHandler(handler EventHandler) {
 	t.eventProcessor.AddEventHandler(handler)
 }
 
+// AddTableEventHandler registers the supplied EventHandler to recieve the cache events
+// of a single table
+func (t *TableCache) AddTableEventHandler(table string, handler EventHandler) {
+	t.eventProcessor.AddTableEventHandler(table, handler)
+}
+
+// RemoveEventHandler unregisters the supplied EventHandler, whether it was registered
+// for all tables or for a single one, so it no longer recieves cache events
+func (t *TableCache) RemoveEventHandler(handler EventHandler) {
+	t.eventProcessor.RemoveEventHandler(handler)
+}
+
 // Run starts the event processing loop. It blocks until the channel is closed.
 func (t *TableCache) Run(stopCh <-chan struct{}) {
 	t.eventProcessor.Run(stopCh)
@@ -267,10 +1217,11 @@ func (t *TableCache) Run(stopCh <-chan struct{}) {
 
 // event encapsualtes a cache event
 type event struct {
-	eventType string
-	table     string
-	old       model.Model
-	new       model.Model
+	eventType      string
+	table          string
+	old            model.Model
+	new            model.Model
+	changedColumns []string
 }
 
 // eventProcessor handles the queueing and processing of cache events
@@ -280,13 +1231,20 @@ type eventProcessor struct {
 	// we don't need a RWMutex in this case as we only have one thread reading and the write
 	// volume is very low (i.e only when AddEventHandler is called)
 	handlersMutex sync.Mutex
-	handlers      []EventHandler
+	handlers      []eventHandlerRegistration
+}
+
+// eventHandlerRegistration is an EventHandler along with the table it is registered for
+// An empty table means all tables
+type eventHandlerRegistration struct {
+	table   string
+	handler EventHandler
 }
 
 func newEventProcessor(capacity int) *eventProcessor {
 	return &eventProcessor{
 		events:   make(chan event, capacity),
-		handlers: []EventHandler{},
+		handlers: []eventHandlerRegistration{},
 	}
 }
 
@@ -297,19 +1255,55 @@ func newEventProcessor(capacity int) *eventProcessor {
 func (e *eventProcessor) AddEventHandler(handler EventHandler) {
 	e.handlersMutex.Lock()
 	defer e.handlersMutex.Unlock()
-	e.handlers = append(e.handlers, handler)
+	e.handlers = append(e.handlers, eventHandlerRegistration{handler: handler})
+}
+
+// AddTableEventHandler registers the supplied EventHandler with the eventProcessor
+// so it only recieves the events of the given table
+// The same considerations as in AddEventHandler apply
+func (e *eventProcessor) AddTableEventHandler(table string, handler EventHandler) {
+	e.handlersMutex.Lock()
+	defer e.handlersMutex.Unlock()
+	e.handlers = append(e.handlers, eventHandlerRegistration{table: table, handler: handler})
+}
+
+// RemoveEventHandler unregisters all the registrations of the supplied EventHandler
+func (e *eventProcessor) RemoveEventHandler(handler EventHandler) {
+	e.handlersMutex.Lock()
+	defer e.handlersMutex.Unlock()
+	handlers := make([]eventHandlerRegistration, 0, len(e.handlers))
+	for _, registration := range e.handlers {
+		if registration.handler != handler {
+			handlers = append(handlers, registration)
+		}
+	}
+	e.handlers = handlers
 }
 
 // AddEvent writes an event to the channel
 func (e *eventProcessor) AddEvent(eventType string, table string, old model.Model, new model.Model) {
 	// We don't need to check for error here since there
 	// is only a single writer. RPC is run in blocking mode
-	event := event{
+	e.addEvent(event{
 		eventType: eventType,
 		table:     table,
 		old:       old,
 		new:       new,
-	}
+	})
+}
+
+// AddUpdateEvent writes an update event to the channel along with the columns that were changed
+func (e *eventProcessor) AddUpdateEvent(table string, old model.Model, new model.Model, changedColumns []string) {
+	e.addEvent(event{
+		eventType:      updateEvent,
+		table:          table,
+		old:            old,
+		new:            new,
+		changedColumns: changedColumns,
+	})
+}
+
+func (e *eventProcessor) addEvent(event event) {
 	select {
 	case e.events <- event:
 		// noop
@@ -330,14 +1324,27 @@ func (e *eventProcessor) Run(stopCh <-chan struct{}) {
 			return
 		case event := <-e.events:
 			e.handlersMutex.Lock()
-			for _, handler := range e.handlers {
+			for _, registration := range e.handlers {
+				// The end of a sync concerns every table
+				if registration.table != "" && registration.table != event.table && event.eventType != syncedEvent {
+					continue
+				}
+				handler := registration.handler
 				switch event.eventType {
 				case addEvent:
 					handler.OnAdd(event.table, event.new)
 				case updateEvent:
-					handler.OnUpdate(event.table, event.old, event.new)
+					if h, ok := handler.(ChangedColumnsEventHandler); ok {
+						h.OnUpdateColumns(event.table, event.old, event.new, event.changedColumns)
+					} else {
+						handler.OnUpdate(event.table, event.old, event.new)
+					}
 				case deleteEvent:
 					handler.OnDelete(event.table, event.old)
+				case syncedEvent:
+					if h, ok := handler.(InitialSyncEventHandler); ok {
+						h.OnInitialSyncComplete()
+					}
 				}
 			}
 			e.handlersMutex.Unlock()
@@ -362,7 +1369,7 @@ func (t *TableCache) CreateModel(tableName string, row *ovsdb.Row, uuid string)
 	}
 
 	if uuid != "" {
-		mapperInfo, err := mapper.NewMapperInfo(table, model)
+		mapperInfo, err := t.mapper.NewMapperInfo(tableName, model)
 		if err != nil {
 			return nil, err
 		}
@@ -373,3 +1380,13 @@ func (t *TableCache) CreateModel(tableName string, row *ovsdb.Row, uuid string)
 
 	return model, nil
 }
+
+// decodeModel creates the model of a row received from the server and normalizes it
+func (t *TableCache) decodeModel(tableName string, row *ovsdb.Row, uuid string) (model.Model, error) {
+	m, err := t.CreateModel(tableName, row, uuid)
+	if err != nil {
+		return nil, err
+	}
+	t.normalize(tableName, m)
+	return m, nil
+}
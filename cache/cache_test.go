@@ -1,10 +1,13 @@
 package cache
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"encoding/json"
 
+	"github.com/ovn-org/libovsdb/mapper"
 	"github.com/ovn-org/libovsdb/model"
 	"github.com/ovn-org/libovsdb/ovsdb"
 	"github.com/stretchr/testify/assert"
@@ -79,6 +82,14 @@ func TestRowCache_Rows(t *testing.T) {
 	}
 }
 
+func TestRowCache_RowsSorted(t *testing.T) {
+	r := NewRowCache(map[string]model.Model{"test3": &testModel{}, "test1": &testModel{}, "test2": &testModel{}})
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, []string{"test1", "test2", "test3"}, r.RowsSorted())
+	}
+	assert.Empty(t, NewRowCache(nil).RowsSorted())
+}
+
 func TestEventHandlerFuncs_OnAdd(t *testing.T) {
 	calls := 0
 	type fields struct {
@@ -349,6 +360,135 @@ func TestTableCache_populate(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestTableCache_populateChangedColumns(t *testing.T) {
+	type testBridge struct {
+		UUID        string            `ovs:"_uuid"`
+		Name        string            `ovs:"name"`
+		ExternalIDs map[string]string `ovs:"external_ids"`
+	}
+	db, err := model.NewDBModel("Open_vSwitch", map[string]model.Model{"Bridge": &testBridge{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "TestDB",
+		  "tables": {
+		    "Bridge": {
+		      "columns": {
+		        "name": {
+			  "type": "string"
+			},
+		        "external_ids": {
+			  "type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}
+			}
+		      }
+		    }
+		 }
+	     }
+	`), &schema)
+	assert.Nil(t, err)
+	tc, err := NewTableCache(&schema, db)
+	assert.Nil(t, err)
+
+	externalIDs, err := ovsdb.NewOvsMap(map[string]string{"foo": "bar"})
+	assert.Nil(t, err)
+	testRow := ovsdb.Row(map[string]interface{}{"name": "br0", "external_ids": *externalIDs})
+	tc.Populate(ovsdb.TableUpdates{
+		"Bridge": {
+			"test": &ovsdb.RowUpdate{New: &testRow},
+		},
+	})
+	event := <-tc.eventProcessor.events
+	assert.Equal(t, addEvent, event.eventType)
+	assert.Nil(t, event.changedColumns)
+
+	updatedIDs, err := ovsdb.NewOvsMap(map[string]string{"foo": "baz"})
+	assert.Nil(t, err)
+	updatedRow := ovsdb.Row(map[string]interface{}{"name": "br0", "external_ids": *updatedIDs})
+	oldRow := ovsdb.Row(map[string]interface{}{"external_ids": *externalIDs})
+	tc.Populate(ovsdb.TableUpdates{
+		"Bridge": {
+			"test": &ovsdb.RowUpdate{Old: &oldRow, New: &updatedRow},
+		},
+	})
+	event = <-tc.eventProcessor.events
+	assert.Equal(t, updateEvent, event.eventType)
+	assert.Equal(t, []string{"external_ids"}, event.changedColumns)
+}
+
+func TestEventHandlerFuncs_OnUpdateColumns(t *testing.T) {
+	var changed []string
+	updateCalls := 0
+	e := &EventHandlerFuncs{
+		UpdateFunc: func(string, model.Model, model.Model) {
+			updateCalls++
+		},
+	}
+	// Falls back to UpdateFunc
+	e.OnUpdateColumns("testTable", &testModel{}, &testModel{Foo: "bar"}, []string{"foo"})
+	assert.Equal(t, 1, updateCalls)
+
+	e.UpdateColumnsFunc = func(_ string, _, _ model.Model, changedColumns []string) {
+		changed = changedColumns
+	}
+	e.OnUpdateColumns("testTable", &testModel{}, &testModel{Foo: "bar"}, []string{"foo"})
+	assert.Equal(t, 1, updateCalls)
+	assert.Equal(t, []string{"foo"}, changed)
+}
+
+func TestTableCache_purge(t *testing.T) {
+	db, err := model.NewDBModel("Open_vSwitch", map[string]model.Model{"Open_vSwitch": &testModel{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "TestDB",
+		  "tables": {
+		    "Open_vSwitch": {
+		      "columns": {
+		        "foo": {
+			  "type": "string"
+			}
+		      }
+		    }
+		 }
+	     }
+	`), &schema)
+	assert.Nil(t, err)
+	tc, err := NewTableCache(&schema, db)
+	assert.Nil(t, err)
+
+	tc.Set("Open_vSwitch", NewRowCache(map[string]model.Model{
+		"test1": &testModel{UUID: "test1", Foo: "bar"},
+		"test2": &testModel{UUID: "test2", Foo: "baz"},
+		"test3": &testModel{UUID: "test3", Foo: "bar"},
+	}))
+
+	t.Log("PurgeWhere")
+	tc.PurgeWhere("Open_vSwitch", func(m model.Model) bool {
+		return m.(*testModel).Foo == "bar"
+	})
+	assert.Equal(t, []string{"test2"}, tc.Table("Open_vSwitch").Rows())
+	var deleted []string
+	for i := 0; i < 2; i++ {
+		event := <-tc.eventProcessor.events
+		assert.Equal(t, deleteEvent, event.eventType)
+		assert.Equal(t, "Open_vSwitch", event.table)
+		deleted = append(deleted, event.old.(*testModel).UUID)
+	}
+	assert.ElementsMatch(t, []string{"test1", "test3"}, deleted)
+
+	t.Log("Purge")
+	tc.Purge("Open_vSwitch")
+	assert.Equal(t, 0, tc.Table("Open_vSwitch").Len())
+	event := <-tc.eventProcessor.events
+	assert.Equal(t, deleteEvent, event.eventType)
+	assert.Equal(t, &testModel{UUID: "test2", Foo: "baz"}, event.old)
+	assert.Equal(t, 0, len(tc.eventProcessor.events))
+
+	t.Log("Unknown table")
+	tc.Purge("Bridge")
+	assert.Equal(t, 0, len(tc.eventProcessor.events))
+}
+
 func TestEventProcessor_AddEvent(t *testing.T) {
 	ep := newEventProcessor(16)
 	var events []event
@@ -378,3 +518,866 @@ func TestEventProcessor_AddEvent(t *testing.T) {
 	// assert channel is empty
 	assert.Equal(t, 0, len(ep.events))
 }
+
+func TestTableCache_populate2(t *testing.T) {
+	type testBridge struct {
+		UUID        string            `ovs:"_uuid"`
+		Name        string            `ovs:"name"`
+		Ports       []string          `ovs:"ports"`
+		ExternalIDs map[string]string `ovs:"external_ids"`
+	}
+	db, err := model.NewDBModel("Open_vSwitch", map[string]model.Model{"Bridge": &testBridge{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "Open_vSwitch",
+		  "tables": {
+		    "Bridge": {
+		      "columns": {
+		        "name": {"type": "string"},
+		        "ports": {"type": {"key": {"type": "uuid"}, "min": 0, "max": "unlimited"}},
+		        "external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+		      }
+		    }
+		  }
+		 }
+	`), &schema)
+	assert.Nil(t, err)
+	tc, err := NewTableCache(&schema, db)
+	assert.Nil(t, err)
+
+	const (
+		brUUID = "2f77b348-9768-4866-b761-89d5177ecda0"
+		port0  = "2f77b348-9768-4866-b761-89d5177ecda1"
+		port1  = "2f77b348-9768-4866-b761-89d5177ecda2"
+		port2  = "2f77b348-9768-4866-b761-89d5177ecda3"
+	)
+	populate := func(payload string) {
+		var updates ovsdb.TableUpdates2
+		err := json.Unmarshal([]byte(payload), &updates)
+		assert.Nil(t, err)
+		tc.Populate2(updates)
+	}
+
+	t.Log("Initial")
+	populate(`{"Bridge": {"` + brUUID + `": {"initial": {
+		"name": "br0",
+		"ports": ["set", [["uuid", "` + port0 + `"], ["uuid", "` + port1 + `"]]],
+		"external_ids": ["map", [["foo", "bar"], ["baz", "quux"]]]}}}}`)
+	assert.Equal(t, &testBridge{
+		UUID:        brUUID,
+		Name:        "br0",
+		Ports:       []string{port0, port1},
+		ExternalIDs: map[string]string{"foo": "bar", "baz": "quux"},
+	}, tc.Table("Bridge").Row(brUUID))
+	event := <-tc.eventProcessor.events
+	assert.Equal(t, addEvent, event.eventType)
+
+	t.Log("Modify")
+	// port0 is removed and port2 is added
+	// foo is updated, baz is removed and new is added
+	populate(`{"Bridge": {"` + brUUID + `": {"modify": {
+		"ports": ["set", [["uuid", "` + port0 + `"], ["uuid", "` + port2 + `"]]],
+		"external_ids": ["map", [["foo", "bar2"], ["baz", "quux"], ["new", "value"]]]}}}}`)
+	assert.Equal(t, &testBridge{
+		UUID:        brUUID,
+		Name:        "br0",
+		Ports:       []string{port1, port2},
+		ExternalIDs: map[string]string{"foo": "bar2", "new": "value"},
+	}, tc.Table("Bridge").Row(brUUID))
+	event = <-tc.eventProcessor.events
+	assert.Equal(t, updateEvent, event.eventType)
+	assert.Equal(t, []string{"external_ids", "ports"}, event.changedColumns)
+
+	t.Log("Modify single element set and scalar")
+	populate(`{"Bridge": {"` + brUUID + `": {"modify": {
+		"name": "br1",
+		"ports": ["uuid", "` + port1 + `"]}}}}`)
+	assert.Equal(t, &testBridge{
+		UUID:        brUUID,
+		Name:        "br1",
+		Ports:       []string{port2},
+		ExternalIDs: map[string]string{"foo": "bar2", "new": "value"},
+	}, tc.Table("Bridge").Row(brUUID))
+	event = <-tc.eventProcessor.events
+	assert.Equal(t, []string{"name", "ports"}, event.changedColumns)
+
+	t.Log("Delete")
+	populate(`{"Bridge": {"` + brUUID + `": {"delete": null}}}`)
+	assert.Nil(t, tc.Table("Bridge").Row(brUUID))
+	event = <-tc.eventProcessor.events
+	assert.Equal(t, deleteEvent, event.eventType)
+}
+
+func TestTableCache_populate2AbsentColumns(t *testing.T) {
+	type testBridge struct {
+		UUID        string            `ovs:"_uuid"`
+		Name        string            `ovs:"name"`
+		Datapath    *string           `ovs:"datapath_type"`
+		Priority    int               `ovs:"priority"`
+		Ports       []string          `ovs:"ports"`
+		ExternalIDs map[string]string `ovs:"external_ids"`
+	}
+	db, err := model.NewDBModel("Open_vSwitch", map[string]model.Model{"Bridge": &testBridge{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "Open_vSwitch",
+		  "tables": {
+		    "Bridge": {
+		      "columns": {
+		        "name": {"type": "string"},
+		        "datapath_type": {"type": {"key": "string", "min": 0, "max": 1}},
+		        "priority": {"type": "integer"},
+		        "ports": {"type": {"key": {"type": "uuid"}, "min": 0, "max": "unlimited"}},
+		        "external_ids": {"type": {"key": "string", "value": "string", "min": 0, "max": "unlimited"}}
+		      }
+		    }
+		  }
+		 }
+	`), &schema)
+	assert.Nil(t, err)
+	tc, err := NewTableCache(&schema, db)
+	assert.Nil(t, err)
+
+	const (
+		brUUID = "2f77b348-9768-4866-b761-89d5177ecda0"
+		port0  = "2f77b348-9768-4866-b761-89d5177ecda1"
+	)
+	populate := func(payload string) {
+		var updates ovsdb.TableUpdates2
+		err := json.Unmarshal([]byte(payload), &updates)
+		assert.Nil(t, err)
+		tc.Populate2(updates)
+	}
+	populate(`{"Bridge": {"` + brUUID + `": {"initial": {
+		"name": "br0",
+		"datapath_type": "",
+		"priority": 10,
+		"ports": ["uuid", "` + port0 + `"],
+		"external_ids": ["map", [["foo", "bar"]]]}}}}`)
+	datapath := ""
+	expected := &testBridge{
+		UUID:        brUUID,
+		Name:        "br0",
+		Datapath:    &datapath,
+		Priority:    10,
+		Ports:       []string{port0},
+		ExternalIDs: map[string]string{"foo": "bar"},
+	}
+	assert.Equal(t, expected, tc.Table("Bridge").Row(brUUID))
+
+	// Only the columns present in the modify are changed
+	populate(`{"Bridge": {"` + brUUID + `": {"modify": {"external_ids": ["map", [["new", "value"]]]}}}}`)
+	expected.ExternalIDs = map[string]string{"foo": "bar", "new": "value"}
+	assert.Equal(t, expected, tc.Table("Bridge").Row(brUUID))
+
+	// Columns present in the modify are changed even to their default value
+	populate(`{"Bridge": {"` + brUUID + `": {"modify": {"name": "", "priority": 0, "datapath_type": ["set", [""]]}}}}`)
+	expected.Name = ""
+	expected.Priority = 0
+	expected.Datapath = nil
+	assert.Equal(t, expected, tc.Table("Bridge").Row(brUUID))
+}
+
+func TestTableCache_ApplyOptimistic(t *testing.T) {
+	type testBridge struct {
+		UUID  string   `ovs:"_uuid"`
+		Name  string   `ovs:"name"`
+		Ports []string `ovs:"ports"`
+	}
+	db, err := model.NewDBModel("Open_vSwitch", map[string]model.Model{"Bridge": &testBridge{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "Open_vSwitch",
+		  "tables": {
+		    "Bridge": {
+		      "columns": {
+		        "name": {"type": "string"},
+		        "ports": {"type": {"key": {"type": "uuid"}, "min": 0, "max": "unlimited"}}
+		      }
+		    }
+		  }
+		 }
+	`), &schema)
+	assert.Nil(t, err)
+
+	const (
+		br0   = "2f77b348-9768-4866-b761-89d5177ecda0"
+		br1   = "2f77b348-9768-4866-b761-89d5177ecda1"
+		port0 = "2f77b348-9768-4866-b761-89d5177ecda2"
+		port1 = "2f77b348-9768-4866-b761-89d5177ecda3"
+	)
+	newCache := func() *TableCache {
+		tc, err := NewTableCache(&schema, db)
+		assert.Nil(t, err)
+		populate2(t, tc, `{"Bridge": {"`+br0+`": {"initial": {
+			"name": "br0",
+			"ports": ["uuid", "`+port0+`"]}}}}`)
+		<-tc.eventProcessor.events
+		return tc
+	}
+
+	t.Run("ApplyOptimistic: patch and add rows", func(t *testing.T) {
+		tc := newCache()
+		err := tc.ApplyOptimistic("Bridge", br0, &testBridge{UUID: br0, Name: "br0", Ports: []string{port0, port1}})
+		assert.Nil(t, err)
+		event := <-tc.eventProcessor.events
+		assert.Equal(t, updateEvent, event.eventType)
+		assert.Equal(t, []string{"ports"}, event.changedColumns)
+		err = tc.ApplyOptimistic("Bridge", br1, &testBridge{UUID: br1, Name: "br1"})
+		assert.Nil(t, err)
+		event = <-tc.eventProcessor.events
+		assert.Equal(t, addEvent, event.eventType)
+		assert.True(t, tc.IsOptimistic("Bridge", br0))
+		assert.True(t, tc.IsOptimistic("Bridge", br1))
+
+		// The update of the server is applied to the row it had before the patch
+		populate2(t, tc, `{"Bridge": {
+			"`+br0+`": {"modify": {"ports": ["uuid", "`+port1+`"]}},
+			"`+br1+`": {"insert": {"name": "br1"}}}}`)
+		assert.Equal(t, &testBridge{UUID: br0, Name: "br0", Ports: []string{port0, port1}}, tc.Table("Bridge").Row(br0))
+		assert.Equal(t, &testBridge{UUID: br1, Name: "br1"}, tc.Table("Bridge").Row(br1))
+		assert.False(t, tc.IsOptimistic("Bridge", br0))
+		assert.False(t, tc.IsOptimistic("Bridge", br1))
+		// The rows already held the view of the server
+		assert.Len(t, tc.eventProcessor.events, 0)
+	})
+
+	t.Run("ApplyOptimistic: the server overwrites conflicting rows", func(t *testing.T) {
+		tc := newCache()
+		err := tc.ApplyOptimistic("Bridge", br0, &testBridge{UUID: br0, Name: "br2", Ports: []string{port0}})
+		assert.Nil(t, err)
+		<-tc.eventProcessor.events
+		populate2(t, tc, `{"Bridge": {"`+br0+`": {"modify": {"name": "br3"}}}}`)
+		assert.Equal(t, &testBridge{UUID: br0, Name: "br3", Ports: []string{port0}}, tc.Table("Bridge").Row(br0))
+		event := <-tc.eventProcessor.events
+		assert.Equal(t, updateEvent, event.eventType)
+		assert.Equal(t, []string{"name"}, event.changedColumns)
+		assert.False(t, tc.IsOptimistic("Bridge", br0))
+	})
+
+	t.Run("ApplyOptimistic: delete rows", func(t *testing.T) {
+		tc := newCache()
+		err := tc.ApplyOptimistic("Bridge", br0, nil)
+		assert.Nil(t, err)
+		event := <-tc.eventProcessor.events
+		assert.Equal(t, deleteEvent, event.eventType)
+		assert.Nil(t, tc.Table("Bridge").Row(br0))
+		assert.True(t, tc.IsOptimistic("Bridge", br0))
+		populate2(t, tc, `{"Bridge": {"`+br0+`": {"delete": null}}}`)
+		assert.Nil(t, tc.Table("Bridge").Row(br0))
+		assert.False(t, tc.IsOptimistic("Bridge", br0))
+		assert.Len(t, tc.eventProcessor.events, 0)
+	})
+
+	t.Run("RevertOptimistic", func(t *testing.T) {
+		tc := newCache()
+		err := tc.ApplyOptimistic("Bridge", br0, &testBridge{UUID: br0, Name: "br2"})
+		assert.Nil(t, err)
+		err = tc.ApplyOptimistic("Bridge", br0, &testBridge{UUID: br0, Name: "br3"})
+		assert.Nil(t, err)
+		err = tc.ApplyOptimistic("Bridge", br1, &testBridge{UUID: br1, Name: "br1"})
+		assert.Nil(t, err)
+		for i := 0; i < 3; i++ {
+			<-tc.eventProcessor.events
+		}
+		tc.RevertOptimistic()
+		assert.Equal(t, &testBridge{UUID: br0, Name: "br0", Ports: []string{port0}}, tc.Table("Bridge").Row(br0))
+		assert.Nil(t, tc.Table("Bridge").Row(br1))
+		assert.False(t, tc.IsOptimistic("Bridge", br0))
+		assert.False(t, tc.IsOptimistic("Bridge", br1))
+		assert.Len(t, tc.eventProcessor.events, 2)
+	})
+
+	t.Run("ApplyOptimistic: unknown table", func(t *testing.T) {
+		tc := newCache()
+		err := tc.ApplyOptimistic("Port", port0, nil)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestTableCache_weakReferenceCleanup(t *testing.T) {
+	type testBridge struct {
+		UUID    string            `ovs:"_uuid"`
+		Name    string            `ovs:"name"`
+		Mirrors []string          `ovs:"mirrors"`
+		Ports   []string          `ovs:"ports"`
+		Flows   map[string]string `ovs:"flows"`
+	}
+	type testMirror struct {
+		UUID string `ovs:"_uuid"`
+		Name string `ovs:"name"`
+	}
+	db, err := model.NewDBModel("Open_vSwitch", map[string]model.Model{"Bridge": &testBridge{}, "Mirror": &testMirror{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "Open_vSwitch",
+		  "tables": {
+		    "Bridge": {
+		      "columns": {
+		        "name": {"type": "string"},
+		        "mirrors": {"type": {"key": {"type": "uuid", "refTable": "Mirror", "refType": "weak"}, "min": 0, "max": "unlimited"}},
+		        "ports": {"type": {"key": {"type": "uuid", "refTable": "Mirror"}, "min": 0, "max": "unlimited"}},
+		        "flows": {"type": {"key": "string", "value": {"type": "uuid", "refTable": "Mirror", "refType": "weak"}, "min": 0, "max": "unlimited"}}
+		      }
+		    },
+		    "Mirror": {
+		      "columns": {
+		        "name": {"type": "string"}
+		      }
+		    }
+		  }
+		 }
+	`), &schema)
+	assert.Nil(t, err)
+
+	const (
+		brUUID  = "2f77b348-9768-4866-b761-89d5177ecda0"
+		mirror0 = "2f77b348-9768-4866-b761-89d5177ecda1"
+		mirror1 = "2f77b348-9768-4866-b761-89d5177ecda2"
+	)
+	initial := `{
+		"Mirror": {
+			"` + mirror0 + `": {"initial": {"name": "mirror0"}},
+			"` + mirror1 + `": {"initial": {"name": "mirror1"}}},
+		"Bridge": {"` + brUUID + `": {"initial": {
+			"name": "br0",
+			"mirrors": ["set", [["uuid", "` + mirror0 + `"], ["uuid", "` + mirror1 + `"]]],
+			"ports": ["set", [["uuid", "` + mirror0 + `"]]],
+			"flows": ["map", [["a", ["uuid", "` + mirror0 + `"]], ["b", ["uuid", "` + mirror1 + `"]]]]}}}}`
+	newCache := func(cleanup bool) *TableCache {
+		tc, err := NewTableCache(&schema, db)
+		assert.Nil(t, err)
+		tc.SetWeakReferenceCleanup(cleanup)
+		populate2(t, tc, initial)
+		for len(tc.eventProcessor.events) > 0 {
+			<-tc.eventProcessor.events
+		}
+		return tc
+	}
+	original := &testBridge{
+		UUID:    brUUID,
+		Name:    "br0",
+		Mirrors: []string{mirror0, mirror1},
+		Ports:   []string{mirror0},
+		Flows:   map[string]string{"a": mirror0, "b": mirror1},
+	}
+	cleaned := &testBridge{
+		UUID:    brUUID,
+		Name:    "br0",
+		Mirrors: []string{mirror1},
+		Ports:   []string{mirror0},
+		Flows:   map[string]string{"b": mirror1},
+	}
+	assertCleaned := func(tc *TableCache) {
+		assert.Equal(t, cleaned, tc.Table("Bridge").Row(brUUID))
+		assert.Len(t, tc.eventProcessor.events, 2)
+		// The tables of an update are applied in no particular order, so the update of the
+		// referencing row may precede the deletion
+		event := <-tc.eventProcessor.events
+		if event.eventType != updateEvent {
+			event = <-tc.eventProcessor.events
+		}
+		assert.Equal(t, updateEvent, event.eventType)
+		assert.Equal(t, original, event.old)
+		assert.Equal(t, cleaned, event.new)
+		assert.ElementsMatch(t, []string{"mirrors", "flows"}, event.changedColumns)
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		tc := newCache(false)
+		populate2(t, tc, `{"Mirror": {"`+mirror0+`": {"delete": {}}}}`)
+		assert.Equal(t, original, tc.Table("Bridge").Row(brUUID))
+		assert.Len(t, tc.eventProcessor.events, 1)
+	})
+
+	t.Run("Populate", func(t *testing.T) {
+		tc := newCache(true)
+		old := ovsdb.Row{"name": "mirror0"}
+		tc.Populate(ovsdb.TableUpdates{"Mirror": {mirror0: &ovsdb.RowUpdate{Old: &old}}})
+		assertCleaned(tc)
+	})
+
+	t.Run("Populate2", func(t *testing.T) {
+		tc := newCache(true)
+		populate2(t, tc, `{"Mirror": {"`+mirror0+`": {"delete": {}}}}`)
+		assertCleaned(tc)
+	})
+
+	t.Run("Populate2 with the update of the referencing row", func(t *testing.T) {
+		tc := newCache(true)
+		populate2(t, tc, `{
+			"Mirror": {"`+mirror0+`": {"delete": {}}},
+			"Bridge": {"`+brUUID+`": {"modify": {
+				"mirrors": ["uuid", "`+mirror0+`"],
+				"flows": ["map", [["a", ["uuid", "`+mirror0+`"]]]]}}}}`)
+		assertCleaned(tc)
+	})
+
+	t.Run("PurgeWhere", func(t *testing.T) {
+		tc := newCache(true)
+		tc.PurgeWhere("Mirror", func(m model.Model) bool {
+			return m.(*testMirror).Name == "mirror0"
+		})
+		assertCleaned(tc)
+	})
+}
+
+// populate2 populates a cache with update2 notifications given in JSON
+func populate2(t *testing.T, tc *TableCache, payload string) {
+	var updates ovsdb.TableUpdates2
+	err := json.Unmarshal([]byte(payload), &updates)
+	assert.Nil(t, err)
+	tc.Populate2(updates)
+}
+
+func TestEventProcessor_TableEventHandlers(t *testing.T) {
+	ep := newEventProcessor(16)
+	var all, bridges []string
+	// allHandler is registered last, so once it is called, the event has been dispatched
+	dispatched := make(chan struct{})
+	allHandler := &EventHandlerFuncs{
+		AddFunc: func(table string, m model.Model) {
+			all = append(all, table)
+			dispatched <- struct{}{}
+		},
+	}
+	bridgeHandler := &EventHandlerFuncs{
+		AddFunc: func(table string, m model.Model) {
+			bridges = append(bridges, table)
+		},
+	}
+	ep.AddTableEventHandler("Bridge", bridgeHandler)
+	ep.AddEventHandler(allHandler)
+
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		ep.Run(stopCh)
+		close(done)
+	}()
+	waitForEvent := func() {
+		select {
+		case <-dispatched:
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for event")
+		}
+	}
+
+	ep.AddEvent(addEvent, "Bridge", nil, &testModel{})
+	ep.AddEvent(addEvent, "Port", nil, &testModel{})
+	waitForEvent()
+	waitForEvent()
+	ep.RemoveEventHandler(bridgeHandler)
+	ep.AddEvent(addEvent, "Bridge", nil, &testModel{})
+	waitForEvent()
+	close(stopCh)
+	<-done
+
+	assert.Equal(t, []string{"Bridge", "Port", "Bridge"}, all)
+	assert.Equal(t, []string{"Bridge"}, bridges)
+}
+
+func TestTableCache_Dump(t *testing.T) {
+	db, err := model.NewDBModel("Open_vSwitch", map[string]model.Model{"Open_vSwitch": &testModel{}, "Bridge": &testModel{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "TestDB",
+		  "tables": {
+		    "Open_vSwitch": {
+		      "columns": {
+		        "foo": {
+			  "type": "string"
+			}
+		      }
+		    },
+		    "Bridge": {
+		      "columns": {
+		        "foo": {
+			  "type": "string"
+			}
+		      }
+		    }
+		 }
+	     }
+	`), &schema)
+	assert.Nil(t, err)
+	tc, err := NewTableCache(&schema, db)
+	assert.Nil(t, err)
+	tc.Set("Open_vSwitch", NewRowCache(map[string]model.Model{
+		"test2": &testModel{UUID: "test2", Foo: "baz"},
+		"test1": &testModel{UUID: "test1", Foo: "bar"},
+	}))
+	tc.Set("Bridge", NewRowCache(map[string]model.Model{
+		"br1": &testModel{UUID: "br1", Foo: "br"},
+	}))
+
+	dump, err := tc.Dump("Open_vSwitch")
+	assert.Nil(t, err)
+	assert.Equal(t, `Open_vSwitch:
+  test1:
+    foo: bar
+  test2:
+    foo: baz
+`, dump)
+
+	dump, err = tc.DumpAll()
+	assert.Nil(t, err)
+	assert.Equal(t, `Bridge:
+  br1:
+    foo: br
+Open_vSwitch:
+  test1:
+    foo: bar
+  test2:
+    foo: baz
+`, dump)
+
+	_, err = tc.Dump("Unknown")
+	assert.NotNil(t, err)
+}
+
+func TestRowCache_LRU(t *testing.T) {
+	r := NewLRURowCache(2)
+	r.Set("test1", &testModel{UUID: "test1"})
+	r.Set("test2", &testModel{UUID: "test2"})
+	// Reading test1 makes test2 the least recently used row
+	assert.NotNil(t, r.Row("test1"))
+	r.Set("test3", &testModel{UUID: "test3"})
+	assert.Equal(t, 2, r.Len())
+	assert.Nil(t, r.Row("test2"))
+	assert.ElementsMatch(t, []string{"test1", "test3"}, r.Rows())
+
+	// Writing an existing row updates its recency without evicting
+	r.Set("test1", &testModel{UUID: "test1", Foo: "bar"})
+	r.Set("test4", &testModel{UUID: "test4"})
+	assert.ElementsMatch(t, []string{"test1", "test4"}, r.Rows())
+	assert.Equal(t, &testModel{UUID: "test1", Foo: "bar"}, r.Row("test1"))
+}
+
+func TestTableCache_SetLRU(t *testing.T) {
+	db, err := model.NewDBModel("Open_vSwitch", map[string]model.Model{"Open_vSwitch": &testModel{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "TestDB",
+		  "tables": {
+		    "Open_vSwitch": {
+		      "columns": {
+		        "foo": {
+			  "type": "string"
+			}
+		      }
+		    }
+		 }
+	     }
+	`), &schema)
+	assert.Nil(t, err)
+	tc, err := NewTableCache(&schema, db)
+	assert.Nil(t, err)
+
+	assert.NotNil(t, tc.SetLRU("Unknown", 2))
+	assert.NotNil(t, tc.SetLRU("Open_vSwitch", 0))
+
+	tc.Set("Open_vSwitch", NewRowCache(map[string]model.Model{
+		"test1": &testModel{UUID: "test1", Foo: "bar"},
+	}))
+	assert.Nil(t, tc.SetLRU("Open_vSwitch", 2))
+	assert.Equal(t, []string{"test1"}, tc.Table("Open_vSwitch").Rows())
+
+	updates := ovsdb.TableUpdates{"Open_vSwitch": {}}
+	for _, uuid := range []string{"test2", "test3"} {
+		row := ovsdb.Row{"_uuid": uuid, "foo": "baz"}
+		updates["Open_vSwitch"][uuid] = &ovsdb.RowUpdate{New: &row}
+	}
+	tc.Populate(updates)
+	assert.Equal(t, 2, tc.Table("Open_vSwitch").Len())
+	assert.ElementsMatch(t, []string{"test2", "test3"}, tc.Table("Open_vSwitch").Rows())
+
+	// Deleting a row frees its entry
+	old := ovsdb.Row{"_uuid": "test2", "foo": "baz"}
+	tc.Populate(ovsdb.TableUpdates{"Open_vSwitch": {"test2": &ovsdb.RowUpdate{Old: &old}}})
+	assert.Equal(t, []string{"test3"}, tc.Table("Open_vSwitch").Rows())
+}
+
+func TestTableCache_Metadata(t *testing.T) {
+	db, err := model.NewDBModel("Open_vSwitch", map[string]model.Model{"Open_vSwitch": &testModel{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "TestDB",
+		  "tables": {
+		    "Open_vSwitch": {
+		      "columns": {
+		        "foo": {
+			  "type": "string"
+			}
+		      }
+		    }
+		 }
+	     }
+	`), &schema)
+	assert.Nil(t, err)
+	tc, err := NewTableCache(&schema, db)
+	assert.Nil(t, err)
+
+	assert.NotNil(t, tc.SetMetadata("Open_vSwitch", "test1", "hash"))
+
+	row := ovsdb.Row{"_uuid": "test1", "foo": "bar"}
+	tc.Populate(ovsdb.TableUpdates{"Open_vSwitch": {"test1": &ovsdb.RowUpdate{New: &row}}})
+	assert.NotNil(t, tc.SetMetadata("Open_vSwitch", "test2", "hash"))
+	assert.Nil(t, tc.SetMetadata("Open_vSwitch", "test1", "hash1"))
+	value, ok := tc.GetMetadata("Open_vSwitch", "test1")
+	assert.True(t, ok)
+	assert.Equal(t, "hash1", value)
+
+	// Updates keep the metadata
+	updated := ovsdb.Row{"_uuid": "test1", "foo": "baz"}
+	tc.Populate(ovsdb.TableUpdates{"Open_vSwitch": {"test1": &ovsdb.RowUpdate{Old: &row, New: &updated}}})
+	value, ok = tc.GetMetadata("Open_vSwitch", "test1")
+	assert.True(t, ok)
+	assert.Equal(t, "hash1", value)
+
+	// Deletes clear it
+	tc.Populate(ovsdb.TableUpdates{"Open_vSwitch": {"test1": &ovsdb.RowUpdate{Old: &updated}}})
+	_, ok = tc.GetMetadata("Open_vSwitch", "test1")
+	assert.False(t, ok)
+
+	// A row inserted again does not get the metadata back
+	tc.Populate(ovsdb.TableUpdates{"Open_vSwitch": {"test1": &ovsdb.RowUpdate{New: &row}}})
+	_, ok = tc.GetMetadata("Open_vSwitch", "test1")
+	assert.False(t, ok)
+
+	// Neither do purged rows
+	assert.Nil(t, tc.SetMetadata("Open_vSwitch", "test1", "hash2"))
+	tc.Purge("Open_vSwitch")
+	_, ok = tc.GetMetadata("Open_vSwitch", "test1")
+	assert.False(t, ok)
+	_, ok = tc.GetMetadata("Unknown", "test1")
+	assert.False(t, ok)
+}
+
+func TestTableCache_SetNormalizer(t *testing.T) {
+	db, err := model.NewDBModel("Open_vSwitch", map[string]model.Model{"Open_vSwitch": &testModel{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "TestDB",
+		  "tables": {
+		    "Open_vSwitch": {
+		      "columns": {
+		        "foo": {
+			  "type": "string"
+			}
+		      }
+		    }
+		 }
+	     }
+	`), &schema)
+	assert.Nil(t, err)
+	tc, err := NewTableCache(&schema, db)
+	assert.Nil(t, err)
+	tc.SetNormalizer("Open_vSwitch", func(m model.Model) {
+		m.(*testModel).Foo = strings.ToLower(m.(*testModel).Foo)
+	})
+
+	row := ovsdb.Row{"_uuid": "test1", "foo": "BAR"}
+	tc.Populate(ovsdb.TableUpdates{"Open_vSwitch": {"test1": &ovsdb.RowUpdate{New: &row}}})
+	assert.Equal(t, &testModel{UUID: "test1", Foo: "bar"}, tc.Table("Open_vSwitch").Row("test1"))
+
+	// Updates that only differ in what is normalized are not changes
+	updated := ovsdb.Row{"_uuid": "test1", "foo": "Bar"}
+	tc.Populate(ovsdb.TableUpdates{"Open_vSwitch": {"test1": &ovsdb.RowUpdate{Old: &row, New: &updated}}})
+	assert.Equal(t, &testModel{UUID: "test1", Foo: "bar"}, tc.Table("Open_vSwitch").Row("test1"))
+	assert.Len(t, tc.eventProcessor.events, 1)
+
+	// update2 modifications are normalized too
+	tc.Populate2(ovsdb.TableUpdates2{"Open_vSwitch": {"test1": &ovsdb.RowUpdate2{Modify: &ovsdb.Row{"foo": "BAZ"}}}})
+	assert.Equal(t, &testModel{UUID: "test1", Foo: "baz"}, tc.Table("Open_vSwitch").Row("test1"))
+	tc.Populate2(ovsdb.TableUpdates2{"Open_vSwitch": {"test2": &ovsdb.RowUpdate2{Insert: &ovsdb.Row{"foo": "QUX"}}}})
+	assert.Equal(t, &testModel{UUID: "test2", Foo: "qux"}, tc.Table("Open_vSwitch").Row("test2"))
+
+	// Only the rows received from the server are normalized
+	m, err := tc.CreateModel("Open_vSwitch", &ovsdb.Row{"foo": "QUX"}, "test4")
+	assert.Nil(t, err)
+	assert.Equal(t, &testModel{UUID: "test4", Foo: "QUX"}, m)
+
+	// Removing the normalizer stores the models as decoded
+	tc.SetNormalizer("Open_vSwitch", nil)
+	tc.Populate2(ovsdb.TableUpdates2{"Open_vSwitch": {"test3": &ovsdb.RowUpdate2{Insert: &ovsdb.Row{"foo": "QUUX"}}}})
+	assert.Equal(t, &testModel{UUID: "test3", Foo: "QUUX"}, tc.Table("Open_vSwitch").Row("test3"))
+}
+
+func TestTableCache_Reindex(t *testing.T) {
+	db, err := model.NewDBModel("Open_vSwitch", map[string]model.Model{"Open_vSwitch": &testModel{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "TestDB",
+		  "tables": {
+		    "Open_vSwitch": {
+		      "columns": {
+		        "foo": {
+			  "type": "string"
+			}
+		      },
+		      "indexes": [["foo"]]
+		    }
+		 }
+	     }
+	`), &schema)
+	assert.Nil(t, err)
+	tc, err := NewTableCache(&schema, db)
+	assert.Nil(t, err)
+
+	// Rows written by updates are indexed
+	row1 := ovsdb.Row{"_uuid": "test1", "foo": "bar"}
+	row2 := ovsdb.Row{"_uuid": "test2", "foo": "baz"}
+	tc.Populate(ovsdb.TableUpdates{"Open_vSwitch": {
+		"test1": &ovsdb.RowUpdate{New: &row1},
+		"test2": &ovsdb.RowUpdate{New: &row2},
+	}})
+	m, err := tc.Table("Open_vSwitch").RowByIndex(&testModel{Foo: "bar"})
+	assert.Nil(t, err)
+	assert.Equal(t, &testModel{UUID: "test1", Foo: "bar"}, m)
+	m, err = tc.Table("Open_vSwitch").RowByIndex(&testModel{UUID: "test2"})
+	assert.Nil(t, err)
+	assert.Equal(t, &testModel{UUID: "test2", Foo: "baz"}, m)
+
+	// Swapping the indexed values keeps the indexes consistent
+	tc.Populate2(ovsdb.TableUpdates2{"Open_vSwitch": {
+		"test1": &ovsdb.RowUpdate2{Modify: &ovsdb.Row{"foo": "baz"}},
+		"test2": &ovsdb.RowUpdate2{Modify: &ovsdb.Row{"foo": "bar"}},
+	}})
+	m, err = tc.Table("Open_vSwitch").RowByIndex(&testModel{Foo: "bar"})
+	assert.Nil(t, err)
+	assert.Equal(t, &testModel{UUID: "test2", Foo: "bar"}, m)
+	m, err = tc.Table("Open_vSwitch").RowByIndex(&testModel{Foo: "baz"})
+	assert.Nil(t, err)
+	assert.Equal(t, &testModel{UUID: "test1", Foo: "baz"}, m)
+
+	// Deleted rows are no longer indexed
+	tc.Populate2(ovsdb.TableUpdates2{"Open_vSwitch": {"test1": &ovsdb.RowUpdate2{Delete: &ovsdb.Row{}}}})
+	m, err = tc.Table("Open_vSwitch").RowByIndex(&testModel{Foo: "baz"})
+	assert.Nil(t, err)
+	assert.Nil(t, m)
+
+	// Bulk-loaded rows are indexed
+	tc.Set("Open_vSwitch", NewRowCache(map[string]model.Model{
+		"test3": &testModel{UUID: "test3", Foo: "qux"},
+		"test4": &testModel{UUID: "test4", Foo: "quux"},
+	}))
+	m, err = tc.Table("Open_vSwitch").RowByIndex(&testModel{Foo: "qux"})
+	assert.Nil(t, err)
+	assert.Equal(t, &testModel{UUID: "test3", Foo: "qux"}, m)
+	m, err = tc.Table("Open_vSwitch").RowByIndex(&testModel{Foo: "bar"})
+	assert.Nil(t, err)
+	assert.Nil(t, m)
+
+	// Reindexing recovers from inconsistent indexes
+	rc := tc.Table("Open_vSwitch")
+	rc.indexes[indexName([]string{"foo"})][`["quux"]`] = map[string]bool{"test3": true}
+	err = tc.ReindexTable("Open_vSwitch")
+	assert.Nil(t, err)
+	m, err = rc.RowByIndex(&testModel{Foo: "quux"})
+	assert.Nil(t, err)
+	assert.Equal(t, &testModel{UUID: "test4", Foo: "quux"}, m)
+	err = tc.Reindex()
+	assert.Nil(t, err)
+
+	// Rows holding the same index values, in an inconsistent cache, are all indexed
+	rc.Set("test5", &testModel{UUID: "test5", Foo: "quux"})
+	uuids, err := rc.RowsByIndexKey(mapper.IndexKey{Columns: []string{"foo"}, Key: `["quux"]`})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test4", "test5"}, uuids)
+	rc.Set("test4", &testModel{UUID: "test4", Foo: "corge"})
+	uuids, err = rc.RowsByIndexKey(mapper.IndexKey{Columns: []string{"foo"}, Key: `["quux"]`})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"test5"}, uuids)
+	_, err = NewRowCache(nil).RowsByIndexKey(mapper.IndexKey{Columns: []string{"foo"}, Key: `["quux"]`})
+	assert.NotNil(t, err)
+
+	err = tc.ReindexTable("Bridge")
+	assert.NotNil(t, err)
+}
+
+func TestTableCache_InitialSync(t *testing.T) {
+	db, err := model.NewDBModel("Open_vSwitch", map[string]model.Model{"Open_vSwitch": &testModel{}})
+	assert.Nil(t, err)
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal([]byte(`
+		 {"name": "Open_vSwitch",
+		  "tables": {
+		    "Open_vSwitch": {
+		      "columns": {
+		        "foo": {"type": "string"}
+		      }
+		    }
+		  }
+	 }
+	`), &schema)
+	assert.Nil(t, err)
+	tc, err := NewTableCache(&schema, db)
+	assert.Nil(t, err)
+	row0 := "0d7a2bc2-7bbe-4a4c-9a4a-3ad2a5d1ac5a"
+	row1 := "ad0f5bc3-8e54-4c7b-8f14-6d3b5f3f3e0f"
+	assert.False(t, tc.Syncing())
+
+	tc.StartInitialSync()
+	tc.StartInitialSync()
+	assert.True(t, tc.Syncing())
+	populate2(t, tc, `{"Open_vSwitch": {"`+row0+`": {"initial": {"foo": "bar"}}}}`)
+	tc.CompleteInitialSync()
+	assert.True(t, tc.Syncing())
+	populate2(t, tc, `{"Open_vSwitch": {"`+row1+`": {"initial": {"foo": "baz"}}}}`)
+	tc.CompleteInitialSync()
+	assert.False(t, tc.Syncing())
+
+	// The end of the sync is notified once, after the events of the rows
+	var events []string
+	for len(tc.eventProcessor.events) > 0 {
+		events = append(events, (<-tc.eventProcessor.events).eventType)
+	}
+	assert.Equal(t, []string{addEvent, addEvent, syncedEvent}, events)
+
+	tc.CompleteInitialSync()
+	assert.False(t, tc.Syncing())
+	assert.Len(t, tc.eventProcessor.events, 0)
+}
+
+func TestEventProcessor_InitialSyncComplete(t *testing.T) {
+	ep := newEventProcessor(16)
+	synced := make(chan string, 2)
+	ep.AddTableEventHandler("Bridge", &EventHandlerFuncs{
+		InitialSyncCompleteFunc: func() { synced <- "Bridge" },
+	})
+	ep.AddEventHandler(&EventHandlerFuncs{
+		InitialSyncCompleteFunc: func() { synced <- "all" },
+	})
+	// Handlers that do not implement InitialSyncEventHandler are skipped
+	ep.AddEventHandler(&struct{ EventHandler }{})
+
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		ep.Run(stopCh)
+		close(done)
+	}()
+	ep.addEvent(event{eventType: syncedEvent})
+	var got []string
+	for i := 0; i < 2; i++ {
+		select {
+		case table := <-synced:
+			got = append(got, table)
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the end of the sync")
+		}
+	}
+	close(stopCh)
+	<-done
+	assert.Equal(t, []string{"Bridge", "all"}, got)
+}
@@ -9,8 +9,40 @@ It implements the ovsdb.NotificationHandler interface
 such that it can be populated automatically by
 update notifications
 
+Rows the server will never delete (e.g: because they
+are no longer monitored) can be removed locally with
+Purge and PurgeWhere
+
+Arbitrary user metadata (e.g: state derived from a
+row) can be attached to cached rows with SetMetadata
+and read with GetMetadata. It is cleared when the row
+is removed from the cache
+
+Memory-constrained clients that do not monitor a table
+can bound its cache with SetLRU, so it only holds the
+most recently read or written rows. Evicted rows are
+dropped silently, so reads in this mode may miss rows
+and callers must fall back to the server
+
+Models can be normalized (e.g: lowercasing a name or
+sorting a set) as they are decoded from the wire with
+SetNormalizer, so comparisons on cached rows are stable
+
+With SetWeakReferenceCleanup, the weak references to
+deleted rows are removed from the sets and maps of the
+cached rows referencing them, as the server does, so
+the cache is consistent before the server sends the
+updates of the referencing rows
+
+Rows are indexed by the _uuid column and the indexes
+defined in the schema, and can be looked up with
+RowByIndex or RowsByIndexKey. ReindexTable and Reindex
+recompute the indexes to recover from inconsistent ones
+
 It also contains an eventProcessor where callers
 may registers functions that will get called on
-every Add/Update/Delete event.
+every Add/Update/Delete event. Handlers that also
+implement ChangedColumnsEventHandler are told which
+columns changed on every Update event.
 */
 package cache
@@ -1,9 +1,15 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"path"
 	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/ovn-org/libovsdb/cache"
 	"github.com/ovn-org/libovsdb/mapper"
@@ -12,10 +18,12 @@ import (
 )
 
 const (
-	opInsert string = "insert"
-	opMutate string = "mutate"
-	opUpdate string = "insert"
-	opDelete string = "delete"
+	opInsert  string = "insert"
+	opMutate  string = "mutate"
+	opUpdate  string = "update"
+	opDelete  string = "delete"
+	opAssert  string = "assert"
+	opComment string = "comment"
 )
 
 // API defines basic operations to interact with the database
@@ -24,6 +32,8 @@ type API interface {
 	// The function parameter must be a pointer to a slice of Models
 	// If the slice is null, the entire cache will be copied into the slice
 	// If it has a capacity != 0, only 'capacity' elements will be filled in
+	// While the cache is syncing (see WithStreamingInitialDump), the slice is filled in with the
+	// rows cached so far and an ErrCacheSyncing error is returned
 	List(result interface{}) error
 
 	// Create a Conditional API from a Function that is used to filter cached data
@@ -38,43 +48,284 @@ type API interface {
 	Where(model.Model, ...model.Condition) ConditionalAPI
 
 	// Create a ConditionalAPI from a Model's index data or a list of Conditions
-	// where operations apply to elements that match all the conditions
+	// where operations apply to elements that match all the conditions. Several conditions
+	// may be on the same field, e.g: a lower and an upper bound to select a range of values
 	WhereAll(model.Model, ...model.Condition) ConditionalAPI
 
+	// Create a ConditionalAPI that matches the cached elements holding the same values as the
+	// Model in the columns of its first index with non-default values (see SelectedIndex), like
+	// Where without conditions does, but comparing the values of string columns case-insensitively,
+	// e.g: to find a switch by a name whose case is inconsistent. OVSDB conditions are
+	// case-sensitive, so matching is done client-side and operations are generated by matching
+	// each of the matched elements by _uuid (see WhereCache)
+	WhereIgnoreCase(model.Model) ConditionalAPI
+
+	// Create a ConditionalAPI that matches the cached elements whose string field (given as
+	// a pointer to a field in the provided Model) contains the provided substring.
+	// For sets of strings, it matches if any of the elements contains the substring.
+	// OVSDB has no such condition function, so matching is done client-side. Operations
+	// are generated by matching each of the matched elements by _uuid (see WhereCache)
+	WhereFieldContains(m model.Model, field interface{}, substr string) ConditionalAPI
+
+	// Create a ConditionalAPI that matches the cached elements whose string field (given as
+	// a pointer to a field in the provided Model) matches the provided glob pattern (as
+	// defined by path.Match).
+	// For sets of strings, it matches if any of the elements matches the pattern.
+	// OVSDB has no such condition function, so matching is done client-side. Operations
+	// are generated by matching each of the matched elements by _uuid (see WhereCache)
+	WhereFieldMatches(m model.Model, field interface{}, pattern string) ConditionalAPI
+
+	// Create a ConditionalAPI that matches the cached elements whose map field (given as
+	// a pointer to a field in the provided Model) has the provided key, regardless of its value.
+	// OVSDB has no such condition function, so matching is done client-side. Operations
+	// are generated by matching each of the matched elements by _uuid (see WhereCache)
+	WhereMapHasKey(m model.Model, field interface{}, key interface{}) ConditionalAPI
+
+	// Create a ConditionalAPI that matches the cached elements whose map field (given as
+	// a pointer to a field in the provided Model) has the provided key set to the provided
+	// value. Elements without the key do not match. Operations are generated with the
+	// equivalent condition (the map includes the key-value pair), so they are evaluated by
+	// the server
+	WhereMapValue(m model.Model, field interface{}, key, value interface{}) ConditionalAPI
+
 	// Get retrieves a model from the cache
 	// The way the object will be fetch depends on the data contained in the
 	// provided model and the indexes defined in the associated schema
 	// For more complex ways of searching for elements in the cache, the
 	// preferred way is Where({condition}).List()
+	// If the cache of the table is bounded (see cache.TableCache.SetLRU), Get and List
+	// may miss rows that exist in the database
+	// If more than one cached row matches the model, an ErrMultipleMatches error reports them
+	// along with the index they were matched by
+	// If the model is not found while the cache is syncing (see WithStreamingInitialDump), an
+	// ErrCacheSyncing error is returned instead of ErrNotFound
 	Get(model.Model) error
 
+	// GetReferenced populates the slice pointed to by dest with the cached rows referenced by the
+	// UUIDs held by the field (a pointer to a field of the model) in the order they are held, e.g:
+	// the ports of a switch. The field's column must reference another table as per the schema
+	// (its refTable), whose Models dest must hold. The field is read from the provided model, which
+	// may be retrieved with Get first. Referenced rows that are not in the cache are skipped
+	GetReferenced(m model.Model, field interface{}, dest interface{}) error
+
 	// Create returns the operation needed to add the model(s) to the Database
 	// Only fields with non-default values will be added to the transaction
 	// If the field associated with column "_uuid" has some content, it will be
-	// treated as named-uuid
+	// treated as named-uuid, unless a CreateOption such as WithNamedUUID follows the model
+	// With CreateOrGet following a model, the row with the same index values can be read back into
+	// the model if the transaction fails because it already exists
+	// Models that implement model.Validator must be valid, or an ErrInvalidModel error is returned.
+	// The same applies to the models written by Update, UpdateFunc and Reconcile
 	Create(...model.Model) ([]ovsdb.Operation, error)
+
+	// Upsert returns the operations needed to make the database hold the model: if a cached row
+	// holds the same index values (its _uuid or the columns of one of the table indexes), an update
+	// of the columns whose values differ from the model's, including the ones it holds default
+	// values for, and an insert of the model otherwise, as Create does. No operation is returned
+	// if the cached row already holds the model's values. As the cache is checked, another client
+	// may create the row before the transaction is committed, see UpsertGuarded
+	Upsert(model.Model) ([]ovsdb.Operation, error)
+
+	// UpsertGuarded is like Upsert, but the insert is preceded by a wait operation for each table
+	// index the model has values for, which makes the transaction fail if a row holding them was
+	// created in the meantime instead of inserting a duplicate. In that case, the wait operation
+	// fails with a "timed out" error (see ovsdb.TimedOut) and the upsert can be retried once the
+	// cache holds the row
+	UpsertGuarded(model.Model) ([]ovsdb.Operation, error)
+
+	// CheckIndexes returns an ErrIndexCollision error if any of the provided models holds the same
+	// values in all the columns of an index (the _uuid column or one of the table indexes) as
+	// another of them or as a cached row of its table, e.g: to catch duplicates before creating
+	// them. Cached rows with the same _uuid as the model are the row itself and do not collide
+	CheckIndexes(models ...model.Model) error
+
+	// SwapIndex returns the operations that swap the values of a string column (given as a pointer
+	// to a field of the first model) between the cached rows identified by the two models, e.g: to
+	// swap the names of two ports without violating a unique index on them. Both rows must be
+	// in the cache
+	SwapIndex(m1, m2 model.Model, field interface{}) ([]ovsdb.Operation, error)
+
+	// DeleteByUUID returns the operations needed to delete the rows of the given table
+	// identified by the provided UUIDs, one operation per UUID.
+	// All the UUIDs are validated before any operation is returned
+	DeleteByUUID(table string, uuids ...string) ([]ovsdb.Operation, error)
+
+	// Reconcile returns the operations needed to make the cached rows of the given table
+	// match the desired Models: an insert for each desired Model without a cached row, an update
+	// of the changed columns for each desired Model whose cached row differs, and a delete for
+	// each cached row that is not desired. By default, desired Models are matched with cached
+	// rows by _uuid or by any of the table indexes. ReconcileOptions can provide a key function
+	// to match them instead and a list of columns to ignore when comparing them
+	Reconcile(table string, desired []model.Model, opts ...ReconcileOption) ([]ovsdb.Operation, error)
+
+	// Assert returns an operation that makes the transaction it is part of fail
+	// if the client does not own the given lock
+	Assert(lock string) ovsdb.Operation
+
+	// ColumnSchema returns the schema of a column of a table (its type, the minimum and maximum
+	// number of elements and the constraints of its keys and values, e.g: enums) as loaded from
+	// the server, so tooling can handle any table generically. The implicit _uuid and _version
+	// columns are supported too. The returned schema is shared and must not be modified
+	ColumnSchema(table, column string) (*ovsdb.ColumnSchema, error)
+
+	// TableColumns returns the sorted names of the columns of a table as defined in the schema,
+	// without the implicit _uuid and _version columns, or nil if the table does not exist
+	TableColumns(table string) []string
+
+	// UnmappedColumns returns the sorted names of the columns of a table, as defined in the schema
+	// loaded from the server, that are not mapped by the Model of the table, e.g: to detect the
+	// columns added by a newer schema that would be silently ignored. The implicit _uuid and
+	// _version columns are not reported. It fails if the table is not in the schema or the model
+	UnmappedColumns(table string) ([]string, error)
+
+	// ModelFromRow returns a new Model of the given table holding the values of the provided row,
+	// e.g: a row received out-of-band, decoded as the rows of a select reply. Only the columns present
+	// in the row are set, including _uuid and _version if the Model maps them, while the fields of the
+	// absent ones keep their zero value. Columns that are not in the schema of the table are an error
+	ModelFromRow(table string, row ovsdb.Row) (model.Model, error)
+
+	// ApplyOptimistic patches the cache with the changes of committed operations, given along with
+	// their results, ahead of the updates the server sends for them, so they can be read back right
+	// away. Inserted rows get the UUIDs the server returned and the cached rows matching the
+	// conditions of the other operations are updated, mutated or deleted. The patched rows are
+	// marked as optimistic (see cache.TableCache.ApplyOptimistic) until the updates of the server
+	// overwrite them. An operation whose conditions match a different number of cached rows than
+	// the server changed, or an insert whose result holds no UUID, is not applied and is reported by
+	// an ErrOptimisticConflict error once the rest are. Other operations (e.g: select or wait) and
+	// the ones on tables without a Model are ignored. It fails if any of the operations failed
+	ApplyOptimistic(operations []ovsdb.Operation, results []ovsdb.OperationResult) error
+
+	// ConditionsFromColumns returns a new Model of the given table along with the Conditions on
+	// its fields that correspond to the provided conditions on columns, given by name, so they can
+	// be passed to Where or WhereAll without the concrete Model type. The columns must be mapped by
+	// the Model and the values must be valid for the condition functions and column types
+	ConditionsFromColumns(table string, conditions map[string]ColumnCondition) (model.Model, []model.Condition, error)
+
+	// WhereColumn returns a conditionalAPI based on a condition on a column of a table given by
+	// its name, e.g: a column the Model of the table does not map. The value must be of the native
+	// type of the column. The generated operations hold the condition itself, while the cache can
+	// only be searched if the Model maps the column
+	WhereColumn(table, column string, function ovsdb.ConditionFunction, value interface{}) ConditionalAPI
+
+	// ToOvsdbMutation returns the ovsdb.Mutation, on the column of the field, corresponding to a
+	// Mutation of a field of the model, like the ones Mutate generates, e.g: to build operations
+	// by hand. The mutator and the value are validated against the type of the column
+	ToOvsdbMutation(m model.Model, mutation model.Mutation) (ovsdb.Mutation, error)
+
+	// WaitForCache blocks until a cached element satisfies the predicate or the context is done.
+	// The predicate has the same form as the one accepted by WhereCache. Elements already in the
+	// cache are checked first, then the cache events are watched for added or updated elements
+	WaitForCache(ctx context.Context, predicate interface{}) error
+
+	// WatchModel delivers the states of the cached row of the model, found like Get does, on the
+	// returned channel: its current state first and then a Model for each update. The channel is
+	// closed when the row is deleted or the returned cancel function is called. The updates are
+	// queued so the cache events are never blocked by a slow receiver
+	WatchModel(m model.Model) (<-chan model.Model, func(), error)
 }
 
 // ConditionalAPI is an interface used to perform operations that require / use Conditions
 type ConditionalAPI interface {
 	// List uses the condition to search on the cache and populates
 	// the slice of Models objects based on their type
+	// Like the API's List, it returns an ErrCacheSyncing error while the cache is syncing
 	List(result interface{}) error
 
+	// ForEach uses the condition to search on the cache and calls the provided function
+	// with each of the matching Models, without building a slice with all of them.
+	// The cache is only locked while each row is read, not while the function runs.
+	// Iteration stops at the first error returned by the function, which is returned
+	ForEach(fn func(model.Model) error) error
+
+	// Exists returns whether any cached Model matches the condition.
+	// It stops searching at the first match
+	Exists() (bool, error)
+
 	// Mutate returns the operations needed to perform the mutation specified
 	// By the model and the list of Mutation objects
 	// Depending on the Condition, it might return one or many operations
 	Mutate(model.Model, ...model.Mutation) ([]ovsdb.Operation, error)
 
+	// AddToSet returns the operations needed to add the provided elements to the set column of
+	// the field (a pointer to a field of the model) of the matching rows, without replacing the
+	// elements other clients may be adding concurrently, as Update would. The elements must be of
+	// the native type of the set's elements
+	AddToSet(m model.Model, field interface{}, elems ...interface{}) ([]ovsdb.Operation, error)
+
+	// RemoveFromSet returns the operations needed to remove the provided elements from the set
+	// column of the field (a pointer to a field of the model) of the matching rows. The elements
+	// must be of the native type of the set's elements
+	RemoveFromSet(m model.Model, field interface{}, elems ...interface{}) ([]ovsdb.Operation, error)
+
 	// Update returns the operations needed to update any number of rows according
 	// to the data in the given model.
 	// By default, all the non-default values contained in model will be updated.
 	// Optional fields can be passed (pointer to fields in the model) to select the
 	// the fields to be updated
+	// Selected fields are updated even if they hold their default value, so selecting a nil
+	// or empty optional field clears its column (i.e: it is sent as the empty set), while
+	// the fields that are not selected are left out of the row
 	Update(model.Model, ...interface{}) ([]ovsdb.Operation, error)
 
+	// UpdateFunc returns the operations needed to update each of the matched cached rows
+	// to the value computed by the provided function. The function receives a copy of the
+	// current Model and returns the desired one. One operation per changed row is returned,
+	// matching the row by _uuid. Rows for which the function returns an unchanged Model
+	// (or nil) are skipped
+	UpdateFunc(fn func(current model.Model) model.Model) ([]ovsdb.Operation, error)
+
+	// ClearFields returns the operations needed to set the columns of the given fields
+	// (pointers to fields in the model) of the matching rows back to their default value:
+	// the empty set or map, the empty string, zero or false
+	ClearFields(model.Model, ...interface{}) ([]ovsdb.Operation, error)
+
 	// Delete returns the Operations needed to delete the models seleted via the condition
 	Delete() ([]ovsdb.Operation, error)
+
+	// MaxAffectedRows returns a ConditionalAPI whose Mutate, Update, UpdateFunc and Delete fail
+	// with an ErrMaxAffectedRows error, instead of returning any operation, if more than n cached
+	// rows match the condition. It is a safety valve against conditions that accidentally match a
+	// whole table, and its use is strongly recommended for destructive operations. As rows are
+	// counted in the cache, rows not yet in it are not accounted for, and conditions that cannot be
+	// evaluated in the cache make them fail. A value <= 0 disables the check
+	MaxAffectedRows(n int) ConditionalAPI
+
+	// WithContext returns a ConditionalAPI whose operations, if their conditions are generated
+	// by scanning the cache (see WhereCache, WhereFieldContains, WhereFieldMatches and
+	// WhereMapHasKey), fail with an ErrGenerateCanceled error, which reports how far the scan
+	// went, as soon as the context is done, e.g: to bound a slow predicate on a huge table
+	WithContext(ctx context.Context) ConditionalAPI
+
+	// Comment returns a ConditionalAPI whose Mutate, AddToSet, RemoveFromSet, Update, UpdateFunc,
+	// ClearFields and Delete operations are preceded by a comment operation, which the server
+	// writes to its log, e.g: to audit who made a change and why
+	Comment(comment string) ConditionalAPI
+
+	// AssertLock returns a ConditionalAPI whose operations, as with Comment, are preceded by an
+	// assert operation on the given lock (see API's Assert), so the transaction they are sent in
+	// fails if the client does not own it. The comment and assert operations come first, in the
+	// order they were chained, followed by the operations built. They are not added if no
+	// operation is built (e.g: UpdateFunc changes no row)
+	AssertLock(lock string) ConditionalAPI
+
+	// Idempotent wraps the operations so that a transaction that is retried after being applied
+	// (e.g: because its reply was lost) changes nothing. The key is stored under IdempotencyMarker
+	// in the external_ids column of the rows matched by the condition, and a wait operation makes
+	// the transaction fail if any of them already holds it. Use AlreadyApplied to tell such failure
+	// apart from others
+	Idempotent(key string, ops ...ovsdb.Operation) ([]ovsdb.Operation, error)
+
+	// SelectedIndex returns the columns of the index that the conditions built from a Model's
+	// index data (see Where) are keyed on: the first one, in order of priority (_uuid, then the
+	// schema indexes), for which the Model has non-default values. It returns nil if there is
+	// none or if the conditions were not built from a Model's index data
+	SelectedIndex() []string
+}
+
+// ColumnCondition is a condition on a column identified by its name
+type ColumnCondition struct {
+	Function ovsdb.ConditionFunction
+	Value    interface{}
 }
 
 // ErrWrongType is used to report the user provided parameter has the wrong type
@@ -87,14 +338,169 @@ func (e *ErrWrongType) Error() string {
 	return fmt.Sprintf("Wrong parameter type (%s): %s", e.inputType, e.reason)
 }
 
+// ErrImmutableColumn is used to inform that an update attempted to modify an immutable column
+type ErrImmutableColumn struct {
+	Table  string
+	Column string
+}
+
+func (e *ErrImmutableColumn) Error() string {
+	return fmt.Sprintf("column %s of table %s is immutable", e.Column, e.Table)
+}
+
+// ErrInvalidModel is used to inform that a model failed its own validation (see model.Validator)
+type ErrInvalidModel struct {
+	Table string
+	Model model.Model
+	Err   error
+}
+
+func (e *ErrInvalidModel) Error() string {
+	return fmt.Sprintf("invalid model of table %s: %v", e.Table, e.Err)
+}
+
+// Unwrap returns the error returned by the model's validation
+func (e *ErrInvalidModel) Unwrap() error {
+	return e.Err
+}
+
+// validateModel returns an ErrInvalidModel error if the model implements model.Validator and fails
+func validateModel(table string, m model.Model) error {
+	if validator, ok := m.(model.Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return &ErrInvalidModel{Table: table, Model: m, Err: err}
+		}
+	}
+	return nil
+}
+
+// ErrDanglingWeakReference is used to inform that an operation sets a weak reference to a row that
+// is not in the cache. The server would silently remove such reference
+type ErrDanglingWeakReference struct {
+	Table    string
+	Column   string
+	RefTable string
+	UUID     string
+}
+
+func (e *ErrDanglingWeakReference) Error() string {
+	return fmt.Sprintf("column %s of table %s weakly references row %s of table %s, which is not in the cache",
+		e.Column, e.Table, e.UUID, e.RefTable)
+}
+
+// ErrMaxAffectedRows is used to inform that more rows than allowed match the condition of an operation
+type ErrMaxAffectedRows struct {
+	Table string
+	Max   int
+}
+
+func (e *ErrMaxAffectedRows) Error() string {
+	return fmt.Sprintf("more than %d rows of table %s match the condition", e.Max, e.Table)
+}
+
+// ErrGenerateCanceled is used to inform that the context of a ConditionalAPI (see WithContext) was
+// done before the scan of the cache generating the conditions of its operations completed
+type ErrGenerateCanceled struct {
+	Table string
+	// Scanned is the number of cached rows evaluated, out of Total, when the scan was canceled
+	Scanned int
+	Total   int
+	// Matched is the number of scanned rows that matched the condition
+	Matched int
+	// Err is the error of the context
+	Err error
+}
+
+func (e *ErrGenerateCanceled) Error() string {
+	return fmt.Sprintf("scan of table %s canceled after %d of %d rows (%d matched): %v", e.Table, e.Scanned, e.Total, e.Matched, e.Err)
+}
+
+func (e *ErrGenerateCanceled) Unwrap() error {
+	return e.Err
+}
+
+// ErrOptimisticConflict is used to inform that the cached rows matching the conditions of some of
+// the operations given to ApplyOptimistic are not the ones the server changed, e.g: because the
+// cache is behind the server, or that the result of an insert holds no UUID, so those operations
+// were not applied to the cache
+type ErrOptimisticConflict struct {
+	// Operations holds the position of the conflicting operations
+	Operations []int
+}
+
+func (e *ErrOptimisticConflict) Error() string {
+	return fmt.Sprintf("operations %v could not be applied to the cache", e.Operations)
+}
+
+// IndexCollision describes two models that hold the same values in all the columns of an index
+type IndexCollision struct {
+	Table string
+	Index []string
+	// Model is one of the checked models
+	Model model.Model
+	// Other is either a previous checked model or, if Cached is true, a cached row
+	Other  model.Model
+	Cached bool
+}
+
+// ErrIndexCollision is used to inform that models hold the same values in the columns of an index
+type ErrIndexCollision struct {
+	Collisions []IndexCollision
+}
+
+func (e *ErrIndexCollision) Error() string {
+	collisions := make([]string, 0, len(e.Collisions))
+	for _, c := range e.Collisions {
+		other := "checked model"
+		if c.Cached {
+			other = "cached row"
+		}
+		collisions = append(collisions, fmt.Sprintf("%+v and %s %+v on index %v of table %s",
+			c.Model, other, c.Other, c.Index, c.Table))
+	}
+	return fmt.Sprintf("%d index collisions: %s", len(e.Collisions), strings.Join(collisions, "; "))
+}
+
+// IdempotencyMarker is the external_ids key that holds the idempotency key of the last transaction
+// built with Idempotent that was applied to a row
+const IdempotencyMarker = "libovsdb-idempotency-key"
+
 // ErrNotFound is used to inform the object or table was not found in the cache
 var ErrNotFound = errors.New("object not found")
 
+// ErrCacheSyncing is used to inform that the cache is still receiving the initial contents of the
+// monitored tables (see WithStreamingInitialDump), so the requested rows may be missing from it
+var ErrCacheSyncing = errors.New("cache is still syncing")
+
+// ErrMultipleMatches is used to inform that more than one cached row matches the index data of a
+// model that should identify a single one, e.g: because the cache is not consistent
+type ErrMultipleMatches struct {
+	Table string
+	// Index holds the columns of the index the model was looked up by
+	Index []string
+	// UUIDs holds the UUIDs of the matching rows, sorted
+	UUIDs []string
+}
+
+func (e *ErrMultipleMatches) Error() string {
+	return fmt.Sprintf("rows %s of table %s match the model on index %v", strings.Join(e.UUIDs, ", "), e.Table, e.Index)
+}
+
 // api struct implements both API and ConditionalAPI
 // Where() can be used to create a ConditionalAPI api
 type api struct {
 	cache *cache.TableCache
 	cond  Conditional
+	// skipImmutable makes updates silently drop immutable columns instead of failing
+	skipImmutable bool
+	// maxAffectedRows, if positive, is the maximum number of cached rows operations can affect
+	maxAffectedRows int
+	// weakRefWarn, if set, is called with the dangling weak references set by the operations
+	weakRefWarn func(error)
+	// ctx, if set, bounds the scans of the cache that generate conditions
+	ctx context.Context
+	// prefix holds the comment and assert operations that precede the operations built
+	prefix []ovsdb.Operation
 }
 
 // List populates a slice of Models given as parameter based on the configured Condition
@@ -109,9 +515,11 @@ func (a api) List(result interface{}) error {
 		return &ErrWrongType{resultPtr.Type(), "Expected pointer to slice of valid Models"}
 	}
 
-	table, err := a.getTableFromModel(reflect.New(resultVal.Type().Elem()).Interface())
-	if err != nil {
-		return err
+	// Fail before reaching the cache if the elements are not models of the database
+	elemType := resultVal.Type().Elem()
+	table := a.cache.DBModel().FindTable(reflect.PtrTo(elemType))
+	if table == "" {
+		return &ErrWrongType{resultPtr.Type(), fmt.Sprintf("type %s is not a registered OVSDB model", elemType)}
 	}
 
 	if a.cond != nil && a.cond.Table() != table {
@@ -121,7 +529,7 @@ func (a api) List(result interface{}) error {
 
 	tableCache := a.cache.Table(table)
 	if tableCache == nil {
-		return ErrNotFound
+		return a.notFound()
 	}
 
 	// If given a null slice, fill it in the cache table completely, if not, just up to
@@ -131,39 +539,184 @@ func (a api) List(result interface{}) error {
 	}
 	i := resultVal.Len()
 
+	var matches func(model.Model) (bool, error)
+	if a.cond != nil {
+		matches = matcher(a.cond)
+	}
 	for _, row := range tableCache.Rows() {
 		elem := tableCache.Row(row)
 		if i >= resultVal.Cap() {
 			break
 		}
 
-		if a.cond != nil {
-			if matches, err := a.cond.Matches(elem); err != nil {
+		if matches != nil {
+			if ok, err := matches(elem); err != nil {
 				return err
-			} else if !matches {
+			} else if !ok {
 				continue
 			}
 		}
 
-		resultVal.Set(reflect.Append(resultVal, reflect.Indirect(reflect.ValueOf(elem))))
+		// Unlike reflect.Append, growing the slice within its capacity does not allocate
+		resultVal.SetLen(i + 1)
+		resultVal.Index(i).Set(reflect.Indirect(reflect.ValueOf(elem)))
 		i++
 	}
+	if a.cache.Syncing() {
+		return ErrCacheSyncing
+	}
+	return nil
+}
+
+// ForEach calls the provided function with each of the models that match the configured Condition
+func (a api) ForEach(fn func(model.Model) error) error {
+	return a.iterate(func(elem model.Model) (bool, error) {
+		// Do not hand out the cached model itself
+		elemVal := reflect.Indirect(reflect.ValueOf(elem))
+		copied := reflect.New(elemVal.Type())
+		copied.Elem().Set(elemVal)
+		if err := fn(copied.Interface().(model.Model)); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+// Exists returns whether any cached model matches the configured Condition
+func (a api) Exists() (bool, error) {
+	found := false
+	err := a.iterate(func(model.Model) (bool, error) {
+		found = true
+		return false, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return found, nil
+}
+
+// iterate calls the provided function with each of the cached models that match the configured
+// Condition until it returns false or an error. The cache is only locked while each row is read
+func (a api) iterate(fn func(model.Model) (bool, error)) error {
+	tableName := a.cond.Table()
+	if a.cache.Mapper().Schema.Table(tableName) == nil {
+		// The condition might not have been created successfully
+		if _, err := a.cond.Generate(); err != nil {
+			return err
+		}
+		return fmt.Errorf("table %s not found in schema", tableName)
+	}
+
+	tableCache := a.cache.Table(tableName)
+	if tableCache == nil {
+		return ErrNotFound
+	}
+
+	matches := matcher(a.cond)
+	for _, uuid := range tableCache.Rows() {
+		elem := tableCache.Row(uuid)
+		if elem == nil {
+			// The row was deleted while iterating
+			continue
+		}
+		if ok, err := matches(elem); err != nil {
+			return err
+		} else if !ok {
+			continue
+		}
+
+		if next, err := fn(elem); err != nil {
+			return err
+		} else if !next {
+			return nil
+		}
+	}
 	return nil
 }
 
 // Where returns a conditionalAPI based on a Condition list
 func (a api) Where(model model.Model, cond ...model.Condition) ConditionalAPI {
-	return newConditionalAPI(a.cache, a.conditionFromModel(false, model, cond...))
+	return a.conditional(a.conditionFromModel(false, model, cond...))
 }
 
 // Where returns a conditionalAPI based on a Condition list
 func (a api) WhereAll(model model.Model, cond ...model.Condition) ConditionalAPI {
-	return newConditionalAPI(a.cache, a.conditionFromModel(true, model, cond...))
+	return a.conditional(a.conditionFromModel(true, model, cond...))
+}
+
+// WhereIgnoreCase returns a conditionalAPI matching the index values of the model case-insensitively
+func (a api) WhereIgnoreCase(m model.Model) ConditionalAPI {
+	table, err := a.getTableFromModel(m)
+	if err != nil {
+		return a.conditional(newErrorConditional(err))
+	}
+	condition, err := newCaseInsensitiveConditional(table, a.cache, m)
+	if err != nil {
+		return a.conditional(newErrorConditional(err))
+	}
+	return a.conditional(condition)
 }
 
 // Where returns a conditionalAPI based a Predicate
 func (a api) WhereCache(predicate interface{}) ConditionalAPI {
-	return newConditionalAPI(a.cache, a.conditionFromFunc(predicate))
+	return a.conditional(a.conditionFromFunc(predicate))
+}
+
+// WhereFieldContains returns a conditionalAPI that matches cached elements whose field contains
+// a substring
+func (a api) WhereFieldContains(m model.Model, field interface{}, substr string) ConditionalAPI {
+	return a.conditional(a.conditionFromStringMatch(m, field, func(value string) bool {
+		return strings.Contains(value, substr)
+	}))
+}
+
+// WhereFieldMatches returns a conditionalAPI that matches cached elements whose field matches
+// a glob pattern
+func (a api) WhereFieldMatches(m model.Model, field interface{}, pattern string) ConditionalAPI {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return a.conditional(newErrorConditional(err))
+	}
+	return a.conditional(a.conditionFromStringMatch(m, field, func(value string) bool {
+		// pattern has already been validated
+		match, _ := path.Match(pattern, value)
+		return match
+	}))
+}
+
+// WhereMapHasKey returns a conditionalAPI that matches cached elements whose map field has a key
+func (a api) WhereMapHasKey(m model.Model, field interface{}, key interface{}) ConditionalAPI {
+	table, err := a.getTableFromModel(m)
+	if err != nil {
+		return a.conditional(newErrorConditional(err))
+	}
+	condition, err := newMapKeyConditional(table, a.cache, m, field, key)
+	if err != nil {
+		return a.conditional(newErrorConditional(err))
+	}
+	return a.conditional(condition)
+}
+
+// WhereMapValue returns a conditionalAPI that matches cached elements whose map field has a key
+// set to a value
+func (a api) WhereMapValue(m model.Model, field interface{}, key, value interface{}) ConditionalAPI {
+	table, err := a.getTableFromModel(m)
+	if err != nil {
+		return a.conditional(newErrorConditional(err))
+	}
+	condition, err := newMapValueConditional(table, a.cache.Mapper(), m, field, key, value)
+	if err != nil {
+		return a.conditional(newErrorConditional(err))
+	}
+	return a.conditional(condition)
+}
+
+// WhereColumn returns a conditionalAPI based on a condition on a column given by its name
+func (a api) WhereColumn(table, column string, function ovsdb.ConditionFunction, value interface{}) ConditionalAPI {
+	condition, err := newColumnConditional(a.cache.Mapper(), table, column, function, value)
+	if err != nil {
+		return a.conditional(newErrorConditional(err))
+	}
+	return a.conditional(condition)
 }
 
 // Conditional interface implementation
@@ -181,6 +734,21 @@ func (a api) conditionFromFunc(predicate interface{}) Conditional {
 	return condition
 }
 
+// conditionFromStringMatch returns a Condition that applies a match function to the string field of
+// a model
+func (a api) conditionFromStringMatch(m model.Model, field interface{}, match func(string) bool) Conditional {
+	table, err := a.getTableFromModel(m)
+	if err != nil {
+		return newErrorConditional(err)
+	}
+
+	condition, err := newStringMatchConditional(table, a.cache, m, field, match)
+	if err != nil {
+		return newErrorConditional(err)
+	}
+	return condition
+}
+
 // FromModel returns a Condition from a model and a list of fields
 func (a api) conditionFromModel(any bool, model model.Model, cond ...model.Condition) Conditional {
 	var conditional Conditional
@@ -220,51 +788,283 @@ func (a api) Get(m model.Model) error {
 
 	tableCache := a.cache.Table(table)
 	if tableCache == nil {
-		return ErrNotFound
+		return a.notFound()
 	}
 
 	// If model contains _uuid value, we can access it via cache index
-	mapperInfo, err := mapper.NewMapperInfo(a.cache.Mapper().Schema.Table(table), m)
+	mapperInfo, err := a.cache.Mapper().NewMapperInfo(table, m)
 	if err != nil {
 		return err
 	}
-	if uuid, err := mapperInfo.FieldByColumn("_uuid"); err != nil && uuid != nil {
-		if found := tableCache.Row(uuid.(string)); found == nil {
-			return ErrNotFound
-		} else {
+	if uuid, err := mapperInfo.FieldByColumn("_uuid"); err == nil && uuid != "" {
+		if found := tableCache.Row(uuid.(string)); found != nil {
 			reflect.ValueOf(m).Elem().Set(reflect.Indirect(reflect.ValueOf(found)))
 			return nil
 		}
 	}
 
-	// Look across the entire cache for table index equality
-	for _, row := range tableCache.Rows() {
-		elem := tableCache.Row(row)
-		equal, err := a.cache.Mapper().EqualFields(table, m, elem.(model.Model))
+	// Look the other indexes up in the index of the cache
+	keys, err := mapperInfo.IndexKeys()
+	if err != nil {
+		return err
+	}
+	matched := make(map[string]bool)
+	var uuids []string
+	for _, key := range keys {
+		rows, err := tableCache.RowsByIndexKey(key)
 		if err != nil {
 			return err
 		}
-		if equal {
-			reflect.ValueOf(m).Elem().Set(reflect.Indirect(reflect.ValueOf(elem)))
-			return nil
+		for _, uuid := range rows {
+			if !matched[uuid] {
+				matched[uuid] = true
+				uuids = append(uuids, uuid)
+			}
 		}
 	}
+	if len(uuids) > 1 {
+		sort.Strings(uuids)
+		return &ErrMultipleMatches{Table: table, Index: selectedIndex(a.cache.Mapper().Schema.Table(table), m), UUIDs: uuids}
+	}
+	var found model.Model
+	if len(uuids) == 1 {
+		found = tableCache.Row(uuids[0])
+	}
+	if found == nil {
+		return a.notFound()
+	}
+	reflect.ValueOf(m).Elem().Set(reflect.Indirect(reflect.ValueOf(found)))
+	return nil
+}
+
+// notFound returns the error of a model that is not in the cache: ErrCacheSyncing if the cache is
+// still syncing, as the model may be added to it later on, or ErrNotFound
+func (a api) notFound() error {
+	if a.cache.Syncing() {
+		return ErrCacheSyncing
+	}
 	return ErrNotFound
 }
 
+// namedUUIDRegexp matches valid named-uuids (<id> as per RFC7047)
+var namedUUIDRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// CreateOption modifies the insert operation that Create generates for the model it follows
+// E.g: Create(&ls, WithNamedUUID("myref"))
+type CreateOption func(*ovsdb.Operation) error
+
+// WithNamedUUID is a CreateOption that sets the named-uuid of the insert operation
+// regardless of the content of the model's _uuid field. The named-uuid can then be used to
+// reference the inserted row in other operations of the same transaction
+func WithNamedUUID(namedUUID string) CreateOption {
+	return func(op *ovsdb.Operation) error {
+		if !namedUUIDRegexp.MatchString(namedUUID) {
+			return fmt.Errorf("invalid named-uuid %q", namedUUID)
+		}
+		op.UUIDName = namedUUID
+		return nil
+	}
+}
+
+// WithRealUUID is a CreateOption that makes the insert operation create the row with the provided
+// real UUID instead of one chosen by the server, e.g: to keep the UUIDs of rows that are recreated.
+// The operation holds no named-uuid, so the other operations of the transaction must reference the
+// row by its real UUID. RFC7047 does not allow choosing the UUID of a row: servers that do not support
+// the "uuid" member of insert operations fail the transaction, see RealUUIDUnsupported
+func WithRealUUID(uuid string) CreateOption {
+	return func(op *ovsdb.Operation) error {
+		if !ovsdb.IsValidUUID(uuid) {
+			return fmt.Errorf("invalid UUID %q", uuid)
+		}
+		op.UUID = uuid
+		op.UUIDName = ""
+		return nil
+	}
+}
+
+// RealUUIDUnsupported returns whether a transaction failed because the server rejected the real
+// UUID an insert operation was created with (see WithRealUUID), in which case the row can be
+// created without it
+func RealUUIDUnsupported(err error) bool {
+	var transactErr *ovsdb.TransactError
+	if !errors.As(err, &transactErr) || transactErr.Operation == nil {
+		return false
+	}
+	op := transactErr.Operation
+	return op.Op == opInsert && op.UUID != "" && transactErr.ErrorName == "syntax error"
+}
+
+// CreateOrGetOption reads back the row that holds the same index values as the model it follows when
+// the transaction inserting the model fails because of it, see CreateOrGet
+type CreateOrGetOption struct {
+	api     api
+	model   model.Model
+	existed bool
+}
+
+// CreateOrGet returns a CreateOrGetOption. Create returns the insert operation of the model it follows
+// as usual, and the error of the transaction is then passed to the option's Resolve: if the insert
+// failed with a constraint violation (see IndexConflict), e.g: because another client created the row
+// concurrently, the model is populated with the row that holds the same values in the columns of an
+// index, the way Get finds it, and Existed reports true. E.g:
+//
+//	existing := client.CreateOrGet()
+//	ops, err := ovs.Create(&ls, existing)
+//	_, err = ovs.Transact(ops...)
+//	err = existing.Resolve(ctx, err)
+//	if existing.Existed() {...}
+func CreateOrGet() *CreateOrGetOption {
+	return &CreateOrGetOption{}
+}
+
+// Existed returns whether the last Resolve call found the row holding the model's index values
+func (o *CreateOrGetOption) Existed() bool {
+	return o.existed
+}
+
+// Resolve handles the error of the transaction holding the insert of the model the option followed in
+// the last Create call. If the insert, or the commit of the transaction, failed with a constraint
+// violation (see IndexConflict), the model is populated with the row holding its index values, which
+// is waited for in the cache until the context is done as the server may not have sent it yet, and nil
+// is returned. Otherwise, or if the row is not found, the error is returned. As the transaction failed
+// as a whole, the other operations it held were not applied either
+func (o *CreateOrGetOption) Resolve(ctx context.Context, err error) error {
+	o.existed = false
+	if o.model == nil {
+		return fmt.Errorf("create option must follow the model it applies to")
+	}
+	if !IndexConflict(err) {
+		return err
+	}
+	tableName, tableErr := o.api.getTableFromModel(o.model)
+	if tableErr != nil {
+		return err
+	}
+	var transactErr *ovsdb.TransactError
+	if errors.As(err, &transactErr) && transactErr.Operation != nil && transactErr.Operation.Table != tableName {
+		// Another insert of the transaction failed
+		return err
+	}
+	if found, _ := o.api.waitForExisting(ctx, tableName, o.model); !found {
+		return err
+	}
+	o.existed = true
+	return nil
+}
+
+// IndexConflict returns whether a transaction failed because of a constraint violation caused by an
+// insert operation or detected when committing it, which is how the server reports rows holding the
+// same values in the columns of an index, e.g: a row created concurrently by another client
+func IndexConflict(err error) bool {
+	var transactErr *ovsdb.TransactError
+	if !errors.As(err, &transactErr) {
+		return false
+	}
+	var violation *ovsdb.ConstraintViolation
+	if !errors.As(err, &violation) {
+		return false
+	}
+	return transactErr.Operation == nil || transactErr.Operation.Op == opInsert
+}
+
+// GetReferenced populates a slice with the cached rows referenced by a field of a model
+func (a api) GetReferenced(m model.Model, field interface{}, dest interface{}) error {
+	tableName, err := a.getTableFromModel(m)
+	if err != nil {
+		return err
+	}
+	table := a.cache.Mapper().Schema.Table(tableName)
+	info, err := mapper.NewMapperInfo(table, m)
+	if err != nil {
+		return err
+	}
+	column, err := info.ColumnByPtr(field)
+	if err != nil {
+		return err
+	}
+	columnSchema := table.Column(column)
+	var refTable string
+	if columnSchema.Type != ovsdb.TypeMap && columnSchema.TypeObj != nil && columnSchema.TypeObj.Key != nil {
+		refTable, _ = columnSchema.TypeObj.Key.RefTable()
+	}
+	if refTable == "" {
+		return fmt.Errorf("column %s of table %s does not reference another table", column, tableName)
+	}
+
+	destPtr := reflect.ValueOf(dest)
+	if destPtr.Kind() != reflect.Ptr || destPtr.Elem().Kind() != reflect.Slice {
+		return &ErrWrongType{reflect.TypeOf(dest), "Expected pointer to slice of valid Models"}
+	}
+	destVal := destPtr.Elem()
+	if a.cache.DBModel().FindTable(reflect.PtrTo(destVal.Type().Elem())) != refTable {
+		return &ErrWrongType{destPtr.Type(),
+			fmt.Sprintf("Expected models of table %s, referenced by column %s", refTable, column)}
+	}
+
+	value, err := info.FieldByColumn(column)
+	if err != nil {
+		return err
+	}
+	ovsValue, err := ovsdb.NativeToOvs(columnSchema, value)
+	if err != nil {
+		return err
+	}
+	refCache := a.cache.Table(refTable)
+	if refCache == nil {
+		return ErrNotFound
+	}
+	uuids := referencedUUIDs(ovsValue)
+	result := reflect.MakeSlice(destVal.Type(), 0, len(uuids))
+	for _, uuid := range uuids {
+		row := refCache.Row(uuid.GoUUID)
+		if row == nil {
+			continue
+		}
+		result = reflect.Append(result, reflect.Indirect(reflect.ValueOf(row)))
+	}
+	destVal.Set(result)
+	return nil
+}
+
 // Create is a generic function capable of creating any row in the DB
 // A valud Model (pointer to object) must be provided.
+// CreateOptions apply to the model that precedes them
 func (a api) Create(models ...model.Model) ([]ovsdb.Operation, error) {
 	var operations []ovsdb.Operation
+	// last is the model the options apply to
+	var last model.Model
 
 	for _, model := range models {
 		var namedUUID string
 		var err error
 
+		if opt, ok := model.(CreateOption); ok {
+			if last == nil {
+				return nil, fmt.Errorf("create option must follow the model it applies to")
+			}
+			if err := opt(&operations[len(operations)-1]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if opt, ok := model.(*CreateOrGetOption); ok {
+			if last == nil {
+				return nil, fmt.Errorf("create option must follow the model it applies to")
+			}
+			opt.api = a
+			opt.model = last
+			opt.existed = false
+			continue
+		}
+		last = model
+
 		tableName, err := a.getTableFromModel(model)
 		if err != nil {
 			return nil, err
 		}
+		if err := validateModel(tableName, model); err != nil {
+			return nil, err
+		}
 
 		table := a.cache.Mapper().Schema.Table(tableName)
 
@@ -283,6 +1083,7 @@ func (a api) Create(models ...model.Model) ([]ovsdb.Operation, error) {
 		if err != nil {
 			return nil, err
 		}
+		a.checkWeakReferences(tableName, info, row)
 
 		operations = append(operations, ovsdb.Operation{
 			Op:       opInsert,
@@ -294,42 +1095,437 @@ func (a api) Create(models ...model.Model) ([]ovsdb.Operation, error) {
 	return operations, nil
 }
 
-// Mutate returns the operations needed to transform the one Model into another one
-func (a api) Mutate(model model.Model, mutationObjs ...model.Mutation) ([]ovsdb.Operation, error) {
-	var mutations []ovsdb.Mutation
-	var operations []ovsdb.Operation
+// waitForExisting populates the model with the cached row that holds the same index values, waiting
+// for it to be cached until the context is done, and returns whether it was found. The model's _uuid
+// field holds a named-uuid, so only the other indexes are considered
+func (a api) waitForExisting(ctx context.Context, tableName string, m model.Model) (bool, error) {
+	found := reflect.New(reflect.TypeOf(m).Elem()).Interface()
+	reflect.ValueOf(found).Elem().Set(reflect.ValueOf(m).Elem())
+	info, err := a.cache.Mapper().NewMapperInfo(tableName, found)
+	if err != nil {
+		return false, err
+	}
+	if err := info.SetField("_uuid", ""); err != nil {
+		return false, err
+	}
+	keys, err := info.IndexKeys()
+	if err != nil {
+		return false, err
+	}
+	if len(keys) == 0 {
+		// Without index values, there is no row to compare the model with
+		return false, nil
+	}
 
-	if len(mutationObjs) < 1 {
-		return nil, fmt.Errorf("At least one Mutation must be provided")
+	// Watch the cache before checking its current contents so no event is missed
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	handler := &cache.EventHandlerFuncs{
+		AddFunc: func(string, model.Model) {
+			notify()
+		},
+		UpdateFunc: func(string, model.Model, model.Model) {
+			notify()
+		},
 	}
+	a.cache.AddTableEventHandler(tableName, handler)
+	defer a.cache.RemoveEventHandler(handler)
 
-	tableName := a.cache.DBModel().FindTable(reflect.ValueOf(model).Type())
-	table := a.cache.Mapper().Schema.Table(tableName)
-	if table == nil {
-		return nil, fmt.Errorf("schema error: table not found in Database Model for type %s", reflect.TypeOf(model))
+	for {
+		err := a.Get(found)
+		if err == nil {
+			break
+		}
+		if err != ErrNotFound && err != ErrCacheSyncing {
+			return false, err
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
 	}
+	reflect.ValueOf(m).Elem().Set(reflect.ValueOf(found).Elem())
+	return true, nil
+}
+
+// Upsert returns the operations needed to update the cached row holding the same index values
+// as the model, or to insert the model if there is none
+func (a api) Upsert(m model.Model) ([]ovsdb.Operation, error) {
+	return a.upsert(m, false)
+}
+
+// UpsertGuarded is like Upsert, but the insert is guarded by waits on the absence of the row
+func (a api) UpsertGuarded(m model.Model) ([]ovsdb.Operation, error) {
+	return a.upsert(m, true)
+}
 
-	conditions, err := a.cond.Generate()
+func (a api) upsert(m model.Model, guarded bool) ([]ovsdb.Operation, error) {
+	tableName, err := a.getTableFromModel(m)
 	if err != nil {
 		return nil, err
 	}
-
-	info, err := mapper.NewMapperInfo(table, model)
+	if err := validateModel(tableName, m); err != nil {
+		return nil, err
+	}
+	table := a.cache.Mapper().Schema.Table(tableName)
+	info, err := mapper.NewMapperInfo(table, m)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := info.IndexKeys()
 	if err != nil {
 		return nil, err
 	}
 
-	for _, mobj := range mutationObjs {
-		col, err := info.ColumnByPtr(mobj.Field)
+	if len(keys) > 0 {
+		// Look the row up in a copy, so the model is not overwritten
+		found := reflect.New(reflect.TypeOf(m).Elem()).Interface().(model.Model)
+		reflect.ValueOf(found).Elem().Set(reflect.ValueOf(m).Elem())
+		if err := a.Get(found); err == nil {
+			return a.upsertUpdate(tableName, found, m)
+		} else if err != ErrNotFound {
+			return nil, err
+		}
+	}
+
+	operations, err := a.Create(m)
+	if err != nil || !guarded {
+		return operations, err
+	}
+	var guards []ovsdb.Operation
+	for _, key := range keys {
+		if len(key.Columns) == 1 && key.Columns[0] == "_uuid" {
+			// The _uuid of an inserted row is either a named-uuid or generated by the server
+			continue
+		}
+		where := make([]ovsdb.Condition, 0, len(key.Columns))
+		for _, column := range key.Columns {
+			value, err := info.FieldByColumn(column)
+			if err != nil {
+				return nil, err
+			}
+			ovsValue, err := ovsdb.NativeToOvs(table.Column(column), value)
+			if err != nil {
+				return nil, err
+			}
+			where = append(where, ovsdb.NewCondition(column, ovsdb.ConditionEqual, ovsValue))
+		}
+		// Wait (without blocking) until no row holds the index values
+		guards = append(guards, ovsdb.Operation{
+			Op:              ovsdb.OperationWait,
+			Table:           tableName,
+			Where:           where,
+			Columns:         []string{"_uuid"},
+			Until:           "==",
+			Rows:            []ovsdb.Row{},
+			ExplicitTimeout: true,
+		})
+	}
+	return append(guards, operations...), nil
+}
+
+// upsertUpdate returns the operation that updates the columns of the cached row that differ
+// from the model, if any
+func (a api) upsertUpdate(tableName string, cached, m model.Model) ([]ovsdb.Operation, error) {
+	info, err := a.cache.Mapper().NewMapperInfo(tableName, cached)
+	if err != nil {
+		return nil, err
+	}
+	uuid, err := info.FieldByColumn("_uuid")
+	if err != nil {
+		return nil, err
+	}
+	changed, err := a.cache.Mapper().ChangedColumns(tableName, cached, m)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, 0, len(changed))
+	for _, column := range changed {
+		if column != "_uuid" && column != "_version" {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		return nil, nil
+	}
+	operation, err := a.updateOperation(tableName, uuid.(string), m, columns)
+	if err != nil {
+		return nil, err
+	}
+	if len(operation.Row) == 0 {
+		return nil, nil
+	}
+	return []ovsdb.Operation{operation}, nil
+}
+
+// CheckIndexes returns an ErrIndexCollision error if models hold the same index values as each
+// other or as cached rows
+func (a api) CheckIndexes(models ...model.Model) error {
+	// indexed holds, by table, the first checked model with each index key, and checked holds
+	// these keys in order, to look them up in the cache
+	indexed := make(map[string]map[string]model.Model)
+	uuids := make(map[model.Model]string)
+	type checkedKey struct {
+		table string
+		key   mapper.IndexKey
+		model model.Model
+	}
+	var checked []checkedKey
+	var collisions []IndexCollision
+	for _, m := range models {
+		switch m.(type) {
+		case CreateOption, *CreateOrGetOption:
+			continue
+		}
+		tableName, err := a.getTableFromModel(m)
+		if err != nil {
+			return err
+		}
+		keys, uuid, err := a.indexKeys(tableName, m)
+		if err != nil {
+			return err
+		}
+		uuids[m] = uuid
+		if _, ok := indexed[tableName]; !ok {
+			indexed[tableName] = make(map[string]model.Model)
+		}
+		for _, key := range keys {
+			id := fmt.Sprint(key.Columns, key.Key)
+			if other, ok := indexed[tableName][id]; ok {
+				collisions = append(collisions, IndexCollision{Table: tableName, Index: key.Columns, Model: m, Other: other})
+				continue
+			}
+			indexed[tableName][id] = m
+			checked = append(checked, checkedKey{table: tableName, key: key, model: m})
+		}
+	}
+
+	for _, c := range checked {
+		tableCache := a.cache.Table(c.table)
+		if tableCache == nil {
+			continue
+		}
+		rows, err := tableCache.RowsByIndexKey(c.key)
+		if err != nil {
+			return err
+		}
+		for _, uuid := range rows {
+			row := tableCache.Row(uuid)
+			if row == nil || uuids[c.model] == uuid {
+				continue
+			}
+			collisions = append(collisions, IndexCollision{Table: c.table, Index: c.key.Columns, Model: c.model, Other: row, Cached: true})
+		}
+	}
+	if len(collisions) > 0 {
+		return &ErrIndexCollision{Collisions: collisions}
+	}
+	return nil
+}
+
+// indexKeys returns the keys of the indexes for which a model has non-default values, along
+// with its _uuid
+func (a api) indexKeys(tableName string, m model.Model) ([]mapper.IndexKey, string, error) {
+	info, err := a.cache.Mapper().NewMapperInfo(tableName, m)
+	if err != nil {
+		return nil, "", err
+	}
+	keys, err := info.IndexKeys()
+	if err != nil {
+		return nil, "", err
+	}
+	uuid, err := info.FieldByColumn("_uuid")
+	if err != nil {
+		return nil, "", err
+	}
+	return keys, uuid.(string), nil
+}
+
+// SwapIndex returns the operations that swap the values of a string column between the cached rows
+// identified by two models, as Get does: the first row is updated to a temporary value derived from
+// its _uuid, the second one to the value of the first and then the first one to the value of the
+// second, so a unique index on the column is never violated in between
+func (a api) SwapIndex(m1, m2 model.Model, field interface{}) ([]ovsdb.Operation, error) {
+	tableName, err := a.getTableFromModel(m1)
+	if err != nil {
+		return nil, err
+	}
+	if other, err := a.getTableFromModel(m2); err != nil {
+		return nil, err
+	} else if other != tableName {
+		return nil, &ErrWrongType{reflect.TypeOf(m2),
+			fmt.Sprintf("Table derived from model (%s) does not match Table %s", other, tableName)}
+	}
+	table := a.cache.Mapper().Schema.Table(tableName)
+	info, err := mapper.NewMapperInfo(table, m1)
+	if err != nil {
+		return nil, err
+	}
+	column, err := info.ColumnByPtr(field)
+	if err != nil {
+		return nil, err
+	}
+	if table.Column(column).Type != ovsdb.TypeString {
+		return nil, fmt.Errorf("column %s of table %s is not a string column whose values can be swapped", column, tableName)
+	}
+
+	// Read the current values from the cache, without modifying the provided models
+	rows := make([]model.Model, 0, 2)
+	uuids := make([]string, 0, 2)
+	values := make([]interface{}, 0, 2)
+	for _, m := range []model.Model{m1, m2} {
+		row := reflect.New(reflect.TypeOf(m).Elem())
+		row.Elem().Set(reflect.ValueOf(m).Elem())
+		if err := a.Get(row.Interface()); err != nil {
+			return nil, fmt.Errorf("row %+v of table %s: %w", m, tableName, err)
+		}
+		rowInfo, err := mapper.NewMapperInfo(table, row.Interface())
+		if err != nil {
+			return nil, err
+		}
+		uuid, err := rowInfo.FieldByColumn("_uuid")
+		if err != nil {
+			return nil, err
+		}
+		value, err := rowInfo.FieldByColumn(column)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row.Interface())
+		uuids = append(uuids, uuid.(string))
+		values = append(values, value)
+	}
+	if uuids[0] == uuids[1] {
+		return nil, fmt.Errorf("both models identify row %s of table %s", uuids[0], tableName)
+	}
+
+	steps := []struct {
+		row   int
+		value interface{}
+	}{
+		{0, "swap-" + uuids[0]},
+		{1, values[0]},
+		{0, values[1]},
+	}
+	operations := make([]ovsdb.Operation, 0, len(steps))
+	for _, step := range steps {
+		rowInfo, err := mapper.NewMapperInfo(table, rows[step.row])
+		if err != nil {
+			return nil, err
+		}
+		if err := rowInfo.SetField(column, step.value); err != nil {
+			return nil, err
+		}
+		operation, err := a.updateOperation(tableName, uuids[step.row], rows[step.row], []string{column})
 		if err != nil {
 			return nil, err
 		}
+		if len(operation.Row) == 0 {
+			return nil, &ErrImmutableColumn{Table: tableName, Column: column}
+		}
+		operations = append(operations, operation)
+	}
+	return operations, nil
+}
+
+// Assert returns an assert operation on the given lock
+// If the lock is not held, the operation result can be checked with ovsdb.CheckOperationResults,
+// which returns an ovsdb.ErrLockNotHeld error for it
+func (a api) Assert(lock string) ovsdb.Operation {
+	return ovsdb.Operation{
+		Op:   opAssert,
+		Lock: &lock,
+	}
+}
+
+// DeleteByUUID returns the Operations needed to delete the rows identified by the provided UUIDs
+func (a api) DeleteByUUID(table string, uuids ...string) ([]ovsdb.Operation, error) {
+	var operations []ovsdb.Operation
+	if a.cache.Mapper().Schema.Table(table) == nil {
+		return nil, fmt.Errorf("table %s not found in schema", table)
+	}
+	for _, uuid := range uuids {
+		if !ovsdb.IsValidUUID(uuid) {
+			return nil, fmt.Errorf("invalid uuid %q", uuid)
+		}
+	}
+
+	for _, uuid := range uuids {
+		operations = append(operations,
+			ovsdb.Operation{
+				Op:    opDelete,
+				Table: table,
+				Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: uuid})},
+			},
+		)
+	}
+	return operations, nil
+}
+
+// ToOvsdbMutation returns the ovsdb.Mutation corresponding to a Mutation of a field of the model
+func (a api) ToOvsdbMutation(m model.Model, mutation model.Mutation) (ovsdb.Mutation, error) {
+	tableName, err := a.getTableFromModel(m)
+	if err != nil {
+		return ovsdb.Mutation{}, err
+	}
+	info, err := a.cache.Mapper().NewMapperInfo(tableName, m)
+	if err != nil {
+		return ovsdb.Mutation{}, err
+	}
+	column, err := info.ColumnByPtr(mutation.Field)
+	if err != nil {
+		return ovsdb.Mutation{}, err
+	}
+	result, err := a.cache.Mapper().NewMutation(tableName, m, column, mutation.Mutator, mutation.Value)
+	if err != nil {
+		return ovsdb.Mutation{}, err
+	}
+	return *result, nil
+}
+
+// Mutate returns the operations needed to transform the one Model into another one
+func (a api) Mutate(model model.Model, mutationObjs ...model.Mutation) ([]ovsdb.Operation, error) {
+	var mutations []ovsdb.Mutation
+	var operations []ovsdb.Operation
+
+	if len(mutationObjs) < 1 {
+		return nil, fmt.Errorf("At least one Mutation must be provided")
+	}
+
+	tableName := a.cache.DBModel().FindTable(reflect.ValueOf(model).Type())
+	table := a.cache.Mapper().Schema.Table(tableName)
+	if table == nil {
+		return nil, fmt.Errorf("schema error: table not found in Database Model for type %s", reflect.TypeOf(model))
+	}
+
+	conditions, err := a.generate()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.checkAffectedRows(); err != nil {
+		return nil, err
+	}
+
+	info, err := mapper.NewMapperInfo(table, model)
+	if err != nil {
+		return nil, err
+	}
 
-		mutation, err := a.cache.Mapper().NewMutation(tableName, model, col, mobj.Mutator, mobj.Value)
+	for _, mobj := range mutationObjs {
+		mutation, err := a.ToOvsdbMutation(model, mobj)
 		if err != nil {
 			return nil, err
 		}
-		mutations = append(mutations, *mutation)
+		if mutation.Mutator == ovsdb.MutateOperationInsert {
+			a.checkWeakReferences(tableName, info, ovsdb.Row{mutation.Column: mutation.Value})
+		}
+		mutations = append(mutations, mutation)
 	}
 	for _, condition := range conditions {
 		operations = append(operations,
@@ -342,112 +1538,1280 @@ func (a api) Mutate(model model.Model, mutationObjs ...model.Mutation) ([]ovsdb.
 		)
 	}
 
-	return operations, nil
+	return a.withPrefix(operations), nil
 }
 
 // Update is a generic function capable of updating any field in any row in the database
 // Additional fields can be passed (variadic opts) to indicate fields to be updated
 func (a api) Update(model model.Model, fields ...interface{}) ([]ovsdb.Operation, error) {
-	var operations []ovsdb.Operation
 	table, err := a.getTableFromModel(model)
 	if err != nil {
 		return nil, err
 	}
+	if err := validateModel(table, model); err != nil {
+		return nil, err
+	}
 
-	conditions, err := a.cond.Generate()
+	conditions, err := a.generate()
 	if err != nil {
 		return nil, err
 	}
+	if err := a.checkAffectedRows(); err != nil {
+		return nil, err
+	}
 
 	row, err := a.cache.Mapper().NewRow(table, model, fields...)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, condition := range conditions {
-		operations = append(operations,
-			ovsdb.Operation{
-				Op:    opUpdate,
-				Table: table,
-				Row:   row,
-				Where: condition,
-			},
-		)
+	info, err := a.cache.Mapper().NewMapperInfo(table, model)
+	if err != nil {
+		return nil, err
 	}
-	return operations, nil
+	a.checkWeakReferences(table, info, row)
+	return a.updateOperations(table, info, row, conditions)
 }
 
-// Delete returns the Operation needed to delete the selected models from the database
-func (a api) Delete() ([]ovsdb.Operation, error) {
+// updateOperations returns an update operation of the row for each list of conditions,
+// once its immutable columns have been dropped (or an error returned, see WithSkipImmutableColumns)
+func (a api) updateOperations(table string, info *mapper.MapperInfo, row ovsdb.Row, conditions [][]ovsdb.Condition) ([]ovsdb.Operation, error) {
 	var operations []ovsdb.Operation
-	conditions, err := a.cond.Generate()
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		columns = append(columns, column)
+	}
+	mutable, err := a.mutableColumns(table, info, columns)
 	if err != nil {
 		return nil, err
 	}
+	if len(mutable) < len(columns) {
+		updated := ovsdb.NewRow()
+		for _, column := range mutable {
+			updated[column] = row[column]
+		}
+		row = updated
+	}
+	if len(row) == 0 {
+		return nil, nil
+	}
 
 	for _, condition := range conditions {
 		operations = append(operations,
 			ovsdb.Operation{
-				Op:    opDelete,
-				Table: a.cond.Table(),
+				Op:    opUpdate,
+				Table: table,
+				Row:   row,
 				Where: condition,
 			},
 		)
 	}
+	return a.withPrefix(operations), nil
+}
 
-	return operations, nil
+// AddToSet returns the operations needed to add elements to a set column of the matching rows
+func (a api) AddToSet(m model.Model, field interface{}, elems ...interface{}) ([]ovsdb.Operation, error) {
+	return a.mutateSet(m, field, ovsdb.MutateOperationInsert, elems)
 }
 
-// getTableFromModel returns the table name from a Model object after performing
-// type verifications on the model
-func (a api) getTableFromModel(m interface{}) (string, error) {
-	if _, ok := m.(model.Model); !ok {
-		return "", &ErrWrongType{reflect.TypeOf(m), "Type does not implement Model interface"}
+// RemoveFromSet returns the operations needed to remove elements from a set column of the matching rows
+func (a api) RemoveFromSet(m model.Model, field interface{}, elems ...interface{}) ([]ovsdb.Operation, error) {
+	return a.mutateSet(m, field, ovsdb.MutateOperationDelete, elems)
+}
+
+// mutateSet returns the operations needed to mutate a set column of the matching rows with the
+// provided elements, once validated against the type of the set's elements
+func (a api) mutateSet(m model.Model, field interface{}, mutator ovsdb.Mutator, elems []interface{}) ([]ovsdb.Operation, error) {
+	if len(elems) == 0 {
+		return nil, fmt.Errorf("at least one element must be provided")
 	}
-	table := a.cache.DBModel().FindTable(reflect.TypeOf(m))
-	if table == "" {
-		return "", &ErrWrongType{reflect.TypeOf(m), "Model not found in Database Model"}
+	table, err := a.getTableFromModel(m)
+	if err != nil {
+		return nil, err
 	}
-	return table, nil
+	tableSchema := a.cache.Mapper().Schema.Table(table)
+	info, err := mapper.NewMapperInfo(tableSchema, m)
+	if err != nil {
+		return nil, err
+	}
+	column, err := info.ColumnByPtr(field)
+	if err != nil {
+		return nil, err
+	}
+	columnSchema := tableSchema.Column(column)
+	if columnSchema.Type != ovsdb.TypeSet {
+		return nil, fmt.Errorf("column %s is not a set column", column)
+	}
+	set := reflect.MakeSlice(ovsdb.NativeType(columnSchema), 0, len(elems))
+	elemType := set.Type().Elem()
+	for _, elem := range elems {
+		if reflect.TypeOf(elem) != elemType {
+			return nil, ovsdb.NewErrWrongType(fmt.Sprintf("Element of set column %s", column), elemType.String(), elem)
+		}
+		set = reflect.Append(set, reflect.ValueOf(elem))
+	}
+	return a.Mutate(m, model.Mutation{Field: field, Mutator: mutator, Value: set.Interface()})
 }
 
-// getTableFromModel returns the table name from a the predicate after performing
-// type verifications
-func (a api) getTableFromFunc(predicate interface{}) (string, error) {
-	predType := reflect.TypeOf(predicate)
-	if predType == nil || predType.Kind() != reflect.Func {
-		return "", &ErrWrongType{predType, "Expected function"}
+// ClearFields returns the operations needed to set the columns of the given fields of the
+// matching rows back to their default value
+func (a api) ClearFields(model model.Model, fields ...interface{}) ([]ovsdb.Operation, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("at least one field must be provided")
 	}
-	if predType.NumIn() != 1 || predType.NumOut() != 1 || predType.Out(0).Kind() != reflect.Bool {
-		return "", &ErrWrongType{predType, "Expected func(Model) bool"}
+	table, err := a.getTableFromModel(model)
+	if err != nil {
+		return nil, err
+	}
+	tableSchema := a.cache.Mapper().Schema.Table(table)
+	info, err := mapper.NewMapperInfo(tableSchema, model)
+	if err != nil {
+		return nil, err
 	}
 
-	modelInterface := reflect.TypeOf((*model.Model)(nil)).Elem()
-	modelType := predType.In(0)
-	if !modelType.Implements(modelInterface) {
-		return "", &ErrWrongType{predType,
-			fmt.Sprintf("Type %s does not implement Model interface", modelType.String())}
+	row := ovsdb.NewRow()
+	for _, field := range fields {
+		column, err := info.ColumnByPtr(field)
+		if err != nil {
+			return nil, err
+		}
+		value, err := defaultOvsValue(tableSchema.Column(column))
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", column, err)
+		}
+		row[column] = value
 	}
 
-	table := a.cache.DBModel().FindTable(modelType)
-	if table == "" {
-		return "", &ErrWrongType{predType,
-			fmt.Sprintf("Model %s not found in Database Model", modelType.String())}
+	conditions, err := a.generate()
+	if err != nil {
+		return nil, err
 	}
-	return table, nil
+	if err := a.checkAffectedRows(); err != nil {
+		return nil, err
+	}
+	return a.updateOperations(table, info, row, conditions)
 }
 
-// newAPI returns a new API to interact with the database
-func newAPI(cache *cache.TableCache) API {
-	return api{
-		cache: cache,
+// defaultOvsValue returns the default value of a column, as defined by RFC7047: the empty set or map,
+// or the default value of the atomic type. Enums have no default that satisfies their constraints
+func defaultOvsValue(column *ovsdb.ColumnSchema) (interface{}, error) {
+	switch column.Type {
+	case ovsdb.TypeEnum:
+		return nil, fmt.Errorf("enum columns have no default value")
+	case ovsdb.TypeUUID:
+		return ovsdb.UUID{GoUUID: "00000000-0000-0000-0000-000000000000"}, nil
+	default:
+		return ovsdb.NativeToOvs(column, reflect.Zero(ovsdb.NativeType(column)).Interface())
 	}
 }
 
-// newConditionalAPI returns a new ConditionalAPI to interact with the database
-func newConditionalAPI(cache *cache.TableCache, cond Conditional) ConditionalAPI {
-	return api{
-		cache: cache,
-		cond:  cond,
+// UpdateFunc returns the operations needed to update each of the matched rows to the value
+// computed by the provided function
+func (a api) UpdateFunc(fn func(current model.Model) model.Model) ([]ovsdb.Operation, error) {
+	var operations []ovsdb.Operation
+	tableName := a.cond.Table()
+	table := a.cache.Mapper().Schema.Table(tableName)
+	if table == nil {
+		// The condition might not have been created successfully
+		if _, err := a.cond.Generate(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("table %s not found in schema", tableName)
 	}
+
+	if err := a.checkAffectedRows(); err != nil {
+		return nil, err
+	}
+
+	tableCache := a.cache.Table(tableName)
+	if tableCache == nil {
+		return nil, nil
+	}
+
+	for _, uuid := range tableCache.RowsSorted() {
+		elem := tableCache.Row(uuid)
+		if elem == nil {
+			continue
+		}
+		if matches, err := a.cond.Matches(elem); err != nil {
+			return nil, err
+		} else if !matches {
+			continue
+		}
+
+		// Do not let the function modify the cached model nor the one used for comparison
+		current, err := a.copyModel(tableName, uuid, elem)
+		if err != nil {
+			return nil, err
+		}
+		input, err := a.copyModel(tableName, uuid, elem)
+		if err != nil {
+			return nil, err
+		}
+		desired := fn(input)
+		if desired == nil {
+			continue
+		}
+		if desiredTable, err := a.getTableFromModel(desired); err != nil {
+			return nil, err
+		} else if desiredTable != tableName {
+			return nil, &ErrWrongType{reflect.TypeOf(desired),
+				fmt.Sprintf("Table derived from returned model (%s) does not match Table from Condition (%s)", desiredTable, tableName)}
+		}
+		if err := validateModel(tableName, desired); err != nil {
+			return nil, err
+		}
+
+		changed, err := a.cache.Mapper().ChangedColumns(tableName, current, desired)
+		if err != nil {
+			return nil, err
+		}
+		if len(changed) == 0 {
+			continue
+		}
+
+		for _, column := range changed {
+			if column == "_uuid" {
+				return nil, fmt.Errorf("column _uuid of row %s cannot be updated", uuid)
+			}
+		}
+		operation, err := a.updateOperation(tableName, uuid, desired, changed)
+		if err != nil {
+			return nil, err
+		}
+		if len(operation.Row) == 0 {
+			continue
+		}
+		operations = append(operations, operation)
+	}
+	return a.withPrefix(operations), nil
+}
+
+// updateOperation returns the operation that updates the given columns of the row identified
+// by uuid with the values held by the model. Immutable columns are handled as per the
+// API configuration, so the returned operation might have an empty row
+func (a api) updateOperation(tableName, uuid string, m model.Model, columns []string) (ovsdb.Operation, error) {
+	table := a.cache.Mapper().Schema.Table(tableName)
+	info, err := mapper.NewMapperInfo(table, m)
+	if err != nil {
+		return ovsdb.Operation{}, err
+	}
+	columns, err = a.mutableColumns(tableName, info, columns)
+	if err != nil {
+		return ovsdb.Operation{}, err
+	}
+	row := ovsdb.NewRow()
+	for _, column := range columns {
+		nativeElem, err := info.FieldByColumn(column)
+		if err != nil {
+			return ovsdb.Operation{}, err
+		}
+		ovsElem, err := ovsdb.NativeToOvs(table.Column(column), nativeElem)
+		if err != nil {
+			return ovsdb.Operation{}, err
+		}
+		row[column] = ovsElem
+	}
+	return ovsdb.Operation{
+		Op:    opUpdate,
+		Table: tableName,
+		Row:   row,
+		Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: uuid})},
+	}, nil
+}
+
+// mutableColumns returns the columns that can be updated. If any of them is immutable,
+// an error is returned unless the API is configured to skip them
+func (a api) mutableColumns(tableName string, info *mapper.MapperInfo, columns []string) ([]string, error) {
+	mutable := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if !info.IsImmutable(column) {
+			mutable = append(mutable, column)
+			continue
+		}
+		if !a.skipImmutable {
+			return nil, &ErrImmutableColumn{Table: tableName, Column: column}
+		}
+	}
+	return mutable, nil
+}
+
+// ReconcileOption configures how Reconcile matches and compares models
+type ReconcileOption func(*reconcileOptions)
+
+type reconcileOptions struct {
+	key    func(model.Model) string
+	ignore map[string]bool
+}
+
+// WithReconcileKey makes Reconcile match the desired and the cached models whose keys, as returned
+// by the given function, are equal. The function must not modify the models it receives
+func WithReconcileKey(key func(model.Model) string) ReconcileOption {
+	return func(o *reconcileOptions) {
+		o.key = key
+	}
+}
+
+// WithReconcileIgnoreColumns makes Reconcile ignore the differences in the given columns
+func WithReconcileIgnoreColumns(columns ...string) ReconcileOption {
+	return func(o *reconcileOptions) {
+		for _, column := range columns {
+			o.ignore[column] = true
+		}
+	}
+}
+
+// Reconcile returns the operations needed to make the cached rows of a table match the desired models
+func (a api) Reconcile(tableName string, desired []model.Model, opts ...ReconcileOption) ([]ovsdb.Operation, error) {
+	options := &reconcileOptions{ignore: map[string]bool{"_uuid": true, "_version": true}}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if a.cache.Mapper().Schema.Table(tableName) == nil {
+		return nil, fmt.Errorf("table %s not found in schema", tableName)
+	}
+	for _, m := range desired {
+		if table, err := a.getTableFromModel(m); err != nil {
+			return nil, err
+		} else if table != tableName {
+			return nil, &ErrWrongType{reflect.TypeOf(m),
+				fmt.Sprintf("Table derived from desired model (%s) does not match Table %s", table, tableName)}
+		}
+		if err := validateModel(tableName, m); err != nil {
+			return nil, err
+		}
+	}
+
+	// Snapshot the cached rows
+	current := make(map[string]model.Model)
+	var uuids []string
+	if tableCache := a.cache.Table(tableName); tableCache != nil {
+		for _, uuid := range tableCache.Rows() {
+			if elem := tableCache.Row(uuid); elem != nil {
+				current[uuid] = elem
+				uuids = append(uuids, uuid)
+			}
+		}
+	}
+	sort.Strings(uuids)
+	var keys map[string]string
+	if options.key != nil {
+		keys = make(map[string]string, len(current))
+		for _, uuid := range uuids {
+			keys[options.key(current[uuid])] = uuid
+		}
+	}
+
+	var operations []ovsdb.Operation
+	matched := make(map[string]bool)
+	for _, m := range desired {
+		// Find the cached row that corresponds to the desired model
+		var found string
+		if options.key != nil {
+			if uuid, ok := keys[options.key(m)]; ok && !matched[uuid] {
+				found = uuid
+			}
+		} else {
+			for _, uuid := range uuids {
+				if matched[uuid] {
+					continue
+				}
+				equal, err := a.cache.Mapper().EqualFields(tableName, m, current[uuid])
+				if err != nil {
+					return nil, err
+				}
+				if equal {
+					found = uuid
+					break
+				}
+			}
+		}
+
+		if found == "" {
+			ops, err := a.Create(m)
+			if err != nil {
+				return nil, err
+			}
+			operations = append(operations, ops...)
+			continue
+		}
+		matched[found] = true
+
+		changed, err := a.cache.Mapper().ChangedColumns(tableName, current[found], m)
+		if err != nil {
+			return nil, err
+		}
+		var columns []string
+		for _, column := range changed {
+			if !options.ignore[column] {
+				columns = append(columns, column)
+			}
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		operation, err := a.updateOperation(tableName, found, m, columns)
+		if err != nil {
+			return nil, err
+		}
+		if len(operation.Row) == 0 {
+			continue
+		}
+		operations = append(operations, operation)
+	}
+
+	// Delete the cached rows that are not desired
+	for _, uuid := range uuids {
+		if matched[uuid] {
+			continue
+		}
+		operations = append(operations, ovsdb.Operation{
+			Op:    opDelete,
+			Table: tableName,
+			Where: []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: uuid})},
+		})
+	}
+	return operations, nil
+}
+
+// Delete returns the Operation needed to delete the selected models from the database
+func (a api) Delete() ([]ovsdb.Operation, error) {
+	var operations []ovsdb.Operation
+	conditions, err := a.generate()
+	if err != nil {
+		return nil, err
+	}
+	if err := a.checkAffectedRows(); err != nil {
+		return nil, err
+	}
+
+	for _, condition := range conditions {
+		operations = append(operations,
+			ovsdb.Operation{
+				Op:    opDelete,
+				Table: a.cond.Table(),
+				Where: condition,
+			},
+		)
+	}
+
+	return a.withPrefix(operations), nil
+}
+
+// copyModel returns a copy of a cached model that does not share any data with it
+func (a api) copyModel(tableName, uuid string, m model.Model) (model.Model, error) {
+	row, err := a.cache.Mapper().NewRow(tableName, m)
+	if err != nil {
+		return nil, err
+	}
+	return a.cache.CreateModel(tableName, &row, uuid)
+}
+
+// getTableFromModel returns the table name from a Model object after performing
+// type verifications on the model
+func (a api) getTableFromModel(m interface{}) (string, error) {
+	if _, ok := m.(model.Model); !ok {
+		return "", &ErrWrongType{reflect.TypeOf(m), "Type does not implement Model interface"}
+	}
+	table := a.cache.DBModel().FindTable(reflect.TypeOf(m))
+	if table == "" {
+		return "", &ErrWrongType{reflect.TypeOf(m), "Model not found in Database Model"}
+	}
+	return table, nil
+}
+
+// getTableFromModel returns the table name from a the predicate after performing
+// type verifications
+func (a api) getTableFromFunc(predicate interface{}) (string, error) {
+	predType := reflect.TypeOf(predicate)
+	if predType == nil || predType.Kind() != reflect.Func {
+		return "", &ErrWrongType{predType, "Expected function"}
+	}
+	if predType.NumIn() != 1 || predType.NumOut() != 1 || predType.Out(0).Kind() != reflect.Bool {
+		return "", &ErrWrongType{predType, "Expected func(Model) bool"}
+	}
+
+	modelInterface := reflect.TypeOf((*model.Model)(nil)).Elem()
+	modelType := predType.In(0)
+	if !modelType.Implements(modelInterface) {
+		return "", &ErrWrongType{predType,
+			fmt.Sprintf("Type %s does not implement Model interface", modelType.String())}
+	}
+
+	table := a.cache.DBModel().FindTable(modelType)
+	if table == "" {
+		return "", &ErrWrongType{predType,
+			fmt.Sprintf("Model %s not found in Database Model", modelType.String())}
+	}
+	return table, nil
+}
+
+// newAPI returns a new API to interact with the database
+func newAPI(cache *cache.TableCache) API {
+	return api{
+		cache: cache,
+	}
+}
+
+// ColumnSchema returns the schema of a column of a table
+func (a api) ColumnSchema(table, column string) (*ovsdb.ColumnSchema, error) {
+	tableSchema := a.cache.Mapper().Schema.Table(table)
+	if tableSchema == nil {
+		return nil, fmt.Errorf("table %s not found in schema", table)
+	}
+	columnSchema := tableSchema.Column(column)
+	if columnSchema == nil {
+		return nil, fmt.Errorf("column %s not found in table %s", column, table)
+	}
+	return columnSchema, nil
+}
+
+// TableColumns returns the sorted names of the columns of a table
+func (a api) TableColumns(table string) []string {
+	tableSchema := a.cache.Mapper().Schema.Table(table)
+	if tableSchema == nil {
+		return nil
+	}
+	columns := make([]string, 0, len(tableSchema.Columns))
+	for column := range tableSchema.Columns {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// UnmappedColumns returns the sorted names of the columns of a table not mapped by its model
+func (a api) UnmappedColumns(table string) ([]string, error) {
+	tableSchema := a.cache.Mapper().Schema.Table(table)
+	if tableSchema == nil {
+		return nil, fmt.Errorf("table %s not found in schema", table)
+	}
+	m, err := a.cache.DBModel().NewModel(table)
+	if err != nil {
+		return nil, err
+	}
+	info, err := mapper.NewMapperInfo(tableSchema, m)
+	if err != nil {
+		return nil, err
+	}
+	var unmapped []string
+	for _, column := range a.TableColumns(table) {
+		if _, err := info.FieldByColumn(column); err != nil {
+			unmapped = append(unmapped, column)
+		}
+	}
+	return unmapped, nil
+}
+
+// ModelFromRow returns a new model of the table holding the columns present in the row
+func (a api) ModelFromRow(table string, row ovsdb.Row) (model.Model, error) {
+	tableSchema := a.cache.Mapper().Schema.Table(table)
+	if tableSchema == nil {
+		return nil, fmt.Errorf("table %s not found in schema", table)
+	}
+	implicit := make(map[string]interface{})
+	for column, value := range row {
+		columnSchema := tableSchema.Column(column)
+		if columnSchema == nil {
+			return nil, fmt.Errorf("column %s not found in table %s", column, table)
+		}
+		if column != "_uuid" && column != "_version" {
+			continue
+		}
+		native, err := ovsdb.OvsToNative(columnSchema, value)
+		if err != nil {
+			return nil, fmt.Errorf("table %s, column %s: %v", table, column, err)
+		}
+		implicit[column] = native
+	}
+	uuid, _ := implicit["_uuid"].(string)
+	m, err := a.cache.CreateModel(table, &row, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if version, ok := implicit["_version"]; ok {
+		info, err := mapper.NewMapperInfo(tableSchema, m)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := info.FieldByColumn("_version"); err == nil {
+			if err := info.SetField("_version", version); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// ApplyOptimistic patches the cache with the changes of committed operations
+func (a api) ApplyOptimistic(operations []ovsdb.Operation, results []ovsdb.OperationResult) error {
+	if _, err := ovsdb.CheckOperationResults(results, operations); err != nil {
+		return err
+	}
+	uuids := make(map[string]string)
+	for i, op := range operations {
+		if op.Op == ovsdb.OperationInsert && op.UUIDName != "" {
+			uuids[op.UUIDName] = results[i].UUID.GoUUID
+		}
+	}
+	var conflicts []int
+	for i, op := range operations {
+		if _, ok := a.cache.DBModel().Types()[op.Table]; !ok {
+			continue
+		}
+		row := make(ovsdb.Row, len(op.Row))
+		for column, value := range op.Row {
+			row[column] = resolveNamedUUIDs(value, uuids)
+		}
+		if op.Op == ovsdb.OperationInsert {
+			uuid := results[i].UUID.GoUUID
+			if uuid == "" {
+				// The row cannot be cached without the UUID the server gave it
+				conflicts = append(conflicts, i)
+				continue
+			}
+			if tableCache := a.cache.Table(op.Table); tableCache != nil && tableCache.Row(uuid) != nil {
+				// The update of the server was already received
+				continue
+			}
+			m, err := a.cache.CreateModel(op.Table, &row, uuid)
+			if err != nil {
+				return err
+			}
+			if err := a.cache.ApplyOptimistic(op.Table, uuid, m); err != nil {
+				return err
+			}
+			continue
+		}
+		if op.Op != ovsdb.OperationUpdate && op.Op != ovsdb.OperationMutate && op.Op != ovsdb.OperationDelete {
+			continue
+		}
+		matches, err := a.optimisticMatches(op.Table, op.Where, uuids)
+		if err != nil || len(matches) != results[i].Count {
+			conflicts = append(conflicts, i)
+			continue
+		}
+		for uuid, m := range matches {
+			var patched model.Model
+			switch op.Op {
+			case ovsdb.OperationUpdate:
+				patched, err = a.optimisticUpdate(op.Table, uuid, m, row)
+			case ovsdb.OperationMutate:
+				patched, err = a.optimisticMutate(op.Table, uuid, m, op.Mutations, uuids)
+			}
+			if err != nil {
+				return err
+			}
+			if err := a.cache.ApplyOptimistic(op.Table, uuid, patched); err != nil {
+				return err
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return &ErrOptimisticConflict{Operations: conflicts}
+	}
+	return nil
+}
+
+// optimisticMatches returns the cached rows of a table, by UUID, that match all the conditions of
+// an operation, whose named UUIDs are replaced by the real ones
+func (a api) optimisticMatches(tableName string, conditions []ovsdb.Condition, uuids map[string]string) (map[string]model.Model, error) {
+	table := a.cache.Mapper().Schema.Table(tableName)
+	values := make([]interface{}, 0, len(conditions))
+	for _, condition := range conditions {
+		column := table.Column(condition.Column)
+		if column == nil {
+			return nil, fmt.Errorf("column %s not found in table %s", condition.Column, tableName)
+		}
+		value, err := ovsdb.OvsToNative(column, resolveNamedUUIDs(condition.Value, uuids))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	matches := make(map[string]model.Model)
+	tableCache := a.cache.Table(tableName)
+	if tableCache == nil {
+		return matches, nil
+	}
+	for _, uuid := range tableCache.Rows() {
+		m := tableCache.Row(uuid)
+		if m == nil {
+			continue
+		}
+		info, err := mapper.NewMapperInfo(table, m)
+		if err != nil {
+			return nil, err
+		}
+		matched := true
+		for i, condition := range conditions {
+			actual, err := info.FieldByColumn(condition.Column)
+			if err != nil {
+				return nil, err
+			}
+			if matched, err = ovsdb.EvaluateCondition(table.Column(condition.Column), condition.Function, actual, values[i]); err != nil {
+				return nil, err
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched {
+			matches[uuid] = m
+		}
+	}
+	return matches, nil
+}
+
+// optimisticUpdate returns a new model resulting from setting the columns of an update row on a
+// cached model
+func (a api) optimisticUpdate(tableName, uuid string, m model.Model, update ovsdb.Row) (model.Model, error) {
+	row, err := a.cache.Mapper().NewRow(tableName, m)
+	if err != nil {
+		return nil, err
+	}
+	for column, value := range update {
+		row[column] = value
+	}
+	return a.cache.CreateModel(tableName, &row, uuid)
+}
+
+// optimisticMutate returns a new model resulting from applying mutations, whose named UUIDs are
+// replaced by the real ones, to a cached model
+func (a api) optimisticMutate(tableName, uuid string, m model.Model, mutations []ovsdb.Mutation, uuids map[string]string) (model.Model, error) {
+	table := a.cache.Mapper().Schema.Table(tableName)
+	row, err := a.cache.Mapper().NewRow(tableName, m)
+	if err != nil {
+		return nil, err
+	}
+	for _, mutation := range mutations {
+		column := table.Column(mutation.Column)
+		if column == nil {
+			return nil, fmt.Errorf("column %s not found in table %s", mutation.Column, tableName)
+		}
+		value, err := nativeMutationValue(column, mutation.Mutator, resolveNamedUUIDs(mutation.Value, uuids))
+		if err != nil {
+			return nil, err
+		}
+		current := reflect.Zero(ovsdb.NativeType(column)).Interface()
+		if ovsElem, ok := row[mutation.Column]; ok {
+			if current, err = ovsdb.OvsToNative(column, ovsElem); err != nil {
+				return nil, err
+			}
+		}
+		result, err := ovsdb.ApplyMutation(column, mutation.Mutator, current, value)
+		if err != nil {
+			return nil, err
+		}
+		if row[mutation.Column], err = ovsdb.NativeToOvs(column, result); err != nil {
+			return nil, err
+		}
+	}
+	return a.cache.CreateModel(tableName, &row, uuid)
+}
+
+// nativeMutationValue returns the native value of a mutation of a column as ovsdb.ApplyMutation
+// expects it: an element for the arithmetic mutations of sets and a set of keys or a map for
+// the deletions from maps
+func nativeMutationValue(column *ovsdb.ColumnSchema, mutator ovsdb.Mutator, value interface{}) (interface{}, error) {
+	switch {
+	case column.Type == ovsdb.TypeSet && mutator != ovsdb.MutateOperationInsert && mutator != ovsdb.MutateOperationDelete:
+		return ovsdb.OvsToNativeAtomic(column.TypeObj.Key.Type, value)
+	case column.Type == ovsdb.TypeMap && mutator == ovsdb.MutateOperationDelete:
+		switch value.(type) {
+		case ovsdb.OvsMap, *ovsdb.OvsMap:
+			return ovsdb.OvsToNative(column, value)
+		}
+		keys := &ovsdb.ColumnSchema{Type: ovsdb.TypeSet, TypeObj: &ovsdb.ColumnType{Key: column.TypeObj.Key}}
+		return ovsdb.OvsToNative(keys, value)
+	}
+	return ovsdb.OvsToNative(column, value)
+}
+
+// ConditionsFromColumns returns a new model of the table and the conditions on its fields that
+// correspond to the provided conditions on columns
+func (a api) ConditionsFromColumns(table string, conditions map[string]ColumnCondition) (model.Model, []model.Condition, error) {
+	tableSchema := a.cache.Mapper().Schema.Table(table)
+	if tableSchema == nil {
+		return nil, nil, fmt.Errorf("table %s not found in schema", table)
+	}
+	m, err := a.cache.DBModel().NewModel(table)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := mapper.NewMapperInfo(tableSchema, m)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := make([]string, 0, len(conditions))
+	for column := range conditions {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	result := make([]model.Condition, 0, len(columns))
+	for _, column := range columns {
+		if tableSchema.Column(column) == nil {
+			return nil, nil, fmt.Errorf("column %s not found in table %s", column, table)
+		}
+		field, err := info.FieldPtrByColumn(column)
+		if err != nil {
+			return nil, nil, err
+		}
+		cond := conditions[column]
+		// Validate the condition as it would be when generating the operations
+		if _, err := a.cache.Mapper().NewCondition(table, m, field, cond.Function, cond.Value); err != nil {
+			return nil, nil, err
+		}
+		result = append(result, model.Condition{Field: field, Function: cond.Function, Value: cond.Value})
+	}
+	return m, result, nil
+}
+
+// WaitForCache blocks until a cached element satisfies the predicate or the context is done
+func (a api) WaitForCache(ctx context.Context, predicate interface{}) error {
+	// Validate the predicate without evaluating it on the cache
+	table, err := a.getTableFromFunc(predicate)
+	if err != nil {
+		return err
+	}
+	cond, err := newPredicateConditional(table, a.cache, predicate)
+	if err != nil {
+		return err
+	}
+
+	// Watch the cache before checking its current contents so no event is missed
+	done := make(chan error, 1)
+	notify := func(m model.Model) {
+		matches, err := cond.Matches(m)
+		if err != nil || matches {
+			select {
+			case done <- err:
+			default:
+			}
+		}
+	}
+	handler := &cache.EventHandlerFuncs{
+		AddFunc: func(table string, m model.Model) {
+			notify(m)
+		},
+		UpdateFunc: func(table string, old, new model.Model) {
+			notify(new)
+		},
+	}
+	a.cache.AddTableEventHandler(cond.Table(), handler)
+	defer a.cache.RemoveEventHandler(handler)
+
+	found, err := a.conditional(cond).Exists()
+	if err != nil && err != ErrNotFound {
+		return err
+	}
+	if found {
+		return nil
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WatchModel delivers the states of the cached row of the model until it is deleted or the watch is canceled
+func (a api) WatchModel(m model.Model) (<-chan model.Model, func(), error) {
+	table, err := a.getTableFromModel(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	tableSchema := a.cache.Mapper().Schema.Table(table)
+	uuidOf := func(m model.Model) string {
+		info, err := mapper.NewMapperInfo(tableSchema, m)
+		if err != nil {
+			return ""
+		}
+		uuid, err := info.FieldByColumn("_uuid")
+		if err != nil {
+			return ""
+		}
+		return uuid.(string)
+	}
+	found := reflect.New(reflect.TypeOf(m).Elem()).Interface()
+	reflect.ValueOf(found).Elem().Set(reflect.ValueOf(m).Elem())
+	if err := a.Get(found); err != nil {
+		return nil, nil, err
+	}
+	uuid := uuidOf(found)
+	if uuid == "" {
+		return nil, nil, fmt.Errorf("model of table %s does not hold the _uuid column", table)
+	}
+
+	w := newModelWatch()
+	handler := &cache.EventHandlerFuncs{
+		UpdateFunc: func(_ string, _, new model.Model) {
+			if uuidOf(new) == uuid {
+				w.push(new)
+			}
+		},
+		DeleteFunc: func(_ string, old model.Model) {
+			if uuidOf(old) == uuid {
+				w.delete()
+			}
+		},
+	}
+	a.cache.AddTableEventHandler(table, handler)
+	// The row is read again once the events are watched, so no change is missed
+	if current := a.cache.Table(table).Row(uuid); current != nil {
+		w.push(current)
+	} else {
+		w.delete()
+	}
+	go w.run()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			a.cache.RemoveEventHandler(handler)
+			close(w.stop)
+		})
+	}
+	return w.out, cancel, nil
+}
+
+// modelWatch queues the states of a watched row until they are received
+type modelWatch struct {
+	mutex sync.Mutex
+	queue []model.Model
+	// deleted is set once the row is deleted
+	deleted bool
+	// notify is signaled when the queue or deleted change
+	notify chan struct{}
+	stop   chan struct{}
+	out    chan model.Model
+}
+
+func newModelWatch() *modelWatch {
+	return &modelWatch{
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		out:    make(chan model.Model),
+	}
+}
+
+// push queues a copy of the model
+func (w *modelWatch) push(m model.Model) {
+	state := reflect.New(reflect.TypeOf(m).Elem())
+	state.Elem().Set(reflect.ValueOf(m).Elem())
+	w.mutex.Lock()
+	w.queue = append(w.queue, state.Interface())
+	w.mutex.Unlock()
+	w.signal()
+}
+
+// delete records the deletion of the row, after which no state is queued
+func (w *modelWatch) delete() {
+	w.mutex.Lock()
+	w.deleted = true
+	w.mutex.Unlock()
+	w.signal()
+}
+
+func (w *modelWatch) signal() {
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers the queued states until the row is deleted or the watch is stopped
+func (w *modelWatch) run() {
+	defer close(w.out)
+	for {
+		w.mutex.Lock()
+		if len(w.queue) > 0 {
+			next := w.queue[0]
+			w.queue = w.queue[1:]
+			w.mutex.Unlock()
+			select {
+			case w.out <- next:
+			case <-w.stop:
+				return
+			}
+			continue
+		}
+		deleted := w.deleted
+		w.mutex.Unlock()
+		if deleted {
+			return
+		}
+		select {
+		case <-w.notify:
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// MaxAffectedRows returns a ConditionalAPI that fails to build operations affecting more than n cached rows
+func (a api) MaxAffectedRows(n int) ConditionalAPI {
+	a.maxAffectedRows = n
+	return a
+}
+
+// WithContext returns a ConditionalAPI whose scans of the cache generating conditions stop when the context is done
+func (a api) WithContext(ctx context.Context) ConditionalAPI {
+	a.ctx = ctx
+	return a
+}
+
+// Comment returns a ConditionalAPI whose operations are preceded by a comment operation
+func (a api) Comment(comment string) ConditionalAPI {
+	a.prefix = append(a.prefix[:len(a.prefix):len(a.prefix)], ovsdb.Operation{
+		Op:      opComment,
+		Comment: &comment,
+	})
+	return a
+}
+
+// AssertLock returns a ConditionalAPI whose operations are preceded by an assert operation
+func (a api) AssertLock(lock string) ConditionalAPI {
+	a.prefix = append(a.prefix[:len(a.prefix):len(a.prefix)], a.Assert(lock))
+	return a
+}
+
+// withPrefix returns the operations preceded by the comment and assert operations, if any
+func (a api) withPrefix(operations []ovsdb.Operation) []ovsdb.Operation {
+	if len(a.prefix) == 0 || len(operations) == 0 {
+		return operations
+	}
+	return append(append(make([]ovsdb.Operation, 0, len(a.prefix)+len(operations)), a.prefix...), operations...)
+}
+
+// generate returns the conditions of the operations, within the context if any
+func (a api) generate() ([][]ovsdb.Condition, error) {
+	if g, ok := a.cond.(contextGenerator); ok && a.ctx != nil {
+		return g.generateContext(a.ctx)
+	}
+	return a.cond.Generate()
+}
+
+// Idempotent returns the operations wrapped by a guard that fails the transaction if the
+// idempotency key was already stored in the matched rows, and by the mutations that store it
+func (a api) Idempotent(key string, ops ...ovsdb.Operation) ([]ovsdb.Operation, error) {
+	if key == "" {
+		return nil, fmt.Errorf("idempotency key cannot be empty")
+	}
+	tableName := a.cond.Table()
+	table := a.cache.Mapper().Schema.Table(tableName)
+	if table == nil {
+		return nil, fmt.Errorf("table %s not found", tableName)
+	}
+	column := table.Column("external_ids")
+	if column == nil || column.Type != ovsdb.TypeMap || column.TypeObj.Key.Type != ovsdb.TypeString ||
+		column.TypeObj.Value.Type != ovsdb.TypeString || !column.Mutable() {
+		return nil, fmt.Errorf("table %s has no mutable external_ids column to hold the idempotency key", tableName)
+	}
+
+	conditions, err := a.generate()
+	if err != nil {
+		return nil, err
+	}
+	if len(conditions) == 0 {
+		return nil, fmt.Errorf("the condition does not match any row of table %s to hold the idempotency key", tableName)
+	}
+
+	marker, err := ovsdb.NewOvsMap(map[string]string{IdempotencyMarker: key})
+	if err != nil {
+		return nil, err
+	}
+	markerKey, err := ovsdb.NewOvsSet([]string{IdempotencyMarker})
+	if err != nil {
+		return nil, err
+	}
+	guards := make([]ovsdb.Operation, 0, len(conditions))
+	marks := make([]ovsdb.Operation, 0, len(conditions))
+	for _, condition := range conditions {
+		where := append(append([]ovsdb.Condition{}, condition...),
+			ovsdb.NewCondition("external_ids", ovsdb.ConditionIncludes, marker))
+		// Wait (without blocking) until no matched row holds the key
+		guards = append(guards, ovsdb.Operation{
+			Op:              ovsdb.OperationWait,
+			Table:           tableName,
+			Where:           where,
+			Columns:         []string{"_uuid"},
+			Until:           "==",
+			Rows:            []ovsdb.Row{},
+			ExplicitTimeout: true,
+		})
+		marks = append(marks, ovsdb.Operation{
+			Op:    opMutate,
+			Table: tableName,
+			Where: condition,
+			Mutations: []ovsdb.Mutation{
+				*ovsdb.NewMutation("external_ids", ovsdb.MutateOperationDelete, markerKey),
+				*ovsdb.NewMutation("external_ids", ovsdb.MutateOperationInsert, marker),
+			},
+		})
+	}
+	result := append(guards, ops...)
+	return append(result, marks...), nil
+}
+
+// AlreadyApplied returns whether a transaction built with Idempotent failed because it had
+// already been applied, given its operations and the results returned by Transact
+func AlreadyApplied(ops []ovsdb.Operation, results []ovsdb.OperationResult) bool {
+	for i, result := range results {
+		if result.Error == "" {
+			continue
+		}
+		return i < len(ops) && result.Error == "timed out" && isIdempotencyGuard(ops[i])
+	}
+	return false
+}
+
+// isIdempotencyGuard returns whether an operation is one of the wait operations added by Idempotent
+func isIdempotencyGuard(op ovsdb.Operation) bool {
+	if op.Op != ovsdb.OperationWait || len(op.Where) == 0 {
+		return false
+	}
+	last := op.Where[len(op.Where)-1]
+	if last.Column != "external_ids" || last.Function != ovsdb.ConditionIncludes {
+		return false
+	}
+	marker, ok := last.Value.(*ovsdb.OvsMap)
+	if !ok {
+		return false
+	}
+	_, ok = marker.GoMap[IdempotencyMarker]
+	return ok
+}
+
+// checkWeakReferences reports, if enabled, the weak references of the row to rows that are
+// not in the cache of the referenced table
+func (a api) checkWeakReferences(table string, info *mapper.MapperInfo, row ovsdb.Row) {
+	if a.weakRefWarn == nil {
+		return
+	}
+	for _, column := range info.WeakReferenceColumns() {
+		value, ok := row[column]
+		if !ok {
+			continue
+		}
+		refTable, _ := info.WeakReference(column)
+		refCache := a.cache.Table(refTable)
+		if refCache == nil {
+			continue
+		}
+		for _, uuid := range referencedUUIDs(value) {
+			if uuid.IsNamed() || refCache.Row(uuid.GoUUID) != nil {
+				continue
+			}
+			a.weakRefWarn(&ErrDanglingWeakReference{Table: table, Column: column, RefTable: refTable, UUID: uuid.GoUUID})
+		}
+	}
+}
+
+// resolveNamedUUIDs returns an ovs value, a UUID or a set or map of them, with the named UUIDs it
+// holds replaced by the real UUIDs they were given
+func resolveNamedUUIDs(value interface{}, uuids map[string]string) interface{} {
+	resolve := func(elem interface{}) interface{} {
+		if uuid, ok := elem.(ovsdb.UUID); ok {
+			if realUUID, ok := uuids[uuid.GoUUID]; ok {
+				return ovsdb.UUID{GoUUID: realUUID}
+			}
+		}
+		return elem
+	}
+	switch v := value.(type) {
+	case *ovsdb.OvsSet:
+		if v != nil {
+			return resolveNamedUUIDs(*v, uuids)
+		}
+	case ovsdb.OvsSet:
+		set := ovsdb.OvsSet{GoSet: make([]interface{}, 0, len(v.GoSet))}
+		for _, elem := range v.GoSet {
+			set.GoSet = append(set.GoSet, resolve(elem))
+		}
+		return set
+	case *ovsdb.OvsMap:
+		if v != nil {
+			return resolveNamedUUIDs(*v, uuids)
+		}
+	case ovsdb.OvsMap:
+		m := ovsdb.OvsMap{GoMap: make(map[interface{}]interface{}, len(v.GoMap))}
+		for key, elem := range v.GoMap {
+			m.GoMap[resolve(key)] = resolve(elem)
+		}
+		return m
+	}
+	return resolve(value)
+}
+
+// referencedUUIDs returns the UUIDs held by an ovs value: a UUID or a set or map of them
+func referencedUUIDs(value interface{}) []ovsdb.UUID {
+	var uuids []ovsdb.UUID
+	add := func(elem interface{}) {
+		if uuid, ok := elem.(ovsdb.UUID); ok {
+			uuids = append(uuids, uuid)
+		}
+	}
+	switch v := value.(type) {
+	case ovsdb.UUID:
+		add(v)
+	case *ovsdb.OvsSet:
+		for _, elem := range v.GoSet {
+			add(elem)
+		}
+	case ovsdb.OvsSet:
+		for _, elem := range v.GoSet {
+			add(elem)
+		}
+	case *ovsdb.OvsMap:
+		for key, elem := range v.GoMap {
+			add(key)
+			add(elem)
+		}
+	case ovsdb.OvsMap:
+		for key, elem := range v.GoMap {
+			add(key)
+			add(elem)
+		}
+	}
+	return uuids
+}
+
+// checkAffectedRows returns an error if more cached rows than allowed match the condition
+func (a api) checkAffectedRows() error {
+	if a.maxAffectedRows <= 0 {
+		return nil
+	}
+	count := 0
+	err := a.iterate(func(model.Model) (bool, error) {
+		count++
+		return count <= a.maxAffectedRows, nil
+	})
+	if err != nil && err != ErrNotFound {
+		return err
+	}
+	if count > a.maxAffectedRows {
+		return &ErrMaxAffectedRows{Table: a.cond.Table(), Max: a.maxAffectedRows}
+	}
+	return nil
+}
+
+// conditional returns a new ConditionalAPI that shares the configuration of the API
+// and applies to the elements matched by the provided Conditional
+func (a api) conditional(cond Conditional) ConditionalAPI {
+	a.cond = cond
+	return a
+}
+
+// SelectedIndex returns the columns of the index the conditions built from a Model's index data
+// are keyed on
+func (a api) SelectedIndex() []string {
+	if selector, ok := a.cond.(indexSelector); ok {
+		return selector.SelectedIndex()
+	}
+	return nil
 }
@@ -1,9 +1,13 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ovn-org/libovsdb/cache"
 	"github.com/ovn-org/libovsdb/model"
@@ -100,7 +104,15 @@ func TestAPIListSimple(t *testing.T) {
 		var result []string
 		api := newAPI(tcache)
 		err := api.List(&result)
-		assert.NotNil(t, err)
+		assert.EqualError(t, err, "Wrong parameter type (*[]string): type string is not a registered OVSDB model")
+	})
+
+	t.Run("ApiList: Error unregistered model", func(t *testing.T) {
+		var result []testMinimalSwitch
+		api := newAPI(tcache)
+		err := api.List(&result)
+		assert.IsType(t, &ErrWrongType{}, err)
+		assert.Contains(t, err.Error(), "type client.testMinimalSwitch is not a registered OVSDB model")
 	})
 
 	t.Run("ApiList: Type Selection", func(t *testing.T) {
@@ -308,6 +320,251 @@ func TestAPIListFields(t *testing.T) {
 	})
 }
 
+func TestAPIListFieldMatch(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspcacheList := []model.Model{
+		&testLogicalSwitchPort{
+			UUID:        aUUID0,
+			Name:        "lsp0",
+			Addresses:   []string{"00:00:00:00:00:01 10.0.0.1"},
+			ExternalIds: map[string]string{"owner": "foo"},
+		},
+		&testLogicalSwitchPort{
+			UUID:      aUUID1,
+			Name:      "magiclsp1",
+			Addresses: []string{"00:00:00:00:00:02 10.0.1.1"},
+		},
+		&testLogicalSwitchPort{
+			UUID: aUUID2,
+			Name: "lsp2",
+		},
+		&testLogicalSwitchPort{
+			UUID:        aUUID3,
+			Name:        "magiclsp2",
+			Addresses:   []string{"router", "00:00:00:00:00:04 10.0.0.4"},
+			ExternalIds: map[string]string{"owner": "", "other": "bar"},
+		},
+	}
+	lspcache := map[string]model.Model{}
+	for i := range lspcacheList {
+		lspcache[lspcacheList[i].(*testLogicalSwitchPort).UUID] = lspcacheList[i]
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspcache))
+
+	testObj := testLogicalSwitchPort{}
+	test := []struct {
+		name    string
+		cond    func(API) ConditionalAPI
+		content []model.Model
+		err     bool
+	}{
+		{
+			name: "substring of string field",
+			cond: func(a API) ConditionalAPI {
+				return a.WhereFieldContains(&testObj, &testObj.Name, "magic")
+			},
+			content: []model.Model{lspcacheList[1], lspcacheList[3]},
+		},
+		{
+			name: "substring of any element in set",
+			cond: func(a API) ConditionalAPI {
+				return a.WhereFieldContains(&testObj, &testObj.Addresses, "10.0.0.")
+			},
+			content: []model.Model{lspcacheList[0], lspcacheList[3]},
+		},
+		{
+			name: "glob pattern",
+			cond: func(a API) ConditionalAPI {
+				return a.WhereFieldMatches(&testObj, &testObj.Name, "lsp[0-9]")
+			},
+			content: []model.Model{lspcacheList[0], lspcacheList[2]},
+		},
+		{
+			name: "no match",
+			cond: func(a API) ConditionalAPI {
+				return a.WhereFieldMatches(&testObj, &testObj.Name, "foo*")
+			},
+			content: []model.Model{},
+		},
+		{
+			name: "wrong pattern",
+			cond: func(a API) ConditionalAPI {
+				return a.WhereFieldMatches(&testObj, &testObj.Name, "[")
+			},
+			err: true,
+		},
+		{
+			name: "non string column",
+			cond: func(a API) ConditionalAPI {
+				return a.WhereFieldContains(&testObj, &testObj.TagRequest, "1")
+			},
+			err: true,
+		},
+		{
+			name: "map column",
+			cond: func(a API) ConditionalAPI {
+				return a.WhereFieldContains(&testObj, &testObj.ExternalIds, "foo")
+			},
+			err: true,
+		},
+		{
+			name: "map has key",
+			cond: func(a API) ConditionalAPI {
+				return a.WhereMapHasKey(&testObj, &testObj.ExternalIds, "owner")
+			},
+			content: []model.Model{lspcacheList[0], lspcacheList[3]},
+		},
+		{
+			name: "map key of wrong type",
+			cond: func(a API) ConditionalAPI {
+				return a.WhereMapHasKey(&testObj, &testObj.ExternalIds, 1)
+			},
+			err: true,
+		},
+		{
+			name: "map key on non map column",
+			cond: func(a API) ConditionalAPI {
+				return a.WhereMapHasKey(&testObj, &testObj.Name, "owner")
+			},
+			err: true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiListFieldMatch: %s", tt.name), func(t *testing.T) {
+			var result []testLogicalSwitchPort
+			api := newAPI(tcache)
+			err := tt.cond(api).List(&result)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			var expected []testLogicalSwitchPort
+			for _, m := range tt.content {
+				expected = append(expected, *m.(*testLogicalSwitchPort))
+			}
+			assert.ElementsMatch(t, expected, result)
+
+			// Operations match each element by _uuid
+			ops, err := tt.cond(api).Delete()
+			assert.Nil(t, err)
+			assert.Len(t, ops, len(tt.content))
+			for _, op := range ops {
+				assert.Len(t, op.Where, 1)
+				assert.Equal(t, "_uuid", op.Where[0].Column)
+			}
+		})
+	}
+}
+
+func TestAPIWhereMapValue(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspcacheList := []model.Model{
+		&testLogicalSwitchPort{
+			UUID:        aUUID0,
+			Name:        "lsp0",
+			ExternalIds: map[string]string{"env": "prod"},
+		},
+		&testLogicalSwitchPort{
+			UUID:        aUUID1,
+			Name:        "lsp1",
+			ExternalIds: map[string]string{"env": "test", "owner": "foo"},
+		},
+		&testLogicalSwitchPort{
+			UUID: aUUID2,
+			Name: "lsp2",
+		},
+		&testLogicalSwitchPort{
+			UUID:        aUUID3,
+			Name:        "lsp3",
+			ExternalIds: map[string]string{"env": "prod", "owner": "bar"},
+		},
+	}
+	lspcache := map[string]model.Model{}
+	for i := range lspcacheList {
+		lspcache[lspcacheList[i].(*testLogicalSwitchPort).UUID] = lspcacheList[i]
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspcache))
+
+	testObj := testLogicalSwitchPort{}
+	test := []struct {
+		name    string
+		key     interface{}
+		value   interface{}
+		field   interface{}
+		content []model.Model
+		err     bool
+	}{
+		{
+			name:    "key with value",
+			field:   &testObj.ExternalIds,
+			key:     "env",
+			value:   "prod",
+			content: []model.Model{lspcacheList[0], lspcacheList[3]},
+		},
+		{
+			name:  "key with other value",
+			field: &testObj.ExternalIds,
+			key:   "owner",
+			value: "baz",
+		},
+		{
+			name:  "missing key",
+			field: &testObj.ExternalIds,
+			key:   "missing",
+			value: "prod",
+		},
+		{
+			name:  "key of wrong type",
+			field: &testObj.ExternalIds,
+			key:   1,
+			value: "prod",
+			err:   true,
+		},
+		{
+			name:  "value of wrong type",
+			field: &testObj.ExternalIds,
+			key:   "env",
+			value: 1,
+			err:   true,
+		},
+		{
+			name:  "non map column",
+			field: &testObj.Name,
+			key:   "env",
+			value: "prod",
+			err:   true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiWhereMapValue: %s", tt.name), func(t *testing.T) {
+			var result []testLogicalSwitchPort
+			api := newAPI(tcache)
+			err := api.WhereMapValue(&testObj, tt.field, tt.key, tt.value).List(&result)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			var expected []testLogicalSwitchPort
+			for _, m := range tt.content {
+				expected = append(expected, *m.(*testLogicalSwitchPort))
+			}
+			assert.ElementsMatch(t, expected, result)
+
+			// Operations are evaluated by the server
+			ops, err := api.WhereMapValue(&testObj, tt.field, tt.key, tt.value).Delete()
+			assert.Nil(t, err)
+			where := []ovsdb.Condition{{
+				Column:   "external_ids",
+				Function: ovsdb.ConditionIncludes,
+				Value:    testOvsMap(t, map[string]string{tt.key.(string): tt.value.(string)}),
+			}}
+			assert.Equal(t, []ovsdb.Operation{{Op: opDelete, Table: "Logical_Switch_Port", Where: where}}, ops)
+		})
+	}
+}
+
 func TestConditionFromFunc(t *testing.T) {
 	test := []struct {
 		name string
@@ -495,6 +752,60 @@ func TestAPIGet(t *testing.T) {
 	}
 }
 
+func TestAPISelectedIndex(t *testing.T) {
+	tcache := apiTestCache(t)
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0"},
+		// An inconsistent cache holding two rows with the same indexed name
+		aUUID1: &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp1"},
+		aUUID2: &testLogicalSwitchPort{UUID: aUUID2, Name: "lsp1"},
+	}))
+	api := newAPI(tcache)
+
+	test := []struct {
+		name  string
+		model *testLogicalSwitchPort
+		index []string
+	}{
+		{
+			name:  "by UUID",
+			model: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0"},
+			index: []string{"_uuid"},
+		},
+		{
+			name:  "by schema index",
+			model: &testLogicalSwitchPort{Name: "lsp0"},
+			index: []string{"name"},
+		},
+		{
+			name:  "no index",
+			model: &testLogicalSwitchPort{Type: "router"},
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiSelectedIndex: %s", tt.name), func(t *testing.T) {
+			assert.Equal(t, tt.index, api.Where(tt.model).SelectedIndex())
+		})
+	}
+	t.Run("ApiSelectedIndex: not built from index data", func(t *testing.T) {
+		lsp := &testLogicalSwitchPort{Name: "lsp0"}
+		assert.Nil(t, api.Where(lsp, model.Condition{Field: &lsp.Name, Function: ovsdb.ConditionEqual, Value: "lsp0"}).SelectedIndex())
+		assert.Nil(t, api.WhereCache(func(*testLogicalSwitchPort) bool { return true }).SelectedIndex())
+	})
+
+	t.Run("ApiSelectedIndex: multiple matches", func(t *testing.T) {
+		lsp := &testLogicalSwitchPort{Name: "lsp1"}
+		err := api.Get(lsp)
+		var multipleErr *ErrMultipleMatches
+		assert.True(t, errors.As(err, &multipleErr), "expected ErrMultipleMatches, got %v", err)
+		assert.Equal(t, &ErrMultipleMatches{Table: "Logical_Switch_Port", Index: []string{"name"}, UUIDs: []string{aUUID1, aUUID2}}, multipleErr)
+		assert.Equal(t, &testLogicalSwitchPort{Name: "lsp1"}, lsp, "the model is not modified")
+
+		lsp = &testLogicalSwitchPort{UUID: aUUID2, Name: "lsp1"}
+		assert.Nil(t, api.Get(lsp))
+	})
+}
+
 func TestAPICreate(t *testing.T) {
 	tcache := apiTestCache(t)
 	lsCacheList := []model.Model{}
@@ -593,6 +904,57 @@ func TestAPICreate(t *testing.T) {
 			}},
 			err: false,
 		},
+		{
+			name: "WithNamedUUID overrides the UUID field",
+			input: []model.Model{&testLogicalSwitch{
+				UUID: aUUID0,
+				Name: "foo",
+			}, WithNamedUUID("myref")},
+			result: []ovsdb.Operation{{
+				Op:       "insert",
+				Table:    "Logical_Switch",
+				Row:      rowFoo,
+				UUIDName: "myref",
+			}},
+			err: false,
+		},
+		{
+			name: "WithNamedUUID applies to the preceding model",
+			input: []model.Model{
+				&testLogicalSwitch{
+					Name: "foo",
+				},
+				WithNamedUUID("fooref"),
+				&testLogicalSwitch{
+					UUID: "barUUID",
+					Name: "bar",
+				},
+			},
+			result: []ovsdb.Operation{{
+				Op:       "insert",
+				Table:    "Logical_Switch",
+				Row:      rowFoo,
+				UUIDName: "fooref",
+			}, {
+				Op:       "insert",
+				Table:    "Logical_Switch",
+				Row:      rowBar,
+				UUIDName: "barUUID",
+			}},
+			err: false,
+		},
+		{
+			name:  "WithNamedUUID without model",
+			input: []model.Model{WithNamedUUID("myref")},
+			err:   true,
+		},
+		{
+			name: "WithNamedUUID invalid",
+			input: []model.Model{&testLogicalSwitch{
+				Name: "foo",
+			}, WithNamedUUID("my-ref")},
+			err: true,
+		},
 	}
 	for _, tt := range test {
 		t.Run(fmt.Sprintf("ApiCreate: %s", tt.name), func(t *testing.T) {
@@ -608,46 +970,282 @@ func TestAPICreate(t *testing.T) {
 	}
 }
 
-func TestAPIMutate(t *testing.T) {
+func TestAPICreateAndReferenceNamedUUID(t *testing.T) {
 	tcache := apiTestCache(t)
-	lspCache := map[string]model.Model{
-		aUUID0: &testLogicalSwitchPort{
-			UUID:        aUUID0,
-			Name:        "lsp0",
-			Type:        "someType",
-			ExternalIds: map[string]string{"foo": "bar"},
-			Enabled:     []bool{true},
-			Tag:         []int{1},
-		},
-		aUUID1: &testLogicalSwitchPort{
-			UUID:        aUUID1,
-			Name:        "lsp1",
-			Type:        "someType",
-			ExternalIds: map[string]string{"foo": "baz"},
-			Tag:         []int{1},
-		},
-		aUUID2: &testLogicalSwitchPort{
-			UUID:        aUUID2,
-			Name:        "lsp2",
-			Type:        "someOtherType",
-			ExternalIds: map[string]string{"foo": "baz"},
-			Tag:         []int{1},
+	lsCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitch{
+			UUID: aUUID0,
+			Name: "ls0",
 		},
 	}
+	tcache.Set("Logical_Switch", cache.NewRowCache(lsCache))
+	api := newAPI(tcache)
+
+	// Insert a port and add it to the parent switch in the same transaction
+	lsp := testLogicalSwitchPort{
+		Name: "lsp0",
+	}
+	createOps, err := api.Create(&lsp, WithNamedUUID("newport"))
+	assert.Nil(t, err)
+
+	ls := testLogicalSwitch{}
+	mutateOps, err := api.Where(&testLogicalSwitch{UUID: aUUID0}).Mutate(&ls, model.Mutation{
+		Field:   &ls.Ports,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   []ovsdb.UUID{{GoUUID: "newport"}},
+	})
+	assert.Nil(t, err)
+
+	operations := append(createOps, mutateOps...)
+	assert.Nil(t, ovsdb.ValidateNamedUUIDs(operations...))
+
+	b, err := json.Marshal(ovsdb.NewTransactArgs("OVN_Northbound", operations...))
+	assert.Nil(t, err)
+	expected := fmt.Sprintf(`["OVN_Northbound",
+		{"op": "insert", "table": "Logical_Switch_Port", "row": {"name": "lsp0"}, "uuid-name": "newport"},
+		{"op": "mutate", "table": "Logical_Switch",
+		 "mutations": [["ports", "insert", ["named-uuid", "newport"]]],
+		 "where": [["_uuid", "==", ["uuid", "%s"]]]}]`, aUUID0)
+	assert.JSONEq(t, expected, string(b))
+
+	// Referencing a row that is not inserted in the same transaction must fail
+	assert.NotNil(t, ovsdb.ValidateNamedUUIDs(mutateOps...))
+}
+
+func TestAPICreateWithRealUUID(t *testing.T) {
+	tcache := apiTestCache(t)
+	api := newAPI(tcache)
+
+	lsp := testLogicalSwitchPort{UUID: "newport", Name: "lsp0"}
+	createOps, err := api.Create(&lsp, WithRealUUID(aUUID1))
+	assert.Nil(t, err)
+	ls := testLogicalSwitch{}
+	mutateOps, err := api.Where(&testLogicalSwitch{UUID: aUUID0}).Mutate(&ls, model.Mutation{
+		Field:   &ls.Ports,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   []string{aUUID1},
+	})
+	assert.Nil(t, err)
+	operations := append(createOps, mutateOps...)
+	assert.Nil(t, ovsdb.ValidateNamedUUIDs(operations...))
+
+	b, err := json.Marshal(ovsdb.NewTransactArgs("OVN_Northbound", operations...))
+	assert.Nil(t, err)
+	expected := fmt.Sprintf(`["OVN_Northbound",
+		{"op": "insert", "table": "Logical_Switch_Port", "row": {"name": "lsp0"}, "uuid": "%s"},
+		{"op": "mutate", "table": "Logical_Switch",
+		 "mutations": [["ports", "insert", ["uuid", "%s"]]],
+		 "where": [["_uuid", "==", ["uuid", "%s"]]]}]`, aUUID1, aUUID1, aUUID0)
+	assert.JSONEq(t, expected, string(b))
+
+	_, err = api.Create(&lsp, WithRealUUID("newport"))
+	assert.NotNil(t, err)
+
+	// Servers that do not support choosing the UUID reject the operation
+	results := []ovsdb.OperationResult{
+		{Error: "syntax error", Details: "Member 'uuid' is present but not allowed here."},
+		{Error: "", Details: ""},
+	}
+	_, err = ovsdb.CheckOperationResults(results, operations)
+	assert.True(t, RealUUIDUnsupported(err))
+	createOps, err = api.Create(&lsp)
+	assert.Nil(t, err)
+	_, err = ovsdb.CheckOperationResults(results, createOps)
+	assert.False(t, RealUUIDUnsupported(err))
+	assert.False(t, RealUUIDUnsupported(nil))
+}
+
+func TestAPICreateOrGet(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "router"},
+	}
 	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspCache))
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go tcache.Run(stopCh)
+	api := newAPI(tcache)
 
-	testObj := testLogicalSwitchPort{}
+	// The models are inserted whether a row holds their index values or not
+	existing := &testLogicalSwitchPort{UUID: "port0", Name: "lsp0"}
+	created := &testLogicalSwitchPort{UUID: "port1", Name: "lsp1"}
+	existingOpt := CreateOrGet()
+	createdOpt := CreateOrGet()
+	ops, err := api.Create(existing, existingOpt, WithNamedUUID("existing"), created, createdOpt, WithNamedUUID("newport"))
+	assert.Nil(t, err)
+	assert.Equal(t, []ovsdb.Operation{{
+		Op:       opInsert,
+		Table:    "Logical_Switch_Port",
+		Row:      ovsdb.Row{"name": "lsp0"},
+		UUIDName: "existing",
+	}, {
+		Op:       opInsert,
+		Table:    "Logical_Switch_Port",
+		Row:      ovsdb.Row{"name": "lsp1"},
+		UUIDName: "newport",
+	}}, ops)
 
-	test := []struct {
-		name      string
-		condition func(API) ConditionalAPI
-		model     model.Model
-		mutations []model.Mutation
-		init      map[string]model.Model
-		result    []ovsdb.Operation
-		err       bool
-	}{
-		{
+	t.Run("ApiCreateOrGet: the existing row is read back on a constraint violation", func(t *testing.T) {
+		_, txnErr := ovsdb.CheckOperationResults([]ovsdb.OperationResult{{Error: "constraint violation"}, {}}, ops)
+		err := existingOpt.Resolve(context.Background(), txnErr)
+		assert.Nil(t, err)
+		assert.True(t, existingOpt.Existed())
+		assert.Equal(t, &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "router"}, existing, "the model holds the existing row")
+	})
+
+	t.Run("ApiCreateOrGet: the existing row is waited for in the cache", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		go func() {
+			row := ovsdb.Row{"_uuid": ovsdb.UUID{GoUUID: aUUID1}, "name": "lsp1", "type": "router"}
+			tcache.Populate(ovsdb.TableUpdates{
+				"Logical_Switch_Port": {aUUID1: &ovsdb.RowUpdate{New: &row}},
+			})
+		}()
+		// Constraint violations detected when committing are not reported for any operation
+		_, txnErr := ovsdb.CheckOperationResults([]ovsdb.OperationResult{{}, {}, {Error: "constraint violation"}}, ops)
+		err := createdOpt.Resolve(ctx, txnErr)
+		assert.Nil(t, err)
+		assert.True(t, createdOpt.Existed())
+		assert.Equal(t, &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp1", Type: "router"}, created)
+	})
+
+	t.Run("ApiCreateOrGet: other errors are returned", func(t *testing.T) {
+		err := existingOpt.Resolve(context.Background(), nil)
+		assert.Nil(t, err)
+		assert.False(t, existingOpt.Existed())
+
+		_, txnErr := ovsdb.CheckOperationResults([]ovsdb.OperationResult{{Error: "referential integrity violation"}, {}}, ops)
+		err = existingOpt.Resolve(context.Background(), txnErr)
+		assert.Equal(t, txnErr, err)
+		assert.False(t, existingOpt.Existed())
+
+		// The existing row is not cached in time
+		missing := &testLogicalSwitchPort{UUID: "port2", Name: "lsp2"}
+		opt := CreateOrGet()
+		missingOps, err := api.Create(missing, opt)
+		assert.Nil(t, err)
+		_, txnErr = ovsdb.CheckOperationResults([]ovsdb.OperationResult{{Error: "constraint violation"}}, missingOps)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err = opt.Resolve(ctx, txnErr)
+		assert.Equal(t, txnErr, err)
+		assert.False(t, opt.Existed())
+		assert.Equal(t, &testLogicalSwitchPort{UUID: "port2", Name: "lsp2"}, missing)
+
+		// Without index values there is nothing to compare the model with
+		opt = CreateOrGet()
+		noIndexOps, err := api.Create(&testLogicalSwitchPort{UUID: "port3", Type: "router"}, opt)
+		assert.Nil(t, err)
+		_, txnErr = ovsdb.CheckOperationResults([]ovsdb.OperationResult{{Error: "constraint violation"}}, noIndexOps)
+		err = opt.Resolve(context.Background(), txnErr)
+		assert.Equal(t, txnErr, err)
+		assert.False(t, opt.Existed())
+	})
+
+	_, err = api.Create(CreateOrGet())
+	assert.NotNil(t, err)
+	assert.NotNil(t, CreateOrGet().Resolve(context.Background(), nil))
+
+	// A row created concurrently makes the transaction fail when it is committed
+	ops, err = api.Create(&testLogicalSwitchPort{UUID: "port3", Name: "lsp3"})
+	assert.Nil(t, err)
+	_, err = ovsdb.CheckOperationResults([]ovsdb.OperationResult{{}, {Error: "constraint violation"}}, ops)
+	assert.True(t, IndexConflict(err))
+	_, err = ovsdb.CheckOperationResults([]ovsdb.OperationResult{{Error: "referential integrity violation"}}, ops)
+	assert.False(t, IndexConflict(err))
+	assert.False(t, IndexConflict(nil))
+}
+
+func TestAPICheckIndexes(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0"},
+		aUUID1: &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp1"},
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspCache))
+	api := newAPI(tcache)
+
+	t.Run("ApiCheckIndexes: no collisions", func(t *testing.T) {
+		err := api.CheckIndexes(
+			&testLogicalSwitchPort{Name: "lsp2"},
+			WithNamedUUID("newport"),
+			&testLogicalSwitchPort{Name: "lsp3"},
+			&testLogicalSwitch{Name: "lsp2"},
+			&testLogicalSwitchPort{},
+		)
+		assert.Nil(t, err)
+	})
+
+	t.Run("ApiCheckIndexes: a cached row does not collide with itself", func(t *testing.T) {
+		err := api.CheckIndexes(&testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "router"})
+		assert.Nil(t, err)
+	})
+
+	t.Run("ApiCheckIndexes: collisions", func(t *testing.T) {
+		first := &testLogicalSwitchPort{Name: "lsp2"}
+		second := &testLogicalSwitchPort{Name: "lsp2"}
+		cached := &testLogicalSwitchPort{Name: "lsp1"}
+		named := &testLogicalSwitchPort{UUID: "port", Name: "lsp4"}
+		sameNamed := &testLogicalSwitchPort{UUID: "port", Name: "lsp5"}
+		err := api.CheckIndexes(first, second, cached, named, sameNamed)
+		var indexErr *ErrIndexCollision
+		assert.True(t, errors.As(err, &indexErr), "expected ErrIndexCollision, got %v", err)
+		assert.Equal(t, []IndexCollision{
+			{Table: "Logical_Switch_Port", Index: []string{"name"}, Model: second, Other: first},
+			{Table: "Logical_Switch_Port", Index: []string{"_uuid"}, Model: sameNamed, Other: named},
+			{Table: "Logical_Switch_Port", Index: []string{"name"}, Model: cached, Other: lspCache[aUUID1], Cached: true},
+		}, indexErr.Collisions)
+	})
+
+	t.Run("ApiCheckIndexes: wrong model", func(t *testing.T) {
+		err := api.CheckIndexes(&struct{ Name string }{Name: "foo"})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestAPIMutate(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{
+			UUID:        aUUID0,
+			Name:        "lsp0",
+			Type:        "someType",
+			ExternalIds: map[string]string{"foo": "bar"},
+			Enabled:     []bool{true},
+			Tag:         []int{1},
+		},
+		aUUID1: &testLogicalSwitchPort{
+			UUID:        aUUID1,
+			Name:        "lsp1",
+			Type:        "someType",
+			ExternalIds: map[string]string{"foo": "baz"},
+			Tag:         []int{1},
+		},
+		aUUID2: &testLogicalSwitchPort{
+			UUID:        aUUID2,
+			Name:        "lsp2",
+			Type:        "someOtherType",
+			ExternalIds: map[string]string{"foo": "baz"},
+			Tag:         []int{1},
+		},
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspCache))
+
+	testObj := testLogicalSwitchPort{}
+	replaceMutations, err := model.NewMapValueReplaceMutations(&testObj.ExternalIds, "foo", "qux")
+	assert.Nil(t, err)
+
+	test := []struct {
+		name      string
+		condition func(API) ConditionalAPI
+		model     model.Model
+		mutations []model.Mutation
+		init      map[string]model.Model
+		result    []ovsdb.Operation
+		err       bool
+	}{
+		{
 			name: "select by UUID addElement to set",
 			condition: func(a API) ConditionalAPI {
 				return a.Where(&testLogicalSwitch{
@@ -749,6 +1347,59 @@ func TestAPIMutate(t *testing.T) {
 			},
 			err: false,
 		},
+		{
+			name: "select by name replace value in map",
+			condition: func(a API) ConditionalAPI {
+				return a.Where(&testLogicalSwitchPort{
+					Name: "lsp2",
+				})
+			},
+			mutations: replaceMutations,
+			result: []ovsdb.Operation{
+				{
+					Op:    opMutate,
+					Table: "Logical_Switch_Port",
+					Mutations: []ovsdb.Mutation{
+						{Column: "external_ids", Mutator: ovsdb.MutateOperationDelete, Value: testOvsSet(t, []string{"foo"})},
+						{Column: "external_ids", Mutator: ovsdb.MutateOperationInsert, Value: testOvsMap(t, map[string]string{"foo": "qux"})},
+					},
+					Where: []ovsdb.Condition{{Column: "name", Function: ovsdb.ConditionEqual, Value: "lsp2"}},
+				},
+			},
+			err: false,
+		},
+		{
+			name: "Division by zero should error",
+			condition: func(a API) ConditionalAPI {
+				return a.Where(&testLogicalSwitchPort{
+					UUID: aUUID0,
+				})
+			},
+			mutations: []model.Mutation{
+				{
+					Field:   &testObj.Tag,
+					Mutator: ovsdb.MutateOperationDivide,
+					Value:   0,
+				},
+			},
+			err: true,
+		},
+		{
+			name: "Arithmetic on a string column should error",
+			condition: func(a API) ConditionalAPI {
+				return a.Where(&testLogicalSwitchPort{
+					UUID: aUUID0,
+				})
+			},
+			mutations: []model.Mutation{
+				{
+					Field:   &testObj.Type,
+					Mutator: ovsdb.MutateOperationAdd,
+					Value:   "foo",
+				},
+			},
+			err: true,
+		},
 		{
 			name: "No mutations should error",
 			condition: func(a API) ConditionalAPI {
@@ -775,6 +1426,56 @@ func TestAPIMutate(t *testing.T) {
 	}
 }
 
+func TestAPIToOvsdbMutation(t *testing.T) {
+	tcache := apiTestCache(t)
+	api := newAPI(tcache)
+	lsp := &testLogicalSwitchPort{}
+
+	test := []struct {
+		name     string
+		mutation model.Mutation
+		expected ovsdb.Mutation
+		err      bool
+	}{
+		{
+			name:     "set insert",
+			mutation: model.Mutation{Field: &lsp.Tag, Mutator: ovsdb.MutateOperationInsert, Value: []int{5}},
+			expected: ovsdb.Mutation{Column: "tag", Mutator: ovsdb.MutateOperationInsert, Value: testOvsSet(t, []int{5})},
+		},
+		{
+			name:     "map delete",
+			mutation: model.Mutation{Field: &lsp.ExternalIds, Mutator: ovsdb.MutateOperationDelete, Value: map[string]string{"foo": "bar"}},
+			expected: ovsdb.Mutation{Column: "external_ids", Mutator: ovsdb.MutateOperationDelete, Value: testOvsMap(t, map[string]string{"foo": "bar"})},
+		},
+		{
+			name:     "wrong mutator for the column type",
+			mutation: model.Mutation{Field: &lsp.Name, Mutator: ovsdb.MutateOperationAdd, Value: "foo"},
+			err:      true,
+		},
+		{
+			name:     "wrong value type",
+			mutation: model.Mutation{Field: &lsp.Tag, Mutator: ovsdb.MutateOperationInsert, Value: []string{"foo"}},
+			err:      true,
+		},
+		{
+			name:     "field of another model",
+			mutation: model.Mutation{Field: &(&testLogicalSwitchPort{}).Tag, Mutator: ovsdb.MutateOperationInsert, Value: []int{5}},
+			err:      true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiToOvsdbMutation: %s", tt.name), func(t *testing.T) {
+			mutation, err := api.ToOvsdbMutation(lsp, tt.mutation)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, mutation)
+		})
+	}
+}
+
 func TestAPIUpdate(t *testing.T) {
 	tcache := apiTestCache(t)
 	lspCache := map[string]model.Model{
@@ -827,7 +1528,7 @@ func TestAPIUpdate(t *testing.T) {
 			},
 			result: []ovsdb.Operation{
 				{
-					Op:    opUpdate,
+					Op:    "update",
 					Table: "Logical_Switch_Port",
 					Row:   testRow,
 					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID0}}},
@@ -848,7 +1549,7 @@ func TestAPIUpdate(t *testing.T) {
 			},
 			result: []ovsdb.Operation{
 				{
-					Op:    opUpdate,
+					Op:    "update",
 					Table: "Logical_Switch_Port",
 					Row:   testRow,
 					Where: []ovsdb.Condition{{Column: "name", Function: ovsdb.ConditionEqual, Value: "lsp1"}},
@@ -874,7 +1575,7 @@ func TestAPIUpdate(t *testing.T) {
 			},
 			result: []ovsdb.Operation{
 				{
-					Op:    opUpdate,
+					Op:    "update",
 					Table: "Logical_Switch_Port",
 					Row:   tagRow,
 					Where: []ovsdb.Condition{{Column: "type", Function: ovsdb.ConditionEqual, Value: "sometype"}},
@@ -903,13 +1604,13 @@ func TestAPIUpdate(t *testing.T) {
 			},
 			result: []ovsdb.Operation{
 				{
-					Op:    opUpdate,
+					Op:    "update",
 					Table: "Logical_Switch_Port",
 					Row:   tagRow,
 					Where: []ovsdb.Condition{{Column: "type", Function: ovsdb.ConditionEqual, Value: "sometype"}},
 				},
 				{
-					Op:    opUpdate,
+					Op:    "update",
 					Table: "Logical_Switch_Port",
 					Row:   tagRow,
 					Where: []ovsdb.Condition{{Column: "enabled", Function: ovsdb.ConditionIncludes, Value: testOvsSet(t, []bool{true})}},
@@ -938,7 +1639,7 @@ func TestAPIUpdate(t *testing.T) {
 			},
 			result: []ovsdb.Operation{
 				{
-					Op:    opUpdate,
+					Op:    "update",
 					Table: "Logical_Switch_Port",
 					Row:   tagRow,
 					Where: []ovsdb.Condition{
@@ -967,7 +1668,7 @@ func TestAPIUpdate(t *testing.T) {
 			},
 			result: []ovsdb.Operation{
 				{
-					Op:    opUpdate,
+					Op:    "update",
 					Table: "Logical_Switch_Port",
 					Row:   tagRow,
 					Where: []ovsdb.Condition{{Column: "type", Function: ovsdb.ConditionNotEqual, Value: "sometype"}},
@@ -988,13 +1689,13 @@ func TestAPIUpdate(t *testing.T) {
 			},
 			result: []ovsdb.Operation{
 				{
-					Op:    opUpdate,
+					Op:    "update",
 					Table: "Logical_Switch_Port",
 					Row:   testRow,
 					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID0}}},
 				},
 				{
-					Op:    opUpdate,
+					Op:    "update",
 					Table: "Logical_Switch_Port",
 					Row:   testRow,
 					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID1}}},
@@ -1205,3 +1906,2123 @@ func TestAPIDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIDeleteByUUID(t *testing.T) {
+	tcache := apiTestCache(t)
+	test := []struct {
+		name   string
+		table  string
+		uuids  []string
+		result []ovsdb.Operation
+		err    bool
+	}{
+		{
+			name:  "one operation per uuid",
+			table: "Logical_Switch",
+			uuids: []string{aUUID0, aUUID1},
+			result: []ovsdb.Operation{
+				{
+					Op:    opDelete,
+					Table: "Logical_Switch",
+					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID0}}},
+				},
+				{
+					Op:    opDelete,
+					Table: "Logical_Switch",
+					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID1}}},
+				},
+			},
+		},
+		{
+			name:  "no uuids",
+			table: "Logical_Switch",
+		},
+		{
+			name:  "malformed uuid fails before emitting operations",
+			table: "Logical_Switch",
+			uuids: []string{aUUID0, "notAUUID"},
+			err:   true,
+		},
+		{
+			name:  "wrong table",
+			table: "Foo",
+			uuids: []string{aUUID0},
+			err:   true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiDeleteByUUID: %s", tt.name), func(t *testing.T) {
+			api := newAPI(tcache)
+			ops, err := api.DeleteByUUID(tt.table, tt.uuids...)
+			if tt.err {
+				assert.NotNil(t, err)
+				assert.Nil(t, ops)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.result, ops)
+			}
+		})
+	}
+}
+
+func TestAPIUpdateFunc(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{
+			UUID:        aUUID0,
+			Name:        "lsp0",
+			Type:        "someType",
+			ExternalIds: map[string]string{"foo": "bar"},
+			Tag:         []int{1},
+		},
+		aUUID1: &testLogicalSwitchPort{
+			UUID:        aUUID1,
+			Name:        "lsp1",
+			Type:        "someType",
+			ExternalIds: map[string]string{"foo": "baz"},
+			Tag:         []int{2},
+		},
+		aUUID2: &testLogicalSwitchPort{
+			UUID:        aUUID2,
+			Name:        "lsp2",
+			Type:        "someOtherType",
+			ExternalIds: map[string]string{"foo": "baz"},
+			Tag:         []int{3},
+		},
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspCache))
+
+	test := []struct {
+		name      string
+		condition func(API) ConditionalAPI
+		fn        func(model.Model) model.Model
+		result    []ovsdb.Operation
+		err       bool
+	}{
+		{
+			name: "per row value",
+			condition: func(a API) ConditionalAPI {
+				return a.WhereCache(func(lsp *testLogicalSwitchPort) bool {
+					return lsp.Type == "someType"
+				})
+			},
+			fn: func(m model.Model) model.Model {
+				lsp := m.(*testLogicalSwitchPort)
+				lsp.Tag = []int{lsp.Tag[0] + 10}
+				return lsp
+			},
+			result: []ovsdb.Operation{
+				{
+					Op:    "update",
+					Table: "Logical_Switch_Port",
+					Row:   ovsdb.Row(map[string]interface{}{"tag": testOvsSet(t, []int{11})}),
+					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID0}}},
+				},
+				{
+					Op:    "update",
+					Table: "Logical_Switch_Port",
+					Row:   ovsdb.Row(map[string]interface{}{"tag": testOvsSet(t, []int{12})}),
+					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID1}}},
+				},
+			},
+		},
+		{
+			name: "unchanged rows are skipped",
+			condition: func(a API) ConditionalAPI {
+				return a.WhereCache(func(lsp *testLogicalSwitchPort) bool {
+					return true
+				})
+			},
+			fn: func(m model.Model) model.Model {
+				lsp := m.(*testLogicalSwitchPort)
+				if lsp.Name == "lsp2" {
+					lsp.ExternalIds["foo"] = "bar"
+				}
+				return lsp
+			},
+			result: []ovsdb.Operation{
+				{
+					Op:    "update",
+					Table: "Logical_Switch_Port",
+					Row:   ovsdb.Row(map[string]interface{}{"external_ids": testOvsMap(t, map[string]string{"foo": "bar"})}),
+					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID2}}},
+				},
+			},
+		},
+		{
+			name: "nil is unchanged",
+			condition: func(a API) ConditionalAPI {
+				return a.Where(&testLogicalSwitchPort{Name: "lsp0"})
+			},
+			fn: func(m model.Model) model.Model {
+				return nil
+			},
+		},
+		{
+			name: "cleared fields are updated",
+			condition: func(a API) ConditionalAPI {
+				return a.Where(&testLogicalSwitchPort{Name: "lsp0"})
+			},
+			fn: func(m model.Model) model.Model {
+				lsp := m.(*testLogicalSwitchPort)
+				lsp.Tag = []int{}
+				return lsp
+			},
+			result: []ovsdb.Operation{
+				{
+					Op:    "update",
+					Table: "Logical_Switch_Port",
+					Row:   ovsdb.Row(map[string]interface{}{"tag": testOvsSet(t, []int{})}),
+					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID0}}},
+				},
+			},
+		},
+		{
+			name: "uuid cannot be updated",
+			condition: func(a API) ConditionalAPI {
+				return a.Where(&testLogicalSwitchPort{Name: "lsp0"})
+			},
+			fn: func(m model.Model) model.Model {
+				lsp := m.(*testLogicalSwitchPort)
+				lsp.UUID = aUUID3
+				return lsp
+			},
+			err: true,
+		},
+		{
+			name: "wrong model",
+			condition: func(a API) ConditionalAPI {
+				return a.Where(&testLogicalSwitchPort{Name: "lsp0"})
+			},
+			fn: func(m model.Model) model.Model {
+				return &testLogicalSwitch{UUID: aUUID0}
+			},
+			err: true,
+		},
+		{
+			name: "wrong condition",
+			condition: func(a API) ConditionalAPI {
+				return a.WhereCache(func(string) bool { return true })
+			},
+			fn: func(m model.Model) model.Model {
+				return m
+			},
+			err: true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiUpdateFunc: %s", tt.name), func(t *testing.T) {
+			api := newAPI(tcache)
+			ops, err := tt.condition(api).UpdateFunc(tt.fn)
+			if tt.err {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.ElementsMatch(t, tt.result, ops)
+			}
+		})
+	}
+
+	// The cached models must not have been modified
+	assert.Equal(t, map[string]string{"foo": "baz"}, lspCache[aUUID2].(*testLogicalSwitchPort).ExternalIds)
+	assert.Equal(t, []int{1}, lspCache[aUUID0].(*testLogicalSwitchPort).Tag)
+}
+
+func TestAPIForEach(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "someType"},
+		aUUID1: &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp1", Type: "someType"},
+		aUUID2: &testLogicalSwitchPort{UUID: aUUID2, Name: "lsp2", Type: "someOtherType"},
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspCache))
+	api := newAPI(tcache)
+
+	t.Run("ApiForEach: matching rows", func(t *testing.T) {
+		var names []string
+		err := api.WhereCache(func(lsp *testLogicalSwitchPort) bool {
+			return lsp.Type == "someType"
+		}).ForEach(func(m model.Model) error {
+			lsp := m.(*testLogicalSwitchPort)
+			names = append(names, lsp.Name)
+			// Changes must not reach the cache
+			lsp.Name = "modified"
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{"lsp0", "lsp1"}, names)
+		assert.Equal(t, "lsp0", lspCache[aUUID0].(*testLogicalSwitchPort).Name)
+	})
+
+	t.Run("ApiForEach: stops on error", func(t *testing.T) {
+		calls := 0
+		stopErr := fmt.Errorf("stop")
+		err := api.WhereCache(func(lsp *testLogicalSwitchPort) bool {
+			return true
+		}).ForEach(func(m model.Model) error {
+			calls++
+			return stopErr
+		})
+		assert.Equal(t, stopErr, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("ApiForEach: wrong condition", func(t *testing.T) {
+		err := api.WhereCache(func(string) bool {
+			return true
+		}).ForEach(func(m model.Model) error {
+			return nil
+		})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestAPIExists(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "someType"},
+		aUUID1: &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp1", Type: "someType"},
+		aUUID2: &testLogicalSwitchPort{UUID: aUUID2, Name: "lsp2", Type: "someOtherType"},
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspCache))
+	api := newAPI(tcache)
+
+	test := []struct {
+		name      string
+		condition func(API) ConditionalAPI
+		exists    bool
+		err       error
+	}{
+		{
+			name: "match",
+			condition: func(a API) ConditionalAPI {
+				return a.Where(&testLogicalSwitchPort{Name: "lsp2"})
+			},
+			exists: true,
+		},
+		{
+			name: "no match",
+			condition: func(a API) ConditionalAPI {
+				return a.Where(&testLogicalSwitchPort{Name: "lsp3"})
+			},
+			exists: false,
+		},
+		{
+			name: "predicate stops at first match",
+			condition: func(a API) ConditionalAPI {
+				calls := 0
+				return a.WhereCache(func(lsp *testLogicalSwitchPort) bool {
+					calls++
+					if calls > 1 {
+						t.Errorf("predicate called after the first match")
+					}
+					return true
+				})
+			},
+			exists: true,
+		},
+		{
+			name: "table not cached",
+			condition: func(a API) ConditionalAPI {
+				return a.Where(&testLogicalSwitch{Name: "ls0"})
+			},
+			err: ErrNotFound,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiExists: %s", tt.name), func(t *testing.T) {
+			exists, err := tt.condition(api).Exists()
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.exists, exists)
+		})
+	}
+
+	t.Run("ApiExists: wrong condition", func(t *testing.T) {
+		_, err := api.WhereCache(func(string) bool {
+			return true
+		}).Exists()
+		assert.NotNil(t, err)
+	})
+}
+
+func TestAPIAssert(t *testing.T) {
+	tcache := apiTestCache(t)
+	api := newAPI(tcache)
+	lock := "leader"
+	assert.Equal(t, ovsdb.Operation{Op: opAssert, Lock: &lock}, api.Assert("leader"))
+}
+
+func TestAPIReconcile(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "someType", ExternalIds: map[string]string{"id": "0"}},
+		aUUID1: &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp1", Type: "someType", ExternalIds: map[string]string{"id": "1"}},
+		aUUID2: &testLogicalSwitchPort{UUID: aUUID2, Name: "lsp2", Type: "someType", ExternalIds: map[string]string{"id": "2"}},
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspCache))
+	api := newAPI(tcache)
+
+	byID := func(m model.Model) string {
+		return m.(*testLogicalSwitchPort).ExternalIds["id"]
+	}
+
+	test := []struct {
+		name    string
+		desired []model.Model
+		opts    []ReconcileOption
+		result  []ovsdb.Operation
+		err     bool
+	}{
+		{
+			name: "match by index",
+			desired: []model.Model{
+				&testLogicalSwitchPort{Name: "lsp0", Type: "otherType", ExternalIds: map[string]string{"id": "0"}},
+				&testLogicalSwitchPort{Name: "lsp1", Type: "someType", ExternalIds: map[string]string{"id": "1"}},
+				&testLogicalSwitchPort{Name: "lsp3", Type: "someType"},
+			},
+			result: []ovsdb.Operation{
+				{
+					Op:    "update",
+					Table: "Logical_Switch_Port",
+					Row:   ovsdb.Row{"type": "otherType"},
+					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID0}}},
+				},
+				{
+					Op:    opInsert,
+					Table: "Logical_Switch_Port",
+					Row:   ovsdb.Row{"name": "lsp3", "type": "someType"},
+				},
+				{
+					Op:    opDelete,
+					Table: "Logical_Switch_Port",
+					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID2}}},
+				},
+			},
+		},
+		{
+			name: "match by key ignoring columns",
+			desired: []model.Model{
+				&testLogicalSwitchPort{Name: "lsp0-renamed", Type: "otherType", ExternalIds: map[string]string{"id": "0"}},
+				&testLogicalSwitchPort{Name: "lsp1", Type: "otherType", ExternalIds: map[string]string{"id": "1"}},
+				&testLogicalSwitchPort{Name: "lsp2", Type: "someType", ExternalIds: map[string]string{"id": "2"}},
+			},
+			opts: []ReconcileOption{WithReconcileKey(byID), WithReconcileIgnoreColumns("type")},
+			result: []ovsdb.Operation{
+				{
+					Op:    "update",
+					Table: "Logical_Switch_Port",
+					Row:   ovsdb.Row{"name": "lsp0-renamed"},
+					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID0}}},
+				},
+			},
+		},
+		{
+			name:    "empty desired deletes all",
+			desired: []model.Model{},
+			result: []ovsdb.Operation{
+				{
+					Op:    opDelete,
+					Table: "Logical_Switch_Port",
+					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID0}}},
+				},
+				{
+					Op:    opDelete,
+					Table: "Logical_Switch_Port",
+					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID1}}},
+				},
+				{
+					Op:    opDelete,
+					Table: "Logical_Switch_Port",
+					Where: []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID2}}},
+				},
+			},
+		},
+		{
+			name:    "wrong model",
+			desired: []model.Model{&testLogicalSwitch{Name: "ls0"}},
+			err:     true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiReconcile: %s", tt.name), func(t *testing.T) {
+			ops, err := api.Reconcile("Logical_Switch_Port", tt.desired, tt.opts...)
+			if tt.err {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.result, ops)
+			}
+		})
+	}
+}
+
+func TestAPIUpdateImmutable(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal(apiTestSchema, &schema)
+	assert.Nil(t, err)
+	var column ovsdb.ColumnSchema
+	err = json.Unmarshal([]byte(`{"type": "string", "mutable": false}`), &column)
+	assert.Nil(t, err)
+	schema.Tables["Logical_Switch_Port"].Columns["type"] = &column
+	db, err := model.NewDBModel("OVN_NorthBound", map[string]model.Model{"Logical_Switch": &testLogicalSwitch{}, "Logical_Switch_Port": &testLogicalSwitchPort{}})
+	assert.Nil(t, err)
+	tcache, err := cache.NewTableCache(&schema, db)
+	assert.Nil(t, err)
+	lspCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "someType"},
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspCache))
+
+	update := &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "otherType", Tag: []int{1}}
+	where := []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID0}}}
+	changeType := func(m model.Model) model.Model {
+		lsp := m.(*testLogicalSwitchPort)
+		lsp.Type = "otherType"
+		return lsp
+	}
+
+	test := []struct {
+		name          string
+		skipImmutable bool
+		fn            func(a api) ([]ovsdb.Operation, error)
+		result        []ovsdb.Operation
+		err           bool
+	}{
+		{
+			name: "update immutable field fails",
+			fn: func(a api) ([]ovsdb.Operation, error) {
+				return a.Where(update).Update(update, &update.Type)
+			},
+			err: true,
+		},
+		{
+			name: "update mutable field succeeds",
+			fn: func(a api) ([]ovsdb.Operation, error) {
+				return a.Where(update).Update(update, &update.Tag)
+			},
+			result: []ovsdb.Operation{
+				{Op: "update", Table: "Logical_Switch_Port", Row: ovsdb.Row{"tag": testOvsSet(t, []int{1})}, Where: where},
+			},
+		},
+		{
+			name:          "update skips immutable field",
+			skipImmutable: true,
+			fn: func(a api) ([]ovsdb.Operation, error) {
+				return a.Where(update).Update(update, &update.Type, &update.Tag)
+			},
+			result: []ovsdb.Operation{
+				{Op: "update", Table: "Logical_Switch_Port", Row: ovsdb.Row{"tag": testOvsSet(t, []int{1})}, Where: where},
+			},
+		},
+		{
+			name:          "update with only immutable fields is dropped",
+			skipImmutable: true,
+			fn: func(a api) ([]ovsdb.Operation, error) {
+				return a.Where(update).Update(update, &update.Type)
+			},
+		},
+		{
+			name: "update func on immutable field fails",
+			fn: func(a api) ([]ovsdb.Operation, error) {
+				return a.WhereCache(func(*testLogicalSwitchPort) bool { return true }).UpdateFunc(changeType)
+			},
+			err: true,
+		},
+		{
+			name:          "update func skips immutable field",
+			skipImmutable: true,
+			fn: func(a api) ([]ovsdb.Operation, error) {
+				return a.WhereCache(func(*testLogicalSwitchPort) bool { return true }).UpdateFunc(changeType)
+			},
+		},
+		{
+			name: "reconcile on immutable field fails",
+			fn: func(a api) ([]ovsdb.Operation, error) {
+				return a.Reconcile("Logical_Switch_Port", []model.Model{&testLogicalSwitchPort{Name: "lsp0", Type: "otherType"}})
+			},
+			err: true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiUpdateImmutable: %s", tt.name), func(t *testing.T) {
+			a := api{cache: tcache, skipImmutable: tt.skipImmutable}
+			ops, err := tt.fn(a)
+			if tt.err {
+				var immutableErr *ErrImmutableColumn
+				assert.True(t, errors.As(err, &immutableErr), "expected ErrImmutableColumn, got %v", err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.ElementsMatch(t, tt.result, ops)
+		})
+	}
+}
+
+func TestAPIWaitForCache(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0"},
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspCache))
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go tcache.Run(stopCh)
+	api := newAPI(tcache)
+
+	t.Run("ApiWaitForCache: already satisfied", func(t *testing.T) {
+		err := api.WaitForCache(context.Background(), func(lsp *testLogicalSwitchPort) bool {
+			return lsp.Name == "lsp0"
+		})
+		assert.Nil(t, err)
+	})
+
+	t.Run("ApiWaitForCache: context expires", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		err := api.WaitForCache(ctx, func(lsp *testLogicalSwitchPort) bool {
+			return lsp.Name == "missing"
+		})
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("ApiWaitForCache: wrong predicate", func(t *testing.T) {
+		err := api.WaitForCache(context.Background(), func(s string) bool { return true })
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ApiWaitForCache: satisfied by update", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		go func() {
+			row := ovsdb.Row{"_uuid": ovsdb.UUID{GoUUID: aUUID1}, "name": "lsp1", "type": "router"}
+			tcache.Populate(ovsdb.TableUpdates{
+				"Logical_Switch_Port": {aUUID1: &ovsdb.RowUpdate{New: &row}},
+			})
+		}()
+		err := api.WaitForCache(ctx, func(lsp *testLogicalSwitchPort) bool {
+			return lsp.Name == "lsp1" && lsp.Type == "router"
+		})
+		assert.Nil(t, err)
+	})
+}
+
+func TestAPIWatchModel(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0"},
+		aUUID1: &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp1"},
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspCache))
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go tcache.Run(stopCh)
+	api := newAPI(tcache)
+
+	receive := func(t *testing.T, ch <-chan model.Model) (model.Model, bool) {
+		select {
+		case m, ok := <-ch:
+			return m, ok
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the watched model")
+			return nil, false
+		}
+	}
+
+	t.Run("ApiWatchModel: not found", func(t *testing.T) {
+		_, _, err := api.WatchModel(&testLogicalSwitchPort{Name: "missing"})
+		assert.Equal(t, ErrNotFound, err)
+	})
+
+	t.Run("ApiWatchModel: updates until deletion", func(t *testing.T) {
+		ch, cancel, err := api.WatchModel(&testLogicalSwitchPort{Name: "lsp0"})
+		assert.Nil(t, err)
+		defer cancel()
+
+		m, ok := receive(t, ch)
+		assert.True(t, ok)
+		assert.Equal(t, &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0"}, m)
+
+		old := ovsdb.Row{"_uuid": ovsdb.UUID{GoUUID: aUUID0}, "name": "lsp0"}
+		updated := ovsdb.Row{"_uuid": ovsdb.UUID{GoUUID: aUUID0}, "name": "lsp0", "type": "router"}
+		other := ovsdb.Row{"_uuid": ovsdb.UUID{GoUUID: aUUID1}, "name": "lsp1", "type": "router"}
+		tcache.Populate(ovsdb.TableUpdates{
+			"Logical_Switch_Port": {
+				aUUID0: &ovsdb.RowUpdate{Old: &old, New: &updated},
+				aUUID1: &ovsdb.RowUpdate{New: &other},
+			},
+		})
+		m, ok = receive(t, ch)
+		assert.True(t, ok)
+		assert.Equal(t, "router", m.(*testLogicalSwitchPort).Type)
+		assert.Equal(t, aUUID0, m.(*testLogicalSwitchPort).UUID, "other rows are not delivered")
+
+		tcache.Populate(ovsdb.TableUpdates{
+			"Logical_Switch_Port": {aUUID0: &ovsdb.RowUpdate{Old: &updated}},
+		})
+		_, ok = receive(t, ch)
+		assert.False(t, ok, "the channel is closed when the row is deleted")
+	})
+
+	t.Run("ApiWatchModel: cancel", func(t *testing.T) {
+		ch, cancel, err := api.WatchModel(&testLogicalSwitchPort{UUID: aUUID1})
+		assert.Nil(t, err)
+		cancel()
+		cancel()
+		assert.Eventually(t, func() bool {
+			_, ok := <-ch
+			return !ok
+		}, time.Second, 10*time.Millisecond, "the channel is closed when the watch is canceled")
+	})
+}
+
+func TestAPIMaxAffectedRows(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "someType"},
+		aUUID1: &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp1", Type: "someType"},
+		aUUID2: &testLogicalSwitchPort{UUID: aUUID2, Name: "lsp2", Type: "otherType"},
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspCache))
+	api := newAPI(tcache)
+
+	all := func(*testLogicalSwitchPort) bool { return true }
+	someType := func(lsp *testLogicalSwitchPort) bool { return lsp.Type == "someType" }
+	lsp := &testLogicalSwitchPort{Type: "newType"}
+	update := func(m model.Model) model.Model {
+		m.(*testLogicalSwitchPort).Type = "newType"
+		return m
+	}
+
+	explicit := &testLogicalSwitchPort{}
+	test := []struct {
+		name    string
+		cond    ConditionalAPI
+		count   int
+		err     bool
+		tooMany bool
+	}{
+		{
+			name:  "disabled",
+			cond:  api.WhereCache(all).MaxAffectedRows(0),
+			count: 3,
+		},
+		{
+			name:  "within limit",
+			cond:  api.WhereCache(someType).MaxAffectedRows(2),
+			count: 2,
+		},
+		{
+			name:    "over limit",
+			cond:    api.WhereCache(all).MaxAffectedRows(2),
+			err:     true,
+			tooMany: true,
+		},
+		{
+			name:    "explicit conditions",
+			cond:    api.WhereAll(explicit, model.Condition{Field: &explicit.Type, Function: ovsdb.ConditionNotEqual, Value: "newType"}).MaxAffectedRows(2),
+			err:     true,
+			tooMany: true,
+		},
+		{
+			name:  "explicit conditions within limit",
+			cond:  api.Where(explicit, model.Condition{Field: &explicit.Type, Function: ovsdb.ConditionEqual, Value: "otherType"}).MaxAffectedRows(1),
+			count: 1,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiMaxAffectedRows: %s", tt.name), func(t *testing.T) {
+			builders := map[string]func() ([]ovsdb.Operation, error){
+				"Delete": tt.cond.Delete,
+				"Update": func() ([]ovsdb.Operation, error) { return tt.cond.Update(lsp, &lsp.Type) },
+				"Mutate": func() ([]ovsdb.Operation, error) {
+					return tt.cond.Mutate(lsp, model.Mutation{Field: &lsp.Type, Mutator: ovsdb.MutateOperationInsert, Value: "foo"})
+				},
+				"UpdateFunc": func() ([]ovsdb.Operation, error) { return tt.cond.UpdateFunc(update) },
+			}
+			for name, build := range builders {
+				ops, err := build()
+				if tt.err {
+					assert.NotNil(t, err, name)
+					if tt.tooMany {
+						var maxErr *ErrMaxAffectedRows
+						assert.True(t, errors.As(err, &maxErr), "%s: expected ErrMaxAffectedRows, got %v", name, err)
+					}
+					assert.Nil(t, ops)
+					continue
+				}
+				if name == "Mutate" {
+					// Mutating a string column is not valid, only the count check matters
+					var maxErr *ErrMaxAffectedRows
+					assert.False(t, errors.As(err, &maxErr))
+					continue
+				}
+				assert.Nil(t, err, name)
+				if name == "Update" {
+					// Predicate conditions generate one operation per row
+					assert.Len(t, ops, tt.count, name)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIWithContext(t *testing.T) {
+	tcache := apiTestCache(t)
+	const rows = 100
+	lspcache := make(map[string]model.Model, rows)
+	for i := 0; i < rows; i++ {
+		uuid := fmt.Sprintf("%08d-0000-0000-0000-000000000000", i)
+		lspcache[uuid] = &testLogicalSwitchPort{UUID: uuid, Name: fmt.Sprintf("lsp%d", i), Type: []string{"router", ""}[i%2]}
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspcache))
+	api := newAPI(tcache)
+
+	t.Run("WithContext: scan completes", func(t *testing.T) {
+		ops, err := api.WhereCache(func(lsp *testLogicalSwitchPort) bool {
+			return lsp.Type == "router"
+		}).WithContext(context.Background()).Delete()
+		assert.Nil(t, err)
+		assert.Len(t, ops, rows/2)
+	})
+
+	t.Run("WithContext: scan canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		evaluated := 0
+		_, err := api.WhereCache(func(lsp *testLogicalSwitchPort) bool {
+			evaluated++
+			if evaluated == 10 {
+				cancel()
+			}
+			return lsp.Type == "router"
+		}).WithContext(ctx).Delete()
+		assert.True(t, errors.Is(err, context.Canceled), "expected a canceled error, got %v", err)
+		var canceled *ErrGenerateCanceled
+		if assert.True(t, errors.As(err, &canceled)) {
+			assert.Equal(t, &ErrGenerateCanceled{Table: "Logical_Switch_Port", Scanned: 10, Total: rows, Matched: 5, Err: context.Canceled}, canceled)
+		}
+		assert.Equal(t, 10, evaluated)
+	})
+
+	t.Run("WithContext: conditions evaluated by the server", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ops, err := api.Where(&testLogicalSwitchPort{Name: "lsp0"}).WithContext(ctx).Delete()
+		assert.Nil(t, err)
+		assert.Len(t, ops, 1)
+	})
+}
+
+func TestAPIColumnSchema(t *testing.T) {
+	api := newAPI(apiTestCache(t))
+
+	t.Run("ColumnSchema: column constraints", func(t *testing.T) {
+		column, err := api.ColumnSchema("Logical_Switch_Port", "tag")
+		assert.Nil(t, err)
+		assert.Equal(t, ovsdb.TypeSet, column.Type)
+		assert.Equal(t, 0, column.TypeObj.Min())
+		assert.Equal(t, 1, column.TypeObj.Max())
+		assert.Equal(t, ovsdb.TypeInteger, column.TypeObj.Key.Type)
+		minInteger, err := column.TypeObj.Key.MinInteger()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, minInteger)
+		maxInteger, err := column.TypeObj.Key.MaxInteger()
+		assert.Nil(t, err)
+		assert.Equal(t, 4095, maxInteger)
+	})
+
+	t.Run("ColumnSchema: implicit columns", func(t *testing.T) {
+		column, err := api.ColumnSchema("Logical_Switch_Port", "_uuid")
+		assert.Nil(t, err)
+		assert.Equal(t, ovsdb.TypeUUID, column.Type)
+		column, err = api.ColumnSchema("Logical_Switch_Port", "_version")
+		assert.Nil(t, err)
+		assert.Equal(t, ovsdb.TypeUUID, column.Type)
+	})
+
+	t.Run("ColumnSchema: errors", func(t *testing.T) {
+		_, err := api.ColumnSchema("Unknown", "name")
+		assert.NotNil(t, err)
+		_, err = api.ColumnSchema("Logical_Switch", "unknown")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("TableColumns", func(t *testing.T) {
+		assert.Equal(t, []string{"acls", "dns_records", "external_ids", "forwarding_groups", "load_balancer",
+			"name", "other_config", "ports", "qos_rules"}, api.TableColumns("Logical_Switch"))
+		assert.Nil(t, api.TableColumns("Unknown"))
+	})
+}
+
+func TestAPIModelFromRow(t *testing.T) {
+	api := newAPI(apiTestCache(t))
+
+	t.Run("ModelFromRow: partial row", func(t *testing.T) {
+		var row ovsdb.Row
+		err := json.Unmarshal([]byte(`{"_uuid": ["uuid", "`+aUUID0+`"], "name": "lsp0", "tag": 10,
+			"options": ["map", [["foo", "bar"]]]}`), &row)
+		assert.Nil(t, err)
+		m, err := api.ModelFromRow("Logical_Switch_Port", row)
+		assert.Nil(t, err)
+		assert.Equal(t, &testLogicalSwitchPort{
+			UUID:    aUUID0,
+			Name:    "lsp0",
+			Tag:     []int{10},
+			Options: map[string]string{"foo": "bar"},
+		}, m)
+	})
+
+	t.Run("ModelFromRow: empty row", func(t *testing.T) {
+		m, err := api.ModelFromRow("Logical_Switch", ovsdb.Row{})
+		assert.Nil(t, err)
+		assert.Equal(t, &testLogicalSwitch{}, m)
+	})
+
+	t.Run("ModelFromRow: errors", func(t *testing.T) {
+		_, err := api.ModelFromRow("Unknown", ovsdb.Row{})
+		assert.NotNil(t, err)
+		_, err = api.ModelFromRow("Logical_Switch", ovsdb.Row{"unknown": "foo"})
+		assert.NotNil(t, err)
+		_, err = api.ModelFromRow("Logical_Switch", ovsdb.Row{"name": 42})
+		assert.NotNil(t, err)
+		_, err = api.ModelFromRow("Logical_Switch", ovsdb.Row{"_uuid": "foo"})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestAPIConditionsFromColumns(t *testing.T) {
+	tcache := apiTestCache(t)
+	api := newAPI(tcache)
+
+	test := []struct {
+		name       string
+		table      string
+		conditions map[string]ColumnCondition
+		where      []ovsdb.Condition
+		err        bool
+	}{
+		{
+			name:  "string and set columns",
+			table: "Logical_Switch_Port",
+			conditions: map[string]ColumnCondition{
+				"type": {Function: ovsdb.ConditionNotEqual, Value: "router"},
+				"name": {Function: ovsdb.ConditionEqual, Value: "lsp0"},
+				"tag":  {Function: ovsdb.ConditionIncludes, Value: []int{1}},
+			},
+			where: []ovsdb.Condition{
+				{Column: "name", Function: ovsdb.ConditionEqual, Value: "lsp0"},
+				{Column: "tag", Function: ovsdb.ConditionIncludes, Value: testOvsSet(t, []int{1})},
+				{Column: "type", Function: ovsdb.ConditionNotEqual, Value: "router"},
+			},
+		},
+		{
+			name:  "unknown table",
+			table: "Unknown",
+			conditions: map[string]ColumnCondition{
+				"name": {Function: ovsdb.ConditionEqual, Value: "lsp0"},
+			},
+			err: true,
+		},
+		{
+			name:  "unknown column",
+			table: "Logical_Switch_Port",
+			conditions: map[string]ColumnCondition{
+				"unknown": {Function: ovsdb.ConditionEqual, Value: "lsp0"},
+			},
+			err: true,
+		},
+		{
+			name:  "wrong value type",
+			table: "Logical_Switch_Port",
+			conditions: map[string]ColumnCondition{
+				"name": {Function: ovsdb.ConditionEqual, Value: 42},
+			},
+			err: true,
+		},
+		{
+			name:  "invalid function",
+			table: "Logical_Switch_Port",
+			conditions: map[string]ColumnCondition{
+				"name": {Function: ovsdb.ConditionGreaterThan, Value: "lsp0"},
+			},
+			err: true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiConditionsFromColumns: %s", tt.name), func(t *testing.T) {
+			m, conditions, err := api.ConditionsFromColumns(tt.table, tt.conditions)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.IsType(t, &testLogicalSwitchPort{}, m)
+			ops, err := api.WhereAll(m, conditions...).Delete()
+			assert.Nil(t, err)
+			assert.Equal(t, []ovsdb.Operation{{Op: opDelete, Table: tt.table, Where: tt.where}}, ops)
+		})
+	}
+}
+
+// testMinimalSwitch maps only some of the columns of the Logical_Switch table
+type testMinimalSwitch struct {
+	UUID string `ovs:"_uuid"`
+	Name string `ovs:"name"`
+}
+
+func (*testMinimalSwitch) Table() string {
+	return "Logical_Switch"
+}
+
+func TestAPIWhereColumn(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal(apiTestSchema, &schema)
+	assert.Nil(t, err)
+	db, err := model.NewDBModel("OVN_NorthBound", map[string]model.Model{"Logical_Switch": &testMinimalSwitch{}})
+	assert.Nil(t, err)
+	minimalCache, err := cache.NewTableCache(&schema, db)
+	assert.Nil(t, err)
+	minimalCache.Set("Logical_Switch", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testMinimalSwitch{UUID: aUUID0, Name: "ls0"},
+	}))
+	api := newAPI(minimalCache)
+
+	where := []ovsdb.Condition{{
+		Column:   "external_ids",
+		Function: ovsdb.ConditionIncludes,
+		Value:    testOvsMap(t, map[string]string{"owner": "foo"}),
+	}}
+
+	t.Run("ApiWhereColumn: operations on a column the model does not map", func(t *testing.T) {
+		cond := api.WhereColumn("Logical_Switch", "external_ids", ovsdb.ConditionIncludes, map[string]string{"owner": "foo"})
+		ops, err := cond.Delete()
+		assert.Nil(t, err)
+		assert.Equal(t, []ovsdb.Operation{{Op: opDelete, Table: "Logical_Switch", Where: where}}, ops)
+
+		ls := &testMinimalSwitch{Name: "renamed"}
+		ops, err = cond.Update(ls, &ls.Name)
+		assert.Nil(t, err)
+		assert.Equal(t, []ovsdb.Operation{{Op: "update", Table: "Logical_Switch", Row: ovsdb.Row{"name": "renamed"}, Where: where}}, ops)
+
+		var result []testMinimalSwitch
+		err = cond.List(&result)
+		assert.NotNil(t, err, "the cache holds no value of the column")
+	})
+
+	t.Run("ApiWhereColumn: cache search on a mapped column", func(t *testing.T) {
+		tcache := apiTestCache(t)
+		tcache.Set("Logical_Switch", cache.NewRowCache(map[string]model.Model{
+			aUUID0: &testLogicalSwitch{UUID: aUUID0, Name: "ls0", ExternalIds: map[string]string{"owner": "foo"}},
+			aUUID1: &testLogicalSwitch{UUID: aUUID1, Name: "ls1", ExternalIds: map[string]string{"owner": "bar"}},
+		}))
+		var result []testLogicalSwitch
+		err := newAPI(tcache).WhereColumn("Logical_Switch", "external_ids", ovsdb.ConditionIncludes, map[string]string{"owner": "foo"}).List(&result)
+		assert.Nil(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, "ls0", result[0].Name)
+	})
+
+	test := []struct {
+		name     string
+		table    string
+		column   string
+		function ovsdb.ConditionFunction
+		value    interface{}
+	}{
+		{
+			name:     "unknown table",
+			table:    "Unknown",
+			column:   "name",
+			function: ovsdb.ConditionEqual,
+			value:    "foo",
+		},
+		{
+			name:     "unknown column",
+			table:    "Logical_Switch",
+			column:   "unknown",
+			function: ovsdb.ConditionEqual,
+			value:    "foo",
+		},
+		{
+			name:     "wrong value type",
+			table:    "Logical_Switch",
+			column:   "external_ids",
+			function: ovsdb.ConditionIncludes,
+			value:    "foo",
+		},
+		{
+			name:     "invalid function",
+			table:    "Logical_Switch",
+			column:   "name",
+			function: ovsdb.ConditionGreaterThan,
+			value:    "foo",
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiWhereColumn: %s", tt.name), func(t *testing.T) {
+			_, err := api.WhereColumn(tt.table, tt.column, tt.function, tt.value).Delete()
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestAPIIdempotent(t *testing.T) {
+	tcache := apiTestCache(t)
+	api := newAPI(tcache)
+
+	op := ovsdb.Operation{Op: opInsert, Table: "Logical_Switch_Port", Row: ovsdb.Row{"name": "lsp0"}}
+	ops, err := api.Where(&testLogicalSwitch{UUID: aUUID0}).Idempotent("txn1", op)
+	assert.Nil(t, err)
+
+	where := []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID0}}}
+	marker := testOvsMap(t, map[string]string{IdempotencyMarker: "txn1"})
+	assert.Equal(t, []ovsdb.Operation{
+		{
+			Op:              ovsdb.OperationWait,
+			Table:           "Logical_Switch",
+			Where:           append(append([]ovsdb.Condition{}, where...), ovsdb.NewCondition("external_ids", ovsdb.ConditionIncludes, marker)),
+			Columns:         []string{"_uuid"},
+			Until:           "==",
+			Rows:            []ovsdb.Row{},
+			ExplicitTimeout: true,
+		},
+		op,
+		{
+			Op:    opMutate,
+			Table: "Logical_Switch",
+			Where: where,
+			Mutations: []ovsdb.Mutation{
+				{Column: "external_ids", Mutator: ovsdb.MutateOperationDelete, Value: testOvsSet(t, []string{IdempotencyMarker})},
+				{Column: "external_ids", Mutator: ovsdb.MutateOperationInsert, Value: marker},
+			},
+		},
+	}, ops)
+
+	t.Run("Idempotent: already applied", func(t *testing.T) {
+		assert.True(t, AlreadyApplied(ops, []ovsdb.OperationResult{{Error: "timed out"}}))
+		assert.False(t, AlreadyApplied(ops, []ovsdb.OperationResult{{}, {}, {}}))
+		assert.False(t, AlreadyApplied(ops, []ovsdb.OperationResult{{}, {Error: "constraint violation"}}))
+		assert.False(t, AlreadyApplied([]ovsdb.Operation{op}, []ovsdb.OperationResult{{Error: "timed out"}}))
+	})
+
+	t.Run("Idempotent: errors", func(t *testing.T) {
+		_, err := api.Where(&testLogicalSwitch{UUID: aUUID0}).Idempotent("", op)
+		assert.NotNil(t, err)
+		_, err = api.WhereCache(func(*testLogicalSwitch) bool { return false }).Idempotent("txn1", op)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestAPIClearFields(t *testing.T) {
+	tcache := apiTestCache(t)
+	lspCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "someType", Tag: []int{1}, ExternalIds: map[string]string{"foo": "bar"}},
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspCache))
+	api := newAPI(tcache)
+
+	lsp := &testLogicalSwitchPort{Name: "lsp0", Tag: []int{5}}
+	test := []struct {
+		name   string
+		fields []interface{}
+		row    string
+		err    bool
+	}{
+		{
+			name:   "optional integer",
+			fields: []interface{}{&lsp.Tag},
+			row:    `{"tag":["set",[]]}`,
+		},
+		{
+			name:   "map and string",
+			fields: []interface{}{&lsp.ExternalIds, &lsp.Type},
+			row:    `{"external_ids":["map",[]],"type":""}`,
+		},
+		{
+			name: "no fields",
+			err:  true,
+		},
+		{
+			name:   "field of another struct",
+			fields: []interface{}{&testLogicalSwitchPort{}},
+			err:    true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ClearFields: %s", tt.name), func(t *testing.T) {
+			ops, err := api.Where(lsp).ClearFields(lsp, tt.fields...)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			if assert.Len(t, ops, 1) {
+				assert.Equal(t, "update", ops[0].Op)
+				assert.Equal(t, "Logical_Switch_Port", ops[0].Table)
+				assert.Equal(t, []ovsdb.Condition{{Column: "name", Function: ovsdb.ConditionEqual, Value: "lsp0"}}, ops[0].Where)
+				row, err := json.Marshal(ops[0].Row)
+				assert.Nil(t, err)
+				assert.JSONEq(t, tt.row, string(row))
+			}
+		})
+	}
+}
+
+func TestAPISetElements(t *testing.T) {
+	tcache := apiTestCache(t)
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Addresses: []string{"router"}},
+	}))
+	api := newAPI(tcache)
+	lsp := &testLogicalSwitchPort{Name: "lsp0"}
+	where := []ovsdb.Condition{{Column: "name", Function: ovsdb.ConditionEqual, Value: "lsp0"}}
+
+	t.Run("SetElements: add", func(t *testing.T) {
+		ops, err := api.Where(lsp).AddToSet(lsp, &lsp.Addresses, "00:00:00:00:00:01", "dynamic")
+		assert.Nil(t, err)
+		assert.Equal(t, []ovsdb.Operation{{
+			Op:        opMutate,
+			Table:     "Logical_Switch_Port",
+			Mutations: []ovsdb.Mutation{{Column: "addresses", Mutator: ovsdb.MutateOperationInsert, Value: testOvsSet(t, []string{"00:00:00:00:00:01", "dynamic"})}},
+			Where:     where,
+		}}, ops)
+	})
+
+	t.Run("SetElements: remove", func(t *testing.T) {
+		ops, err := api.Where(lsp).RemoveFromSet(lsp, &lsp.Tag, 42)
+		assert.Nil(t, err)
+		assert.Equal(t, []ovsdb.Operation{{
+			Op:        opMutate,
+			Table:     "Logical_Switch_Port",
+			Mutations: []ovsdb.Mutation{{Column: "tag", Mutator: ovsdb.MutateOperationDelete, Value: testOvsSet(t, []int{42})}},
+			Where:     where,
+		}}, ops)
+	})
+
+	test := []struct {
+		name  string
+		field interface{}
+		elems []interface{}
+	}{
+		{
+			name:  "no elements",
+			field: &lsp.Addresses,
+		},
+		{
+			name:  "element of wrong type",
+			field: &lsp.Addresses,
+			elems: []interface{}{42},
+		},
+		{
+			name:  "map column",
+			field: &lsp.ExternalIds,
+			elems: []interface{}{"foo"},
+		},
+		{
+			name:  "atomic column",
+			field: &lsp.Type,
+			elems: []interface{}{"router"},
+		},
+		{
+			name:  "field of another struct",
+			field: &testLogicalSwitchPort{},
+			elems: []interface{}{"router"},
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("SetElements: %s", tt.name), func(t *testing.T) {
+			_, err := api.Where(lsp).AddToSet(lsp, tt.field, tt.elems...)
+			assert.NotNil(t, err)
+			_, err = api.Where(lsp).RemoveFromSet(lsp, tt.field, tt.elems...)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestAPIUpdateClearOptional(t *testing.T) {
+	type testOptionalPort struct {
+		UUID       string  `ovs:"_uuid"`
+		Name       string  `ovs:"name"`
+		Type       string  `ovs:"type"`
+		ParentName *string `ovs:"parent_name"`
+		Tag        []int   `ovs:"tag"`
+	}
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal(apiTestSchema, &schema)
+	assert.Nil(t, err)
+	db, err := model.NewDBModel("OVN_NorthBound", map[string]model.Model{"Logical_Switch_Port": &testOptionalPort{}})
+	assert.Nil(t, err)
+	tcache, err := cache.NewTableCache(&schema, db)
+	assert.Nil(t, err)
+	parent := "parent"
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testOptionalPort{UUID: aUUID0, Name: "lsp0", ParentName: &parent, Tag: []int{1}},
+	}))
+	api := newAPI(tcache)
+
+	lsp := &testOptionalPort{Name: "lsp0", Type: "router"}
+	test := []struct {
+		name   string
+		fields []interface{}
+		row    string
+	}{
+		{
+			name: "unset optional fields are left out",
+			row:  `{"name":"lsp0","type":"router"}`,
+		},
+		{
+			name:   "selected optional pointer is cleared",
+			fields: []interface{}{&lsp.Type, &lsp.ParentName},
+			row:    `{"type":"router","parent_name":["set",[]]}`,
+		},
+		{
+			name:   "selected optional slice is cleared",
+			fields: []interface{}{&lsp.Type, &lsp.Tag},
+			row:    `{"type":"router","tag":["set",[]]}`,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("UpdateClearOptional: %s", tt.name), func(t *testing.T) {
+			ops, err := api.Where(lsp).Update(lsp, tt.fields...)
+			assert.Nil(t, err)
+			if assert.Len(t, ops, 1) {
+				row, err := json.Marshal(ops[0].Row)
+				assert.Nil(t, err)
+				assert.JSONEq(t, tt.row, string(row))
+			}
+		})
+	}
+}
+
+func TestDefaultOvsValue(t *testing.T) {
+	test := []struct {
+		column string
+		value  string
+		err    bool
+	}{
+		{column: `{"type":"integer"}`, value: `0`},
+		{column: `{"type":"real"}`, value: `0`},
+		{column: `{"type":"boolean"}`, value: `false`},
+		{column: `{"type":"uuid"}`, value: `["uuid","00000000-0000-0000-0000-000000000000"]`},
+		{column: `{"type":{"key":{"type":"uuid"},"min":0,"max":"unlimited"}}`, value: `["set",[]]`},
+		{column: `{"type":{"key":{"type":"string","enum":["set",["foo","bar"]]}}}`, err: true},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("DefaultOvsValue: %s", tt.column), func(t *testing.T) {
+			var column ovsdb.ColumnSchema
+			err := json.Unmarshal([]byte(tt.column), &column)
+			assert.Nil(t, err)
+			value, err := defaultOvsValue(&column)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			data, err := json.Marshal(value)
+			assert.Nil(t, err)
+			assert.JSONEq(t, tt.value, string(data))
+		})
+	}
+}
+
+func TestAPIWeakReferenceWarning(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal(apiTestSchema, &schema)
+	assert.Nil(t, err)
+	var column ovsdb.ColumnSchema
+	err = json.Unmarshal([]byte(`{"type": {"key": {"type": "uuid", "refTable": "Logical_Switch_Port", "refType": "weak"}, "min": 0, "max": "unlimited"}}`), &column)
+	assert.Nil(t, err)
+	schema.Tables["Logical_Switch"].Columns["ports"] = &column
+	db, err := model.NewDBModel("OVN_NorthBound", map[string]model.Model{"Logical_Switch": &testLogicalSwitch{}, "Logical_Switch_Port": &testLogicalSwitchPort{}})
+	assert.Nil(t, err)
+	tcache, err := cache.NewTableCache(&schema, db)
+	assert.Nil(t, err)
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0"},
+	}))
+
+	var warnings []error
+	a := api{cache: tcache, weakRefWarn: func(err error) { warnings = append(warnings, err) }}
+	ls := &testLogicalSwitch{UUID: aUUID3, Name: "ls"}
+	dangling := func(uuid string) error {
+		return &ErrDanglingWeakReference{Table: "Logical_Switch", Column: "ports", RefTable: "Logical_Switch_Port", UUID: uuid}
+	}
+
+	test := []struct {
+		name     string
+		fn       func() ([]ovsdb.Operation, error)
+		warnings []error
+	}{
+		{
+			name: "create",
+			fn: func() ([]ovsdb.Operation, error) {
+				return a.Create(&testLogicalSwitch{Name: "ls", Ports: []string{aUUID0, aUUID1}})
+			},
+			warnings: []error{dangling(aUUID1)},
+		},
+		{
+			name: "create with named-uuid",
+			fn: func() ([]ovsdb.Operation, error) {
+				return a.Create(&testLogicalSwitch{Name: "ls", Ports: []string{"newport"}})
+			},
+		},
+		{
+			name: "update",
+			fn: func() ([]ovsdb.Operation, error) {
+				update := &testLogicalSwitch{UUID: aUUID3, Ports: []string{aUUID2}}
+				return a.Where(update).Update(update, &update.Ports)
+			},
+			warnings: []error{dangling(aUUID2)},
+		},
+		{
+			name: "mutate insert",
+			fn: func() ([]ovsdb.Operation, error) {
+				return a.Where(ls).Mutate(ls, model.Mutation{Field: &ls.Ports, Mutator: ovsdb.MutateOperationInsert, Value: []string{aUUID1}})
+			},
+			warnings: []error{dangling(aUUID1)},
+		},
+		{
+			name: "mutate delete",
+			fn: func() ([]ovsdb.Operation, error) {
+				return a.Where(ls).Mutate(ls, model.Mutation{Field: &ls.Ports, Mutator: ovsdb.MutateOperationDelete, Value: []string{aUUID1}})
+			},
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("WeakReferenceWarning: %s", tt.name), func(t *testing.T) {
+			warnings = nil
+			ops, err := tt.fn()
+			assert.Nil(t, err)
+			assert.Len(t, ops, 1)
+			assert.Equal(t, tt.warnings, warnings)
+		})
+	}
+
+	t.Run("WeakReferenceWarning: disabled", func(t *testing.T) {
+		warnings = nil
+		_, err := newAPI(tcache).Create(&testLogicalSwitch{Name: "ls", Ports: []string{aUUID1}})
+		assert.Nil(t, err)
+		assert.Empty(t, warnings)
+	})
+}
+
+func TestAPIUpdateVersion(t *testing.T) {
+	type testVersionedSwitch struct {
+		UUID    string `ovs:"_uuid"`
+		Version string `ovs:"_version"`
+		Name    string `ovs:"name"`
+	}
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal(apiTestSchema, &schema)
+	assert.Nil(t, err)
+	db, err := model.NewDBModel("OVN_NorthBound", map[string]model.Model{"Logical_Switch": &testVersionedSwitch{}})
+	assert.Nil(t, err)
+	tcache, err := cache.NewTableCache(&schema, db)
+	assert.Nil(t, err)
+
+	// The version is cached if it is sent by the server
+	row := ovsdb.Row{"name": "ls0", "_version": ovsdb.UUID{GoUUID: aUUID1}}
+	tcache.Populate(ovsdb.TableUpdates{"Logical_Switch": {aUUID0: &ovsdb.RowUpdate{New: &row}}})
+	cached := tcache.Table("Logical_Switch").Row(aUUID0).(*testVersionedSwitch)
+	assert.Equal(t, &testVersionedSwitch{UUID: aUUID0, Version: aUUID1, Name: "ls0"}, cached)
+
+	// The update only applies if the row was not modified since it was cached
+	a := api{cache: tcache}
+	ls := &testVersionedSwitch{UUID: cached.UUID, Version: cached.Version, Name: "ls1"}
+	ops, err := a.Where(ls, model.Condition{
+		Field:    &ls.Version,
+		Function: ovsdb.ConditionEqual,
+		Value:    cached.Version,
+	}).Update(ls, &ls.Name)
+	assert.Nil(t, err)
+	assert.Equal(t, []ovsdb.Operation{
+		{
+			Op:    "update",
+			Table: "Logical_Switch",
+			Row:   ovsdb.Row{"name": "ls1"},
+			Where: []ovsdb.Condition{{Column: "_version", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID1}}},
+		},
+	}, ops)
+	assert.True(t, schema.ValidateOperations(ops...))
+
+	matches, err := a.Where(ls, model.Condition{Field: &ls.Version, Function: ovsdb.ConditionEqual, Value: aUUID2}).(api).cond.Matches(cached)
+	assert.Nil(t, err)
+	assert.False(t, matches)
+}
+
+// testValidatedSwitchPort is a Logical_Switch_Port whose tag must be a valid VLAN
+type testValidatedSwitchPort struct {
+	UUID string `ovs:"_uuid"`
+	Name string `ovs:"name"`
+	Tag  []int  `ovs:"tag"`
+}
+
+func (lsp *testValidatedSwitchPort) Validate() error {
+	for _, tag := range lsp.Tag {
+		if tag < 1 || tag > 4094 {
+			return fmt.Errorf("invalid VLAN tag %d", tag)
+		}
+	}
+	return nil
+}
+
+func TestAPIValidate(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal(apiTestSchema, &schema)
+	assert.Nil(t, err)
+	db, err := model.NewDBModel("OVN_NorthBound", map[string]model.Model{"Logical_Switch_Port": &testValidatedSwitchPort{}})
+	assert.Nil(t, err)
+	tcache, err := cache.NewTableCache(&schema, db)
+	assert.Nil(t, err)
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testValidatedSwitchPort{UUID: aUUID0, Name: "lsp0", Tag: []int{1}},
+	}))
+	a := api{cache: tcache}
+	valid := &testValidatedSwitchPort{UUID: aUUID0, Name: "lsp0", Tag: []int{10}}
+	invalid := &testValidatedSwitchPort{UUID: aUUID0, Name: "lsp0", Tag: []int{4095}}
+
+	test := []struct {
+		name string
+		fn   func() ([]ovsdb.Operation, error)
+		err  bool
+	}{
+		{
+			name: "create valid model",
+			fn: func() ([]ovsdb.Operation, error) {
+				return a.Create(&testValidatedSwitchPort{Name: "lsp1", Tag: []int{10}})
+			},
+		},
+		{
+			name: "create invalid model",
+			fn: func() ([]ovsdb.Operation, error) {
+				return a.Create(&testValidatedSwitchPort{Name: "lsp1"}, &testValidatedSwitchPort{Name: "lsp2", Tag: []int{0}})
+			},
+			err: true,
+		},
+		{
+			name: "update valid model",
+			fn:   func() ([]ovsdb.Operation, error) { return a.Where(valid).Update(valid, &valid.Tag) },
+		},
+		{
+			name: "update invalid model",
+			fn:   func() ([]ovsdb.Operation, error) { return a.Where(invalid).Update(invalid, &invalid.Tag) },
+			err:  true,
+		},
+		{
+			name: "update func returning an invalid model",
+			fn: func() ([]ovsdb.Operation, error) {
+				return a.WhereCache(func(*testValidatedSwitchPort) bool { return true }).UpdateFunc(func(m model.Model) model.Model {
+					m.(*testValidatedSwitchPort).Tag = []int{-1}
+					return m
+				})
+			},
+			err: true,
+		},
+		{
+			name: "reconcile with an invalid model",
+			fn: func() ([]ovsdb.Operation, error) {
+				return a.Reconcile("Logical_Switch_Port", []model.Model{invalid})
+			},
+			err: true,
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("ApiValidate: %s", tt.name), func(t *testing.T) {
+			ops, err := tt.fn()
+			if tt.err {
+				var invalidErr *ErrInvalidModel
+				assert.True(t, errors.As(err, &invalidErr), "expected ErrInvalidModel, got %v", err)
+				assert.Equal(t, "Logical_Switch_Port", invalidErr.Table)
+				assert.Nil(t, ops)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Len(t, ops, 1)
+		})
+	}
+}
+
+func TestAPISwapIndex(t *testing.T) {
+	tcache := apiTestCache(t)
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "router"},
+		aUUID1: &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp1"},
+	}))
+	a := api{cache: tcache}
+	where := func(uuid string) []ovsdb.Condition {
+		return []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: uuid}}}
+	}
+
+	lsp0 := &testLogicalSwitchPort{UUID: aUUID0}
+	lsp1 := &testLogicalSwitchPort{UUID: aUUID1}
+	ops, err := a.SwapIndex(lsp0, lsp1, &lsp0.Name)
+	assert.Nil(t, err)
+	assert.Equal(t, []ovsdb.Operation{
+		{Op: "update", Table: "Logical_Switch_Port", Row: ovsdb.Row{"name": "swap-" + aUUID0}, Where: where(aUUID0)},
+		{Op: "update", Table: "Logical_Switch_Port", Row: ovsdb.Row{"name": "lsp0"}, Where: where(aUUID1)},
+		{Op: "update", Table: "Logical_Switch_Port", Row: ovsdb.Row{"name": "lsp1"}, Where: where(aUUID0)},
+	}, ops)
+	assert.Equal(t, &testLogicalSwitchPort{UUID: aUUID0}, lsp0, "the provided models are not modified")
+
+	// Empty values are swapped too
+	ops, err = a.SwapIndex(lsp0, lsp1, &lsp0.Type)
+	assert.Nil(t, err)
+	assert.Len(t, ops, 3)
+	assert.Equal(t, ovsdb.Row{"type": ""}, ops[2].Row)
+
+	_, err = a.SwapIndex(lsp0, &testLogicalSwitchPort{UUID: aUUID2}, &lsp0.Name)
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+	_, err = a.SwapIndex(lsp0, &testLogicalSwitchPort{UUID: aUUID0}, &lsp0.Name)
+	assert.NotNil(t, err)
+	_, err = a.SwapIndex(lsp0, lsp1, &lsp0.Tag)
+	assert.NotNil(t, err)
+	_, err = a.SwapIndex(lsp0, &testLogicalSwitch{UUID: aUUID1}, &lsp0.Name)
+	assert.NotNil(t, err)
+}
+
+func BenchmarkListLarge(b *testing.B) {
+	tcache := apiTestCache(b)
+	const rows = 10000
+	lspcache := make(map[string]model.Model, rows)
+	for i := 0; i < rows; i++ {
+		uuid := fmt.Sprintf("%08d-0000-0000-0000-000000000000", i)
+		lspcache[uuid] = &testLogicalSwitchPort{
+			UUID:        uuid,
+			Name:        fmt.Sprintf("lsp%d", i),
+			Type:        []string{"router", ""}[i%2],
+			Addresses:   []string{fmt.Sprintf("10.0.%d.%d", i/256, i%256)},
+			ExternalIds: map[string]string{"index": fmt.Sprint(i)},
+		}
+	}
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(lspcache))
+	api := newAPI(tcache)
+
+	b.Run("all rows", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var result []testLogicalSwitchPort
+			if err := api.List(&result); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("explicit conditions", func(b *testing.B) {
+		b.ReportAllocs()
+		lsp := &testLogicalSwitchPort{Type: "router"}
+		for i := 0; i < b.N; i++ {
+			var result []testLogicalSwitchPort
+			err := api.WhereAll(lsp, model.Condition{
+				Field:    &lsp.Type,
+				Function: ovsdb.ConditionEqual,
+				Value:    "router",
+			}).List(&result)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(result) != rows/2 {
+				b.Fatalf("expected %d rows, got %d", rows/2, len(result))
+			}
+		}
+	})
+}
+
+func TestAPICommentAssertLock(t *testing.T) {
+	tcache := apiTestCache(t)
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "router"},
+	}))
+	api := newAPI(tcache)
+	lsp := &testLogicalSwitchPort{Name: "lsp0"}
+	where := []ovsdb.Condition{{Column: "name", Function: ovsdb.ConditionEqual, Value: "lsp0"}}
+	comment := "audit: disable lsp0"
+	lock := "leader"
+	prefix := []ovsdb.Operation{
+		{Op: opComment, Comment: &comment},
+		{Op: opAssert, Lock: &lock},
+	}
+
+	t.Run("CommentAssertLock: update", func(t *testing.T) {
+		ops, err := api.Where(lsp).Comment(comment).AssertLock(lock).Update(&testLogicalSwitchPort{Type: "localnet"})
+		assert.Nil(t, err)
+		assert.Equal(t, append(prefix, ovsdb.Operation{
+			Op:    "update",
+			Table: "Logical_Switch_Port",
+			Row:   ovsdb.Row{"type": "localnet"},
+			Where: where,
+		}), ops)
+	})
+
+	t.Run("CommentAssertLock: mutate", func(t *testing.T) {
+		ops, err := api.Where(lsp).Comment(comment).AssertLock(lock).AddToSet(lsp, &lsp.Addresses, "dynamic")
+		assert.Nil(t, err)
+		assert.Equal(t, append(prefix, ovsdb.Operation{
+			Op:        opMutate,
+			Table:     "Logical_Switch_Port",
+			Mutations: []ovsdb.Mutation{{Column: "addresses", Mutator: ovsdb.MutateOperationInsert, Value: testOvsSet(t, []string{"dynamic"})}},
+			Where:     where,
+		}), ops)
+	})
+
+	t.Run("CommentAssertLock: delete in chaining order", func(t *testing.T) {
+		ops, err := api.Where(lsp).AssertLock(lock).Comment(comment).Delete()
+		assert.Nil(t, err)
+		assert.Equal(t, []ovsdb.Operation{prefix[1], prefix[0], {
+			Op:    opDelete,
+			Table: "Logical_Switch_Port",
+			Where: where,
+		}}, ops)
+	})
+
+	t.Run("CommentAssertLock: update func", func(t *testing.T) {
+		ops, err := api.WhereCache(func(*testLogicalSwitchPort) bool { return true }).Comment(comment).UpdateFunc(func(current model.Model) model.Model {
+			current.(*testLogicalSwitchPort).Type = "localnet"
+			return current
+		})
+		assert.Nil(t, err)
+		assert.Len(t, ops, 2)
+		assert.Equal(t, prefix[0], ops[0])
+		assert.Equal(t, "update", ops[1].Op)
+
+		// No operation is built when no row changes
+		ops, err = api.WhereCache(func(*testLogicalSwitchPort) bool { return true }).Comment(comment).UpdateFunc(func(current model.Model) model.Model {
+			return current
+		})
+		assert.Nil(t, err)
+		assert.Empty(t, ops)
+	})
+
+	t.Run("CommentAssertLock: chains do not share operations", func(t *testing.T) {
+		commented := api.Where(lsp).Comment(comment)
+		first, err := commented.AssertLock("first").Delete()
+		assert.Nil(t, err)
+		second, err := commented.Comment("second").Delete()
+		assert.Nil(t, err)
+		assert.Equal(t, "first", *first[1].Lock)
+		assert.Equal(t, "second", *second[1].Comment)
+		ops, err := commented.Delete()
+		assert.Nil(t, err)
+		assert.Len(t, ops, 2)
+	})
+}
+
+func TestAPIUnmappedColumns(t *testing.T) {
+	api := newAPI(apiTestCache(t))
+	unmapped, err := api.UnmappedColumns("Logical_Switch")
+	assert.Nil(t, err)
+	assert.Empty(t, unmapped)
+
+	type testPartialSwitch struct {
+		UUID  string   `ovs:"_uuid"`
+		Name  string   `ovs:"name"`
+		Ports []string `ovs:"ports"`
+	}
+	var schema ovsdb.DatabaseSchema
+	err = json.Unmarshal(apiTestSchema, &schema)
+	assert.Nil(t, err)
+	db, err := model.NewDBModel("OVN_NorthBound", map[string]model.Model{"Logical_Switch": &testPartialSwitch{}})
+	assert.Nil(t, err)
+	tcache, err := cache.NewTableCache(&schema, db)
+	assert.Nil(t, err)
+	api = newAPI(tcache)
+
+	unmapped, err = api.UnmappedColumns("Logical_Switch")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"acls", "dns_records", "external_ids", "forwarding_groups", "load_balancer",
+		"other_config", "qos_rules"}, unmapped)
+
+	// The table is in the schema but not in the model
+	_, err = api.UnmappedColumns("Logical_Switch_Port")
+	assert.NotNil(t, err)
+	_, err = api.UnmappedColumns("Unknown")
+	assert.NotNil(t, err)
+}
+
+func TestAPIUpsert(t *testing.T) {
+	tcache := apiTestCache(t)
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "router", Addresses: []string{"router"}},
+	}))
+	api := newAPI(tcache)
+	byUUID := []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: aUUID0})}
+
+	t.Run("Upsert: update the changed columns of the cached row", func(t *testing.T) {
+		lsp := &testLogicalSwitchPort{Name: "lsp0", Type: "localnet", Addresses: []string{"router"}}
+		ops, err := api.Upsert(lsp)
+		assert.Nil(t, err)
+		assert.Equal(t, []ovsdb.Operation{{
+			Op:    "update",
+			Table: "Logical_Switch_Port",
+			Row:   ovsdb.Row{"type": "localnet"},
+			Where: byUUID,
+		}}, ops)
+		// The model is not modified
+		assert.Equal(t, "", lsp.UUID)
+	})
+
+	t.Run("Upsert: default values are written", func(t *testing.T) {
+		ops, err := api.Upsert(&testLogicalSwitchPort{UUID: aUUID0, Name: "lsp0", Type: "router"})
+		assert.Nil(t, err)
+		assert.Equal(t, []ovsdb.Operation{{
+			Op:    "update",
+			Table: "Logical_Switch_Port",
+			Row:   ovsdb.Row{"addresses": testOvsSet(t, []string{})},
+			Where: byUUID,
+		}}, ops)
+	})
+
+	t.Run("Upsert: unchanged row", func(t *testing.T) {
+		ops, err := api.UpsertGuarded(&testLogicalSwitchPort{Name: "lsp0", Type: "router", Addresses: []string{"router"}})
+		assert.Nil(t, err)
+		assert.Empty(t, ops)
+	})
+
+	t.Run("Upsert: insert", func(t *testing.T) {
+		lsp := &testLogicalSwitchPort{UUID: "lsp1", Name: "lsp1", Type: "router"}
+		ops, err := api.Upsert(lsp)
+		assert.Nil(t, err)
+		create, err := api.Create(lsp)
+		assert.Nil(t, err)
+		assert.Equal(t, create, ops)
+
+		ops, err = api.UpsertGuarded(lsp)
+		assert.Nil(t, err)
+		assert.Equal(t, append([]ovsdb.Operation{{
+			Op:              ovsdb.OperationWait,
+			Table:           "Logical_Switch_Port",
+			Where:           []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "lsp1")},
+			Columns:         []string{"_uuid"},
+			Until:           "==",
+			Rows:            []ovsdb.Row{},
+			ExplicitTimeout: true,
+		}}, create...), ops)
+	})
+
+	t.Run("Upsert: insert without index values", func(t *testing.T) {
+		ls := &testLogicalSwitch{Ports: []string{aUUID0}}
+		ops, err := api.UpsertGuarded(ls)
+		assert.Nil(t, err)
+		create, err := api.Create(ls)
+		assert.Nil(t, err)
+		assert.Equal(t, create, ops)
+	})
+
+	t.Run("Upsert: wrong model", func(t *testing.T) {
+		_, err := api.Upsert(&struct{ A string }{})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestAPIGetReferenced(t *testing.T) {
+	tcache := apiTestCache(t)
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(map[string]model.Model{
+		aUUID1: &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp1"},
+		aUUID2: &testLogicalSwitchPort{UUID: aUUID2, Name: "lsp2"},
+	}))
+	api := newAPI(tcache)
+	ls := &testLogicalSwitch{UUID: aUUID0, Name: "ls0", Ports: []string{aUUID2, aUUID3, aUUID1}}
+
+	t.Run("GetReferenced: cached rows in order", func(t *testing.T) {
+		var ports []testLogicalSwitchPort
+		err := api.GetReferenced(ls, &ls.Ports, &ports)
+		assert.Nil(t, err)
+		assert.Equal(t, []testLogicalSwitchPort{{UUID: aUUID2, Name: "lsp2"}, {UUID: aUUID1, Name: "lsp1"}}, ports)
+
+		// The slice is replaced
+		empty := &testLogicalSwitch{}
+		err = api.GetReferenced(empty, &empty.Ports, &ports)
+		assert.Nil(t, err)
+		assert.Empty(t, ports)
+	})
+
+	test := []struct {
+		name  string
+		field interface{}
+		dest  interface{}
+	}{
+		{
+			name:  "not a reference column",
+			field: &ls.Name,
+			dest:  &[]testLogicalSwitchPort{},
+		},
+		{
+			name:  "models of another table",
+			field: &ls.Ports,
+			dest:  &[]testLogicalSwitch{},
+		},
+		{
+			name:  "not a pointer to a slice",
+			field: &ls.Ports,
+			dest:  []testLogicalSwitchPort{},
+		},
+		{
+			name:  "field of another model",
+			field: &(&testLogicalSwitch{}).Ports,
+			dest:  &[]testLogicalSwitchPort{},
+		},
+		{
+			name:  "table not in the model",
+			field: &ls.QosRules,
+			dest:  &[]testLogicalSwitchPort{},
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("GetReferenced: %s", tt.name), func(t *testing.T) {
+			err := api.GetReferenced(ls, tt.field, tt.dest)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestAPIWhereIgnoreCase(t *testing.T) {
+	tcache := apiTestCache(t)
+	tcache.Set("Logical_Switch_Port", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testLogicalSwitchPort{UUID: aUUID0, Name: "LSP-Uplink", Type: "localnet"},
+		aUUID1: &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp-vm1", Type: "router"},
+		aUUID2: &testLogicalSwitchPort{UUID: aUUID2, Name: "lsp-vm2"},
+	}))
+	api := newAPI(tcache)
+
+	test := []struct {
+		name    string
+		model   *testLogicalSwitchPort
+		matches []string
+	}{
+		{
+			name:    "lower case name",
+			model:   &testLogicalSwitchPort{Name: "lsp-uplink"},
+			matches: []string{aUUID0},
+		},
+		{
+			name:    "mixed case name",
+			model:   &testLogicalSwitchPort{Name: "LSP-vm1"},
+			matches: []string{aUUID1},
+		},
+		{
+			name:    "exact name",
+			model:   &testLogicalSwitchPort{Name: "lsp-vm2"},
+			matches: []string{aUUID2},
+		},
+		{
+			name:  "no match",
+			model: &testLogicalSwitchPort{Name: "lsp-vm3"},
+		},
+		{
+			name:    "uuid takes precedence",
+			model:   &testLogicalSwitchPort{UUID: aUUID1, Name: "LSP-UPLINK"},
+			matches: []string{aUUID1},
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("WhereIgnoreCase: %s", tt.name), func(t *testing.T) {
+			var result []testLogicalSwitchPort
+			err := api.WhereIgnoreCase(tt.model).List(&result)
+			assert.Nil(t, err)
+			var uuids []string
+			for _, lsp := range result {
+				uuids = append(uuids, lsp.UUID)
+			}
+			assert.ElementsMatch(t, tt.matches, uuids)
+
+			ops, err := api.WhereIgnoreCase(tt.model).Delete()
+			assert.Nil(t, err)
+			var where [][]ovsdb.Condition
+			for _, uuid := range tt.matches {
+				where = append(where, []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: uuid})})
+			}
+			assert.Len(t, ops, len(where))
+			for i := range ops {
+				assert.Equal(t, where[i], ops[i].Where)
+			}
+		})
+	}
+
+	t.Run("WhereIgnoreCase: selected index", func(t *testing.T) {
+		assert.Equal(t, []string{"name"}, api.WhereIgnoreCase(&testLogicalSwitchPort{Name: "LSP-VM1"}).SelectedIndex())
+	})
+
+	t.Run("WhereIgnoreCase: no index values", func(t *testing.T) {
+		_, err := api.WhereIgnoreCase(&testLogicalSwitchPort{Type: "router"}).Delete()
+		assert.NotNil(t, err)
+	})
+}
+
+func TestAPIApplyOptimistic(t *testing.T) {
+	newCache := func() *cache.TableCache {
+		tcache := apiTestCache(t)
+		tcache.Set("Logical_Switch", cache.NewRowCache(map[string]model.Model{
+			aUUID0: &testLogicalSwitch{UUID: aUUID0, Name: "ls0", Ports: []string{aUUID1}},
+		}))
+		tcache.Set("Logical_Switch_Port", cache.NewRowCache(map[string]model.Model{
+			aUUID1: &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp1", ExternalIds: map[string]string{"foo": "bar", "baz": "quux"}},
+		}))
+		return tcache
+	}
+
+	t.Run("ApplyOptimistic: insert, update and mutate", func(t *testing.T) {
+		tcache := newCache()
+		api := newAPI(tcache)
+		operations := []ovsdb.Operation{
+			{
+				Op:       ovsdb.OperationInsert,
+				Table:    "Logical_Switch_Port",
+				UUIDName: "lsp2",
+				Row:      ovsdb.Row{"name": "lsp2"},
+			},
+			{
+				Op:        ovsdb.OperationMutate,
+				Table:     "Logical_Switch",
+				Where:     []ovsdb.Condition{ovsdb.NewCondition("_uuid", ovsdb.ConditionEqual, ovsdb.UUID{GoUUID: aUUID0})},
+				Mutations: []ovsdb.Mutation{*ovsdb.NewMutation("ports", ovsdb.MutateOperationInsert, ovsdb.UUID{GoUUID: "lsp2"})},
+			},
+			{
+				Op:    ovsdb.OperationUpdate,
+				Table: "Logical_Switch_Port",
+				Where: []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "lsp1")},
+				Row:   ovsdb.Row{"type": "router"},
+			},
+			{
+				Op:        ovsdb.OperationMutate,
+				Table:     "Logical_Switch_Port",
+				Where:     []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "lsp1")},
+				Mutations: []ovsdb.Mutation{*ovsdb.NewMutation("external_ids", ovsdb.MutateOperationDelete, testOvsSet(t, []string{"foo"}))},
+			},
+			{
+				Op:    ovsdb.OperationSelect,
+				Table: "Logical_Switch_Port",
+			},
+		}
+		results := []ovsdb.OperationResult{{UUID: ovsdb.UUID{GoUUID: aUUID2}}, {Count: 1}, {Count: 1}, {Count: 1}, {}}
+		err := api.ApplyOptimistic(operations, results)
+		assert.Nil(t, err)
+		assert.Equal(t, &testLogicalSwitchPort{UUID: aUUID2, Name: "lsp2"}, tcache.Table("Logical_Switch_Port").Row(aUUID2))
+		assert.Equal(t, &testLogicalSwitch{UUID: aUUID0, Name: "ls0", Ports: []string{aUUID1, aUUID2}}, tcache.Table("Logical_Switch").Row(aUUID0))
+		assert.Equal(t, &testLogicalSwitchPort{UUID: aUUID1, Name: "lsp1", Type: "router", ExternalIds: map[string]string{"baz": "quux"}},
+			tcache.Table("Logical_Switch_Port").Row(aUUID1))
+		assert.True(t, tcache.IsOptimistic("Logical_Switch", aUUID0))
+		assert.True(t, tcache.IsOptimistic("Logical_Switch_Port", aUUID1))
+		assert.True(t, tcache.IsOptimistic("Logical_Switch_Port", aUUID2))
+	})
+
+	t.Run("ApplyOptimistic: operations built by the API", func(t *testing.T) {
+		tcache := newCache()
+		api := newAPI(tcache)
+		lsp := &testLogicalSwitchPort{Name: "lsp1", Type: "router"}
+		operations, err := api.Where(lsp).Update(lsp, &lsp.Type)
+		assert.Nil(t, err)
+		assert.Equal(t, "update", operations[0].Op)
+		created, err := api.Create(&testLogicalSwitchPort{Name: "lsp2"})
+		assert.Nil(t, err)
+		operations = append(operations, created...)
+		results := []ovsdb.OperationResult{{Count: 1}, {UUID: ovsdb.UUID{GoUUID: aUUID2}}}
+		err = api.ApplyOptimistic(operations, results)
+		assert.Nil(t, err)
+		assert.Equal(t, "router", tcache.Table("Logical_Switch_Port").Row(aUUID1).(*testLogicalSwitchPort).Type)
+		assert.Equal(t, "lsp2", tcache.Table("Logical_Switch_Port").Row(aUUID2).(*testLogicalSwitchPort).Name)
+		assert.Nil(t, tcache.Table("Logical_Switch_Port").Row(""))
+		assert.Equal(t, 2, tcache.Table("Logical_Switch_Port").Len())
+	})
+
+	t.Run("ApplyOptimistic: insert without UUID", func(t *testing.T) {
+		tcache := newCache()
+		api := newAPI(tcache)
+		operations := []ovsdb.Operation{
+			{
+				Op:    ovsdb.OperationInsert,
+				Table: "Logical_Switch_Port",
+				Row:   ovsdb.Row{"name": "lsp2"},
+			},
+		}
+		err := api.ApplyOptimistic(operations, []ovsdb.OperationResult{{}})
+		assert.Equal(t, &ErrOptimisticConflict{Operations: []int{0}}, err)
+		assert.Nil(t, tcache.Table("Logical_Switch_Port").Row(""))
+		assert.Equal(t, 1, tcache.Table("Logical_Switch_Port").Len())
+	})
+
+	t.Run("ApplyOptimistic: conflicting operations are not applied", func(t *testing.T) {
+		tcache := newCache()
+		api := newAPI(tcache)
+		operations := []ovsdb.Operation{
+			{
+				Op:    ovsdb.OperationDelete,
+				Table: "Logical_Switch_Port",
+				Where: []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "lsp2")},
+			},
+			{
+				Op:    ovsdb.OperationDelete,
+				Table: "Logical_Switch_Port",
+				Where: []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "lsp1")},
+			},
+			{
+				Op:    ovsdb.OperationUpdate,
+				Table: "Logical_Switch",
+				Row:   ovsdb.Row{"name": "ls1"},
+			},
+		}
+		results := []ovsdb.OperationResult{{Count: 1}, {Count: 1}, {Count: 2}}
+		err := api.ApplyOptimistic(operations, results)
+		assert.Equal(t, &ErrOptimisticConflict{Operations: []int{0, 2}}, err)
+		assert.Nil(t, tcache.Table("Logical_Switch_Port").Row(aUUID1))
+		assert.True(t, tcache.IsOptimistic("Logical_Switch_Port", aUUID1))
+		assert.Equal(t, "ls0", tcache.Table("Logical_Switch").Row(aUUID0).(*testLogicalSwitch).Name)
+		assert.False(t, tcache.IsOptimistic("Logical_Switch", aUUID0))
+	})
+
+	t.Run("ApplyOptimistic: failed transaction", func(t *testing.T) {
+		tcache := newCache()
+		api := newAPI(tcache)
+		operations := []ovsdb.Operation{
+			{
+				Op:    ovsdb.OperationDelete,
+				Table: "Logical_Switch_Port",
+				Where: []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "lsp1")},
+			},
+		}
+		results := []ovsdb.OperationResult{{Count: 1}, {Error: "referential integrity violation"}}
+		err := api.ApplyOptimistic(operations, results)
+		assert.NotNil(t, err)
+		assert.NotNil(t, tcache.Table("Logical_Switch_Port").Row(aUUID1))
+		assert.False(t, tcache.IsOptimistic("Logical_Switch_Port", aUUID1))
+	})
+}
+
+func TestAPICacheSyncing(t *testing.T) {
+	tcache := apiTestCache(t)
+	lsCache := map[string]model.Model{
+		aUUID0: &testLogicalSwitch{UUID: aUUID0, Name: "ls0"},
+	}
+	tcache.Set("Logical_Switch", cache.NewRowCache(lsCache))
+	api := newAPI(tcache)
+
+	tcache.StartInitialSync()
+	t.Run("ApiCacheSyncing: list returns the rows cached so far", func(t *testing.T) {
+		var result []testLogicalSwitch
+		err := api.List(&result)
+		assert.True(t, errors.Is(err, ErrCacheSyncing), "expected a syncing error, got %v", err)
+		assert.Equal(t, []testLogicalSwitch{{UUID: aUUID0, Name: "ls0"}}, result)
+	})
+	t.Run("ApiCacheSyncing: get of a cached row", func(t *testing.T) {
+		result := testLogicalSwitch{UUID: aUUID0}
+		err := api.Get(&result)
+		assert.Nil(t, err)
+		assert.Equal(t, "ls0", result.Name)
+	})
+	t.Run("ApiCacheSyncing: get of a missing row", func(t *testing.T) {
+		result := testLogicalSwitch{Name: "ls1"}
+		err := api.Get(&result)
+		assert.True(t, errors.Is(err, ErrCacheSyncing), "expected a syncing error, got %v", err)
+	})
+
+	tcache.CompleteInitialSync()
+	t.Run("ApiCacheSyncing: synced list", func(t *testing.T) {
+		var result []testLogicalSwitch
+		err := api.List(&result)
+		assert.Nil(t, err)
+		assert.Len(t, result, 1)
+	})
+	t.Run("ApiCacheSyncing: synced get of a missing row", func(t *testing.T) {
+		result := testLogicalSwitch{Name: "ls1"}
+		err := api.Get(&result)
+		assert.Equal(t, ErrNotFound, err)
+	})
+}
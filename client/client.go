@@ -1,18 +1,24 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net"
 	"net/url"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/cenkalti/rpc2"
 	"github.com/cenkalti/rpc2/jsonrpc"
 	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/mapper"
 	"github.com/ovn-org/libovsdb/model"
 	"github.com/ovn-org/libovsdb/ovsdb"
 )
@@ -26,6 +32,37 @@ type OvsdbClient struct {
 	Cache         *cache.TableCache
 	stopCh        chan struct{}
 	api           API
+	// locks holds the ownership state of the locks this client has requested
+	locks      map[string]bool
+	locksMutex *sync.RWMutex
+	// observer, if set, is notified of every transaction
+	observer Observer
+	// databases holds the additional databases the client is connected to, by name
+	databases     map[string]*database
+	databaseNames []string
+	// monitors maps the json context of the monitors to the name of their database
+	monitors map[string]string
+	// condMonitors holds the conditional monitors, by json context
+	condMonitors  map[string]*condMonitor
+	monitorsMutex *sync.RWMutex
+	// requestTimeout, if not zero, bounds the time every RPC waits for its reply
+	requestTimeout time.Duration
+	// inflight, if not nil, holds a token for every RPC waiting for its reply
+	inflight chan struct{}
+	// maxTimeouts, if not zero, is the number of consecutive RPCs that time out after which the
+	// connection is closed, and timeouts counts them
+	maxTimeouts int32
+	timeouts    *int32
+	// lastTxnID holds the id of the last transaction whose updates were received by the
+	// monitors created with MonitorCondSince
+	lastTxnID *transactionID
+	// pending holds the transactions waiting for their reply, see Shutdown
+	pending *pendingTransactions
+	// history, if not nil, records the last transactions
+	history *transactionHistory
+	// streamBatch, if not zero, is the number of rows of the initial dumps delivered to the
+	// cache at once
+	streamBatch int
 }
 
 func newOvsdbClient() *OvsdbClient {
@@ -33,6 +70,15 @@ func newOvsdbClient() *OvsdbClient {
 	ovs := &OvsdbClient{
 		handlersMutex: &sync.Mutex{},
 		stopCh:        make(chan struct{}),
+		locks:         make(map[string]bool),
+		locksMutex:    &sync.RWMutex{},
+		databases:     make(map[string]*database),
+		monitors:      make(map[string]string),
+		condMonitors:  make(map[string]*condMonitor),
+		monitorsMutex: &sync.RWMutex{},
+		lastTxnID:     &transactionID{},
+		pending:       newPendingTransactions(),
+		timeouts:      new(int32),
 	}
 	return ovs
 }
@@ -46,13 +92,30 @@ const (
 	UNIX               = "unix"
 )
 
+// ErrSchemaVersionMismatch is returned when the version of the schema reported by the
+// server is not the expected one
+type ErrSchemaVersionMismatch struct {
+	Expected string
+	Actual   string
+}
+
+func (e *ErrSchemaVersionMismatch) Error() string {
+	return fmt.Sprintf("schema version mismatch: expected %s, server has %s", e.Expected, e.Actual)
+}
+
 // Connect to ovn, using endpoint in format ovsdb Connection Methods
 // If address is empty, use default address for specified protocol
-func Connect(endpoints string, database *model.DBModel, tlsConfig *tls.Config) (*OvsdbClient, error) {
+// Additional options can be provided to configure the client's behavior
+func Connect(endpoints string, database *model.DBModel, tlsConfig *tls.Config, opts ...Option) (*OvsdbClient, error) {
 	var c net.Conn
 	var err error
 	var u *url.URL
 
+	options, err := newOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, endpoint := range strings.Split(endpoints, ",") {
 		if u, err = url.Parse(endpoint); err != nil {
 			return nil, err
@@ -69,26 +132,52 @@ func Connect(endpoints string, database *model.DBModel, tlsConfig *tls.Config) (
 			if len(path) == 0 {
 				path = defaultUnixAddress
 			}
+			if options.unixSocketOwner != nil {
+				if err = checkSocketOwner(path, options.unixSocketOwner); err != nil {
+					continue
+				}
+			}
 			c, err = net.Dial(u.Scheme, path)
 		case TCP:
 			c, err = net.Dial(u.Scheme, host)
 		case SSL:
-			c, err = tls.Dial("tcp", host, tlsConfig)
+			if options.tlsConfig != nil {
+				tlsConfig = options.tlsConfig
+			}
+			c, err = dialTLS(host, tlsConfig)
 		default:
 			err = fmt.Errorf("unknown network protocol %s", u.Scheme)
 		}
 
 		if err == nil {
-			return newRPC2Client(c, database)
+			return newRPC2Client(c, database, options)
 		}
 	}
 
-	return nil, fmt.Errorf("failed to connect to endpoints %q: %v", endpoints, err)
+	return nil, fmt.Errorf("failed to connect to endpoints %q: %w", endpoints, err)
 }
 
-func newRPC2Client(conn net.Conn, database *model.DBModel) (*OvsdbClient, error) {
+func newRPC2Client(conn net.Conn, database *model.DBModel, options *options) (*OvsdbClient, error) {
 	ovs := newOvsdbClient()
-	ovs.rpcClient = rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(conn))
+	ovs.observer = options.observer
+	ovs.requestTimeout = options.requestTimeout
+	ovs.maxTimeouts = int32(options.maxTimeouts)
+	ovs.streamBatch = options.streamBatch
+	if options.maxInflight > 0 {
+		ovs.inflight = make(chan struct{}, options.maxInflight)
+	}
+	if options.historySize > 0 {
+		ovs.history = newTransactionHistory(options.historySize)
+	}
+	if options.maxMessageSize > 0 {
+		conn = newLimitedConn(conn, options.maxMessageSize)
+	}
+	if options.streamBatch > 0 {
+		// The initial contents of the monitored tables are read from the connection
+		ovs.rpcClient = rpc2.NewClientWithCodec(newStreamingCodec(conn))
+	} else {
+		ovs.rpcClient = rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(conn))
+	}
 	ovs.rpcClient.SetBlocking(true)
 	ovs.rpcClient.Handle("echo", func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
 		return ovs.echo(args, reply)
@@ -96,58 +185,86 @@ func newRPC2Client(conn net.Conn, database *model.DBModel) (*OvsdbClient, error)
 	ovs.rpcClient.Handle("update", func(_ *rpc2.Client, args []json.RawMessage, reply *[]interface{}) error {
 		return ovs.update(args, reply)
 	})
+	ovs.rpcClient.Handle("update2", func(_ *rpc2.Client, args []json.RawMessage, reply *[]interface{}) error {
+		return ovs.update2(args, reply)
+	})
+	ovs.rpcClient.Handle("update3", func(_ *rpc2.Client, args []json.RawMessage, reply *[]interface{}) error {
+		return ovs.update3(args, reply)
+	})
+	ovs.rpcClient.Handle("locked", func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+		return ovs.locked(args, reply)
+	})
+	ovs.rpcClient.Handle("stolen", func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+		return ovs.stolen(args, reply)
+	})
 	go ovs.rpcClient.Run()
 	go ovs.handleDisconnectNotification()
 
-	dbs, err := ovs.ListDbs()
+	dbs, err := ovs.ListDatabases(context.Background())
 	if err != nil {
 		ovs.rpcClient.Close()
 		return nil, err
 	}
 
-	found := false
-	for _, db := range dbs {
-		if db == database.Name() {
-			found = true
-			break
-		}
-	}
-	if !found {
+	primary, err := ovs.newDatabase(dbs, database, options, true)
+	if err != nil {
 		ovs.rpcClient.Close()
-		return nil, fmt.Errorf("target database not found")
+		return nil, err
 	}
-
-	schema, err := ovs.GetSchema(database.Name())
-	errors := database.Validate(schema)
-	if len(errors) > 0 {
-		var combined []string
-		for _, err := range errors {
-			combined = append(combined, err.Error())
+	if options.resume != nil && !options.noCache {
+		if err := ovs.resumeFrom(options.resume, primary); err != nil {
+			ovs.rpcClient.Close()
+			return nil, err
 		}
-		return nil, fmt.Errorf("database validation error (%d): %s", len(errors),
-			strings.Join(combined, ". "))
 	}
+	ovs.Schema = *primary.schema
+	ovs.Cache = primary.cache
+	ovs.api = primary.api
 
-	if err == nil {
-		ovs.Schema = *schema
-		if cache, err := cache.NewTableCache(schema, database); err == nil {
-			ovs.Cache = cache
-			ovs.Register(ovs.Cache)
-			ovs.api = newAPI(ovs.Cache)
-		} else {
+	for _, dbModel := range options.databases {
+		if _, ok := ovs.databases[dbModel.Name()]; ok || dbModel.Name() == database.Name() {
+			ovs.rpcClient.Close()
+			return nil, fmt.Errorf("database %s registered more than once", dbModel.Name())
+		}
+		db, err := ovs.newDatabase(dbs, dbModel, options, false)
+		if err != nil {
 			ovs.rpcClient.Close()
 			return nil, err
 		}
-	} else {
-		ovs.rpcClient.Close()
-		return nil, err
+		ovs.databases[dbModel.Name()] = db
+		ovs.databaseNames = append(ovs.databaseNames, dbModel.Name())
 	}
 
-	go ovs.Cache.Run(ovs.stopCh)
+	if !options.noCache {
+		ovs.Register(ovs.Cache)
+		go ovs.Cache.Run(ovs.stopCh)
+		for _, db := range ovs.databases {
+			go db.cache.Run(ovs.stopCh)
+		}
+	}
+	if options.keepalive != nil {
+		go ovs.keepalive(options.keepalive)
+	}
 
 	return ovs, nil
 }
 
+// checkSchemaVersion compares the version of the schema with the expected one, if any
+func checkSchemaVersion(options *options, schema *ovsdb.DatabaseSchema) error {
+	if options.schemaVersion == "" || options.schemaVersion == schema.Version {
+		return nil
+	}
+	err := &ErrSchemaVersionMismatch{
+		Expected: options.schemaVersion,
+		Actual:   schema.Version,
+	}
+	if options.schemaVersionWarn {
+		log.Printf("warning: %s", err)
+		return nil
+	}
+	return err
+}
+
 // Register registers the supplied NotificationHandler to recieve OVSDB Notifications
 func (ovs *OvsdbClient) Register(handler ovsdb.NotificationHandler) {
 	ovs.handlersMutex.Lock()
@@ -203,6 +320,12 @@ func (ovs *OvsdbClient) update(args []json.RawMessage, reply *[]interface{}) err
 	if err != nil {
 		return err
 	}
+	// Updates of the monitors of additional databases only go to their cache
+	if db := ovs.monitorDatabase(value); db != nil {
+		db.cache.Update(value, updates)
+		*reply = []interface{}{}
+		return nil
+	}
 	// Update the local DB cache with the tableUpdates
 	ovs.handlersMutex.Lock()
 	defer ovs.handlersMutex.Unlock()
@@ -213,51 +336,552 @@ func (ovs *OvsdbClient) update(args []json.RawMessage, reply *[]interface{}) err
 	return nil
 }
 
+// update2 notification as described in ovsdb-server(7)
+// It is delivered to the handlers that implement ovsdb.Update2NotificationHandler
+func (ovs *OvsdbClient) update2(args []json.RawMessage, reply *[]interface{}) error {
+	var value interface{}
+	if len(args) != 2 {
+		return fmt.Errorf("update2 requires exactly 2 args")
+	}
+	err := json.Unmarshal(args[0], &value)
+	if err != nil {
+		return err
+	}
+	var updates ovsdb.TableUpdates2
+	err = json.Unmarshal(args[1], &updates)
+	if err != nil {
+		return err
+	}
+	if db := ovs.monitorDatabase(value); db != nil {
+		db.cache.Update2(value, updates)
+		*reply = []interface{}{}
+		return nil
+	}
+	ovs.handlersMutex.Lock()
+	defer ovs.handlersMutex.Unlock()
+	for _, handler := range ovs.handlers {
+		if h, ok := handler.(ovsdb.Update2NotificationHandler); ok {
+			h.Update2(value, updates)
+		}
+	}
+	*reply = []interface{}{}
+	return nil
+}
+
+// update3 notification as described in ovsdb-server(7), sent to the monitors created with
+// MonitorCondSince. The updates are delivered like update2 ones and, once the handlers got them,
+// the id of their transaction is recorded, for the database of the monitor, so the monitors can
+// be resumed from it
+func (ovs *OvsdbClient) update3(args []json.RawMessage, reply *[]interface{}) error {
+	var value interface{}
+	if len(args) != 3 {
+		return fmt.Errorf("update3 requires exactly 3 args")
+	}
+	err := json.Unmarshal(args[0], &value)
+	if err != nil {
+		return err
+	}
+	var txnID string
+	err = json.Unmarshal(args[1], &txnID)
+	if err != nil {
+		return err
+	}
+	var updates ovsdb.TableUpdates2
+	err = json.Unmarshal(args[2], &updates)
+	if err != nil {
+		return err
+	}
+	ovs.handlersMutex.Lock()
+	defer ovs.handlersMutex.Unlock()
+	if db := ovs.monitorDatabase(value); db != nil {
+		db.cache.Update2(value, updates)
+		db.lastTxnID.set(txnID)
+		*reply = []interface{}{}
+		return nil
+	}
+	for _, handler := range ovs.handlers {
+		if h, ok := handler.(ovsdb.Update2NotificationHandler); ok {
+			h.Update2(value, updates)
+		}
+	}
+	ovs.lastTxnID.set(txnID)
+	*reply = []interface{}{}
+	return nil
+}
+
+// RFC 7047 : Locked Notification Section 4.1.9
+func (ovs *OvsdbClient) locked(args []interface{}, reply *[]interface{}) error {
+	if len(args) != 1 {
+		return fmt.Errorf("locked notification requires exactly 1 arg")
+	}
+	id, ok := args[0].(string)
+	if !ok {
+		return fmt.Errorf("invalid lock id %v", args[0])
+	}
+	ovs.setLockState(id, true)
+	ovs.handlersMutex.Lock()
+	defer ovs.handlersMutex.Unlock()
+	for _, handler := range ovs.handlers {
+		handler.Locked(args)
+	}
+	*reply = []interface{}{}
+	return nil
+}
+
+// RFC 7047 : Stolen Notification Section 4.1.10
+func (ovs *OvsdbClient) stolen(args []interface{}, reply *[]interface{}) error {
+	if len(args) != 1 {
+		return fmt.Errorf("stolen notification requires exactly 1 arg")
+	}
+	id, ok := args[0].(string)
+	if !ok {
+		return fmt.Errorf("invalid lock id %v", args[0])
+	}
+	ovs.setLockState(id, false)
+	ovs.handlersMutex.Lock()
+	defer ovs.handlersMutex.Unlock()
+	for _, handler := range ovs.handlers {
+		handler.Stolen(args)
+	}
+	*reply = []interface{}{}
+	return nil
+}
+
+func (ovs *OvsdbClient) setLockState(id string, locked bool) {
+	ovs.locksMutex.Lock()
+	defer ovs.locksMutex.Unlock()
+	ovs.locks[id] = locked
+}
+
+// Lock requests the lock with the provided id and returns whether it has
+// been acquired. If it has not, the server will grant it later on, which is
+// notified through the NotificationHandler's Locked function
+// RFC 7047 : lock
+func (ovs *OvsdbClient) Lock(id string) (bool, error) {
+	var reply ovsdb.LockResult
+	args := ovsdb.NewLockArgs(id)
+	err := ovs.call(context.Background(), "lock", args, &reply)
+	if err != nil {
+		return false, err
+	}
+	ovs.setLockState(id, reply.Locked)
+	return reply.Locked, nil
+}
+
+// Steal acquires the lock with the provided id, taking it away from its current owner
+// RFC 7047 : steal
+func (ovs *OvsdbClient) Steal(id string) error {
+	var reply ovsdb.LockResult
+	args := ovsdb.NewLockArgs(id)
+	err := ovs.call(context.Background(), "steal", args, &reply)
+	if err != nil {
+		return err
+	}
+	ovs.setLockState(id, reply.Locked)
+	return nil
+}
+
+// Unlock releases the lock with the provided id, or cancels a pending
+// lock request
+// RFC 7047 : unlock
+func (ovs *OvsdbClient) Unlock(id string) error {
+	var reply interface{}
+	args := ovsdb.NewLockArgs(id)
+	err := ovs.call(context.Background(), "unlock", args, &reply)
+	if err != nil {
+		return err
+	}
+	ovs.locksMutex.Lock()
+	defer ovs.locksMutex.Unlock()
+	delete(ovs.locks, id)
+	return nil
+}
+
+// HasLock returns whether this client currently owns the lock with the provided id
+func (ovs *OvsdbClient) HasLock(id string) bool {
+	ovs.locksMutex.RLock()
+	defer ovs.locksMutex.RUnlock()
+	return ovs.locks[id]
+}
+
+// call performs an RPC and waits for its reply or for the context, bounded by the request
+// timeout, to be done. The reply is only decoded into the provided one if it is received in
+// time, so a late one is discarded
+func (ovs OvsdbClient) call(ctx context.Context, method string, args interface{}, reply interface{}) error {
+	if ovs.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ovs.requestTimeout)
+		defer cancel()
+	}
+	if ovs.inflight != nil {
+		select {
+		case ovs.inflight <- struct{}{}:
+		case <-ctx.Done():
+			// The slots are held by requests still waiting for their reply
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				ovs.timedOut()
+			}
+			return ctx.Err()
+		}
+	}
+	var raw json.RawMessage
+	call := ovs.rpcClient.Go(method, args, &raw, make(chan *rpc2.Call, 1))
+	select {
+	case <-call.Done:
+		ovs.releaseInflight()
+		atomic.StoreInt32(ovs.timeouts, 0)
+		if call.Error != nil {
+			return call.Error
+		}
+		if reply == nil {
+			return nil
+		}
+		return json.Unmarshal(raw, reply)
+	case <-ctx.Done():
+		if ovs.inflight != nil {
+			// The request is still outstanding until the reply arrives or the connection is closed
+			go func() {
+				<-call.Done
+				ovs.releaseInflight()
+			}()
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			ovs.timedOut()
+		}
+		return ctx.Err()
+	}
+}
+
+// timedOut records an RPC that timed out and closes the connection if it is the last of the
+// maximum number of consecutive ones, so the server that stopped responding does not hold the
+// pending requests and their inflight slots anymore
+func (ovs OvsdbClient) timedOut() {
+	if ovs.maxTimeouts > 0 && atomic.AddInt32(ovs.timeouts, 1) == ovs.maxTimeouts {
+		log.Printf("closing the connection after %d consecutive request timeouts", ovs.maxTimeouts)
+		ovs.rpcClient.Close()
+	}
+}
+
+// releaseInflight frees the inflight slot of an RPC that got its reply
+func (ovs OvsdbClient) releaseInflight() {
+	if ovs.inflight != nil {
+		<-ovs.inflight
+	}
+}
+
 // GetSchema returns the schema in use for the provided database name
 // RFC 7047 : get_schema
-func (ovs OvsdbClient) GetSchema(dbName string) (*ovsdb.DatabaseSchema, error) {
+func (ovs OvsdbClient) GetSchema(ctx context.Context, dbName string) (*ovsdb.DatabaseSchema, error) {
 	args := ovsdb.NewGetSchemaArgs(dbName)
 	var reply ovsdb.DatabaseSchema
-	err := ovs.rpcClient.Call("get_schema", args, &reply)
+	err := ovs.call(ctx, "get_schema", args, &reply)
 	if err != nil {
 		return nil, err
 	}
-	ovs.Schema = reply
 	return &reply, err
 }
 
-// ListDbs returns the list of databases on the server
+// ListDatabases returns the list of databases on the server
 // RFC 7047 : list_dbs
-func (ovs OvsdbClient) ListDbs() ([]string, error) {
+func (ovs OvsdbClient) ListDatabases(ctx context.Context) ([]string, error) {
 	var dbs []string
-	err := ovs.rpcClient.Call("list_dbs", nil, &dbs)
+	err := ovs.call(ctx, "list_dbs", nil, &dbs)
 	if err != nil {
 		return nil, fmt.Errorf("listdbs failure - %v", err)
 	}
 	return dbs, err
 }
 
+// ListDbs returns the list of databases on the server
+// It is equivalent to ListDatabases without a deadline
+func (ovs OvsdbClient) ListDbs() ([]string, error) {
+	return ovs.ListDatabases(context.Background())
+}
+
 // Transact performs the provided Operation's on the database
+// If the client is connected to additional databases, the operations are performed on the
+// database they are valid for. If they are valid for several of them, TransactDatabase has to
+// be used instead
 // RFC 7047 : transact
 func (ovs OvsdbClient) Transact(operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
-	var reply []ovsdb.OperationResult
+	schema, err := ovs.schemaForOperations(operation)
+	if err != nil {
+		return nil, err
+	}
+	return ovs.transactSchema(context.Background(), schema, operation...)
+}
 
-	if ok := ovs.Schema.ValidateOperations(operation...); !ok {
+// TransactContext validates and performs the provided Operation's, typically built by several API
+// calls, in a single transaction, like Transact does, unless the context is done first. The
+// results are checked too: if the transaction was executed but some operation failed, the results
+// are returned along with the error describing the failure (see ovsdb.CheckOperationResults)
+func (ovs OvsdbClient) TransactContext(ctx context.Context, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+	schema, err := ovs.schemaForOperations(operation)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := ovs.transactSchema(ctx, schema, operation...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ovsdb.CheckOperationResults(reply, operation); err != nil {
+		return reply, err
+	}
+	return reply, nil
+}
+
+// TransactDatabase performs the provided Operation's on the given database, which is either the
+// one the client was connected to or one of the additional ones (see WithDatabase)
+func (ovs OvsdbClient) TransactDatabase(dbName string, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+	schema := &ovs.Schema
+	if dbName != schema.Name {
+		db, err := ovs.database(dbName)
+		if err != nil {
+			return nil, err
+		}
+		schema = db.schema
+	}
+	if ok := schema.ValidateOperations(operation...); !ok {
 		return nil, fmt.Errorf("validation failed for the operation")
 	}
+	return ovs.transactSchema(context.Background(), schema, operation...)
+}
+
+// transactSchema performs the provided, already validated, Operation's on the database of the schema
+func (ovs OvsdbClient) transactSchema(ctx context.Context, schema *ovsdb.DatabaseSchema, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+	if err := ovsdb.ValidateNamedUUIDs(operation...); err != nil {
+		return nil, err
+	}
 
-	args := ovsdb.NewTransactArgs(ovs.Schema.Name, operation...)
-	err := ovs.rpcClient.Call("transact", args, &reply)
+	if ovs.observer != nil {
+		return observeTransact(ovs.observer, operation, func() ([]ovsdb.OperationResult, error) {
+			return ovs.transact(ctx, schema.Name, operation...)
+		})
+	}
+	return ovs.transact(ctx, schema.Name, operation...)
+}
+
+// transact sends the transact RPC to the server
+func (ovs OvsdbClient) transact(ctx context.Context, dbName string, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+	id, err := ovs.pending.add(dbName, operation)
+	if err != nil {
+		return nil, err
+	}
+	defer ovs.pending.remove(id)
+	var reply []ovsdb.OperationResult
+	args := ovsdb.NewTransactArgs(dbName, operation...)
+	start := time.Now()
+	err = ovs.call(ctx, "transact", args, &reply)
+	if ovs.history != nil {
+		record := TxnRecord{Database: dbName, Operations: operation, Error: err, Timestamp: start, Duration: time.Since(start)}
+		if err == nil {
+			record.Results = reply
+			_, record.Error = ovsdb.CheckOperationResults(reply, operation)
+		}
+		ovs.history.add(record)
+	}
 	if err != nil {
 		return nil, err
 	}
 	return reply, nil
 }
 
+// CountFromServer returns the number of rows of the table that match all the conditions (or all
+// the rows of the table, if none is given) as counted by the server, without populating the cache.
+// OVSDB has no count operation, so a select of just the _uuid column is performed and its rows are
+// counted: only the UUIDs of the matching rows are transferred. The table may belong to any of the
+// databases the client is connected to
+func (ovs OvsdbClient) CountFromServer(ctx context.Context, table string, conditions []ovsdb.Condition) (int, error) {
+	operation := ovsdb.Operation{
+		Op:      ovsdb.OperationSelect,
+		Table:   table,
+		Where:   conditions,
+		Columns: []string{"_uuid"},
+	}
+	schema, err := ovs.schemaForOperations([]ovsdb.Operation{operation})
+	if err != nil {
+		return 0, err
+	}
+	reply, err := ovs.transactSchema(ctx, schema, operation)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := ovsdb.CheckOperationResults(reply, []ovsdb.Operation{operation}); err != nil {
+		return 0, err
+	}
+	return len(reply[0].Rows), nil
+}
+
+// TransactResult holds the results of a transaction along with the real UUIDs
+// assigned by the server to the inserted rows
+type TransactResult struct {
+	Results []ovsdb.OperationResult
+	// UUIDs maps the named UUID of each insert operation to the real UUID of the inserted row
+	UUIDs map[string]string
+	// models maps the models provided to TransactModels to the real UUID of the row inserted from them
+	models map[model.Model]string
+	// operations holds the operations the results are for
+	operations []ovsdb.Operation
+	// cacheFor returns the cache of the database a model belongs to, whose mapper decodes the rows
+	cacheFor func(model.Model) *cache.TableCache
+}
+
+// UUIDFor returns the real UUID of the row inserted from a model provided to TransactModels, e.g: to
+// reference it from the rows created in a subsequent transaction. False is returned if the model was
+// not provided or no row was inserted from it
+func (r *TransactResult) UUIDFor(m model.Model) (string, bool) {
+	uuid, ok := r.models[m]
+	return uuid, ok
+}
+
+// Models populates a slice of Models given as parameter with the rows returned by the select
+// operations of the transaction on the provided table, in order, decoding them like the cache
+// does. The _uuid and _version columns are decoded too if they were selected
+func (r *TransactResult) Models(table string, result interface{}) error {
+	resultPtr := reflect.ValueOf(result)
+	if resultPtr.Type().Kind() != reflect.Ptr {
+		return &ErrWrongType{resultPtr.Type(), "Expected pointer to slice of valid Models"}
+	}
+	resultVal := reflect.Indirect(resultPtr)
+	if resultVal.Type().Kind() != reflect.Slice {
+		return &ErrWrongType{resultPtr.Type(), "Expected pointer to slice of valid Models"}
+	}
+	elemType := resultVal.Type().Elem()
+	if r.cacheFor == nil {
+		return fmt.Errorf("the result does not hold the database model to decode the rows")
+	}
+	tcache := r.cacheFor(reflect.New(elemType).Interface())
+	if tableName := tcache.DBModel().FindTable(reflect.PtrTo(elemType)); tableName != table {
+		return &ErrWrongType{resultPtr.Type(),
+			fmt.Sprintf("Table derived from input type (%s) does not match the provided table (%s)", tableName, table)}
+	}
+	tableSchema := tcache.Mapper().Schema.Table(table)
+	if tableSchema == nil {
+		return fmt.Errorf("table %s not found in schema", table)
+	}
+
+	for i, op := range r.operations {
+		if op.Op != ovsdb.OperationSelect || op.Table != table || i >= len(r.Results) {
+			continue
+		}
+		for j := range r.Results[i].Rows {
+			row := r.Results[i].Rows[j]
+			elem := reflect.New(elemType)
+			if err := tcache.Mapper().GetRowData(table, &row, elem.Interface()); err != nil {
+				return err
+			}
+			if uuid, ok := row["_uuid"]; ok {
+				info, err := mapper.NewMapperInfo(tableSchema, elem.Interface())
+				if err != nil {
+					return err
+				}
+				nativeUUID, err := ovsdb.OvsToNative(&ovsdb.UUIDColumn, uuid)
+				if err != nil {
+					return err
+				}
+				if err := info.SetField("_uuid", nativeUUID); err != nil {
+					return err
+				}
+			}
+			resultVal.Set(reflect.Append(resultVal, elem.Elem()))
+		}
+	}
+	return nil
+}
+
+// TransactModels performs a transaction and maps the results of the insert operations back to
+// their named UUIDs. If models are provided (e.g: the ones used to Create the operations), the
+// named UUID held in their _uuid field is replaced by the real UUID of the inserted row, which
+// can also be read with the result's UUIDFor. The rows returned by select operations can be
+// decoded with the result's Models. If any operation fails, the result is returned along with
+// the error
+func (ovs OvsdbClient) TransactModels(operations []ovsdb.Operation, models ...model.Model) (*TransactResult, error) {
+	reply, err := ovs.Transact(operations...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := newTransactResult(operations, reply)
+	if err != nil {
+		return result, err
+	}
+	result.cacheFor = ovs.cacheForModel
+	for _, m := range models {
+		if err := result.resolveModelUUIDs(ovs.cacheForModel(m), m); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// newTransactResult creates a TransactResult from the operations of a transaction and their results
+func newTransactResult(operations []ovsdb.Operation, results []ovsdb.OperationResult) (*TransactResult, error) {
+	result := &TransactResult{
+		Results:    results,
+		UUIDs:      make(map[string]string),
+		models:     make(map[model.Model]string),
+		operations: operations,
+	}
+	if _, err := ovsdb.CheckOperationResults(results, operations); err != nil {
+		return result, err
+	}
+	for i, op := range operations {
+		if op.Op == opInsert && op.UUIDName != "" {
+			result.UUIDs[op.UUIDName] = results[i].UUID.GoUUID
+		}
+	}
+	return result, nil
+}
+
+// resolveModelUUIDs replaces the named UUIDs held in the _uuid field of the models by their real UUIDs
+// and records them for UUIDFor. Models whose _uuid field does not hold one of the named UUIDs are
+// left untouched
+func (r *TransactResult) resolveModelUUIDs(tcache *cache.TableCache, models ...model.Model) error {
+	for _, m := range models {
+		tableName := tcache.DBModel().FindTable(reflect.TypeOf(m))
+		table := tcache.Mapper().Schema.Table(tableName)
+		if table == nil {
+			return &ErrWrongType{reflect.TypeOf(m), "Model not found in Database Model"}
+		}
+		info, err := mapper.NewMapperInfo(table, m)
+		if err != nil {
+			return err
+		}
+		namedUUID, err := info.FieldByColumn("_uuid")
+		if err != nil {
+			return err
+		}
+		if realUUID, ok := r.UUIDs[namedUUID.(string)]; ok {
+			if err := info.SetField("_uuid", realUUID); err != nil {
+				return err
+			}
+			r.models[m] = realUUID
+		}
+	}
+	return nil
+}
+
 // MonitorAll is a convenience method to monitor every table/column
 func (ovs OvsdbClient) MonitorAll(jsonContext interface{}) error {
+	return ovs.Monitor(jsonContext, monitorAllRequests(&ovs.Schema))
+}
+
+// MonitorAllDatabase is like MonitorAll, but on the given database, which is either the
+// one the client was connected to or one of the additional ones (see WithDatabase)
+func (ovs OvsdbClient) MonitorAllDatabase(dbName string, jsonContext interface{}) error {
+	if dbName == ovs.Schema.Name {
+		return ovs.MonitorAll(jsonContext)
+	}
+	db, err := ovs.database(dbName)
+	if err != nil {
+		return err
+	}
+	return ovs.MonitorDatabase(dbName, jsonContext, monitorAllRequests(db.schema))
+}
+
+// monitorAllRequests returns the monitor requests for every table/column of the schema
+func monitorAllRequests(schema *ovsdb.DatabaseSchema) map[string]ovsdb.MonitorRequest {
 	requests := make(map[string]ovsdb.MonitorRequest)
-	for table, tableSchema := range ovs.Schema.Tables {
+	for table, tableSchema := range schema.Tables {
 		var columns []string
 		for column := range tableSchema.Columns {
 			columns = append(columns, column)
@@ -267,7 +891,7 @@ func (ovs OvsdbClient) MonitorAll(jsonContext interface{}) error {
 			Select:  ovsdb.NewDefaultMonitorSelect(),
 		}
 	}
-	return ovs.Monitor(jsonContext, requests)
+	return requests
 }
 
 // MonitorCancel will request cancel a previously issued monitor request
@@ -277,10 +901,12 @@ func (ovs OvsdbClient) MonitorCancel(jsonContext interface{}) error {
 
 	args := ovsdb.NewMonitorCancelArgs(jsonContext)
 
-	err := ovs.rpcClient.Call("monitor_cancel", args, &reply)
+	err := ovs.call(context.Background(), "monitor_cancel", args, &reply)
 	if err != nil {
 		return err
 	}
+	ovs.removeMonitor(jsonContext)
+	ovs.clearCondMonitor(jsonContext)
 	if reply.Error != "" {
 		return fmt.Errorf("error while executing transaction: %s", reply.Error)
 	}
@@ -294,20 +920,63 @@ func (ovs OvsdbClient) MonitorCancel(jsonContext interface{}) error {
 func (ovs OvsdbClient) Monitor(jsonContext interface{}, requests map[string]ovsdb.MonitorRequest) error {
 	var reply ovsdb.TableUpdates
 
+	if err := ovs.addMonitor(jsonContext, ovs.Schema.Name); err != nil {
+		return err
+	}
 	args := ovsdb.NewMonitorArgs(ovs.Schema.Name, jsonContext, requests)
-	err := ovs.rpcClient.Call("monitor", args, &reply)
+	var err error
+	if ovs.streamBatch > 0 {
+		err = ovs.monitorStreaming(ovs.Cache, "monitor", args)
+	} else if err = ovs.call(context.Background(), "monitor", args, &reply); err == nil {
+		ovs.Cache.Populate(reply)
+	}
+	if err != nil {
+		ovs.removeMonitor(jsonContext)
+		return err
+	}
+	return nil
+}
+
+// MonitorDatabase is like Monitor, but on the given database, which is either the one
+// the client was connected to or one of the additional ones (see WithDatabase).
+// The updates are delivered to the cache of that database
+func (ovs OvsdbClient) MonitorDatabase(dbName string, jsonContext interface{}, requests map[string]ovsdb.MonitorRequest) error {
+	if dbName == ovs.Schema.Name {
+		return ovs.Monitor(jsonContext, requests)
+	}
+	db, err := ovs.database(dbName)
+	if err != nil {
+		return err
+	}
+
+	var reply ovsdb.TableUpdates
+	// Record the database before the request so no update notification is misrouted
+	if err := ovs.addMonitor(jsonContext, dbName); err != nil {
+		return err
+	}
+	args := ovsdb.NewMonitorArgs(dbName, jsonContext, requests)
+	if ovs.streamBatch > 0 {
+		err = ovs.monitorStreaming(db.cache, "monitor", args)
+	} else if err = ovs.call(context.Background(), "monitor", args, &reply); err == nil {
+		db.cache.Populate(reply)
+	}
 	if err != nil {
+		ovs.removeMonitor(jsonContext)
 		return err
 	}
-	ovs.Cache.Populate(reply)
 	return nil
 }
 
 // Echo tests the liveness of the OVSDB connetion
 func (ovs *OvsdbClient) Echo() error {
+	return ovs.echoWithContext(context.Background())
+}
+
+// echoWithContext is like Echo, but it stops waiting for the reply when the context is done
+func (ovs *OvsdbClient) echoWithContext(ctx context.Context) error {
 	args := ovsdb.NewEchoArgs()
 	var reply []interface{}
-	err := ovs.rpcClient.Call("echo", args, &reply)
+	err := ovs.call(ctx, "echo", args, &reply)
 	if err != nil {
 		return err
 	}
@@ -317,7 +986,44 @@ func (ovs *OvsdbClient) Echo() error {
 	return nil
 }
 
+// keepalive sends an echo request periodically until the client is disconnected. If one
+// fails or times out, the failure callback is called and the connection is closed
+func (ovs *OvsdbClient) keepalive(k *keepalive) {
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+	disconnected := ovs.rpcClient.DisconnectNotify()
+	for {
+		select {
+		case <-ovs.stopCh:
+			return
+		case <-disconnected:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), k.timeout)
+			err := ovs.echoWithContext(ctx)
+			cancel()
+			if err != nil {
+				if k.onFailure != nil {
+					k.onFailure(fmt.Errorf("echo keepalive failed: %v", err))
+				}
+				ovs.rpcClient.Close()
+				return
+			}
+		}
+	}
+}
+
 func (ovs *OvsdbClient) clearConnection() {
+	// The locks belong to the session, which is over
+	// The locks belong to the session, which is over. The map is emptied in place as it is
+	// shared with the copies of the client
+	ovs.locksMutex.Lock()
+	for id := range ovs.locks {
+		delete(ovs.locks, id)
+	}
+	ovs.locksMutex.Unlock()
+	ovs.handlersMutex.Lock()
+	defer ovs.handlersMutex.Unlock()
 	for _, handler := range ovs.handlers {
 		if handler != nil {
 			handler.Disconnected()
@@ -332,8 +1038,14 @@ func (ovs *OvsdbClient) handleDisconnectNotification() {
 }
 
 // Disconnect will close the OVSDB connection
+// The transactions waiting for their reply fail, see Shutdown to let them complete first
+// It can be called more than once, e.g: on a client that was resumed from (see WithResume)
 func (ovs OvsdbClient) Disconnect() {
-	close(ovs.stopCh)
+	select {
+	case <-ovs.stopCh:
+	default:
+		close(ovs.stopCh)
+	}
 	ovs.rpcClient.Close()
 }
 
@@ -349,11 +1061,51 @@ func (ovs OvsdbClient) Get(model model.Model) error {
 	return ovs.api.Get(model)
 }
 
+//GetReferenced implements the API interface's GetReferenced function
+func (ovs OvsdbClient) GetReferenced(m model.Model, field interface{}, dest interface{}) error {
+	return ovs.api.GetReferenced(m, field, dest)
+}
+
 //Create implementes the API interface's Create function
 func (ovs OvsdbClient) Create(models ...model.Model) ([]ovsdb.Operation, error) {
 	return ovs.api.Create(models...)
 }
 
+//Upsert implements the API interface's Upsert function
+func (ovs OvsdbClient) Upsert(m model.Model) ([]ovsdb.Operation, error) {
+	return ovs.api.Upsert(m)
+}
+
+//UpsertGuarded implements the API interface's UpsertGuarded function
+func (ovs OvsdbClient) UpsertGuarded(m model.Model) ([]ovsdb.Operation, error) {
+	return ovs.api.UpsertGuarded(m)
+}
+
+//CheckIndexes implements the API interface's CheckIndexes function
+func (ovs OvsdbClient) CheckIndexes(models ...model.Model) error {
+	return ovs.api.CheckIndexes(models...)
+}
+
+//SwapIndex implements the API interface's SwapIndex function
+func (ovs OvsdbClient) SwapIndex(m1, m2 model.Model, field interface{}) ([]ovsdb.Operation, error) {
+	return ovs.api.SwapIndex(m1, m2, field)
+}
+
+//Reconcile implements the API interface's Reconcile function
+func (ovs OvsdbClient) Reconcile(table string, desired []model.Model, opts ...ReconcileOption) ([]ovsdb.Operation, error) {
+	return ovs.api.Reconcile(table, desired, opts...)
+}
+
+//Assert implements the API interface's Assert function
+func (ovs OvsdbClient) Assert(lock string) ovsdb.Operation {
+	return ovs.api.Assert(lock)
+}
+
+//DeleteByUUID implements the API interface's DeleteByUUID function
+func (ovs OvsdbClient) DeleteByUUID(table string, uuids ...string) ([]ovsdb.Operation, error) {
+	return ovs.api.DeleteByUUID(table, uuids...)
+}
+
 //List implements the API interface's List function
 func (ovs OvsdbClient) List(result interface{}) error {
 	return ovs.api.List(result)
@@ -369,7 +1121,82 @@ func (ovs OvsdbClient) WhereAll(m model.Model, conditions ...model.Condition) Co
 	return ovs.api.WhereAll(m, conditions...)
 }
 
+//WhereIgnoreCase implements the API interface's WhereIgnoreCase function
+func (ovs OvsdbClient) WhereIgnoreCase(m model.Model) ConditionalAPI {
+	return ovs.api.WhereIgnoreCase(m)
+}
+
 //WhereCache implements the API interface's WhereCache function
 func (ovs OvsdbClient) WhereCache(predicate interface{}) ConditionalAPI {
 	return ovs.api.WhereCache(predicate)
 }
+
+//WhereFieldContains implements the API interface's WhereFieldContains function
+func (ovs OvsdbClient) WhereFieldContains(m model.Model, field interface{}, substr string) ConditionalAPI {
+	return ovs.api.WhereFieldContains(m, field, substr)
+}
+
+//WhereFieldMatches implements the API interface's WhereFieldMatches function
+func (ovs OvsdbClient) WhereFieldMatches(m model.Model, field interface{}, pattern string) ConditionalAPI {
+	return ovs.api.WhereFieldMatches(m, field, pattern)
+}
+
+//ColumnSchema implements the API interface's ColumnSchema function
+func (ovs OvsdbClient) ColumnSchema(table, column string) (*ovsdb.ColumnSchema, error) {
+	return ovs.api.ColumnSchema(table, column)
+}
+
+//TableColumns implements the API interface's TableColumns function
+func (ovs OvsdbClient) TableColumns(table string) []string {
+	return ovs.api.TableColumns(table)
+}
+
+//UnmappedColumns implements the API interface's UnmappedColumns function
+func (ovs OvsdbClient) UnmappedColumns(table string) ([]string, error) {
+	return ovs.api.UnmappedColumns(table)
+}
+
+//ModelFromRow implements the API interface's ModelFromRow function
+func (ovs OvsdbClient) ModelFromRow(table string, row ovsdb.Row) (model.Model, error) {
+	return ovs.api.ModelFromRow(table, row)
+}
+
+//ApplyOptimistic implements the API interface's ApplyOptimistic function
+func (ovs OvsdbClient) ApplyOptimistic(operations []ovsdb.Operation, results []ovsdb.OperationResult) error {
+	return ovs.api.ApplyOptimistic(operations, results)
+}
+
+//ConditionsFromColumns implements the API interface's ConditionsFromColumns function
+func (ovs OvsdbClient) ConditionsFromColumns(table string, conditions map[string]ColumnCondition) (model.Model, []model.Condition, error) {
+	return ovs.api.ConditionsFromColumns(table, conditions)
+}
+
+//WhereColumn implements the API interface's WhereColumn function
+func (ovs OvsdbClient) WhereColumn(table, column string, function ovsdb.ConditionFunction, value interface{}) ConditionalAPI {
+	return ovs.api.WhereColumn(table, column, function, value)
+}
+
+//ToOvsdbMutation implements the API interface's ToOvsdbMutation function
+func (ovs OvsdbClient) ToOvsdbMutation(m model.Model, mutation model.Mutation) (ovsdb.Mutation, error) {
+	return ovs.api.ToOvsdbMutation(m, mutation)
+}
+
+//WaitForCache implements the API interface's WaitForCache function
+func (ovs OvsdbClient) WaitForCache(ctx context.Context, predicate interface{}) error {
+	return ovs.api.WaitForCache(ctx, predicate)
+}
+
+//WatchModel implements the API interface's WatchModel function
+func (ovs OvsdbClient) WatchModel(m model.Model) (<-chan model.Model, func(), error) {
+	return ovs.api.WatchModel(m)
+}
+
+//WhereMapHasKey implements the API interface's WhereMapHasKey function
+func (ovs OvsdbClient) WhereMapHasKey(m model.Model, field interface{}, key interface{}) ConditionalAPI {
+	return ovs.api.WhereMapHasKey(m, field, key)
+}
+
+//WhereMapValue implements the API interface's WhereMapValue function
+func (ovs OvsdbClient) WhereMapValue(m model.Model, field interface{}, key, value interface{}) ConditionalAPI {
+	return ovs.api.WhereMapValue(m, field, key, value)
+}
@@ -1,12 +1,19 @@
 package client
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/model"
 	"github.com/ovn-org/libovsdb/ovsdb"
 	"github.com/stretchr/testify/assert"
 )
@@ -204,3 +211,891 @@ func TestUpdate(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestLockNotifications(t *testing.T) {
+	ovs := newOvsdbClient()
+	var locked, stolen []interface{}
+	ovs.Register(&testNotifier{
+		locked: func(args []interface{}) { locked = args },
+		stolen: func(args []interface{}) { stolen = args },
+	})
+	assert.False(t, ovs.HasLock("foo"))
+
+	var reply []interface{}
+	err := ovs.locked([]interface{}{"foo"}, &reply)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"foo"}, locked)
+	assert.True(t, ovs.HasLock("foo"))
+	assert.False(t, ovs.HasLock("bar"))
+
+	err = ovs.stolen([]interface{}{"foo"}, &reply)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"foo"}, stolen)
+	assert.False(t, ovs.HasLock("foo"))
+
+	err = ovs.locked([]interface{}{}, &reply)
+	assert.NotNil(t, err)
+	err = ovs.stolen([]interface{}{42}, &reply)
+	assert.NotNil(t, err)
+}
+
+func TestLocksClearedOnDisconnect(t *testing.T) {
+	ovs, _, err := newTestDatabaseClient(t)
+	assert.Nil(t, err)
+	var reply []interface{}
+	err = ovs.locked([]interface{}{"foo"}, &reply)
+	assert.Nil(t, err)
+	assert.True(t, ovs.HasLock("foo"))
+
+	ovs.Disconnect()
+	assert.Eventually(t, func() bool {
+		return !ovs.HasLock("foo")
+	}, time.Second, 10*time.Millisecond, "the locks are released with the connection")
+}
+
+type testNotifier struct {
+	locked func([]interface{})
+	stolen func([]interface{})
+}
+
+func (n *testNotifier) Update(interface{}, ovsdb.TableUpdates) {}
+func (n *testNotifier) Locked(args []interface{}) {
+	if n.locked != nil {
+		n.locked(args)
+	}
+}
+func (n *testNotifier) Stolen(args []interface{}) {
+	if n.stolen != nil {
+		n.stolen(args)
+	}
+}
+func (n *testNotifier) Echo([]interface{}) {}
+func (n *testNotifier) Disconnected()      {}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	schema := &ovsdb.DatabaseSchema{Name: "Open_vSwitch", Version: "8.2.0"}
+
+	opts, err := newOptions()
+	assert.Nil(t, err)
+	assert.Nil(t, checkSchemaVersion(opts, schema))
+
+	opts, err = newOptions(WithSchemaVersion("8.2.0"))
+	assert.Nil(t, err)
+	assert.Nil(t, checkSchemaVersion(opts, schema))
+
+	opts, err = newOptions(WithSchemaVersion("8.1.0"))
+	assert.Nil(t, err)
+	err = checkSchemaVersion(opts, schema)
+	assert.Equal(t, &ErrSchemaVersionMismatch{Expected: "8.1.0", Actual: "8.2.0"}, err)
+
+	opts, err = newOptions(WithSchemaVersionWarning("8.1.0"))
+	assert.Nil(t, err)
+	assert.Nil(t, checkSchemaVersion(opts, schema))
+}
+
+func TestTransactResult(t *testing.T) {
+	tcache := apiTestCache(t)
+	api := newAPI(tcache)
+	lsp := &testLogicalSwitchPort{UUID: "lsp", Name: "lsp"}
+	ls := &testLogicalSwitch{UUID: "ls", Name: "ls"}
+	other := &testLogicalSwitch{UUID: aUUID3, Name: "other"}
+	ops, err := api.Create(lsp, ls)
+	assert.Nil(t, err)
+	ops = append(ops, ovsdb.Operation{Op: opDelete, Table: "Logical_Switch"})
+	results := []ovsdb.OperationResult{
+		{UUID: ovsdb.UUID{GoUUID: aUUID0}},
+		{UUID: ovsdb.UUID{GoUUID: aUUID1}},
+		{Count: 1},
+	}
+
+	result, err := newTransactResult(ops, results)
+	assert.Nil(t, err)
+	assert.Equal(t, results, result.Results)
+	assert.Equal(t, map[string]string{"lsp": aUUID0, "ls": aUUID1}, result.UUIDs)
+
+	err = result.resolveModelUUIDs(tcache, lsp, ls, other)
+	assert.Nil(t, err)
+	assert.Equal(t, aUUID0, lsp.UUID)
+	assert.Equal(t, aUUID1, ls.UUID)
+	assert.Equal(t, aUUID3, other.UUID)
+
+	uuid, ok := result.UUIDFor(lsp)
+	assert.True(t, ok)
+	assert.Equal(t, aUUID0, uuid)
+	uuid, ok = result.UUIDFor(ls)
+	assert.True(t, ok)
+	assert.Equal(t, aUUID1, uuid)
+	_, ok = result.UUIDFor(other)
+	assert.False(t, ok)
+	_, ok = result.UUIDFor(&testLogicalSwitch{UUID: "ls"})
+	assert.False(t, ok)
+
+	// The real UUIDs can be used in the operations of a subsequent transaction
+	child := &testLogicalSwitch{Name: "child", Ports: []string{uuid}}
+	ops, err = api.Create(child)
+	assert.Nil(t, err)
+	assert.Equal(t, testOvsSet(t, []ovsdb.UUID{{GoUUID: aUUID1}}), ops[0].Row["ports"])
+	ops, err = api.Where(ls).Delete()
+	assert.Nil(t, err)
+	assert.Equal(t, []ovsdb.Condition{{Column: "_uuid", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID1}}}, ops[0].Where)
+
+	// Failed operations are reported
+	results[1] = ovsdb.OperationResult{Error: "constraint violation"}
+	_, err = newTransactResult(ops, results)
+	assert.NotNil(t, err)
+}
+
+func TestTransactResultModels(t *testing.T) {
+	tcache := apiTestCache(t)
+	ops := []ovsdb.Operation{
+		{Op: ovsdb.OperationSelect, Table: "Logical_Switch", Where: []ovsdb.Condition{}},
+		{Op: ovsdb.OperationSelect, Table: "Logical_Switch_Port", Where: []ovsdb.Condition{}},
+		{Op: ovsdb.OperationSelect, Table: "Logical_Switch", Where: []ovsdb.Condition{}, Columns: []string{"name"}},
+	}
+	var results []ovsdb.OperationResult
+	err := json.Unmarshal([]byte(`[
+		{"rows": [
+			{"_uuid": ["uuid", "`+aUUID0+`"], "name": "ls0", "ports": ["uuid", "`+aUUID2+`"], "external_ids": ["map", [["foo", "bar"]]]},
+			{"_uuid": ["uuid", "`+aUUID1+`"], "name": "ls1", "ports": ["set", []], "external_ids": ["map", []]}
+		]},
+		{"rows": [{"_uuid": ["uuid", "`+aUUID2+`"], "name": "lsp0"}]},
+		{"rows": [{"name": "ls2"}]}
+	]`), &results)
+	assert.Nil(t, err)
+
+	result, err := newTransactResult(ops, results)
+	assert.Nil(t, err)
+	var switches []testLogicalSwitch
+	err = result.Models("Logical_Switch", &switches)
+	assert.NotNil(t, err, "the result of a transaction not performed by TransactModels cannot decode rows")
+
+	result.cacheFor = func(model.Model) *cache.TableCache { return tcache }
+	err = result.Models("Logical_Switch", &switches)
+	assert.Nil(t, err)
+	assert.Equal(t, []testLogicalSwitch{
+		{UUID: aUUID0, Name: "ls0", Ports: []string{aUUID2}, ExternalIds: map[string]string{"foo": "bar"}},
+		{UUID: aUUID1, Name: "ls1", Ports: []string{}, ExternalIds: map[string]string{}},
+		{Name: "ls2"},
+	}, switches)
+
+	var ports []testLogicalSwitchPort
+	err = result.Models("Logical_Switch_Port", &ports)
+	assert.Nil(t, err)
+	assert.Equal(t, []testLogicalSwitchPort{{UUID: aUUID2, Name: "lsp0"}}, ports)
+
+	err = result.Models("Logical_Switch_Port", &switches)
+	assert.NotNil(t, err)
+	err = result.Models("Logical_Switch", switches)
+	assert.NotNil(t, err)
+}
+
+func TestTransactAssert(t *testing.T) {
+	ls := testLogicalSwitch{Name: "foo"}
+	tests := []struct {
+		name    string
+		results []ovsdb.OperationResult
+		err     bool
+	}{
+		{
+			name:    "lock held",
+			results: []ovsdb.OperationResult{{}, {UUID: ovsdb.UUID{GoUUID: aUUID0}}},
+		},
+		{
+			name:    "lock not held",
+			results: []ovsdb.OperationResult{{Error: "not owner", Details: "Asserted lock leader not held."}, {}},
+			err:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("TransactAssert: %s", tt.name), func(t *testing.T) {
+			ovs := newTransactTestClient(t, nil, tt.results)
+			ovs.api = newAPI(apiTestCache(t))
+
+			createOps, err := ovs.Create(&ls)
+			assert.Nil(t, err)
+			operations := append([]ovsdb.Operation{ovs.Assert("leader")}, createOps...)
+			reply, err := ovs.Transact(operations...)
+			assert.Nil(t, err)
+
+			errs, err := ovsdb.CheckOperationResults(reply, operations)
+			if !tt.err {
+				assert.Nil(t, err)
+				return
+			}
+			assert.NotNil(t, err)
+			if assert.Len(t, errs, 1) {
+				lockErr, ok := errs[0].(*ovsdb.ErrLockNotHeld)
+				assert.True(t, ok)
+				assert.Equal(t, "leader", lockErr.Lock)
+				assert.Equal(t, &operations[0], lockErr.Operation())
+			}
+		})
+	}
+}
+
+func TestKeepalive(t *testing.T) {
+	failures := make(chan error, 1)
+	onFailure := func(err error) {
+		failures <- err
+	}
+	ovs, server, err := newTestDatabaseClient(t, WithKeepalive(10*time.Millisecond, 50*time.Millisecond, onFailure))
+	assert.Nil(t, err)
+	disconnected := ovs.rpcClient.DisconnectNotify()
+
+	select {
+	case err := <-failures:
+		t.Fatalf("unexpected keepalive failure: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	server.setEchoDelay(200 * time.Millisecond)
+	select {
+	case err := <-failures:
+		assert.NotNil(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("keepalive failure not notified")
+	}
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+		t.Fatal("connection not closed after a keepalive failure")
+	}
+}
+
+func TestKeepaliveOptions(t *testing.T) {
+	o, err := newOptions(WithKeepalive(0, 0, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, defaultKeepaliveInterval, o.keepalive.interval)
+	assert.Equal(t, defaultKeepaliveTimeout, o.keepalive.timeout)
+
+	_, err = newOptions(WithKeepalive(-time.Second, 0, nil))
+	assert.NotNil(t, err)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	ovs, server, err := newTestDatabaseClient(t, WithRequestTimeout(50*time.Millisecond))
+	assert.Nil(t, err)
+	assert.Nil(t, ovs.Echo())
+
+	server.setEchoDelay(200 * time.Millisecond)
+	err = ovs.Echo()
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected a deadline exceeded error, got %v", err)
+
+	// The late reply is discarded and does not disturb the next requests
+	server.setEchoDelay(0)
+	time.Sleep(250 * time.Millisecond)
+	assert.Nil(t, ovs.Echo())
+	dbs, err := ovs.ListDatabases(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"OVN_Northbound", "Second_DB"}, dbs)
+}
+
+func TestMaxInflight(t *testing.T) {
+	ovs, server, err := newTestDatabaseClient(t, WithMaxInflight(1), WithRequestTimeout(100*time.Millisecond))
+	assert.Nil(t, err)
+
+	server.setEchoDelay(300 * time.Millisecond)
+	first := make(chan error, 1)
+	go func() { first <- ovs.Echo() }()
+	time.Sleep(20 * time.Millisecond)
+
+	// The first request holds the only slot, even after timing out, until its reply arrives
+	server.setEchoDelay(0)
+	err = ovs.Echo()
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected a deadline exceeded error, got %v", err)
+	err = <-first
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected a deadline exceeded error, got %v", err)
+
+	time.Sleep(250 * time.Millisecond)
+	assert.Nil(t, ovs.Echo())
+	assert.Nil(t, ovs.Echo())
+}
+
+func TestMaxTimeouts(t *testing.T) {
+	ovs, server, err := newTestDatabaseClient(t, WithRequestTimeout(20*time.Millisecond), WithMaxInflight(2), WithMaxTimeouts(3))
+	assert.Nil(t, err)
+
+	// The server never replies to transactions
+	release := make(chan struct{})
+	server.mutex.Lock()
+	server.beforeTransact = func() { <-release }
+	server.mutex.Unlock()
+	defer close(release)
+	ops := []ovsdb.Operation{{Op: opInsert, Table: "Logical_Switch", Row: ovsdb.Row{"name": "foo"}}}
+
+	// A reply resets the count of consecutive timeouts
+	_, err = ovs.Transact(ops...)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected a deadline exceeded error, got %v", err)
+	assert.Nil(t, ovs.Echo())
+	_, err = ovs.Transact(ops...)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected a deadline exceeded error, got %v", err)
+
+	// The timed out transactions hold the inflight slots, so the next requests time out too
+	// until the connection is closed
+	for i := 0; i < 2; i++ {
+		err = ovs.Echo()
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected a deadline exceeded error, got %v", err)
+	}
+	assert.Eventually(t, func() bool {
+		return len(ovs.inflight) == 0
+	}, time.Second, 10*time.Millisecond, "the pending requests release their slots")
+	err = ovs.Echo()
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, context.DeadlineExceeded), "expected the connection to be closed, got %v", err)
+}
+
+func TestRequestOptions(t *testing.T) {
+	o, err := newOptions(WithRequestTimeout(time.Second), WithMaxInflight(10))
+	assert.Nil(t, err)
+	assert.Equal(t, time.Second, o.requestTimeout)
+	assert.Equal(t, 10, o.maxInflight)
+
+	_, err = newOptions(WithRequestTimeout(-time.Second))
+	assert.NotNil(t, err)
+	_, err = newOptions(WithMaxInflight(-1))
+	assert.NotNil(t, err)
+
+	o, err = newOptions()
+	assert.Nil(t, err)
+	assert.Equal(t, defaultMaxTimeouts, o.maxTimeouts)
+	o, err = newOptions(WithMaxTimeouts(0))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, o.maxTimeouts)
+	_, err = newOptions(WithMaxTimeouts(-1))
+	assert.NotNil(t, err)
+}
+
+func TestWeakReferenceWarningOption(t *testing.T) {
+	o, err := newOptions()
+	assert.Nil(t, err)
+	assert.Nil(t, o.weakRefWarn)
+
+	o, err = newOptions(WithWeakReferenceWarning(nil))
+	assert.Nil(t, err)
+	assert.NotNil(t, o.weakRefWarn, "dangling weak references are logged by default")
+
+	ovs, _, err := newTestDatabaseClient(t, WithWeakReferenceWarning(func(error) {}))
+	assert.Nil(t, err)
+	assert.NotNil(t, ovs.api.(api).weakRefWarn)
+}
+
+func TestCountFromServer(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   string
+		results []ovsdb.OperationResult
+		count   int
+		err     bool
+	}{
+		{
+			name:    "rows are counted",
+			table:   "Logical_Switch",
+			results: []ovsdb.OperationResult{{Rows: []ovsdb.Row{{"_uuid": ovsdb.UUID{GoUUID: aUUID0}}, {"_uuid": ovsdb.UUID{GoUUID: aUUID1}}}}},
+			count:   2,
+		},
+		{
+			name:    "no rows",
+			table:   "Logical_Switch",
+			results: []ovsdb.OperationResult{{}},
+			count:   0,
+		},
+		{
+			name:    "operation error",
+			table:   "Logical_Switch",
+			results: []ovsdb.OperationResult{{Error: "syntax error"}},
+			err:     true,
+		},
+		{
+			name:  "unknown table",
+			table: "Unknown",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("CountFromServer: %s", tt.name), func(t *testing.T) {
+			ovs := newTransactTestClient(t, nil, tt.results)
+			conditions := []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionNotEqual, "foo")}
+			count, err := ovs.CountFromServer(context.Background(), tt.table, conditions)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.count, count)
+		})
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	insert := ovsdb.Operation{Op: opInsert, Table: "Logical_Switch", Row: ovsdb.Row{"name": "foo"}}
+
+	t.Run("Shutdown: drains in-flight transactions", func(t *testing.T) {
+		ovs, server, err := newTestDatabaseClient(t)
+		assert.Nil(t, err)
+		started := make(chan struct{})
+		release := make(chan struct{})
+		server.mutex.Lock()
+		server.beforeTransact = func() {
+			close(started)
+			<-release
+		}
+		server.mutex.Unlock()
+
+		inflight := make(chan error, 1)
+		go func() {
+			_, err := ovs.Transact(insert)
+			inflight <- err
+		}()
+		<-started
+
+		shutdown := make(chan error, 1)
+		go func() {
+			pending, err := ovs.Shutdown(context.Background())
+			assert.Empty(t, pending)
+			shutdown <- err
+		}()
+
+		// New transactions are rejected while the in-flight one completes
+		assert.Eventually(t, func() bool {
+			_, err := ovs.Transact(insert)
+			return errors.Is(err, ErrShutdown)
+		}, time.Second, 10*time.Millisecond)
+		close(release)
+		assert.Nil(t, <-inflight)
+		assert.Nil(t, <-shutdown)
+		assert.Eventually(t, func() bool {
+			return ovs.Echo() != nil
+		}, time.Second, 10*time.Millisecond, "the connection is closed")
+	})
+
+	t.Run("Shutdown: returns the transactions that did not complete in time", func(t *testing.T) {
+		ovs, server, err := newTestDatabaseClient(t)
+		assert.Nil(t, err)
+		started := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+		server.mutex.Lock()
+		server.beforeTransact = func() {
+			close(started)
+			<-release
+		}
+		server.mutex.Unlock()
+
+		inflight := make(chan error, 1)
+		go func() {
+			_, err := ovs.Transact(insert)
+			inflight <- err
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		pending, err := ovs.Shutdown(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected a deadline exceeded error, got %v", err)
+		assert.Equal(t, []PendingTransaction{{Database: "OVN_Northbound", Operations: []ovsdb.Operation{insert}}}, pending)
+		assert.NotNil(t, <-inflight, "the transaction fails once the connection is closed")
+	})
+}
+
+func TestTransactionHistory(t *testing.T) {
+	_, err := newOptions(WithTransactionHistory(0))
+	assert.NotNil(t, err)
+
+	ovs, _, err := newTestDatabaseClient(t)
+	assert.Nil(t, err)
+	_, err = ovs.Transact(ovsdb.Operation{Op: opInsert, Table: "Logical_Switch", Row: ovsdb.Row{"name": "foo"}})
+	assert.Nil(t, err)
+	assert.Nil(t, ovs.TransactionHistory(), "transactions are not recorded by default")
+
+	ovs, server, err := newTestDatabaseClient(t, WithTransactionHistory(2), WithRequestTimeout(50*time.Millisecond))
+	assert.Nil(t, err)
+	assert.Empty(t, ovs.TransactionHistory())
+	var ops []ovsdb.Operation
+	for _, name := range []string{"ls0", "ls1", "ls2"} {
+		op := ovsdb.Operation{Op: opInsert, Table: "Logical_Switch", Row: ovsdb.Row{"name": name}}
+		ops = append(ops, op)
+		_, err = ovs.Transact(op)
+		assert.Nil(t, err)
+	}
+
+	// Only the last transactions are kept, oldest first
+	history := ovs.TransactionHistory()
+	assert.Len(t, history, 2)
+	for i, record := range history {
+		assert.Equal(t, "OVN_Northbound", record.Database)
+		assert.Equal(t, []ovsdb.Operation{ops[i+1]}, record.Operations)
+		assert.Len(t, record.Results, 1)
+		assert.Nil(t, record.Error)
+	}
+	assert.False(t, history[1].Timestamp.Before(history[0].Timestamp))
+
+	// Failures are recorded too
+	server.mutex.Lock()
+	server.beforeTransact = func() { time.Sleep(100 * time.Millisecond) }
+	server.mutex.Unlock()
+	_, err = ovs.Transact(ops[0])
+	assert.NotNil(t, err)
+	history = ovs.TransactionHistory()
+	assert.Len(t, history, 2)
+	assert.Equal(t, []ovsdb.Operation{ops[2]}, history[0].Operations)
+	assert.Equal(t, []ovsdb.Operation{ops[0]}, history[1].Operations)
+	assert.True(t, errors.Is(history[1].Error, context.DeadlineExceeded), "expected a deadline exceeded error, got %v", history[1].Error)
+	assert.Nil(t, history[1].Results)
+}
+
+func TestPool(t *testing.T) {
+	var clients []*OvsdbClient
+	var servers []*testDatabaseServer
+	for i := 0; i < 3; i++ {
+		var opts []Option
+		if i > 0 {
+			opts = append(opts, withoutCache())
+		}
+		ovs, server, err := newTestDatabaseClient(t, opts...)
+		assert.Nil(t, err)
+		clients = append(clients, ovs)
+		servers = append(servers, server)
+	}
+	pool := newPool(clients)
+	assert.Equal(t, 3, pool.Size())
+	assert.Equal(t, clients[0].Cache, pool.Cache, "the cache is the one of the first connection")
+	for _, ovs := range clients[1:] {
+		assert.Nil(t, ovs.Cache, "the other connections have no cache")
+	}
+
+	for i := 0; i < 6; i++ {
+		_, err := pool.Transact(ovsdb.Operation{Op: opInsert, Table: "Logical_Switch", Row: ovsdb.Row{"name": fmt.Sprintf("ls%d", i)}})
+		assert.Nil(t, err)
+	}
+	for i, server := range servers {
+		server.mutex.Lock()
+		assert.Len(t, server.targets, 2, "transactions sent to server %d", i)
+		server.mutex.Unlock()
+	}
+
+	// Transactions asserting a lock are sent over the connection that owns it
+	lock := "lock"
+	for i := 0; i < 3; i++ {
+		_, err := pool.Transact(
+			ovsdb.Operation{Op: ovsdb.OperationAssert, Lock: &lock},
+			ovsdb.Operation{Op: opInsert, Table: "Logical_Switch", Row: ovsdb.Row{"name": fmt.Sprintf("locked%d", i)}},
+		)
+		assert.Nil(t, err)
+	}
+	for i, server := range servers {
+		expected := 2
+		if i == 0 {
+			expected = 5
+		}
+		server.mutex.Lock()
+		assert.Len(t, server.targets, expected, "transactions sent to server %d", i)
+		server.mutex.Unlock()
+	}
+
+	pool.Disconnect()
+	for _, ovs := range clients {
+		assert.Eventually(t, func() bool {
+			return ovs.Echo() != nil
+		}, time.Second, 10*time.Millisecond, "the connection is closed")
+	}
+
+	_, err := NewPool("tcp:127.0.0.1:6640", 0, nil, nil)
+	assert.NotNil(t, err)
+}
+
+func TestTransactContext(t *testing.T) {
+	ovs, server, err := newTestDatabaseClient(t)
+	assert.Nil(t, err)
+
+	ops, err := ovs.Create(&testLogicalSwitch{Name: "foo"})
+	assert.Nil(t, err)
+	results, err := ovs.TransactContext(context.Background(), ops...)
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, "OVN_Northbound", server.lastTarget())
+
+	_, err = ovs.TransactContext(context.Background(), ovsdb.Operation{Op: opInsert, Table: "Unknown", Row: ovsdb.Row{"name": "foo"}})
+	assert.NotNil(t, err)
+
+	release := make(chan struct{})
+	server.mutex.Lock()
+	server.beforeTransact = func() { <-release }
+	server.mutex.Unlock()
+	defer close(release)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = ovs.TransactContext(ctx, ops...)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected a deadline error, got %v", err)
+}
+
+func TestTransactAndWait(t *testing.T) {
+	ovs, server, err := newTestDatabaseClient(t)
+	assert.Nil(t, err)
+	err = ovs.MonitorCond("cond", map[string]ovsdb.MonitorCondRequest{"Logical_Switch": {}})
+	assert.Nil(t, err)
+	lsCache := ovs.Cache.Table("Logical_Switch")
+
+	// sendLater sends the updates of a transaction after replying to it
+	sendLater := func(updates ovsdb.TableUpdates2) {
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			var reply []interface{}
+			err := server.server.Call("update2", []interface{}{"cond", updates}, &reply)
+			assert.Nil(t, err)
+		}()
+	}
+	lastOps := func() []ovsdb.Operation {
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		return server.transactOps[len(server.transactOps)-1]
+	}
+
+	t.Run("TransactAndWait: insert", func(t *testing.T) {
+		server.mutex.Lock()
+		server.transactResults = func(operations []ovsdb.Operation) []ovsdb.OperationResult {
+			sendLater(ovsdb.TableUpdates2{"Logical_Switch": {aUUID2: &ovsdb.RowUpdate2{Insert: &ovsdb.Row{"name": "baz"}}}})
+			return []ovsdb.OperationResult{{UUID: ovsdb.UUID{GoUUID: aUUID2}}}
+		}
+		server.mutex.Unlock()
+		ops, err := ovs.Create(&testLogicalSwitch{Name: "baz"})
+		assert.Nil(t, err)
+		results, err := ovs.TransactAndWait(context.Background(), ops...)
+		assert.Nil(t, err)
+		assert.Equal(t, []ovsdb.OperationResult{{UUID: ovsdb.UUID{GoUUID: aUUID2}}}, results)
+		assert.NotNil(t, lsCache.Row(aUUID2))
+		assert.Len(t, lastOps(), 1)
+	})
+
+	t.Run("TransactAndWait: delete", func(t *testing.T) {
+		server.mutex.Lock()
+		server.transactResults = func(operations []ovsdb.Operation) []ovsdb.OperationResult {
+			sendLater(ovsdb.TableUpdates2{"Logical_Switch": {aUUID0: &ovsdb.RowUpdate2{Delete: &ovsdb.Row{}}}})
+			return []ovsdb.OperationResult{{Rows: []ovsdb.Row{{"_uuid": ovsdb.UUID{GoUUID: aUUID0}}}}, {Count: 1}}
+		}
+		server.mutex.Unlock()
+		ops, err := ovs.Where(&testLogicalSwitch{UUID: aUUID0}).Delete()
+		assert.Nil(t, err)
+		results, err := ovs.TransactAndWait(context.Background(), ops...)
+		assert.Nil(t, err)
+		assert.Equal(t, []ovsdb.OperationResult{{Count: 1}}, results)
+		assert.Nil(t, lsCache.Row(aUUID0))
+		sent := lastOps()
+		if assert.Len(t, sent, 2) {
+			assert.Equal(t, ovsdb.OperationSelect, sent[0].Op)
+			assert.Equal(t, []string{"_uuid", "_version"}, sent[0].Columns)
+			assert.Equal(t, ovsdb.OperationDelete, sent[1].Op)
+		}
+	})
+
+	t.Run("TransactAndWait: updates never received", func(t *testing.T) {
+		server.mutex.Lock()
+		server.transactResults = func(operations []ovsdb.Operation) []ovsdb.OperationResult {
+			return []ovsdb.OperationResult{{UUID: ovsdb.UUID{GoUUID: aUUID3}}}
+		}
+		server.mutex.Unlock()
+		ops, err := ovs.Create(&testLogicalSwitch{Name: "qux"})
+		assert.Nil(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		results, err := ovs.TransactAndWait(ctx, ops...)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded), "expected a deadline error, got %v", err)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("TransactAndWait: failed transaction", func(t *testing.T) {
+		server.mutex.Lock()
+		server.transactResults = func(operations []ovsdb.Operation) []ovsdb.OperationResult {
+			return []ovsdb.OperationResult{{}, {Error: "constraint violation"}}
+		}
+		server.mutex.Unlock()
+		ls := &testLogicalSwitch{Name: "renamed"}
+		ops, err := ovs.Where(&testLogicalSwitch{UUID: aUUID1}).Update(ls, &ls.Name)
+		assert.Nil(t, err)
+		results, err := ovs.TransactAndWait(context.Background(), ops...)
+		assert.NotNil(t, err)
+		assert.Equal(t, []ovsdb.OperationResult{{Error: "constraint violation"}}, results)
+	})
+}
+
+func TestTransactAndWaitVersion(t *testing.T) {
+	type testVersionedSwitch struct {
+		UUID    string `ovs:"_uuid"`
+		Version string `ovs:"_version"`
+		Name    string `ovs:"name"`
+	}
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal(apiTestSchema, &schema)
+	assert.Nil(t, err)
+	db, err := model.NewDBModel("OVN_Northbound", map[string]model.Model{"Logical_Switch": &testVersionedSwitch{}})
+	assert.Nil(t, err)
+	tcache, err := cache.NewTableCache(&schema, db)
+	assert.Nil(t, err)
+	tcache.Set("Logical_Switch", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testVersionedSwitch{UUID: aUUID0, Version: aUUID1, Name: "ls0"},
+	}))
+
+	update := ovsdb.Operation{Op: ovsdb.OperationUpdate, Table: "Logical_Switch", Row: ovsdb.Row{"name": "ls1"}}
+	operations, positions, selects := waitOperations(tcache, []ovsdb.Operation{update})
+	assert.Equal(t, []int{1}, positions)
+	assert.Equal(t, map[int]waitSelect{0: {before: -1}, 2: {before: 0}}, selects)
+	selected := []ovsdb.Row{{"_uuid": ovsdb.UUID{GoUUID: aUUID0}, "_version": ovsdb.UUID{GoUUID: aUUID1}}}
+	reply := []ovsdb.OperationResult{
+		{Rows: selected},
+		{Count: 1},
+		{Rows: []ovsdb.Row{{"_uuid": ovsdb.UUID{GoUUID: aUUID0}, "_version": ovsdb.UUID{GoUUID: aUUID2}}}},
+	}
+	expected := expectedRows(operations, reply, selects)
+	assert.Equal(t, map[string]map[string]expectedRow{"Logical_Switch": {aUUID0: {version: aUUID2}}}, expected)
+
+	assert.False(t, cacheReflects(tcache, expected), "the cached row has the previous version")
+	tcache.Set("Logical_Switch", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testVersionedSwitch{UUID: aUUID0, Version: aUUID2, Name: "ls1"},
+	}))
+	assert.True(t, cacheReflects(tcache, expected))
+}
+
+func TestTransactAndWaitConditionColumn(t *testing.T) {
+	tcache := apiTestCache(t)
+	api := newAPI(tcache)
+	tcache.Set("Logical_Switch", cache.NewRowCache(map[string]model.Model{
+		aUUID0: &testLogicalSwitch{UUID: aUUID0, Name: "ls0"},
+		aUUID1: &testLogicalSwitch{UUID: aUUID1, Name: "ls1"},
+	}))
+
+	// The update renames the rows selected by name, so they no longer match its condition
+	ls := &testLogicalSwitch{}
+	renamed := &testLogicalSwitch{Name: "renamed"}
+	update, err := api.WhereAll(ls, model.Condition{Field: &ls.Name, Function: ovsdb.ConditionEqual, Value: "ls0"}).
+		Update(renamed, &renamed.Name)
+	assert.Nil(t, err)
+	operations, positions, selects := waitOperations(tcache, update)
+	assert.Equal(t, []int{1}, positions)
+	if assert.Len(t, operations, 3) {
+		assert.Equal(t, ovsdb.OperationSelect, operations[0].Op)
+		assert.Equal(t, update[0].Where, operations[0].Where)
+		assert.Equal(t, "update", operations[1].Op)
+		assert.Equal(t, ovsdb.OperationSelect, operations[2].Op)
+		assert.Empty(t, operations[2].Where)
+	}
+
+	// The select after the update gets every row, only the renamed one is expected
+	reply := []ovsdb.OperationResult{
+		{Rows: []ovsdb.Row{{"_uuid": ovsdb.UUID{GoUUID: aUUID0}, "_version": ovsdb.UUID{GoUUID: aUUID2}}}},
+		{Count: 1},
+		{Rows: []ovsdb.Row{
+			{"_uuid": ovsdb.UUID{GoUUID: aUUID0}, "_version": ovsdb.UUID{GoUUID: aUUID3}},
+			{"_uuid": ovsdb.UUID{GoUUID: aUUID1}, "_version": ovsdb.UUID{GoUUID: aUUID2}},
+		}},
+	}
+	expected := expectedRows(operations, reply, selects)
+	assert.Equal(t, map[string]map[string]expectedRow{"Logical_Switch": {aUUID0: {version: aUUID3}}}, expected)
+
+	// Conditions on the columns left unchanged are kept
+	mutate := ovsdb.Operation{
+		Op:        ovsdb.OperationMutate,
+		Table:     "Logical_Switch",
+		Where:     []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "ls1"), ovsdb.NewCondition("ports", ovsdb.ConditionIncludes, testOvsSet(t, []ovsdb.UUID{{GoUUID: aUUID2}}))},
+		Mutations: []ovsdb.Mutation{*ovsdb.NewMutation("ports", ovsdb.MutateOperationDelete, testOvsSet(t, []ovsdb.UUID{{GoUUID: aUUID2}}))},
+	}
+	operations, _, _ = waitOperations(tcache, []ovsdb.Operation{mutate})
+	if assert.Len(t, operations, 3) {
+		assert.Equal(t, mutate.Where, operations[0].Where)
+		assert.Equal(t, mutate.Where[:1], operations[2].Where)
+	}
+}
+
+func TestStreamingInitialDump(t *testing.T) {
+	_, err := newOptions(WithStreamingInitialDump(0))
+	assert.NotNil(t, err)
+
+	ovs, _, err := newTestDatabaseClient(t, WithStreamingInitialDump(1))
+	assert.Nil(t, err)
+	synced := make(chan struct{})
+	ovs.Cache.AddEventHandler(&cache.EventHandlerFuncs{
+		InitialSyncCompleteFunc: func() { close(synced) },
+	})
+	err = ovs.MonitorCond("cond", map[string]ovsdb.MonitorCondRequest{"Logical_Switch": {}})
+	assert.Nil(t, err)
+	assert.False(t, ovs.Cache.Syncing())
+	assert.Equal(t, 2, ovs.Cache.Table("Logical_Switch").Len())
+	select {
+	case <-synced:
+	case <-time.After(time.Second):
+		t.Fatal("end of the initial sync not notified")
+	}
+	var result []testLogicalSwitch
+	assert.Nil(t, ovs.List(&result))
+	assert.Len(t, result, 2)
+}
+
+func TestStreamTableUpdates(t *testing.T) {
+	t.Run("StreamTableUpdates: update2 batches", func(t *testing.T) {
+		tcache := apiTestCache(t)
+		raw := []byte(`{
+			"Logical_Switch": {
+				"` + aUUID0 + `": {"initial": {"name": "ls0"}},
+				"` + aUUID1 + `": {"initial": {"name": "ls1"}}
+			},
+			"Logical_Switch_Port": {
+				"` + aUUID2 + `": {"initial": {"name": "lsp0"}}
+			}
+		}`)
+		err := streamTableUpdates2(testDecoder(raw), 2, tcache)
+		assert.Nil(t, err)
+		assert.Equal(t, "ls0", tcache.Table("Logical_Switch").Row(aUUID0).(*testLogicalSwitch).Name)
+		assert.Equal(t, "ls1", tcache.Table("Logical_Switch").Row(aUUID1).(*testLogicalSwitch).Name)
+		assert.Equal(t, "lsp0", tcache.Table("Logical_Switch_Port").Row(aUUID2).(*testLogicalSwitchPort).Name)
+	})
+
+	t.Run("StreamTableUpdates: update batches", func(t *testing.T) {
+		tcache := apiTestCache(t)
+		raw := []byte(`{
+			"Logical_Switch": {
+				"` + aUUID0 + `": {"new": {"name": "ls0"}},
+				"` + aUUID1 + `": {"new": {"name": "ls1"}},
+				"` + aUUID2 + `": {"new": {"name": "ls2"}}
+			}
+		}`)
+		err := streamTableUpdates(testDecoder(raw), 2, tcache)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, tcache.Table("Logical_Switch").Len())
+		assert.Equal(t, "ls2", tcache.Table("Logical_Switch").Row(aUUID2).(*testLogicalSwitch).Name)
+	})
+
+	t.Run("StreamTableUpdates: no updates", func(t *testing.T) {
+		tcache := apiTestCache(t)
+		assert.Nil(t, streamTableUpdates2(testDecoder([]byte(`null`)), 2, tcache))
+		assert.Nil(t, streamTableUpdates2(testDecoder([]byte(`{}`)), 2, tcache))
+	})
+
+	t.Run("StreamTableUpdates: invalid row keeps the previous batches", func(t *testing.T) {
+		tcache := apiTestCache(t)
+		raw := []byte(`{
+			"Logical_Switch": {
+				"` + aUUID0 + `": {"initial": {"name": "ls0"}},
+				"` + aUUID1 + `": ["initial"]
+			}
+		}`)
+		err := streamTableUpdates2(testDecoder(raw), 1, tcache)
+		assert.True(t, errors.As(err, new(*rejectedResult)), "expected a rejected result, got %v", err)
+		assert.Contains(t, err.Error(), aUUID1)
+		assert.NotNil(t, tcache.Table("Logical_Switch").Row(aUUID0))
+	})
+
+	t.Run("StreamTableUpdates: invalid table updates", func(t *testing.T) {
+		tcache := apiTestCache(t)
+		for _, raw := range []string{`[]`, `"foo"`, `{"Logical_Switch": [{"a": 1}], "Logical_Switch_Port": {}}`} {
+			// The invalid table updates are read whole
+			dec := testDecoder([]byte(raw + ` 42`))
+			err := streamTableUpdates2(dec, 1, tcache)
+			assert.True(t, errors.As(err, new(*rejectedResult)), "expected a rejected result, got %v", err)
+			var next int
+			assert.Nil(t, dec.Decode(&next))
+			assert.Equal(t, 42, next)
+		}
+	})
+}
+
+func testDecoder(raw []byte) *json.Decoder {
+	return json.NewDecoder(bytes.NewReader(raw))
+}
@@ -0,0 +1,281 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/cenkalti/rpc2"
+)
+
+// resultStream consumes the result of a reply from the decoder as it is read. It must read the
+// whole result unless it returns an error other than a rejectedResult one, which closes the
+// connection as the decoder is left in the middle of the result
+type resultStream func(dec *json.Decoder) error
+
+// rejectedResult is returned by a resultStream that read the whole result but rejects it: the
+// request fails with it and the connection is kept
+type rejectedResult struct {
+	err error
+}
+
+func (e *rejectedResult) Error() string {
+	return e.err.Error()
+}
+
+func (e *rejectedResult) Unwrap() error {
+	return e.err
+}
+
+// streamedArgs are the arguments of a request whose result is passed to stream as it is read
+// instead of being decoded into the reply, see streamingCodec
+type streamedArgs struct {
+	args   interface{}
+	stream resultStream
+}
+
+// streamingCodec is the JSON-RPC codec of rpc2/jsonrpc, but for the results of the requests
+// sent with streamedArgs, which are passed to their stream as they are read from the
+// connection. A result read before the id of its reply cannot be matched with its request
+// until the id is, so it is read whole before being passed to the stream
+type streamingCodec struct {
+	dec *json.Decoder
+	enc *json.Encoder
+	c   io.Closer
+	// encMutex serializes the messages written, as requests and responses are written
+	// concurrently
+	encMutex sync.Mutex
+
+	// params and result hold the members of the message being read
+	params *json.RawMessage
+	result *json.RawMessage
+
+	// mutex protects seq, pending and streams. The requests received get a sequence number,
+	// and their id is kept in pending to be sent back with their response. streams holds the
+	// streams of the requests sent, by sequence number
+	mutex   sync.Mutex
+	seq     uint64
+	pending map[uint64]*json.RawMessage
+	streams map[uint64]resultStream
+}
+
+func newStreamingCodec(conn io.ReadWriteCloser) *streamingCodec {
+	return &streamingCodec{
+		dec:     json.NewDecoder(conn),
+		enc:     json.NewEncoder(conn),
+		c:       conn,
+		pending: make(map[uint64]*json.RawMessage),
+		streams: make(map[uint64]resultStream),
+	}
+}
+
+// jsonNull is the result of the replies passed to their stream
+var jsonNull = json.RawMessage("null")
+
+// ReadHeader reads the next message up to its end, passing its result to the stream of its
+// request if there is one
+func (c *streamingCodec) ReadHeader(req *rpc2.Request, resp *rpc2.Response) error {
+	token, err := c.dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("invalid message: expected an object, got %v", token)
+	}
+	c.params, c.result = nil, nil
+	var method string
+	var id *json.RawMessage
+	var replyErr interface{}
+	// streamed is set once the result is passed to its stream, and streamErr holds the
+	// rejection of the stream, if any
+	streamed := false
+	var streamErr error
+	for c.dec.More() {
+		key, err := c.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "method":
+			err = c.dec.Decode(&method)
+		case "params":
+			err = c.dec.Decode(&c.params)
+		case "id":
+			// A null id, e.g: of a notification, is left nil
+			err = c.dec.Decode(&id)
+		case "error":
+			err = c.dec.Decode(&replyErr)
+		case "result":
+			if stream := c.stream(id); stream != nil {
+				streamed = true
+				if err = stream(c.dec); errors.As(err, new(*rejectedResult)) {
+					streamErr, err = err, nil
+				}
+			} else {
+				err = c.dec.Decode(&c.result)
+			}
+		default:
+			var ignored json.RawMessage
+			err = c.dec.Decode(&ignored)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// Closing brace of the message
+	if _, err := c.dec.Token(); err != nil {
+		return err
+	}
+
+	if method != "" {
+		// request from the server
+		req.Method = method
+		if id != nil {
+			c.mutex.Lock()
+			c.seq++
+			c.pending[c.seq] = id
+			req.Seq = c.seq
+			c.mutex.Unlock()
+		}
+		return nil
+	}
+
+	// reply to a request of the client
+	if id == nil {
+		return fmt.Errorf("invalid reply: missing id")
+	}
+	if err := json.Unmarshal(*id, &resp.Seq); err != nil {
+		return err
+	}
+	c.mutex.Lock()
+	stream := c.streams[resp.Seq]
+	delete(c.streams, resp.Seq)
+	c.mutex.Unlock()
+	if stream != nil && !streamed && c.result != nil {
+		// The result was read before the id
+		streamed = true
+		streamErr = stream(json.NewDecoder(bytes.NewReader(*c.result)))
+	}
+	if streamed {
+		c.result = &jsonNull
+	}
+
+	resp.Error = ""
+	if streamErr != nil {
+		resp.Error = streamErr.Error()
+	} else if replyErr != nil || c.result == nil {
+		x, ok := replyErr.(string)
+		if !ok {
+			return fmt.Errorf("invalid error %v", replyErr)
+		}
+		if x == "" {
+			x = "unspecified error"
+		}
+		resp.Error = x
+	}
+	return nil
+}
+
+// stream returns the stream of the request with the provided id, if any
+func (c *streamingCodec) stream(id *json.RawMessage) resultStream {
+	if id == nil {
+		return nil
+	}
+	var seq uint64
+	if err := json.Unmarshal(*id, &seq); err != nil {
+		return nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.streams[seq]
+}
+
+// ReadRequestBody decodes the params of the request read into x
+func (c *streamingCodec) ReadRequestBody(x interface{}) error {
+	if x == nil {
+		return nil
+	}
+	if c.params == nil {
+		return fmt.Errorf("jsonrpc: request body missing params")
+	}
+	rt := reflect.TypeOf(x)
+	if rt.Kind() == reflect.Ptr && rt.Elem().Kind() == reflect.Slice {
+		return json.Unmarshal(*c.params, x)
+	}
+	// Anything else is the single element of the params
+	return json.Unmarshal(*c.params, &[]interface{}{x})
+}
+
+// ReadResponseBody decodes the result of the reply read into x
+func (c *streamingCodec) ReadResponseBody(x interface{}) error {
+	if x == nil {
+		return nil
+	}
+	return json.Unmarshal(*c.result, x)
+}
+
+// WriteRequest writes a request, recording the stream of its result if sent with streamedArgs
+func (c *streamingCodec) WriteRequest(r *rpc2.Request, param interface{}) error {
+	if args, ok := param.(*streamedArgs); ok {
+		c.mutex.Lock()
+		c.streams[r.Seq] = args.stream
+		c.mutex.Unlock()
+		param = args.args
+	}
+	req := struct {
+		Method string      `json:"method"`
+		Params interface{} `json:"params"`
+		ID     *uint64     `json:"id"`
+	}{Method: r.Method}
+	if param != nil && reflect.TypeOf(param).Kind() == reflect.Slice {
+		req.Params = param
+	} else {
+		req.Params = []interface{}{param}
+	}
+	if r.Seq != 0 {
+		seq := r.Seq
+		req.ID = &seq
+	}
+	c.encMutex.Lock()
+	defer c.encMutex.Unlock()
+	err := c.enc.Encode(req)
+	if err != nil {
+		c.mutex.Lock()
+		delete(c.streams, r.Seq)
+		c.mutex.Unlock()
+	}
+	return err
+}
+
+// WriteResponse writes the response to a request of the server
+func (c *streamingCodec) WriteResponse(r *rpc2.Response, x interface{}) error {
+	c.mutex.Lock()
+	id, ok := c.pending[r.Seq]
+	delete(c.pending, r.Seq)
+	c.mutex.Unlock()
+	if !ok {
+		return fmt.Errorf("invalid sequence number in response")
+	}
+	resp := struct {
+		ID     *json.RawMessage `json:"id"`
+		Result interface{}      `json:"result"`
+		Error  interface{}      `json:"error"`
+	}{ID: id}
+	if r.Error == "" {
+		resp.Result = x
+	} else {
+		resp.Error = r.Error
+	}
+	c.encMutex.Lock()
+	defer c.encMutex.Unlock()
+	return c.enc.Encode(resp)
+}
+
+// Close closes the connection
+func (c *streamingCodec) Close() error {
+	return c.c.Close()
+}
@@ -0,0 +1,168 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/stretchr/testify/assert"
+)
+
+// testCodecMessage is a message received by the server end of newTestStreamingClient
+type testCodecMessage struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+}
+
+// newTestStreamingClient returns an rpc2 client using a streamingCodec, along with the server end
+// of its connection, whose messages are written by the test, and the messages it received
+func newTestStreamingClient(t *testing.T) (*rpc2.Client, net.Conn, chan testCodecMessage) {
+	clientConn, serverConn := net.Pipe()
+	client := rpc2.NewClientWithCodec(newStreamingCodec(clientConn))
+	client.SetBlocking(true)
+	go client.Run()
+	t.Cleanup(func() { client.Close(); serverConn.Close() })
+
+	requests := make(chan testCodecMessage, 10)
+	go func() {
+		dec := json.NewDecoder(serverConn)
+		for {
+			var req testCodecMessage
+			if err := dec.Decode(&req); err != nil {
+				return
+			}
+			requests <- req
+		}
+	}()
+	return client, serverConn, requests
+}
+
+// testRowStream returns a resultStream that sends the UUIDs of the row updates it reads
+func testRowStream(uuids chan<- string) resultStream {
+	return func(dec *json.Decoder) error {
+		return decodeTableUpdates(dec, func(table, uuid string, raw json.RawMessage) error {
+			var row map[string]interface{}
+			if err := json.Unmarshal(raw, &row); err != nil {
+				return err
+			}
+			uuids <- uuid
+			return nil
+		})
+	}
+}
+
+func TestStreamingCodec(t *testing.T) {
+	t.Run("StreamingCodec: rows delivered as they are read", func(t *testing.T) {
+		client, server, requests := newTestStreamingClient(t)
+		uuids := make(chan string, 10)
+		call := client.Go("monitor", &streamedArgs{args: []interface{}{"db", "ctx"}, stream: testRowStream(uuids)}, nil, make(chan *rpc2.Call, 1))
+		req := <-requests
+		assert.Equal(t, "monitor", req.Method)
+		assert.JSONEq(t, `["db","ctx"]`, string(req.Params))
+
+		_, err := fmt.Fprintf(server, `{"id":%s,"result":{"Logical_Switch":{"%s":{"initial":{}},`, req.ID, aUUID0)
+		assert.Nil(t, err)
+		select {
+		case uuid := <-uuids:
+			assert.Equal(t, aUUID0, uuid)
+		case <-time.After(time.Second):
+			t.Fatal("the first row is not delivered before the rest of the reply is sent")
+		}
+		select {
+		case <-call.Done:
+			t.Fatal("the call completed before its reply")
+		default:
+		}
+
+		_, err = fmt.Fprintf(server, `"%s":{"initial":{}}}},"error":null}`, aUUID1)
+		assert.Nil(t, err)
+		<-call.Done
+		assert.Nil(t, call.Error)
+		assert.Equal(t, aUUID1, <-uuids)
+	})
+
+	t.Run("StreamingCodec: result read before the id", func(t *testing.T) {
+		client, server, requests := newTestStreamingClient(t)
+		uuids := make(chan string, 10)
+		call := client.Go("monitor", &streamedArgs{args: []interface{}{"db"}, stream: testRowStream(uuids)}, nil, make(chan *rpc2.Call, 1))
+		req := <-requests
+		_, err := fmt.Fprintf(server, `{"result":{"Logical_Switch":{"%s":{"initial":{}}}},"error":null,"id":%s}`, aUUID0, req.ID)
+		assert.Nil(t, err)
+		<-call.Done
+		assert.Nil(t, call.Error)
+		assert.Equal(t, aUUID0, <-uuids)
+	})
+
+	t.Run("StreamingCodec: rejected result keeps the connection", func(t *testing.T) {
+		client, server, requests := newTestStreamingClient(t)
+		uuids := make(chan string, 10)
+		call := client.Go("monitor", &streamedArgs{args: []interface{}{"db"}, stream: testRowStream(uuids)}, nil, make(chan *rpc2.Call, 1))
+		req := <-requests
+		_, err := fmt.Fprintf(server, `{"id":%s,"result":{"Logical_Switch":[]},"error":null}`, req.ID)
+		assert.Nil(t, err)
+		<-call.Done
+		assert.NotNil(t, call.Error)
+
+		var reply []string
+		call = client.Go("echo", []interface{}{"foo"}, &reply, make(chan *rpc2.Call, 1))
+		req = <-requests
+		_, err = fmt.Fprintf(server, `{"id":%s,"result":["foo"],"error":null}`, req.ID)
+		assert.Nil(t, err)
+		<-call.Done
+		assert.Nil(t, call.Error)
+		assert.Equal(t, []string{"foo"}, reply)
+	})
+
+	t.Run("StreamingCodec: error reply", func(t *testing.T) {
+		client, server, requests := newTestStreamingClient(t)
+		uuids := make(chan string, 10)
+		call := client.Go("monitor", &streamedArgs{args: []interface{}{"db"}, stream: testRowStream(uuids)}, nil, make(chan *rpc2.Call, 1))
+		req := <-requests
+		_, err := fmt.Fprintf(server, `{"id":%s,"result":null,"error":"unknown database"}`, req.ID)
+		assert.Nil(t, err)
+		<-call.Done
+		assert.Equal(t, rpc2.ServerError("unknown database"), call.Error)
+		assert.Len(t, uuids, 0)
+	})
+
+	t.Run("StreamingCodec: requests of the server", func(t *testing.T) {
+		client, server, requests := newTestStreamingClient(t)
+		updates := make(chan []interface{}, 1)
+		client.Handle("update", func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+			updates <- args
+			return nil
+		})
+		client.Handle("echo", func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+			*reply = args
+			return nil
+		})
+
+		// A notification has a null id and gets no response
+		_, err := fmt.Fprint(server, `{"method":"update","params":["ctx",{}],"id":null}`)
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{"ctx", map[string]interface{}{}}, <-updates)
+
+		_, err = fmt.Fprint(server, `{"method":"echo","params":["foo"],"id":"echo"}`)
+		assert.Nil(t, err)
+		resp := <-requests
+		assert.JSONEq(t, `"echo"`, string(resp.ID))
+		assert.JSONEq(t, `["foo"]`, string(resp.Result))
+		select {
+		case msg := <-requests:
+			t.Fatalf("unexpected message %v", msg)
+		default:
+		}
+	})
+}
+
+func TestStreamingCodecRejectedResult(t *testing.T) {
+	err := &rejectedResult{err: errors.New("invalid")}
+	assert.Equal(t, "invalid", err.Error())
+	assert.True(t, errors.As(fmt.Errorf("wrapped: %w", err), new(*rejectedResult)))
+}
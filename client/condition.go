@@ -1,8 +1,10 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/ovn-org/libovsdb/cache"
 	"github.com/ovn-org/libovsdb/mapper"
@@ -22,6 +24,29 @@ type Conditional interface {
 	Table() string
 }
 
+// contextGenerator is implemented by the Conditionals that generate conditions by scanning the
+// cache, so the scan can be canceled
+type contextGenerator interface {
+	// generateContext is like Generate, but it fails with an ErrGenerateCanceled error as soon
+	// as the context is done
+	generateContext(ctx context.Context) ([][]ovsdb.Condition, error)
+}
+
+// preparedConditional is implemented by the Conditionals that can prepare, once per scan of
+// the cached models, what Matches otherwise does for every model
+type preparedConditional interface {
+	// prepareMatches returns a function equivalent to Matches. It is not safe for concurrent use
+	prepareMatches() func(m model.Model) (bool, error)
+}
+
+// matcher returns the function used to evaluate a Conditional on the models of a scan
+func matcher(c Conditional) func(m model.Model) (bool, error) {
+	if p, ok := c.(preparedConditional); ok {
+		return p.prepareMatches()
+	}
+	return c.Matches
+}
+
 // equalityConditional uses the information available in a model to generate conditions
 // The conditions are based on the equality of the first available index.
 // The priority of indexes is: uuid, {schema index}
@@ -58,6 +83,33 @@ func (c *equalityConditional) Generate() ([][]ovsdb.Condition, error) {
 	return result, nil
 }
 
+// SelectedIndex returns the columns of the index the conditions are keyed on
+func (c *equalityConditional) SelectedIndex() []string {
+	return selectedIndex(c.mapper.Schema.Table(c.tableName), c.model)
+}
+
+// indexSelector is implemented by the Conditionals keyed on an index of a model
+type indexSelector interface {
+	SelectedIndex() []string
+}
+
+// selectedIndex returns the columns of the first index, in order of priority (_uuid, then the
+// schema indexes), for which the model has non-default values, or nil if there is none
+func selectedIndex(table *ovsdb.TableSchema, m model.Model) []string {
+	if table == nil {
+		return nil
+	}
+	info, err := mapper.NewMapperInfo(table, m)
+	if err != nil {
+		return nil
+	}
+	keys, err := info.IndexKeys()
+	if err != nil || len(keys) == 0 {
+		return nil
+	}
+	return keys[0].Columns
+}
+
 // NewEqualityCondition creates a new equalityConditional
 func newEqualityConditional(mapper *mapper.Mapper, table string, all bool, model model.Model, fields ...interface{}) (Conditional, error) {
 	return &equalityConditional{
@@ -77,8 +129,77 @@ type explicitConditional struct {
 	singleOp   bool
 }
 
+// Matches evaluates the conditions on the model's field values, as the server would. The model
+// matches if all the conditions match (WhereAll) or any of them does (Where)
 func (c *explicitConditional) Matches(m model.Model) (bool, error) {
-	return false, fmt.Errorf("cannot perform cache comparisons using explicit conditions")
+	return c.prepareMatches()(m)
+}
+
+// prepareMatches converts the conditions to their native values once and evaluates them on
+// the models through a copy held by a single MapperInfo, so neither is done for every model
+func (c *explicitConditional) prepareMatches() func(m model.Model) (bool, error) {
+	type nativeCondition struct {
+		column   *ovsdb.ColumnSchema
+		name     string
+		function ovsdb.ConditionFunction
+		value    interface{}
+		// err is returned when the condition is evaluated if it could not be converted
+		err error
+	}
+	table := c.mapper.Schema.Table(c.tableName)
+	if table == nil {
+		err := fmt.Errorf("table %s not found in schema", c.tableName)
+		return func(model.Model) (bool, error) { return false, err }
+	}
+	conditions := make([]nativeCondition, 0, len(c.conditions))
+	for _, cond := range c.conditions {
+		ovsdbCond, err := c.mapper.NewCondition(c.tableName, c.model, cond.Field, cond.Function, cond.Value)
+		if err != nil {
+			conditions = append(conditions, nativeCondition{err: err})
+			continue
+		}
+		column := table.Column(ovsdbCond.Column)
+		value, err := ovsdb.OvsToNative(column, ovsdbCond.Value)
+		conditions = append(conditions, nativeCondition{column, ovsdbCond.Column, ovsdbCond.Function, value, err})
+	}
+	// holder is the copy of the evaluated model that info reads the field values from
+	var holder reflect.Value
+	var info *mapper.MapperInfo
+	return func(m model.Model) (bool, error) {
+		mVal := reflect.ValueOf(m)
+		if mVal.Kind() != reflect.Ptr || mVal.IsNil() {
+			// Let the mapper report the wrong type
+			_, err := mapper.NewMapperInfo(table, m)
+			return false, err
+		}
+		if !holder.IsValid() || holder.Type() != mVal.Type() {
+			holder = reflect.New(mVal.Type().Elem())
+			var infoErr error
+			if info, infoErr = mapper.NewMapperInfo(table, holder.Interface()); infoErr != nil {
+				holder = reflect.Value{}
+				return false, infoErr
+			}
+		}
+		holder.Elem().Set(mVal.Elem())
+		for _, cond := range conditions {
+			if cond.err != nil {
+				return false, cond.err
+			}
+			actual, err := info.FieldByColumn(cond.name)
+			if err != nil {
+				return false, err
+			}
+			matches, err := ovsdb.EvaluateCondition(cond.column, cond.function, actual, cond.value)
+			if err != nil {
+				return false, fmt.Errorf("condition on column %s: %w", cond.name, err)
+			}
+			if matches != c.singleOp {
+				// A mismatch fails all the conditions, a match satisfies any of them
+				return matches, nil
+			}
+		}
+		return c.singleOp, nil
+	}
 }
 
 func (c *explicitConditional) Table() string {
@@ -119,6 +240,95 @@ func newExplicitConditional(mapper *mapper.Mapper, table string, all bool, model
 	}, nil
 }
 
+// caseInsensitiveConditional matches the models holding the same values as a model in the
+// columns of its first index with non-default values, like equalityConditional, but comparing
+// strings case-insensitively. OVSDB conditions are case-sensitive so, like predicateConditional,
+// it generates one condition per matching cache element based on _uuid equality
+type caseInsensitiveConditional struct {
+	tableName string
+	columns   []string
+	values    []interface{}
+	cache     *cache.TableCache
+}
+
+// Matches returns whether the model holds the same index values, ignoring the case of strings
+func (c *caseInsensitiveConditional) Matches(m model.Model) (bool, error) {
+	info, err := c.cache.Mapper().NewMapperInfo(c.tableName, m)
+	if err != nil {
+		return false, err
+	}
+	for i, column := range c.columns {
+		value, err := info.FieldByColumn(column)
+		if err != nil {
+			return false, err
+		}
+		if !equalFold(c.values[i], value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (c *caseInsensitiveConditional) Table() string {
+	return c.tableName
+}
+
+// Generate returns a list of conditions that match, by _uuid equality, all the objects
+// holding the same index values
+func (c *caseInsensitiveConditional) Generate() ([][]ovsdb.Condition, error) {
+	return c.generateContext(context.Background())
+}
+
+func (c *caseInsensitiveConditional) generateContext(ctx context.Context) ([][]ovsdb.Condition, error) {
+	return generateFromCache(ctx, c.cache, c.tableName, c.Matches)
+}
+
+// SelectedIndex returns the columns of the index the model is matched by
+func (c *caseInsensitiveConditional) SelectedIndex() []string {
+	return c.columns
+}
+
+// equalFold returns whether two values are equal, ignoring the case if they are strings
+func equalFold(one, other interface{}) bool {
+	if oneString, ok := one.(string); ok {
+		if otherString, ok := other.(string); ok {
+			return strings.EqualFold(oneString, otherString)
+		}
+	}
+	return reflect.DeepEqual(one, other)
+}
+
+// newCaseInsensitiveConditional creates a new caseInsensitiveConditional matching the values
+// of the first index for which the model has non-default values
+func newCaseInsensitiveConditional(table string, cache *cache.TableCache, m model.Model) (Conditional, error) {
+	info, err := cache.Mapper().NewMapperInfo(table, m)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := info.IndexKeys()
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("failed to find a valid index")
+	}
+	columns := keys[0].Columns
+	values := make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		value, err := info.FieldByColumn(column)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return &caseInsensitiveConditional{
+		tableName: table,
+		columns:   columns,
+		values:    values,
+		cache:     cache,
+	}, nil
+}
+
 // predicateConditional is a Conditional that calls a provided function pointer
 // to match on models.
 type predicateConditional struct {
@@ -141,19 +351,245 @@ func (c *predicateConditional) Table() string {
 // generate returns a list of conditions that match, by _uuid equality, all the objects that
 // match the predicate
 func (c *predicateConditional) Generate() ([][]ovsdb.Condition, error) {
+	return c.generateContext(context.Background())
+}
+
+func (c *predicateConditional) generateContext(ctx context.Context) ([][]ovsdb.Condition, error) {
+	return generateFromCache(ctx, c.cache, c.tableName, c.Matches)
+}
+
+// newPredicateConditional creates a new predicateConditional
+func newPredicateConditional(table string, cache *cache.TableCache, predicate interface{}) (Conditional, error) {
+	return &predicateConditional{
+		tableName: table,
+		predicate: predicate,
+		cache:     cache,
+	}, nil
+}
+
+// fieldMatchConditional is a Conditional that matches cache objects by evaluating a
+// function on the value of one of their fields.
+// Such matches cannot be expressed as RFC7047 conditions so, like predicateConditional,
+// it generates one condition per matching cache element based on _uuid equality
+type fieldMatchConditional struct {
+	tableName string
+	column    string
+	match     func(value interface{}) bool
+	cache     *cache.TableCache
+}
+
+// Matches returns the result of the match function on the model's field value
+func (c *fieldMatchConditional) Matches(m model.Model) (bool, error) {
+	info, err := c.cache.Mapper().NewMapperInfo(c.tableName, m)
+	if err != nil {
+		return false, err
+	}
+	value, err := info.FieldByColumn(c.column)
+	if err != nil {
+		return false, err
+	}
+	return c.match(value), nil
+}
+
+func (c *fieldMatchConditional) Table() string {
+	return c.tableName
+}
+
+// Generate returns a list of conditions that match, by _uuid equality, all the objects
+// whose field matches
+func (c *fieldMatchConditional) Generate() ([][]ovsdb.Condition, error) {
+	return c.generateContext(context.Background())
+}
+
+func (c *fieldMatchConditional) generateContext(ctx context.Context) ([][]ovsdb.Condition, error) {
+	return generateFromCache(ctx, c.cache, c.tableName, c.Matches)
+}
+
+// newStringMatchConditional creates a new fieldMatchConditional that applies the match
+// function to the values of a string column. The field must be a pointer to a field of
+// the provided model that corresponds to a string, optional string or set of strings column
+// in which case, the conditional matches if any of the strings matches
+func newStringMatchConditional(table string, cache *cache.TableCache, m model.Model, field interface{}, match func(string) bool) (Conditional, error) {
+	tableSchema := cache.Mapper().Schema.Table(table)
+	info, err := mapper.NewMapperInfo(tableSchema, m)
+	if err != nil {
+		return nil, err
+	}
+	column, err := info.ColumnByPtr(field)
+	if err != nil {
+		return nil, err
+	}
+	columnSchema := tableSchema.Column(column)
+	if columnSchema.TypeObj == nil || columnSchema.TypeObj.Key.Type != ovsdb.TypeString || columnSchema.Type == ovsdb.TypeMap {
+		return nil, fmt.Errorf("column %s is not a string column", column)
+	}
+	return &fieldMatchConditional{
+		tableName: table,
+		column:    column,
+		match: func(value interface{}) bool {
+			switch v := value.(type) {
+			case string:
+				return match(v)
+			case *string:
+				return v != nil && match(*v)
+			case []string:
+				for _, elem := range v {
+					if match(elem) {
+						return true
+					}
+				}
+			}
+			return false
+		},
+		cache: cache,
+	}, nil
+}
+
+// newMapKeyConditional creates a new fieldMatchConditional that matches the objects whose
+// map field (a pointer to a field of the provided model) has the provided key, regardless
+// of its value. The key must be of the native type of the map column's keys
+func newMapKeyConditional(table string, cache *cache.TableCache, m model.Model, field interface{}, key interface{}) (Conditional, error) {
+	tableSchema := cache.Mapper().Schema.Table(table)
+	info, err := mapper.NewMapperInfo(tableSchema, m)
+	if err != nil {
+		return nil, err
+	}
+	column, err := info.ColumnByPtr(field)
+	if err != nil {
+		return nil, err
+	}
+	columnSchema := tableSchema.Column(column)
+	if columnSchema.Type != ovsdb.TypeMap {
+		return nil, fmt.Errorf("column %s is not a map column", column)
+	}
+	if keyType := ovsdb.NativeTypeFromAtomic(columnSchema.TypeObj.Key.Type); reflect.TypeOf(key) != keyType {
+		return nil, ovsdb.NewErrWrongType(fmt.Sprintf("Key of map column %s", column), keyType.String(), key)
+	}
+	keyVal := reflect.ValueOf(key)
+	return &fieldMatchConditional{
+		tableName: table,
+		column:    column,
+		match: func(value interface{}) bool {
+			return reflect.ValueOf(value).MapIndex(keyVal).IsValid()
+		},
+		cache: cache,
+	}, nil
+}
+
+// columnConditional is a conditional on a column given by its name, which the models of the
+// table do not need to map. It can only be evaluated on the cached models if they do
+type columnConditional struct {
+	mapper    *mapper.Mapper
+	tableName string
+	condition ovsdb.Condition
+	// value is the native value of the condition
+	value interface{}
+}
+
+// Matches evaluates the condition on the model's value of the column, as the server would
+func (c *columnConditional) Matches(m model.Model) (bool, error) {
+	info, err := c.mapper.NewMapperInfo(c.tableName, m)
+	if err != nil {
+		return false, err
+	}
+	actual, err := info.FieldByColumn(c.condition.Column)
+	if err != nil {
+		return false, fmt.Errorf("column %s is not mapped by the model, so the condition cannot be evaluated on the cache: %w",
+			c.condition.Column, err)
+	}
+	column := c.mapper.Schema.Table(c.tableName).Column(c.condition.Column)
+	return ovsdb.EvaluateCondition(column, c.condition.Function, actual, c.value)
+}
+
+func (c *columnConditional) Table() string {
+	return c.tableName
+}
+
+// Generate returns the condition on the column
+func (c *columnConditional) Generate() ([][]ovsdb.Condition, error) {
+	return [][]ovsdb.Condition{{c.condition}}, nil
+}
+
+// newColumnConditional creates a new columnConditional. The value must be of the native type
+// of the column
+func newColumnConditional(mapper *mapper.Mapper, table, column string, function ovsdb.ConditionFunction, value interface{}) (Conditional, error) {
+	tableSchema := mapper.Schema.Table(table)
+	if tableSchema == nil {
+		return nil, fmt.Errorf("table %s not found in schema", table)
+	}
+	columnSchema := tableSchema.Column(column)
+	if columnSchema == nil {
+		return nil, fmt.Errorf("column %s not found in table %s", column, table)
+	}
+	if err := ovsdb.ValidateCondition(columnSchema, function, value); err != nil {
+		return nil, fmt.Errorf("condition on column %s: %w", column, err)
+	}
+	ovsValue, err := ovsdb.NativeToOvs(columnSchema, value)
+	if err != nil {
+		return nil, err
+	}
+	return &columnConditional{
+		mapper:    mapper,
+		tableName: table,
+		condition: ovsdb.NewCondition(column, function, ovsValue),
+		value:     value,
+	}, nil
+}
+
+// newMapValueConditional creates a new columnConditional that matches the objects whose map
+// field (a pointer to a field of the provided model) has the provided key set to the provided
+// value, i.e: whose map includes the key-value pair. The key and the value must be of the
+// native types of the map column's keys and values
+func newMapValueConditional(table string, m *mapper.Mapper, model model.Model, field interface{}, key, value interface{}) (Conditional, error) {
+	tableSchema := m.Schema.Table(table)
+	info, err := mapper.NewMapperInfo(tableSchema, model)
+	if err != nil {
+		return nil, err
+	}
+	column, err := info.ColumnByPtr(field)
+	if err != nil {
+		return nil, err
+	}
+	columnSchema := tableSchema.Column(column)
+	if columnSchema.Type != ovsdb.TypeMap {
+		return nil, fmt.Errorf("column %s is not a map column", column)
+	}
+	if keyType := ovsdb.NativeTypeFromAtomic(columnSchema.TypeObj.Key.Type); reflect.TypeOf(key) != keyType {
+		return nil, ovsdb.NewErrWrongType(fmt.Sprintf("Key of map column %s", column), keyType.String(), key)
+	}
+	if valueType := ovsdb.NativeTypeFromAtomic(columnSchema.TypeObj.Value.Type); reflect.TypeOf(value) != valueType {
+		return nil, ovsdb.NewErrWrongType(fmt.Sprintf("Value of map column %s", column), valueType.String(), value)
+	}
+	pair := reflect.MakeMap(ovsdb.NativeType(columnSchema))
+	pair.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(value))
+	return newColumnConditional(m, table, column, ovsdb.ConditionIncludes, pair.Interface())
+}
+
+// generateFromCache returns a list of conditions that match, by _uuid equality, all the objects
+// in the cache that match the provided function. The scan stops as soon as the context is done
+func generateFromCache(ctx context.Context, tcache *cache.TableCache, tableName string, matches func(model.Model) (bool, error)) ([][]ovsdb.Condition, error) {
 	allConditions := make([][]ovsdb.Condition, 0)
-	tableCache := c.cache.Table(c.tableName)
+	tableCache := tcache.Table(tableName)
 	if tableCache == nil {
 		return nil, ErrNotFound
 	}
-	for _, row := range tableCache.Rows() {
+	// Sorting the rows makes the generated conditions stable
+	rows := tableCache.RowsSorted()
+	for i, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return nil, &ErrGenerateCanceled{Table: tableName, Scanned: i, Total: len(rows), Matched: len(allConditions), Err: err}
+		}
 		elem := tableCache.Row(row)
-		match, err := c.Matches(elem)
+		if elem == nil {
+			// The row was deleted while iterating
+			continue
+		}
+		match, err := matches(elem)
 		if err != nil {
 			return nil, err
 		}
 		if match {
-			elemCond, err := c.cache.Mapper().NewEqualityCondition(c.tableName, elem)
+			elemCond, err := tcache.Mapper().NewEqualityCondition(tableName, elem)
 			if err != nil {
 				return nil, err
 			}
@@ -163,15 +599,6 @@ func (c *predicateConditional) Generate() ([][]ovsdb.Condition, error) {
 	return allConditions, nil
 }
 
-// newPredicateConditional creates a new predicateConditional
-func newPredicateConditional(table string, cache *cache.TableCache, predicate interface{}) (Conditional, error) {
-	return &predicateConditional{
-		tableName: table,
-		predicate: predicate,
-		cache:     cache,
-	}, nil
-}
-
 // errorConditional is a conditional that encapsulates an error
 // It is used to delay the reporting of errors from conditional creation to API method call
 type errorConditional struct {
@@ -1,10 +1,13 @@
 package client
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/mapper"
 	"github.com/ovn-org/libovsdb/model"
 	"github.com/ovn-org/libovsdb/ovsdb"
 	"github.com/stretchr/testify/assert"
@@ -249,7 +252,8 @@ func TestPredicateConditional(t *testing.T) {
 				assert.NotNil(t, err)
 			} else {
 				assert.Nil(t, err)
-				assert.ElementsMatch(t, tt.condition, generated)
+				// Conditions are generated in a stable order
+				assert.Equal(t, tt.condition, generated)
 			}
 		})
 	}
@@ -261,18 +265,21 @@ func TestExplicitConditional(t *testing.T) {
 		&testLogicalSwitchPort{
 			UUID:        aUUID0,
 			Name:        "lsp0",
+			Addresses:   []string{"10.0.0.1"},
 			ExternalIds: map[string]string{"foo": "bar"},
 			Enabled:     []bool{true},
 		},
 		&testLogicalSwitchPort{
 			UUID:        aUUID1,
 			Name:        "lsp1",
+			Addresses:   []string{"10.0.0.2"},
 			ExternalIds: map[string]string{"foo": "baz"},
 			Enabled:     []bool{false},
 		},
 		&testLogicalSwitchPort{
 			UUID:        aUUID2,
 			Name:        "lsp2",
+			Addresses:   []string{"10.0.0.1", "10.0.0.3"},
 			ExternalIds: map[string]string{"unique": "id"},
 			Enabled:     []bool{false},
 		},
@@ -292,11 +299,12 @@ func TestExplicitConditional(t *testing.T) {
 	testObj := &testLogicalSwitchPort{}
 
 	test := []struct {
-		name   string
-		args   []model.Condition
-		result [][]ovsdb.Condition
-		all    bool
-		err    bool
+		name    string
+		args    []model.Condition
+		result  [][]ovsdb.Condition
+		all     bool
+		err     bool
+		matches []string
 	}{
 		{
 			name: "inequality comparison",
@@ -314,6 +322,7 @@ func TestExplicitConditional(t *testing.T) {
 						Function: ovsdb.ConditionNotEqual,
 						Value:    "lsp0",
 					}}},
+			matches: []string{"lsp1", "lsp2", "lsp3"},
 		},
 		{
 			name: "inequality comparison all",
@@ -331,7 +340,8 @@ func TestExplicitConditional(t *testing.T) {
 						Function: ovsdb.ConditionNotEqual,
 						Value:    "lsp0",
 					}}},
-			all: true,
+			all:     true,
+			matches: []string{"lsp1", "lsp2", "lsp3"},
 		},
 		{
 			name: "map comparison",
@@ -349,6 +359,7 @@ func TestExplicitConditional(t *testing.T) {
 						Function: ovsdb.ConditionIncludes,
 						Value:    testOvsMap(t, map[string]string{"foo": "baz"}),
 					}}},
+			matches: []string{"lsp1", "lsp3"},
 		},
 		{
 			name: "set comparison",
@@ -366,6 +377,43 @@ func TestExplicitConditional(t *testing.T) {
 						Function: ovsdb.ConditionEqual,
 						Value:    testOvsSet(t, []bool{true}),
 					}}},
+			matches: []string{"lsp0", "lsp3"},
+		},
+		{
+			name: "empty set comparison",
+			args: []model.Condition{
+				{
+					Field:    &testObj.Addresses,
+					Function: ovsdb.ConditionEqual,
+					Value:    []string{},
+				},
+			},
+			result: [][]ovsdb.Condition{
+				{
+					{
+						Column:   "addresses",
+						Function: ovsdb.ConditionEqual,
+						Value:    testOvsSet(t, []string{}),
+					}}},
+			matches: []string{"lsp3"},
+		},
+		{
+			name: "empty optional set comparison",
+			args: []model.Condition{
+				{
+					Field:    &testObj.Enabled,
+					Function: ovsdb.ConditionNotEqual,
+					Value:    []bool{},
+				},
+			},
+			result: [][]ovsdb.Condition{
+				{
+					{
+						Column:   "enabled",
+						Function: ovsdb.ConditionNotEqual,
+						Value:    testOvsSet(t, []bool{}),
+					}}},
+			matches: []string{"lsp0", "lsp1", "lsp2", "lsp3"},
 		},
 		{
 			name: "multiple conditions",
@@ -394,6 +442,7 @@ func TestExplicitConditional(t *testing.T) {
 						Function: ovsdb.ConditionNotEqual,
 						Value:    "foo",
 					}}},
+			matches: []string{"lsp0", "lsp1", "lsp2", "lsp3"},
 		},
 		{
 			name: "multiple conditions all",
@@ -420,15 +469,114 @@ func TestExplicitConditional(t *testing.T) {
 					Function: ovsdb.ConditionNotEqual,
 					Value:    "foo",
 				}}},
-			all: true,
+			all:     true,
+			matches: []string{"lsp0", "lsp3"},
+		},
+		{
+			name: "set includes",
+			args: []model.Condition{
+				{
+					Field:    &testObj.Addresses,
+					Function: ovsdb.ConditionIncludes,
+					Value:    []string{"10.0.0.1"},
+				},
+			},
+			result: [][]ovsdb.Condition{
+				{
+					{
+						Column:   "addresses",
+						Function: ovsdb.ConditionIncludes,
+						Value:    testOvsSet(t, []string{"10.0.0.1"}),
+					}}},
+			matches: []string{"lsp0", "lsp2"},
+		},
+		{
+			name: "set excludes",
+			args: []model.Condition{
+				{
+					Field:    &testObj.Addresses,
+					Function: ovsdb.ConditionExcludes,
+					Value:    []string{"10.0.0.1"},
+				},
+			},
+			result: [][]ovsdb.Condition{
+				{
+					{
+						Column:   "addresses",
+						Function: ovsdb.ConditionExcludes,
+						Value:    testOvsSet(t, []string{"10.0.0.1"}),
+					}}},
+			matches: []string{"lsp1", "lsp3"},
+		},
+		{
+			name: "set excludes any of several elements",
+			args: []model.Condition{
+				{
+					Field:    &testObj.Addresses,
+					Function: ovsdb.ConditionExcludes,
+					Value:    []string{"10.0.0.2", "10.0.0.3"},
+				},
+			},
+			result: [][]ovsdb.Condition{
+				{
+					{
+						Column:   "addresses",
+						Function: ovsdb.ConditionExcludes,
+						Value:    testOvsSet(t, []string{"10.0.0.2", "10.0.0.3"}),
+					}}},
+			matches: []string{"lsp0", "lsp3"},
+		},
+		{
+			name: "map includes",
+			args: []model.Condition{
+				{
+					Field:    &testObj.ExternalIds,
+					Function: ovsdb.ConditionIncludes,
+					Value:    map[string]string{"foo": "baz"},
+				},
+			},
+			result: [][]ovsdb.Condition{
+				{
+					{
+						Column:   "external_ids",
+						Function: ovsdb.ConditionIncludes,
+						Value:    testOvsMap(t, map[string]string{"foo": "baz"}),
+					}}},
+			matches: []string{"lsp1", "lsp3"},
+		},
+		{
+			name: "map excludes",
+			args: []model.Condition{
+				{
+					Field:    &testObj.ExternalIds,
+					Function: ovsdb.ConditionExcludes,
+					Value:    map[string]string{"foo": "baz"},
+				},
+			},
+			result: [][]ovsdb.Condition{
+				{
+					{
+						Column:   "external_ids",
+						Function: ovsdb.ConditionExcludes,
+						Value:    testOvsMap(t, map[string]string{"foo": "baz"}),
+					}}},
+			// a key with a different value is not excluded
+			matches: []string{"lsp0", "lsp2"},
 		},
 	}
 	for _, tt := range test {
 		t.Run(fmt.Sprintf("Explicit Conditional: %s", tt.name), func(t *testing.T) {
 			cond, err := newExplicitConditional(tcache.Mapper(), "Logical_Switch_Port", tt.all, testObj, tt.args...)
 			assert.Nil(t, err)
-			_, err = cond.Matches(testObj)
-			assert.NotNilf(t, err, "Explicit conditions should fail to match on cache")
+			matches := []string{}
+			for _, m := range lspcacheList {
+				ok, err := cond.Matches(m)
+				assert.Nil(t, err)
+				if ok {
+					matches = append(matches, m.(*testLogicalSwitchPort).Name)
+				}
+			}
+			assert.ElementsMatch(t, tt.matches, matches)
 			generated, err := cond.Generate()
 			if tt.err {
 				assert.NotNil(t, err)
@@ -439,3 +587,188 @@ func TestExplicitConditional(t *testing.T) {
 		})
 	}
 }
+
+func TestExplicitConditionalWrongElementType(t *testing.T) {
+	tcache := apiTestCache(t)
+	lsp := &testLogicalSwitchPort{}
+	test := []struct {
+		name   string
+		cond   model.Condition
+		column string
+	}{
+		{
+			name:   "set of integers",
+			cond:   model.Condition{Field: &lsp.Tag, Function: ovsdb.ConditionIncludes, Value: []string{"foo"}},
+			column: "tag",
+		},
+		{
+			name:   "map of strings",
+			cond:   model.Condition{Field: &lsp.ExternalIds, Function: ovsdb.ConditionIncludes, Value: map[string]int{"foo": 1}},
+			column: "external_ids",
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("Explicit Conditional Wrong Element Type: %s", tt.name), func(t *testing.T) {
+			cond, err := newExplicitConditional(tcache.Mapper(), "Logical_Switch_Port", true, lsp, tt.cond)
+			assert.Nil(t, err)
+			_, err = cond.Generate()
+			var elemErr *ovsdb.ErrWrongElementType
+			assert.True(t, errors.As(err, &elemErr), "expected ErrWrongElementType, got %v", err)
+			assert.Contains(t, err.Error(), tt.column)
+		})
+	}
+}
+
+type testMeterBand struct {
+	UUID      string  `ovs:"_uuid"`
+	Rate      int     `ovs:"rate"`
+	BurstSize float64 `ovs:"burst_size"`
+}
+
+func (*testMeterBand) Table() string {
+	return "Meter_Band"
+}
+
+func TestExplicitConditionalNumericComparisons(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal([]byte(`{
+		"name": "Meters",
+		"version": "1.0.0",
+		"tables": {
+			"Meter_Band": {
+				"columns": {
+					"rate": {"type": "integer"},
+					"burst_size": {"type": "real"}
+				}
+			}
+		}
+	}`), &schema)
+	assert.Nil(t, err)
+	m := mapper.NewMapper(&schema)
+
+	bands := []*testMeterBand{
+		{UUID: aUUID0, Rate: 10, BurstSize: 0.5},
+		{UUID: aUUID1, Rate: 20, BurstSize: 1.5},
+		{UUID: aUUID2, Rate: 30, BurstSize: 2.5},
+	}
+	testObj := &testMeterBand{}
+	test := []struct {
+		name    string
+		args    []model.Condition
+		all     bool
+		matches []string
+	}{
+		{
+			name:    "integer less than",
+			args:    []model.Condition{{Field: &testObj.Rate, Function: ovsdb.ConditionLessThan, Value: 20}},
+			matches: []string{aUUID0},
+		},
+		{
+			name:    "integer less than or equal",
+			args:    []model.Condition{{Field: &testObj.Rate, Function: ovsdb.ConditionLessThanOrEqual, Value: 20}},
+			matches: []string{aUUID0, aUUID1},
+		},
+		{
+			name:    "integer greater than",
+			args:    []model.Condition{{Field: &testObj.Rate, Function: ovsdb.ConditionGreaterThan, Value: 20}},
+			matches: []string{aUUID2},
+		},
+		{
+			name:    "integer greater than or equal",
+			args:    []model.Condition{{Field: &testObj.Rate, Function: ovsdb.ConditionGreaterThanOrEqual, Value: 20}},
+			matches: []string{aUUID1, aUUID2},
+		},
+		{
+			name:    "real less than",
+			args:    []model.Condition{{Field: &testObj.BurstSize, Function: ovsdb.ConditionLessThan, Value: 1.5}},
+			matches: []string{aUUID0},
+		},
+		{
+			name:    "real less than or equal",
+			args:    []model.Condition{{Field: &testObj.BurstSize, Function: ovsdb.ConditionLessThanOrEqual, Value: 1.5}},
+			matches: []string{aUUID0, aUUID1},
+		},
+		{
+			name:    "real greater than",
+			args:    []model.Condition{{Field: &testObj.BurstSize, Function: ovsdb.ConditionGreaterThan, Value: 1.5}},
+			matches: []string{aUUID2},
+		},
+		{
+			name:    "real greater than or equal",
+			args:    []model.Condition{{Field: &testObj.BurstSize, Function: ovsdb.ConditionGreaterThanOrEqual, Value: 1.5}},
+			matches: []string{aUUID1, aUUID2},
+		},
+		{
+			name: "range all",
+			args: []model.Condition{
+				{Field: &testObj.Rate, Function: ovsdb.ConditionGreaterThan, Value: 10},
+				{Field: &testObj.BurstSize, Function: ovsdb.ConditionLessThan, Value: 2.5},
+			},
+			all:     true,
+			matches: []string{aUUID1},
+		},
+		{
+			name: "range any",
+			args: []model.Condition{
+				{Field: &testObj.Rate, Function: ovsdb.ConditionLessThan, Value: 20},
+				{Field: &testObj.BurstSize, Function: ovsdb.ConditionGreaterThan, Value: 1.5},
+			},
+			matches: []string{aUUID0, aUUID2},
+		},
+		{
+			name: "range on the same column all",
+			args: []model.Condition{
+				{Field: &testObj.Rate, Function: ovsdb.ConditionGreaterThanOrEqual, Value: 15},
+				{Field: &testObj.Rate, Function: ovsdb.ConditionLessThanOrEqual, Value: 30},
+			},
+			all:     true,
+			matches: []string{aUUID1, aUUID2},
+		},
+		{
+			name: "empty range on the same column all",
+			args: []model.Condition{
+				{Field: &testObj.Rate, Function: ovsdb.ConditionGreaterThan, Value: 10},
+				{Field: &testObj.Rate, Function: ovsdb.ConditionLessThan, Value: 20},
+			},
+			all:     true,
+			matches: []string{},
+		},
+		{
+			name: "outside a range on the same column any",
+			args: []model.Condition{
+				{Field: &testObj.Rate, Function: ovsdb.ConditionLessThan, Value: 15},
+				{Field: &testObj.Rate, Function: ovsdb.ConditionGreaterThan, Value: 25},
+			},
+			matches: []string{aUUID0, aUUID2},
+		},
+	}
+	for _, tt := range test {
+		t.Run(fmt.Sprintf("Explicit Conditional Numeric Comparisons: %s", tt.name), func(t *testing.T) {
+			cond, err := newExplicitConditional(m, "Meter_Band", tt.all, testObj, tt.args...)
+			assert.Nil(t, err)
+			matches := []string{}
+			for _, band := range bands {
+				ok, err := cond.Matches(band)
+				assert.Nil(t, err)
+				if ok {
+					matches = append(matches, band.UUID)
+				}
+			}
+			assert.ElementsMatch(t, tt.matches, matches)
+		})
+	}
+
+	t.Run("Explicit Conditional Numeric Comparisons: range on the same column generates both conditions", func(t *testing.T) {
+		cond, err := newExplicitConditional(m, "Meter_Band", true, testObj,
+			model.Condition{Field: &testObj.Rate, Function: ovsdb.ConditionGreaterThanOrEqual, Value: 15},
+			model.Condition{Field: &testObj.Rate, Function: ovsdb.ConditionLessThanOrEqual, Value: 30},
+		)
+		assert.Nil(t, err)
+		generated, err := cond.Generate()
+		assert.Nil(t, err)
+		assert.Equal(t, [][]ovsdb.Condition{{
+			ovsdb.NewCondition("rate", ovsdb.ConditionGreaterThanOrEqual, 15),
+			ovsdb.NewCondition("rate", ovsdb.ConditionLessThanOrEqual, 30),
+		}}, generated)
+	})
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// database holds the schema, cache and API of one of the databases the client is connected to
+type database struct {
+	model  *model.DBModel
+	schema *ovsdb.DatabaseSchema
+	cache  *cache.TableCache
+	api    API
+	// lastTxnID holds the id of the last transaction whose updates were received by the
+	// monitors created with MonitorCondSinceDatabase
+	lastTxnID *transactionID
+}
+
+// newDatabase fetches the schema of the provided database model from the server, validates the model
+// against it and creates its cache. dbs is the list of databases available on the server
+func (ovs *OvsdbClient) newDatabase(dbs []string, dbModel *model.DBModel, options *options, primary bool) (*database, error) {
+	found := false
+	for _, db := range dbs {
+		if db == dbModel.Name() {
+			found = true
+			break
+		}
+	}
+	if !found {
+		if primary {
+			return nil, fmt.Errorf("target database not found")
+		}
+		return nil, fmt.Errorf("target database %s not found", dbModel.Name())
+	}
+
+	schema, err := ovs.GetSchema(context.Background(), dbModel.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	if primary {
+		if err := checkSchemaVersion(options, schema); err != nil {
+			return nil, err
+		}
+	}
+
+	errors := dbModel.Validate(schema)
+	if len(errors) > 0 {
+		var combined []string
+		for _, err := range errors {
+			combined = append(combined, err.Error())
+		}
+		return nil, fmt.Errorf("database validation error (%d): %s", len(errors),
+			strings.Join(combined, ". "))
+	}
+
+	if options.noCache {
+		return &database{model: dbModel, schema: schema, lastTxnID: &transactionID{}}, nil
+	}
+	tcache, err := cache.NewTableCache(schema, dbModel)
+	if err != nil {
+		return nil, err
+	}
+	return &database{
+		model:     dbModel,
+		schema:    schema,
+		cache:     tcache,
+		api:       api{cache: tcache, skipImmutable: options.skipImmutable, weakRefWarn: options.weakRefWarn},
+		lastTxnID: &transactionID{},
+	}, nil
+}
+
+// resumeFrom makes the primary database reuse the cache of a disconnected client, along with the
+// id of the last transaction received by its monitors, unless their schemas or models differ
+func (ovs *OvsdbClient) resumeFrom(previous *OvsdbClient, primary *database) error {
+	select {
+	case <-previous.stopCh:
+	default:
+		return fmt.Errorf("the client to resume from must be disconnected")
+	}
+	if !reflect.DeepEqual(previous.Schema, *primary.schema) ||
+		!reflect.DeepEqual(previous.Cache.DBModel().Types(), primary.model.Types()) {
+		return nil
+	}
+	primaryAPI := primary.api.(api)
+	primaryAPI.cache = previous.Cache
+	primary.api = primaryAPI
+	primary.cache = previous.Cache
+	ovs.lastTxnID.set(previous.lastTxnID.get())
+	return nil
+}
+
+// database returns one of the databases the client is connected to
+func (ovs OvsdbClient) database(name string) (*database, error) {
+	db, ok := ovs.databases[name]
+	if !ok {
+		return nil, fmt.Errorf("database %s not found", name)
+	}
+	return db, nil
+}
+
+// DatabaseCache returns the cache of one of the databases the client is connected to,
+// or nil if the client is not connected to it
+func (ovs OvsdbClient) DatabaseCache(name string) *cache.TableCache {
+	if name == ovs.Schema.Name {
+		return ovs.Cache
+	}
+	db, err := ovs.database(name)
+	if err != nil {
+		return nil
+	}
+	return db.cache
+}
+
+// DatabaseAPI returns the API to interact with one of the databases the client is connected to,
+// or nil if the client is not connected to it
+func (ovs OvsdbClient) DatabaseAPI(name string) API {
+	if name == ovs.Schema.Name {
+		return ovs.api
+	}
+	db, err := ovs.database(name)
+	if err != nil {
+		return nil
+	}
+	return db.api
+}
+
+// schemaForOperations returns the schema of the database the operations are valid for. It fails if
+// they are not valid for any of the databases the client is connected to, or if they are valid for
+// several of them (e.g: operations on a table both databases define), which TransactDatabase has to
+// tell apart
+func (ovs OvsdbClient) schemaForOperations(operations []ovsdb.Operation) (*ovsdb.DatabaseSchema, error) {
+	var schema *ovsdb.DatabaseSchema
+	var valid []string
+	if ovs.Schema.ValidateOperations(operations...) {
+		schema = &ovs.Schema
+		valid = append(valid, ovs.Schema.Name)
+	}
+	for _, name := range ovs.databaseNames {
+		db := ovs.databases[name]
+		if db.schema.ValidateOperations(operations...) {
+			schema = db.schema
+			valid = append(valid, name)
+		}
+	}
+	switch len(valid) {
+	case 0:
+		return nil, fmt.Errorf("validation failed for the operation")
+	case 1:
+		return schema, nil
+	default:
+		return nil, fmt.Errorf("the operations are valid for databases %s, use TransactDatabase to choose one", strings.Join(valid, ", "))
+	}
+}
+
+// cacheForModel returns the cache of the database the model belongs to. If it does not
+// belong to any of them, the cache of the database the client was connected to is returned
+func (ovs OvsdbClient) cacheForModel(m model.Model) *cache.TableCache {
+	if ovs.Cache == nil || ovs.Cache.DBModel().FindTable(reflect.TypeOf(m)) != "" {
+		return ovs.Cache
+	}
+	for _, name := range ovs.databaseNames {
+		db := ovs.databases[name]
+		if db.model.FindTable(reflect.TypeOf(m)) != "" {
+			return db.cache
+		}
+	}
+	return ovs.Cache
+}
+
+// addMonitor records the database a monitor is requested for, so its update notifications are
+// delivered to the cache of that database. As the notifications only hold the json context of the
+// monitor, it must not be used by another monitor, whatever its database
+func (ovs *OvsdbClient) addMonitor(jsonContext interface{}, name string) error {
+	ovs.monitorsMutex.Lock()
+	defer ovs.monitorsMutex.Unlock()
+	key := fmt.Sprint(jsonContext)
+	if db, ok := ovs.monitors[key]; ok {
+		return fmt.Errorf("json context %v is already used by a monitor of database %s", jsonContext, db)
+	}
+	ovs.monitors[key] = name
+	return nil
+}
+
+// removeMonitor forgets the database a monitor was requested for
+func (ovs *OvsdbClient) removeMonitor(jsonContext interface{}) {
+	ovs.monitorsMutex.Lock()
+	defer ovs.monitorsMutex.Unlock()
+	delete(ovs.monitors, fmt.Sprint(jsonContext))
+}
+
+// monitorDatabase returns the additional database a monitor was requested for, or nil if it
+// was requested for the database the client was connected to
+func (ovs *OvsdbClient) monitorDatabase(jsonContext interface{}) *database {
+	if len(ovs.databases) == 0 {
+		return nil
+	}
+	ovs.monitorsMutex.RLock()
+	defer ovs.monitorsMutex.RUnlock()
+	return ovs.databases[ovs.monitors[fmt.Sprint(jsonContext)]]
+}
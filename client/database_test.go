@@ -0,0 +1,532 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/cenkalti/rpc2/jsonrpc"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+)
+
+// serialCodec serializes the responses of the test server, whose handlers run concurrently, as
+// the json codec does not
+type serialCodec struct {
+	rpc2.Codec
+	mutex sync.Mutex
+}
+
+func (c *serialCodec) WriteResponse(resp *rpc2.Response, reply interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.Codec.WriteResponse(resp, reply)
+}
+
+func (c *serialCodec) WriteRequest(req *rpc2.Request, args interface{}) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.Codec.WriteRequest(req, args)
+}
+
+var secondTestSchema = []byte(`{
+    "name": "Second_DB",
+    "version": "1.0.0",
+    "tables": {
+        "Chassis": {
+            "columns": {
+                "name": {"type": "string"}
+            }
+        }
+    }
+}`)
+
+type testChassis struct {
+	UUID string `ovs:"_uuid"`
+	Name string `ovs:"name"`
+}
+
+// Table returns the table name. It's part of the Model interface
+func (*testChassis) Table() string {
+	return "Chassis"
+}
+
+// testDatabaseServer is a fake server that serves the test schemas and records
+// the database each transact and monitor request is sent to
+type testDatabaseServer struct {
+	server  *rpc2.Client
+	mutex   sync.Mutex
+	targets []string
+	// echoDelay delays the replies to echo requests
+	echoDelay time.Duration
+	// beforeTransact, if set, is called before replying to transact requests
+	beforeTransact func()
+	// transactResults, if set, builds the results of transact requests, which are otherwise empty
+	transactResults func(operations []ovsdb.Operation) []ovsdb.OperationResult
+	// transactOps holds the operations of the transact requests
+	transactOps [][]ovsdb.Operation
+	// condChanges holds the arguments of the monitor_cond_change requests
+	condChanges [][]json.RawMessage
+	// beforeCondChange, if set, is called before replying to monitor_cond_change requests
+	beforeCondChange func()
+	// condSince, if set, builds the replies to monitor_cond_since requests, which otherwise
+	// get the same rows as monitor_cond ones and the "txn1" transaction id
+	condSince func(lastTxnID string) ovsdb.MonitorCondSinceReply
+	// condSinceTxnIDs holds the transaction ids of the monitor_cond_since requests
+	condSinceTxnIDs []string
+}
+
+func (s *testDatabaseServer) setEchoDelay(delay time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.echoDelay = delay
+}
+
+func (s *testDatabaseServer) record(args []json.RawMessage) error {
+	var db string
+	if err := json.Unmarshal(args[0], &db); err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.targets = append(s.targets, db)
+	return nil
+}
+
+func (s *testDatabaseServer) lastTarget() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.targets) == 0 {
+		return ""
+	}
+	return s.targets[len(s.targets)-1]
+}
+
+func newTestDatabaseClient(t *testing.T, opts ...Option) (*OvsdbClient, *testDatabaseServer, error) {
+	schemas := make(map[string]json.RawMessage)
+	for _, raw := range [][]byte{apiTestSchema, secondTestSchema} {
+		var schema ovsdb.DatabaseSchema
+		err := json.Unmarshal(raw, &schema)
+		assert.Nil(t, err)
+		schemas[schema.Name] = raw
+	}
+
+	clientConn, serverConn := net.Pipe()
+	s := &testDatabaseServer{server: rpc2.NewClientWithCodec(&serialCodec{Codec: jsonrpc.NewJSONCodec(serverConn)})}
+	s.server.Handle("list_dbs", func(_ *rpc2.Client, args []interface{}, reply *[]string) error {
+		*reply = []string{"OVN_Northbound", "Second_DB"}
+		return nil
+	})
+	s.server.Handle("get_schema", func(_ *rpc2.Client, args []string, reply *json.RawMessage) error {
+		*reply = schemas[args[0]]
+		return nil
+	})
+	s.server.Handle("echo", func(_ *rpc2.Client, args []interface{}, reply *[]interface{}) error {
+		s.mutex.Lock()
+		delay := s.echoDelay
+		s.mutex.Unlock()
+		time.Sleep(delay)
+		*reply = args
+		return nil
+	})
+	s.server.Handle("transact", func(_ *rpc2.Client, args []json.RawMessage, reply *[]ovsdb.OperationResult) error {
+		operations := make([]ovsdb.Operation, len(args)-1)
+		for i := range operations {
+			if err := json.Unmarshal(args[i+1], &operations[i]); err != nil {
+				return err
+			}
+		}
+		s.mutex.Lock()
+		beforeTransact := s.beforeTransact
+		transactResults := s.transactResults
+		s.transactOps = append(s.transactOps, operations)
+		s.mutex.Unlock()
+		if beforeTransact != nil {
+			beforeTransact()
+		}
+		if transactResults != nil {
+			*reply = transactResults(operations)
+		} else {
+			*reply = make([]ovsdb.OperationResult, len(args)-1)
+		}
+		return s.record(args)
+	})
+	s.server.Handle("monitor", func(_ *rpc2.Client, args []json.RawMessage, reply *ovsdb.TableUpdates) error {
+		*reply = ovsdb.TableUpdates{}
+		return s.record(args)
+	})
+	s.server.Handle("monitor_cond", func(_ *rpc2.Client, args []json.RawMessage, reply *ovsdb.TableUpdates2) error {
+		*reply = ovsdb.TableUpdates2{
+			"Logical_Switch": {
+				aUUID0: &ovsdb.RowUpdate2{Initial: &ovsdb.Row{"name": "foo"}},
+				aUUID1: &ovsdb.RowUpdate2{Initial: &ovsdb.Row{"name": "bar"}},
+			},
+		}
+		return s.record(args)
+	})
+	s.server.Handle("monitor_cond_since", func(_ *rpc2.Client, args []json.RawMessage, reply *ovsdb.MonitorCondSinceReply) error {
+		var lastTxnID string
+		if err := json.Unmarshal(args[3], &lastTxnID); err != nil {
+			return err
+		}
+		s.mutex.Lock()
+		s.condSinceTxnIDs = append(s.condSinceTxnIDs, lastTxnID)
+		condSince := s.condSince
+		s.mutex.Unlock()
+		if condSince != nil {
+			*reply = condSince(lastTxnID)
+		} else {
+			*reply = ovsdb.MonitorCondSinceReply{
+				LastTransactionID: "txn1",
+				Updates: ovsdb.TableUpdates2{
+					"Logical_Switch": {
+						aUUID0: &ovsdb.RowUpdate2{Initial: &ovsdb.Row{"name": "foo"}},
+						aUUID1: &ovsdb.RowUpdate2{Initial: &ovsdb.Row{"name": "bar"}},
+					},
+				},
+			}
+		}
+		return s.record(args)
+	})
+	s.server.Handle("monitor_cond_change", func(_ *rpc2.Client, args []json.RawMessage, reply *map[string]interface{}) error {
+		s.mutex.Lock()
+		s.condChanges = append(s.condChanges, args)
+		beforeCondChange := s.beforeCondChange
+		s.mutex.Unlock()
+		if beforeCondChange != nil {
+			beforeCondChange()
+		}
+		*reply = map[string]interface{}{}
+		return nil
+	})
+	go s.server.Run()
+	t.Cleanup(func() { s.server.Close() })
+
+	dbModel, err := model.NewDBModel("OVN_Northbound", map[string]model.Model{"Logical_Switch": &testLogicalSwitch{}, "Logical_Switch_Port": &testLogicalSwitchPort{}})
+	assert.Nil(t, err)
+	options, err := newOptions(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	ovs, err := newRPC2Client(clientConn, dbModel, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	t.Cleanup(ovs.Disconnect)
+	return ovs, s, nil
+}
+
+func TestMultipleDatabases(t *testing.T) {
+	secondModel, err := model.NewDBModel("Second_DB", map[string]model.Model{"Chassis": &testChassis{}})
+	assert.Nil(t, err)
+
+	ovs, server, err := newTestDatabaseClient(t, WithDatabase(secondModel))
+	assert.Nil(t, err)
+	assert.NotNil(t, ovs.DatabaseCache("OVN_Northbound"))
+	assert.NotNil(t, ovs.DatabaseCache("Second_DB"))
+	assert.Nil(t, ovs.DatabaseCache("Unknown"))
+	assert.Nil(t, ovs.DatabaseAPI("Unknown"))
+
+	t.Run("MultipleDatabases: transact infers the database", func(t *testing.T) {
+		_, err := ovs.Transact(ovsdb.Operation{Op: opInsert, Table: "Logical_Switch", Row: ovsdb.Row{"name": "foo"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "OVN_Northbound", server.lastTarget())
+
+		ops, err := ovs.DatabaseAPI("Second_DB").Create(&testChassis{Name: "chassis"})
+		assert.Nil(t, err)
+		_, err = ovs.Transact(ops...)
+		assert.Nil(t, err)
+		assert.Equal(t, "Second_DB", server.lastTarget())
+
+		_, err = ovs.Transact(ovsdb.Operation{Op: opInsert, Table: "Unknown", Row: ovsdb.Row{"name": "foo"}})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("MultipleDatabases: transact fails for operations valid for several databases", func(t *testing.T) {
+		comment := "ambiguous"
+		_, err := ovs.Transact(ovsdb.Operation{Op: ovsdb.OperationComment, Comment: &comment})
+		assert.NotNil(t, err)
+		_, err = ovs.TransactContext(context.Background(), ovsdb.Operation{Op: ovsdb.OperationComment, Comment: &comment})
+		assert.NotNil(t, err)
+
+		_, err = ovs.TransactDatabase("Second_DB", ovsdb.Operation{Op: ovsdb.OperationComment, Comment: &comment})
+		assert.Nil(t, err)
+		assert.Equal(t, "Second_DB", server.lastTarget())
+	})
+
+	t.Run("MultipleDatabases: transact on a given database", func(t *testing.T) {
+		_, err := ovs.TransactDatabase("Second_DB", ovsdb.Operation{Op: opInsert, Table: "Chassis", Row: ovsdb.Row{"name": "foo"}})
+		assert.Nil(t, err)
+		assert.Equal(t, "Second_DB", server.lastTarget())
+
+		_, err = ovs.TransactDatabase("Second_DB", ovsdb.Operation{Op: opInsert, Table: "Logical_Switch", Row: ovsdb.Row{"name": "foo"}})
+		assert.NotNil(t, err)
+
+		_, err = ovs.TransactDatabase("Unknown", ovsdb.Operation{Op: opInsert, Table: "Chassis", Row: ovsdb.Row{"name": "foo"}})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("MultipleDatabases: updates go to the monitored database", func(t *testing.T) {
+		err := ovs.MonitorAllDatabase("Second_DB", "second")
+		assert.Nil(t, err)
+		assert.Equal(t, "Second_DB", server.lastTarget())
+
+		row := ovsdb.Row{"_uuid": ovsdb.UUID{GoUUID: aUUID0}, "name": "chassis"}
+		var reply []interface{}
+		err = server.server.Call("update", []interface{}{"second", ovsdb.TableUpdates{
+			"Chassis": {aUUID0: &ovsdb.RowUpdate{New: &row}},
+		}}, &reply)
+		assert.Nil(t, err)
+
+		assert.Eventually(t, func() bool {
+			return ovs.DatabaseCache("Second_DB").Table("Chassis").Row(aUUID0) != nil
+		}, time.Second, 10*time.Millisecond)
+		assert.Nil(t, ovs.Cache.Table("Chassis"))
+	})
+
+	t.Run("MultipleDatabases: the last transaction id is recorded per database", func(t *testing.T) {
+		server.mutex.Lock()
+		server.condSince = func(lastTxnID string) ovsdb.MonitorCondSinceReply {
+			return ovsdb.MonitorCondSinceReply{
+				LastTransactionID: "second1",
+				Updates: ovsdb.TableUpdates2{
+					"Chassis": {aUUID1: &ovsdb.RowUpdate2{Initial: &ovsdb.Row{"name": "since"}}},
+				},
+			}
+		}
+		server.mutex.Unlock()
+		err := ovs.MonitorCondSinceDatabase("Second_DB", "second-since", map[string]ovsdb.MonitorCondRequest{"Chassis": {}})
+		assert.Nil(t, err)
+		assert.Equal(t, "Second_DB", server.lastTarget())
+		assert.NotNil(t, ovs.DatabaseCache("Second_DB").Table("Chassis").Row(aUUID1))
+		assert.Equal(t, "second1", ovs.LastTransactionIDDatabase("Second_DB"))
+
+		var reply []interface{}
+		err = server.server.Call("update3", []interface{}{"second-since", "second2", ovsdb.TableUpdates2{
+			"Chassis": {aUUID2: &ovsdb.RowUpdate2{Insert: &ovsdb.Row{"name": "since2"}}},
+		}}, &reply)
+		assert.Nil(t, err)
+		assert.NotNil(t, ovs.DatabaseCache("Second_DB").Table("Chassis").Row(aUUID2))
+		assert.Equal(t, "second2", ovs.LastTransactionIDDatabase("Second_DB"))
+		assert.Equal(t, ovsdb.ZeroTransactionID, ovs.LastTransactionID())
+		assert.Equal(t, ovsdb.ZeroTransactionID, ovs.LastTransactionIDDatabase("Unknown"))
+		assert.NotNil(t, ovs.MonitorCondSinceDatabase("Unknown", "unknown", nil))
+	})
+
+	t.Run("MultipleDatabases: json contexts are unique across databases", func(t *testing.T) {
+		err := ovs.Monitor("second", map[string]ovsdb.MonitorRequest{"Logical_Switch": {}})
+		assert.NotNil(t, err)
+		err = ovs.MonitorCond("second", map[string]ovsdb.MonitorCondRequest{"Logical_Switch": {}})
+		assert.NotNil(t, err)
+
+		err = ovs.MonitorCond("first", map[string]ovsdb.MonitorCondRequest{"Logical_Switch": {}})
+		assert.Nil(t, err)
+		err = ovs.MonitorAllDatabase("Second_DB", "first")
+		assert.NotNil(t, err)
+
+		// Updates of the monitor of the primary database still go to its cache
+		var reply []interface{}
+		err = server.server.Call("update2", []interface{}{"first", ovsdb.TableUpdates2{
+			"Logical_Switch": {aUUID2: &ovsdb.RowUpdate2{Insert: &ovsdb.Row{"name": "baz"}}},
+		}}, &reply)
+		assert.Nil(t, err)
+		assert.Eventually(t, func() bool {
+			return ovs.Cache.Table("Logical_Switch").Row(aUUID2) != nil
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestMultipleDatabasesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		db   string
+	}{
+		{
+			name: "not on the server",
+			db:   "Unknown",
+		},
+		{
+			name: "registered twice",
+			db:   "OVN_Northbound",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("MultipleDatabasesErrors: %s", tt.name), func(t *testing.T) {
+			dbModel, err := model.NewDBModel(tt.db, map[string]model.Model{"Chassis": &testChassis{}})
+			assert.Nil(t, err)
+			_, _, err = newTestDatabaseClient(t, WithDatabase(dbModel))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestListDatabasesAndGetSchema(t *testing.T) {
+	ovs, _, err := newTestDatabaseClient(t)
+	assert.Nil(t, err)
+
+	dbs, err := ovs.ListDatabases(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"OVN_Northbound", "Second_DB"}, dbs)
+
+	schema, err := ovs.GetSchema(context.Background(), "Second_DB")
+	assert.Nil(t, err)
+	assert.Equal(t, "Second_DB", schema.Name)
+	assert.Equal(t, "1.0.0", schema.Version)
+	assert.NotNil(t, schema.Table("Chassis"))
+	assert.Equal(t, ovsdb.TypeString, schema.Table("Chassis").Column("name").Type)
+}
+
+func TestUpdateMonitorConditions(t *testing.T) {
+	ovs, server, err := newTestDatabaseClient(t)
+	assert.Nil(t, err)
+
+	err = ovs.UpdateMonitorConditions("Logical_Switch", nil)
+	assert.NotNil(t, err, "the table is not monitored with conditions")
+
+	err = ovs.MonitorCond("cond", map[string]ovsdb.MonitorCondRequest{
+		"Logical_Switch": {Where: []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionNotEqual, "baz")}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, ovs.Cache.Table("Logical_Switch").Len())
+
+	// A row that matches the new conditions is sent while the change is in flight
+	server.beforeCondChange = func() {
+		var reply []interface{}
+		err := server.server.Call("update2", []interface{}{"cond", ovsdb.TableUpdates2{
+			"Logical_Switch": {aUUID2: &ovsdb.RowUpdate2{Insert: &ovsdb.Row{"name": "baz"}}},
+		}}, &reply)
+		assert.Nil(t, err)
+	}
+
+	err = ovs.UpdateMonitorConditions("Logical_Switch", []ovsdb.Condition{
+		ovsdb.NewCondition("name", ovsdb.ConditionEqual, "foo"),
+		ovsdb.NewCondition("name", ovsdb.ConditionEqual, "baz"),
+	})
+	assert.Nil(t, err)
+	lsCache := ovs.Cache.Table("Logical_Switch")
+	assert.NotNil(t, lsCache.Row(aUUID0))
+	assert.Nil(t, lsCache.Row(aUUID1))
+	assert.NotNil(t, lsCache.Row(aUUID2))
+
+	server.mutex.Lock()
+	assert.Len(t, server.condChanges, 1)
+	assert.JSONEq(t, `{"Logical_Switch":[{"where":[["name","==","foo"],["name","==","baz"]]}]}`, string(server.condChanges[0][2]))
+	server.mutex.Unlock()
+
+	t.Run("UpdateMonitorConditions: invalid conditions", func(t *testing.T) {
+		err := ovs.UpdateMonitorConditions("Logical_Switch", []ovsdb.Condition{ovsdb.NewCondition("unknown", ovsdb.ConditionEqual, "foo")})
+		assert.NotNil(t, err)
+		err = ovs.UpdateMonitorConditions("Logical_Switch", []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, 42)})
+		assert.NotNil(t, err)
+		err = ovs.UpdateMonitorConditions("Unknown", nil)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("UpdateMonitorConditions: no conditions monitor every row", func(t *testing.T) {
+		err := ovs.UpdateMonitorConditions("Logical_Switch", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, ovs.Cache.Table("Logical_Switch").Len())
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		assert.JSONEq(t, `{"Logical_Switch":[{"where":[]}]}`, string(server.condChanges[len(server.condChanges)-1][2]))
+	})
+}
+
+func TestMonitorCondSince(t *testing.T) {
+	requests := map[string]ovsdb.MonitorCondRequest{"Logical_Switch": {}}
+	ovs, server, err := newTestDatabaseClient(t)
+	assert.Nil(t, err)
+	assert.Equal(t, ovsdb.ZeroTransactionID, ovs.LastTransactionID())
+
+	err = ovs.MonitorCondSince("since", requests)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, ovs.Cache.Table("Logical_Switch").Len())
+	assert.Equal(t, "txn1", ovs.LastTransactionID())
+
+	var reply []interface{}
+	err = server.server.Call("update3", []interface{}{"since", "txn2", ovsdb.TableUpdates2{
+		"Logical_Switch": {aUUID2: &ovsdb.RowUpdate2{Insert: &ovsdb.Row{"name": "baz"}}},
+	}}, &reply)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, ovs.Cache.Table("Logical_Switch").Len())
+	assert.Equal(t, "txn2", ovs.LastTransactionID())
+
+	_, _, err = newTestDatabaseClient(t, WithResume(ovs))
+	assert.NotNil(t, err, "the client to resume from must be disconnected")
+	ovs.Disconnect()
+
+	t.Run("MonitorCondSince: resume with the changes since the last transaction", func(t *testing.T) {
+		resumed, server, err := newTestDatabaseClient(t, WithResume(ovs))
+		assert.Nil(t, err)
+		assert.True(t, ovs.Cache == resumed.Cache, "the cache is reused")
+		server.mutex.Lock()
+		server.condSince = func(lastTxnID string) ovsdb.MonitorCondSinceReply {
+			return ovsdb.MonitorCondSinceReply{
+				Found:             true,
+				LastTransactionID: "txn3",
+				Updates: ovsdb.TableUpdates2{
+					"Logical_Switch": {aUUID1: &ovsdb.RowUpdate2{Delete: &ovsdb.Row{}}},
+				},
+			}
+		}
+		server.mutex.Unlock()
+		err = resumed.MonitorCondSince("since", requests)
+		assert.Nil(t, err)
+		server.mutex.Lock()
+		assert.Equal(t, []string{"txn2"}, server.condSinceTxnIDs)
+		server.mutex.Unlock()
+		lsCache := resumed.Cache.Table("Logical_Switch")
+		assert.Equal(t, 2, lsCache.Len())
+		assert.NotNil(t, lsCache.Row(aUUID0))
+		assert.NotNil(t, lsCache.Row(aUUID2))
+		assert.Equal(t, "txn3", resumed.LastTransactionID())
+		resumed.Disconnect()
+
+		// The transaction is too old, the cache is replaced by the full contents of the table
+		again, server, err := newTestDatabaseClient(t, WithResume(resumed))
+		assert.Nil(t, err)
+		server.mutex.Lock()
+		server.condSince = func(lastTxnID string) ovsdb.MonitorCondSinceReply {
+			return ovsdb.MonitorCondSinceReply{
+				LastTransactionID: "txn9",
+				Updates: ovsdb.TableUpdates2{
+					"Logical_Switch": {
+						aUUID0: &ovsdb.RowUpdate2{Initial: &ovsdb.Row{"name": "foo"}},
+						aUUID3: &ovsdb.RowUpdate2{Initial: &ovsdb.Row{"name": "qux"}},
+					},
+				},
+			}
+		}
+		server.mutex.Unlock()
+		err = again.MonitorCondSince("since", requests)
+		assert.Nil(t, err)
+		server.mutex.Lock()
+		assert.Equal(t, []string{"txn3"}, server.condSinceTxnIDs)
+		server.mutex.Unlock()
+		lsCache = again.Cache.Table("Logical_Switch")
+		assert.Equal(t, 2, lsCache.Len())
+		assert.NotNil(t, lsCache.Row(aUUID0))
+		assert.NotNil(t, lsCache.Row(aUUID3))
+		assert.Equal(t, "txn9", again.LastTransactionID())
+
+		// The conditions of the monitor can be changed
+		assert.Nil(t, again.UpdateMonitorConditions("Logical_Switch", nil))
+	})
+
+	t.Run("MonitorCondSince: a different schema is not resumed", func(t *testing.T) {
+		previous, _, err := newTestDatabaseClient(t)
+		assert.Nil(t, err)
+		previous.Schema.Version = "0.0.1"
+		previous.Disconnect()
+		fresh, _, err := newTestDatabaseClient(t, WithResume(previous))
+		assert.Nil(t, err)
+		assert.True(t, fresh.Cache != previous.Cache, "the cache of a different schema is not reused")
+		assert.Equal(t, ovsdb.ZeroTransactionID, fresh.LastTransactionID())
+	})
+}
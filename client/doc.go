@@ -27,6 +27,110 @@ A client instance is created by simply specifying the connection information and
 
      ovs, _ := client.Connect("tcp:172.18.0.4:6641", dbModel, nil)
 
+Additional options can be passed to Connect(). For instance, the version of the schema the model was built
+against can be checked against the one reported by the server:
+
+     ovs, err := client.Connect("tcp:172.18.0.4:6641", dbModel, nil, client.WithSchemaVersion("5.31.0"))
+
+To connect to ssl endpoints with a client certificate, WithClientCert() loads it along with the CA certificates the
+server certificate is verified against, and sets the server name sent for SNI. WithTLSConfig() provides the whole
+TLS configuration instead. A failed handshake returns an ErrTLSHandshake error, unlike failing to connect at all:
+
+     ovs, err := client.Connect("ssl:172.18.0.4:6641", dbModel, nil,
+     	client.WithClientCert("client.crt", "client.key", "ca.crt", "ovn-central.example"))
+
+An Observer can be registered with WithObserver() to be notified before and after every transaction, along with
+its duration and whether it failed (e.g: to collect latency metrics).
+
+For post-mortem debugging, WithTransactionHistory() makes the client keep the last transactions it performed, along
+with their results, errors and timestamps, which are returned by TransactionHistory(). The operations can be logged
+as they are sent to the server, in indented JSON with a stable ordering, with ovsdb.Operations:
+
+	for _, record := range ovs.TransactionHistory() {
+		log.Printf("transaction failed: %v\n%s", record.Error, ovsdb.Operations(record.Operations))
+	}
+
+Connections that may die silently (e.g: behind a NAT) can be checked with WithKeepalive(), which sends an echo
+request periodically and, if its reply does not arrive in time, calls a failure callback and closes the connection
+so the handlers are notified of the disconnection. To keep requests from hanging on a server that stopped
+responding, WithRequestTimeout() makes every RPC fail with a context.DeadlineExceeded error if its reply does not
+arrive in time, and WithMaxInflight() bounds the number of RPCs waiting for their reply. After a few consecutive
+timeouts (see WithMaxTimeouts()), the connection is closed, failing the RPCs still waiting. Until then, the RPCs
+that timed out stay pending as their reply may still arrive. The messages received are
+decoded as they are read, whatever their size, unless WithMaxMessageSize() bounds it: a larger message closes the
+connection, and the RPCs waiting for their reply fail with an ErrMessageTooLarge error.
+
+Large databases can take long to be cached when monitored. WithStreamingInitialDump() makes Monitor(),
+MonitorCond() and MonitorDatabase() deliver their initial contents to the cache in batches of rows as the reply is
+read from the connection, so event handlers start to receive them before the whole reply is. Until the last batch
+is delivered, List() and Get() return an ErrCacheSyncing error along with the rows cached so far, and handlers with
+an InitialSyncCompleteFunc are notified once it is. E.g:
+
+	ovs, _ := client.Connect("tcp:172.18.0.4:6641", dbModel, nil, client.WithStreamingInitialDump(1000))
+	ovs.Cache.AddEventHandler(&cache.EventHandlerFuncs{
+		InitialSyncCompleteFunc: func() { log.Print("cache synced") },
+	})
+	err := ovs.MonitorAll("")
+
+A single client can also connect to additional databases over the same connection with WithDatabase(). Each of
+them gets its own cache, accessed with DatabaseCache(), and API, accessed with DatabaseAPI(). MonitorDatabase()
+and MonitorAllDatabase() monitor them, TransactDatabase() performs operations on them and Transact() performs them
+on the database they are valid for, failing if they are valid for several of them (e.g: both define the table).
+The json context of a monitor cannot be used by another one, whatever their databases. E.g:
+
+	ovs, _ := client.Connect("tcp:172.18.0.4:6641", nbModel, nil, client.WithDatabase(sbModel))
+	err := ovs.MonitorAllDatabase("OVN_Southbound", "sb")
+	ops, err := ovs.DatabaseAPI("OVN_Southbound").Create(&Chassis{Name: "chassis1"})
+	reply, err := ovs.Transact(ops...)
+
+A single connection limits the write throughput, as the server handles its transactions one at a time. NewPool()
+opens several connections to the same server: Transact() sends transactions over them in turn, while the monitors,
+the cache, the locks and the rest of the API use the first one. Transactions that assert a lock are sent over the
+first connection, which owns it. OVSDB does not order transactions sent over different connections, so operations
+that depend on each other must be sent in the same transaction. E.g:
+
+	pool, err := client.NewPool("tcp:172.18.0.4:6641", 4, dbModel, nil)
+	err = pool.MonitorAll("")
+	reply, err := pool.Transact(ops...)
+
+MonitorCond() only monitors the rows that match any of the conditions of each table. UpdateMonitorConditions()
+changes them: once the server acknowledges the change, the cached rows that no longer match are removed and the
+server sends the rows that started matching. E.g:
+
+	err := ovs.MonitorCond("cond", map[string]ovsdb.MonitorCondRequest{
+		"Logical_Switch": {Where: []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, "foo")}},
+	})
+	err = ovs.UpdateMonitorConditions("Logical_Switch", []ovsdb.Condition{
+		ovsdb.NewCondition("name", ovsdb.ConditionEqual, "bar"),
+	})
+
+MonitorCondSince() is like MonitorCond(), but it records the id of the last transaction it received updates for.
+A client connected with WithResume() reuses the cache of a disconnected one along with that id, so its
+MonitorCondSince() only gets the changes made since then instead of the full contents of the tables. If the server
+no longer knows that transaction, it sends the full contents and the cache is updated to match them. E.g:
+
+	ovs.Disconnect()
+	ovs, err = client.Connect(endpoints, dbModel, nil, client.WithResume(ovs))
+	err = ovs.MonitorCondSince("since", requests)
+
+MonitorCondSinceDatabase() does the same on one of the additional databases, whose last transaction id is
+recorded apart from the one of the database the client was connected to (see LastTransactionIDDatabase()).
+
+Disconnect() closes the connection right away, failing the transactions waiting for their reply. Shutdown() lets
+them complete first, up to the given context's deadline, and rejects new ones with ErrShutdown. The transactions
+that did not complete in time are returned, so the caller can decide whether to retry them. E.g:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	pending, err := ovs.Shutdown(ctx)
+
+Columns whose UUIDs are weak references (see mapper.MapperInfo's WeakReference) are cleared by the server when the
+referenced rows are deleted, so setting them to rows that do not exist is silently undone. WithWeakReferenceWarning()
+reports such references, found in the operations built by the client, as ErrDanglingWeakReference errors.
+
+Columns that the schema declares as immutable cannot be changed by Update, UpdateFunc or Reconcile: by default
+they fail with an ErrImmutableColumn error. WithSkipImmutableColumns() makes them silently drop those columns instead.
+
 Main API
 
 After creating a OvsdbClient using the Connect() function, we can use a number of CRUD-like
@@ -40,6 +144,34 @@ In terms of return values, some of these functions like Create(), Update(), Muta
 interact with the database so they return list of ovsdb.Operation objects that can be grouped together
 and passed to client.Transact().
 
+Operations built by different calls can be combined and dispatched with a single explicit call to
+TransactContext(), which validates them, honors the context's deadline and reports the failed operations. E.g:
+
+	ops, err := ovs.Create(&lsp)
+	lsOps, err := ovs.Where(&ls).Mutate(&ls, mutation)
+	results, err := ovs.TransactContext(ctx, append(ops, lsOps...)...)
+
+The cache is updated when the monitors deliver the updates of a transaction, which the server may send after
+replying to it. TransactAndWait() performs the operations like TransactContext() and then blocks until the cache
+reflects them, so they can be read back right away. It adds selects of the _uuid and _version columns to the
+transaction to learn the rows that are deleted and the versions the modified rows will have, and waits for the
+inserted rows to be added, the deleted ones to be removed and, if their model maps the _version column, the
+modified ones to have their new version:
+
+	results, err := ovs.TransactAndWait(ctx, ops...)
+	err = ovs.Get(&lsp)
+
+Alternatively, ApplyOptimistic() patches the cache right away with the changes of the committed operations: inserted
+rows are added with the UUIDs returned by the server and the cached rows matching the conditions of the other
+operations are updated, mutated or deleted. The patched rows are marked as optimistic until the server updates
+them, at which point its update is applied to the rows they had before the patch, so the server's view prevails
+whenever it differs. An operation whose conditions match a different number of cached rows than the server changed,
+e.g: because the cache is behind, is not applied and is reported by an ErrOptimisticConflict error. If the server
+will never update the patched rows, RevertOptimistic() on the cache restores them:
+
+	results, err := ovs.TransactContext(ctx, ops...)
+	err = ovs.ApplyOptimistic(ops, results)
+
 Others, such as List() and Get(), interact with the client's internal cache and are able to
 return Model instances (or a list thereof) directly.
 
@@ -75,6 +207,11 @@ two statements are equivalent:
 		Value: "myUUID"},
 	    })
 
+The columns of the index that was used can be inspected with SelectedIndex(), e.g: to debug which row a condition
+matches:
+
+	index := ovs.Where(ls).SelectedIndex() // []string{"_uuid"}
+
 Where() accepts multiple Condition instances (through variadic arguments).
 If provided, the client will generate multiple operations each matching one condition.
 For example, the following operation will delete all the Logical Switches named "foo" OR "bar":
@@ -91,6 +228,48 @@ For example, the following operation will delete all the Logical Switches named
 	}).Delete()
 
 To create a Condition that matches all of the conditions simultaneously (i.e: AND semantics), use WhereAll().
+Several conditions can be on the same field, e.g: to match a range of values:
+
+	ops, err := ovs.WhereAll(&acl,
+		model.Condition{Field: &acl.Priority, Function: ovsdb.ConditionGreaterThanOrEqual, Value: 100},
+		model.Condition{Field: &acl.Priority, Function: ovsdb.ConditionLessThanOrEqual, Value: 200},
+	).Delete()
+
+Callers that do not know the concrete Model type (e.g: generic tooling) can build the conditions from column
+names with ConditionsFromColumns(), which returns a new Model of the table along with the Conditions on its fields:
+
+	m, conditions, err := ovs.ConditionsFromColumns("Logical_Switch", map[string]client.ColumnCondition{
+		"name": {Function: ovsdb.ConditionEqual, Value: "foo"},
+	})
+	ops, err := ovs.WhereAll(m, conditions...).Delete()
+
+Such tooling can read the columns of a table and their schema (type, minimum and maximum number of elements,
+enums and other constraints) with TableColumns() and ColumnSchema():
+
+	for _, name := range ovs.TableColumns("Logical_Switch") {
+		column, err := ovs.ColumnSchema("Logical_Switch", name)
+		...
+	}
+
+UnmappedColumns() returns the columns of a table that its Model does not map, e.g: to detect, when the schema
+evolves, the columns that are silently ignored instead of being read and written:
+
+	unmapped, err := ovs.UnmappedColumns("Logical_Switch") // []string{"copp"}
+
+Rows obtained out-of-band (e.g: from the snapshot of another system) can be decoded into the Model of their table with
+ModelFromRow(). Only the columns present in the row are set, so partial rows are supported:
+
+	var row ovsdb.Row
+	err := json.Unmarshal([]byte(`{"name": "foo", "external_ids": ["map", [["owner", "bar"]]]}`), &row)
+	m, err := ovs.ModelFromRow("Logical_Switch", row)
+	ls := m.(*MyLogicalSwitch)
+
+WhereColumn() builds a condition on a column given by its name, validated against the schema, so a Model that does not
+map every column can still be used to target rows by the ones it omits. The cache can only be searched with it if the
+Model maps the column. E.g:
+
+	ops, err := ovs.WhereColumn("Logical_Switch", "external_ids", ovsdb.ConditionIncludes,
+		map[string]string{"owner": "foo"}).Delete()
 
 Where() and WhereAll() inject conditions into operations that will be evaluated by the server.
 However, to perform searches on the local cache, a more flexible mechanism is available: WhereCache()
@@ -109,6 +288,36 @@ cache element, an operation will be created matching on the "_uuid" column. The
 quite large depending on the cache size and the provided function. Most likely there is a way to express the
 same condition using Where() or WhereAll() which will be more efficient.
 
+Generating those operations evaluates the function on every cached row of the table, which can take a while on
+huge tables. WithContext() bounds it: once the context is done, the scan stops and an ErrGenerateCanceled error
+reports how many rows were scanned and matched. E.g:
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ops, err := ovs.WhereCache(predicate).WithContext(ctx).Delete()
+
+For the common case of matching on string fields, WhereFieldContains() and WhereFieldMatches()
+build such cache-side conditions without having to write the function. E.g:
+
+	ls := &LogicalSwitch{}
+	err := ovs.WhereFieldMatches(ls, &ls.Name, "ext_*").List(lsList)
+
+WhereIgnoreCase() matches the elements holding the index values of a Model, as Where() does, but comparing strings
+case-insensitively, so a name whose case is inconsistent can still be found. As OVSDB conditions are case-sensitive,
+the operations are generated by matching the matched elements by _uuid:
+
+	err := ovs.WhereIgnoreCase(&LogicalSwitch{Name: "EXT_switch"}).List(lsList)
+
+Similarly, WhereMapHasKey() matches the elements whose map field has a given key, whatever its value:
+
+	err := ovs.WhereMapHasKey(ls, &ls.ExternalIDs, "owner").List(lsList)
+
+WhereMapValue() matches the elements whose map field has a given key set to a given value. Unlike the
+above, it is equivalent to an RFC7047 condition (the map includes the key-value pair), which the
+operations are generated with:
+
+	err := ovs.WhereMapValue(ls, &ls.ExternalIDs, "env", "prod").List(lsList)
+
 Get
 
 Get() operation is a simple operation capable of retrieving one Model based on some of its indexes. E.g:
@@ -117,6 +326,15 @@ Get() operation is a simple operation capable of retrieving one Model based on s
 	err := ovs.Get(ls)
 	fmt.Printf("Name of the switch is: &s", ls.Name)
 
+If more than one cached row matches the Model, Get() fails with an ErrMultipleMatches error holding their UUIDs and
+the index they were matched by.
+
+GetReferenced() resolves the UUIDs held by a field whose column references another table, as per the schema, into
+the cached rows of that table, in the order they are held. Rows that are not cached are skipped. E.g:
+
+	var ports []LogicalSwitchPort
+	err := ovs.GetReferenced(ls, &ls.Ports, &ports)
+
 List
 
 List() searches the cache and populates a slice of Models. It can be used directly or using WhereCache()
@@ -135,12 +353,109 @@ Create returns a list of operations to create the models provided. E.g:
 
 	ops, err := ovs.Create(&LogicalSwitch{Name:"foo")}, &LogicalSwitch{Name:"bar"})
 
+Models can enforce domain constraints beyond the ones of the schema by implementing model.Validator. Create,
+Update, UpdateFunc and Reconcile fail with an ErrInvalidModel error, wrapping the one returned by Validate, instead
+of building operations that would write an invalid model. E.g:
+
+	func (lsp *LogicalSwitchPort) Validate() error {
+		if lsp.Tag != nil && (*lsp.Tag < 1 || *lsp.Tag > 4094) {
+			return fmt.Errorf("invalid VLAN tag %d", *lsp.Tag)
+		}
+		return nil
+	}
+
+The content of the field associated with the "_uuid" column is used as named-uuid. To use a different one
+(e.g: because such field holds the real UUID), add WithNamedUUID after the model it applies to. The named-uuid
+can then be referenced by other operations of the same transaction. E.g:
+
+	ops, err := ovs.Create(&lsp, client.WithNamedUUID("newport"))
+
+Servers that support the non-standard "uuid" member of insert operations let the client choose the real UUID of
+the inserted row with WithRealUUID, e.g: to keep the UUIDs of rows that are recreated. Other servers fail the
+transaction, which RealUUIDUnsupported detects so the row can be created without it. E.g:
+
+	ops, err := ovs.Create(&lsp, client.WithRealUUID(uuid))
+
+Columns holding UUIDs also accept ovsdb.UUID values, which are sent as named-uuid references when they do not
+hold a valid UUID. Transact fails if a named-uuid is not declared by any insert operation of the transaction. E.g:
+
+	ops, err := ovs.Where(&ls).Mutate(&ls, model.Mutation{
+		Field:   &ls.Ports,
+		Mutator: ovsdb.MutateOperationInsert,
+		Value:   []ovsdb.UUID{{GoUUID: "newport"}},
+	})
+
+Inserting a row that holds the same index values as an existing one, e.g: created concurrently by another client,
+makes the transaction fail with a constraint violation, which IndexConflict detects. With CreateOrGet following a
+model, passing the error of the transaction to the option's Resolve populates the model with the existing row
+instead, waiting for it to be cached if needed. E.g:
+
+	existing := client.CreateOrGet()
+	ops, err := ovs.Create(&ls, existing)
+	_, err = ovs.Transact(ops...)
+	err = existing.Resolve(ctx, err)
+	if existing.Existed() {
+		fmt.Printf("switch %s already exists", ls.UUID)
+	}
+
+Upsert sets a row to the state of a model, creating it if absent: it returns an update of the columns that differ if
+a cached row holds the same index values as the model, and its insert otherwise. UpsertGuarded precedes the insert
+with wait operations that make the transaction fail if another client created the row in the meantime. E.g:
+
+	ops, err := ovs.UpsertGuarded(&LogicalSwitch{Name: "foo", Config: config})
+	reply, err := ovs.Transact(ops...)
+
+CheckIndexes reports, as an ErrIndexCollision error, the models that hold the same values in the columns of an index
+as each other or as cached rows (e.g: two ports with the same name), so duplicates can be fixed before creating them:
+
+	err := ovs.CheckIndexes(&lsp0, &lsp1)
+
+SwapIndex returns the operations that swap the values of a string column between two cached rows, going through a
+temporary value so a unique index on the column is never violated in between. E.g: to swap the names of two ports:
+
+	ops, err := ovs.SwapIndex(&lsp0, &lsp1, &lsp0.Name)
+
+Rows created in a subsequent transaction can reference the inserted ones through their real UUIDs, which
+TransactModels sets in the models provided to it and returns through the result's UUIDFor. E.g:
+
+	result, err := ovs.TransactModels(ops, ls)
+	lsUUID, ok := result.UUIDFor(ls)
+
+The rows returned by the select operations of a transaction performed with TransactModels can be decoded into
+Models, like List does with the cached ones, with the result's Models. E.g:
+
+	var lsList []LogicalSwitch
+	err := result.Models("Logical_Switch", &lsList)
+
 Update
 Update returns a list of operations to update the matching rows to match the values of the provided model. E.g:
 
 	ls := &LogicalSwitch{ExternalIDs: map[string]string {"foo": "bar"}}
 	ops, err := ovs.Where(...).Update(&ls, &ls.ExternalIDs}
 
+The selected fields are updated even if they hold their default value, whereas the other ones are left out of the
+row. A nil or empty optional field can thus be selected to clear its column, which is sent as the empty set,
+along with the columns being set. E.g:
+
+	lsp := &LogicalSwitchPort{Type: "router", Tag: nil}
+	ops, err := ovs.Where(lsp).Update(lsp, &lsp.Type, &lsp.Tag)
+
+Models can map the "_version" column, which changes on every modification of a row, like the "_uuid" one. It is
+never written, but it is cached when the server sends it (i.e: when it is included in the monitored columns) and
+conditions can target it, so an update can be made to only apply if the row was not modified since it was read,
+without a separate lock. E.g:
+
+	ops, err := ovs.Where(ls, model.Condition{
+		Field:    &ls.Version,
+		Function: ovsdb.ConditionEqual,
+		Value:    ls.Version,
+	}).Update(ls, &ls.Name)
+
+ClearFields returns the operations needed to set some columns back to their default value (the empty set or map,
+the empty string, zero or false), which makes "unset" operations explicit. E.g:
+
+	ops, err := ovs.Where(lsp).ClearFields(lsp, &lsp.Tag, &lsp.ExternalIDs)
+
 Mutate
 
 Mutate returns a list of operations needed to mutate the matching rows as described by the list of Mutation objects. E.g:
@@ -152,11 +467,106 @@ Mutate returns a list of operations needed to mutate the matching rows as descri
 		Value:   map[string]string{"foo":"bar"},
 	})
 
+Inserting a key that is already present in a map does not modify its value. To replace the value of a key,
+model.NewMapValueReplaceMutations returns a delete mutation followed by an insert mutation. OVSDB applies the
+mutations of an operation in order, so passing both to the same Mutate call replaces the value atomically. E.g:
+
+	mutations, err := model.NewMapValueReplaceMutations(&ls.Config, "foo", "baz")
+	ops, err := ovs.Where(...).Mutate(&ls, mutations...)
+
+Unlike an Update of a set column, which replaces the whole set, AddToSet and RemoveFromSet build the mutations
+that add or remove some elements, validated against the type of the set's elements, so concurrent changes to
+the other elements are preserved. E.g:
+
+	ops, err := ovs.Where(ls).AddToSet(ls, &ls.Ports, lsp1.UUID, lsp2.UUID)
+	ops, err = ovs.Where(ls).RemoveFromSet(ls, &ls.Ports, lsp0.UUID)
+
+The mutations of operations built by hand can be expressed with field pointers too, with ToOvsdbMutation. E.g:
+
+	mutation, err := ovs.ToOvsdbMutation(&ls, model.Mutation{
+		Field:   &ls.Config,
+		Mutator: ovsdb.MutateOperationDelete,
+		Value:   []string{"foo"},
+	})
+
 Delete
 
 Delete returns a list of operations needed to delete the matching rows. E.g:
 
 	ops, err := ovs.Where(...).Delete()
 
+A condition that accidentally matches a whole table (e.g: a predicate that always returns true) generates an
+operation for each of its rows. MaxAffectedRows makes Delete, Update, UpdateFunc and Mutate fail instead if more
+cached rows than allowed match the condition. It is off by default, but its use is strongly recommended for
+destructive operations. E.g:
+
+	ops, err := ovs.WhereCache(func(ls *LogicalSwitch) bool { return ls.Config["stale"] == "true" }).MaxAffectedRows(10).Delete()
+
+Reconcile
+
+Reconcile returns the operations needed to make the cached rows of a table match a list of desired models:
+inserts for the new ones, updates of the changed columns for the existing ones and deletes for the cached rows
+that are not desired. Models are matched by index unless a key function is provided. E.g:
+
+	ops, err := ovs.Reconcile("Logical_Switch", desired,
+		client.WithReconcileKey(func(m model.Model) string { return m.(*LogicalSwitch).ExternalIDs["id"] }),
+		client.WithReconcileIgnoreColumns("other_config"))
+
+CountFromServer
+
+CountFromServer returns the number of rows of a table that match some conditions, as counted by the server, without
+populating the cache. OVSDB has no count operation, so a select of only the _uuid column is sent and the returned rows
+are counted: there is no fallback to counting the cache, and errors reported by the server are returned. E.g:
+
+	count, err := ovs.CountFromServer(ctx, "Logical_Switch", []ovsdb.Condition{
+		ovsdb.NewCondition("name", ovsdb.ConditionNotEqual, "foo"),
+	})
+
+WaitForCache
+
+WaitForCache blocks until a cached element satisfies a predicate (as accepted by WhereCache) or the context is
+done. The current cache contents are checked first and then the cache events are watched, so no polling is needed. E.g:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := ovs.WaitForCache(ctx, func(ls *LogicalSwitch) bool { return ls.Name == "foo" })
+
+WatchModel delivers the states of a single cached row, found like Get does: its current state first and then its
+state after every update, until it is deleted or the watch is canceled. E.g:
+
+	states, cancel, err := ovs.WatchModel(&LogicalSwitch{Name: "foo"})
+	defer cancel()
+	for ls := range states {
+		fmt.Printf("ports of the switch: %v", ls.(*LogicalSwitch).Ports)
+	}
+
+Assert
+
+Assert returns an operation that makes the whole transaction fail if the client does not own the given lock.
+In that case, ovsdb.CheckOperationResults returns an ovsdb.ErrLockNotHeld error for it. E.g:
+
+	ops := append([]ovsdb.Operation{ovs.Assert("leader")}, updateOps...)
+	reply, err := ovs.Transact(ops...)
+
+The operations built from a ConditionalAPI can be preceded by comment and assert operations chained with Comment()
+and AssertLock(), so audited, lock-guarded writes read fluently. The comment and assert operations always come first,
+in the order they were chained, followed by the built operations. They are left out if no operation is built. E.g:
+
+	ops, err := ovs.Where(ls).Comment("disabled by the operator").AssertLock("leader").Update(ls, &ls.Config)
+	reply, err := ovs.Transact(ops...)
+
+Idempotent
+
+A transaction whose reply is lost may have been applied, so retrying it could apply it twice. Idempotent wraps its
+operations so that the retry changes nothing: the given key is stored in the external_ids column of the rows matched
+by the condition, and the transaction fails if any of them already holds it. AlreadyApplied tells such failure apart
+from others. E.g:
+
+	ops, err := ovs.Where(&LogicalSwitch{UUID: lsUUID}).Idempotent(requestID, portOps...)
+	reply, err := ovs.Transact(ops...)
+	if client.AlreadyApplied(ops, reply) {
+		// nothing to do
+	}
+
 */
 package client
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// TxnRecord describes a transaction performed by the client, see WithTransactionHistory
+type TxnRecord struct {
+	// Database is the database the transaction was performed on
+	Database string
+	// Operations are the operations of the transaction
+	Operations []ovsdb.Operation
+	// Results are the results returned by the server, if any
+	Results []ovsdb.OperationResult
+	// Error is the error returned by Transact or, if the transaction was executed but some
+	// operation failed, the error describing the failure
+	Error error
+	// Timestamp is the time the transaction was sent at
+	Timestamp time.Time
+	// Duration is the time elapsed until the transaction completed
+	Duration time.Duration
+}
+
+// transactionHistory is a ring buffer holding the last transactions performed by the client
+type transactionHistory struct {
+	mutex   sync.Mutex
+	records []TxnRecord
+	// next is the position the next record is written at
+	next int
+	full bool
+}
+
+func newTransactionHistory(size int) *transactionHistory {
+	return &transactionHistory{records: make([]TxnRecord, size)}
+}
+
+// add records a transaction, replacing the oldest one if the history is full
+func (h *transactionHistory) add(record TxnRecord) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list returns the recorded transactions, oldest first
+func (h *transactionHistory) list() []TxnRecord {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if !h.full {
+		return append([]TxnRecord{}, h.records[:h.next]...)
+	}
+	result := make([]TxnRecord, 0, len(h.records))
+	result = append(result, h.records[h.next:]...)
+	return append(result, h.records[:h.next]...)
+}
+
+// TransactionHistory returns the last transactions performed by the client, oldest first, if it was
+// configured to record them with WithTransactionHistory. Transactions still waiting for their reply
+// are not included
+func (ovs OvsdbClient) TransactionHistory() []TxnRecord {
+	if ovs.history == nil {
+		return nil
+	}
+	return ovs.history.list()
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"fmt"
+	"log"
+	"net"
+)
+
+// ErrMessageTooLarge is returned when a message received from the server exceeds the maximum size
+// set with WithMaxMessageSize. The connection is closed, so the requests waiting for their reply
+// fail with it
+type ErrMessageTooLarge struct {
+	Limit int
+}
+
+func (e *ErrMessageTooLarge) Error() string {
+	return fmt.Sprintf("message received from the server exceeds the maximum size of %d bytes", e.Limit)
+}
+
+// limitedConn is a net.Conn that fails reading a JSON-RPC message once it is larger than max
+// bytes. The messages are delimited by scanning the JSON values read, which are passed on as they
+// arrive, so they are still decoded as a stream
+type limitedConn struct {
+	net.Conn
+	max int
+	// size is the number of bytes read of the current message
+	size int
+	// depth is the number of objects and arrays of the current message left open
+	depth int
+	// inString and escaped track whether the bytes read are within a string, where braces and
+	// brackets do not delimit anything, and whether the previous one escapes the next
+	inString bool
+	escaped  bool
+	err      error
+}
+
+func newLimitedConn(conn net.Conn, max int) *limitedConn {
+	return &limitedConn{Conn: conn, max: max}
+}
+
+// Read reads from the connection, up to the byte that makes the current message exceed the
+// maximum size, after which an ErrMessageTooLarge error is returned
+func (c *limitedConn) Read(b []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.Conn.Read(b)
+	for i := 0; i < n; i++ {
+		if !c.scan(b[i]) {
+			c.err = &ErrMessageTooLarge{Limit: c.max}
+			log.Printf("closing the connection: %s", c.err)
+			return i, c.err
+		}
+	}
+	return n, err
+}
+
+// scan accounts for the next byte read and returns false if the current message exceeds the
+// maximum size with it
+func (c *limitedConn) scan(b byte) bool {
+	if c.depth == 0 {
+		// Between messages
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			return true
+		}
+		c.size = 0
+	}
+	c.size++
+	if c.size > c.max {
+		return false
+	}
+	switch {
+	case c.inString && c.escaped:
+		c.escaped = false
+	case c.inString && b == '\\':
+		c.escaped = true
+	case b == '"':
+		c.inString = !c.inString
+	case c.inString:
+	case b == '{' || b == '[':
+		c.depth++
+	case (b == '}' || b == ']') && c.depth > 0:
+		c.depth--
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitedConn(t *testing.T) {
+	large := `{"id":2,"result":"` + strings.Repeat("x", 100) + `"}`
+	tests := []struct {
+		name     string
+		stream   string
+		messages int
+		tooLarge bool
+	}{
+		{
+			name:     "messages within the limit",
+			stream:   `{"id":1,"result":[]} {"id":2,"result":{"a":["b"]}}` + "\n" + `[1,2]`,
+			messages: 3,
+		},
+		{
+			name:     "delimiters within strings",
+			stream:   `{"id":1,"result":"}}]] \"{{"}` + `{"id":2,"result":"\\"}`,
+			messages: 2,
+		},
+		{
+			name:     "message exceeding the limit",
+			stream:   `{"id":1,"result":[]}` + large + `{"id":3,"result":[]}`,
+			messages: 1,
+			tooLarge: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("LimitedConn: %s", tt.name), func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			go func() {
+				// Write in small chunks so messages span several reads
+				for i := 0; i < len(tt.stream); i += 7 {
+					end := i + 7
+					if end > len(tt.stream) {
+						end = len(tt.stream)
+					}
+					if _, err := server.Write([]byte(tt.stream[i:end])); err != nil {
+						return
+					}
+				}
+				server.Close()
+			}()
+			dec := json.NewDecoder(newLimitedConn(client, 64))
+			messages := 0
+			var err error
+			for {
+				var msg interface{}
+				if err = dec.Decode(&msg); err != nil {
+					break
+				}
+				messages++
+			}
+			assert.Equal(t, tt.messages, messages)
+			var tooLarge *ErrMessageTooLarge
+			if tt.tooLarge {
+				assert.True(t, errors.As(err, &tooLarge), "expected a message size error, got %v", err)
+				assert.Equal(t, 64, tooLarge.Limit)
+			} else {
+				assert.False(t, errors.As(err, &tooLarge), "unexpected message size error")
+			}
+		})
+	}
+}
+
+func TestMaxMessageSize(t *testing.T) {
+	_, err := newOptions(WithMaxMessageSize(0))
+	assert.NotNil(t, err)
+
+	ovs, _, err := newTestDatabaseClient(t, WithMaxMessageSize(1<<20))
+	assert.Nil(t, err)
+	assert.Nil(t, ovs.Echo())
+
+	// The reply holding the schema exceeds the limit
+	_, _, err = newTestDatabaseClient(t, WithMaxMessageSize(128))
+	var tooLarge *ErrMessageTooLarge
+	assert.True(t, errors.As(err, &tooLarge), "expected a message size error, got %v", err)
+}
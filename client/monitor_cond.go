@@ -0,0 +1,281 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/mapper"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// condMonitor holds the json context of a conditional monitor and the current
+// conditions of each of its tables
+type condMonitor struct {
+	jsonContext interface{}
+	conditions  map[string][]ovsdb.Condition
+}
+
+// MonitorCond is like Monitor, but only the rows that match the conditions of each
+// request are monitored. They can later be changed with UpdateMonitorConditions
+// ovsdb-server(7) : monitor_cond
+func (ovs OvsdbClient) MonitorCond(jsonContext interface{}, requests map[string]ovsdb.MonitorCondRequest) error {
+	var reply ovsdb.TableUpdates2
+
+	if err := ovs.addMonitor(jsonContext, ovs.Schema.Name); err != nil {
+		return err
+	}
+	args := ovsdb.NewMonitorCondArgs(ovs.Schema.Name, jsonContext, requests)
+	var err error
+	if ovs.streamBatch > 0 {
+		err = ovs.monitorStreaming(ovs.Cache, "monitor_cond", args)
+	} else if err = ovs.call(context.Background(), "monitor_cond", args, &reply); err == nil {
+		ovs.Cache.Populate2(reply)
+	}
+	if err != nil {
+		ovs.removeMonitor(jsonContext)
+		return err
+	}
+	ovs.addCondMonitor(jsonContext, requests)
+	return nil
+}
+
+// MonitorCondSince is like MonitorCond, but the server is asked for the changes made since the
+// last transaction received by the monitors of the client (see LastTransactionID) instead of the
+// full contents of the tables, e.g: after reconnecting to a server with WithResume. If the server
+// cannot find that transaction, it sends the full contents of the tables instead, and the cached
+// rows of the monitored tables that are not part of them are removed. Servers that do not support
+// it (older than 2.12) fail the request, so MonitorCond has to be used instead
+// ovsdb-server(7) : monitor_cond_since
+func (ovs OvsdbClient) MonitorCondSince(jsonContext interface{}, requests map[string]ovsdb.MonitorCondRequest) error {
+	return ovs.monitorCondSince(ovs.Schema.Name, &ovs.Schema, ovs.Cache, ovs.lastTxnID, jsonContext, requests)
+}
+
+// MonitorCondSinceDatabase is like MonitorCondSince, but on the given database, which is either
+// the one the client was connected to or one of the additional ones (see WithDatabase). The id of
+// the last transaction received is recorded per database, see LastTransactionIDDatabase
+func (ovs OvsdbClient) MonitorCondSinceDatabase(dbName string, jsonContext interface{}, requests map[string]ovsdb.MonitorCondRequest) error {
+	if dbName == ovs.Schema.Name {
+		return ovs.MonitorCondSince(jsonContext, requests)
+	}
+	db, err := ovs.database(dbName)
+	if err != nil {
+		return err
+	}
+	return ovs.monitorCondSince(dbName, db.schema, db.cache, db.lastTxnID, jsonContext, requests)
+}
+
+// monitorCondSince requests a monitor_cond_since monitor on a database, resuming from the last
+// transaction it received
+func (ovs OvsdbClient) monitorCondSince(dbName string, schema *ovsdb.DatabaseSchema, tcache *cache.TableCache, lastTxnID *transactionID,
+	jsonContext interface{}, requests map[string]ovsdb.MonitorCondRequest) error {
+	var reply ovsdb.MonitorCondSinceReply
+
+	if err := ovs.addMonitor(jsonContext, dbName); err != nil {
+		return err
+	}
+	args := ovsdb.NewMonitorCondSinceArgs(dbName, jsonContext, requests, lastTxnID.get())
+	err := ovs.call(context.Background(), "monitor_cond_since", args, &reply)
+	if err != nil {
+		ovs.removeMonitor(jsonContext)
+		return err
+	}
+
+	ovs.handlersMutex.Lock()
+	if !reply.Found {
+		for table := range requests {
+			tableSchema := schema.Table(table)
+			current := reply.Updates[table]
+			tcache.PurgeWhere(table, func(m model.Model) bool {
+				info, err := mapper.NewMapperInfo(tableSchema, m)
+				if err != nil {
+					return false
+				}
+				uuid, err := info.FieldByColumn("_uuid")
+				if err != nil {
+					return false
+				}
+				_, ok := current[uuid.(string)]
+				return !ok
+			})
+		}
+	}
+	tcache.Populate2(reply.Updates)
+	lastTxnID.set(reply.LastTransactionID)
+	ovs.handlersMutex.Unlock()
+
+	ovs.addCondMonitor(jsonContext, requests)
+	return nil
+}
+
+// LastTransactionID returns the id of the last transaction whose updates were received by the
+// monitors created with MonitorCondSince, or ovsdb.ZeroTransactionID if there is none
+func (ovs OvsdbClient) LastTransactionID() string {
+	return ovs.lastTxnID.get()
+}
+
+// LastTransactionIDDatabase is like LastTransactionID, but for the monitors of the given database
+// created with MonitorCondSinceDatabase
+func (ovs OvsdbClient) LastTransactionIDDatabase(dbName string) string {
+	if dbName == ovs.Schema.Name {
+		return ovs.LastTransactionID()
+	}
+	db, err := ovs.database(dbName)
+	if err != nil {
+		return ovsdb.ZeroTransactionID
+	}
+	return db.lastTxnID.get()
+}
+
+// transactionID holds the id of the last transaction received by the monitors of a database
+type transactionID struct {
+	mutex sync.RWMutex
+	id    string
+}
+
+func (t *transactionID) get() string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	if t.id == "" {
+		return ovsdb.ZeroTransactionID
+	}
+	return t.id
+}
+
+func (t *transactionID) set(id string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.id = id
+}
+
+// addCondMonitor records the conditions of the tables of a conditional monitor
+func (ovs OvsdbClient) addCondMonitor(jsonContext interface{}, requests map[string]ovsdb.MonitorCondRequest) {
+	monitor := &condMonitor{
+		jsonContext: jsonContext,
+		conditions:  make(map[string][]ovsdb.Condition),
+	}
+	for table, request := range requests {
+		monitor.conditions[table] = request.Where
+	}
+	ovs.monitorsMutex.Lock()
+	defer ovs.monitorsMutex.Unlock()
+	ovs.condMonitors[fmt.Sprint(jsonContext)] = monitor
+}
+
+// UpdateMonitorConditions replaces the conditions of a table monitored with MonitorCond.
+// A row is monitored if it matches any of the conditions, or if there are none.
+// Once the server acknowledges the change, the cached rows that no longer match are removed
+// (generating delete events) and the server sends the rows that started matching.
+// Update notifications are applied in the order they are received, so the ones sent before
+// the change are processed before the cache is pruned, and the pruning is done while no
+// update notification is being processed
+// ovsdb-server(7) : monitor_cond_change
+func (ovs OvsdbClient) UpdateMonitorConditions(table string, conditions []ovsdb.Condition) error {
+	tableSchema := ovs.Schema.Table(table)
+	if tableSchema == nil {
+		return fmt.Errorf("table %s not found", table)
+	}
+	values := make([]interface{}, 0, len(conditions))
+	for _, condition := range conditions {
+		column := tableSchema.Column(condition.Column)
+		if column == nil {
+			return fmt.Errorf("column %s not found in table %s", condition.Column, table)
+		}
+		value, err := ovsdb.OvsToNative(column, condition.Value)
+		if err != nil {
+			return fmt.Errorf("condition on column %s: %w", condition.Column, err)
+		}
+		if err := ovsdb.ValidateCondition(column, condition.Function, value); err != nil {
+			return fmt.Errorf("condition on column %s: %w", condition.Column, err)
+		}
+		values = append(values, value)
+	}
+
+	monitor, err := ovs.condMonitorForTable(table)
+	if err != nil {
+		return err
+	}
+
+	// A null where would leave the conditions unchanged
+	if conditions == nil {
+		conditions = []ovsdb.Condition{}
+	}
+	var reply interface{}
+	args := ovsdb.NewMonitorCondChangeArgs(monitor.jsonContext, monitor.jsonContext,
+		map[string][]ovsdb.MonitorCondChangeRequest{table: {{Where: conditions}}})
+	err = ovs.call(context.Background(), "monitor_cond_change", args, &reply)
+	if err != nil {
+		return err
+	}
+
+	ovs.monitorsMutex.Lock()
+	monitor.conditions[table] = conditions
+	ovs.monitorsMutex.Unlock()
+
+	ovs.handlersMutex.Lock()
+	defer ovs.handlersMutex.Unlock()
+	ovs.Cache.PurgeWhere(table, func(m model.Model) bool {
+		matches, err := matchesMonitorConditions(tableSchema, m, conditions, values)
+		// Rows that cannot be evaluated are kept, the server will delete them if needed
+		return err == nil && !matches
+	})
+	return nil
+}
+
+// condMonitorForTable returns the conditional monitor that monitors a table
+func (ovs OvsdbClient) condMonitorForTable(table string) (*condMonitor, error) {
+	ovs.monitorsMutex.RLock()
+	defer ovs.monitorsMutex.RUnlock()
+	var found *condMonitor
+	for _, monitor := range ovs.condMonitors {
+		if _, ok := monitor.conditions[table]; !ok {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("table %s is monitored by more than one conditional monitor", table)
+		}
+		found = monitor
+	}
+	if found == nil {
+		return nil, fmt.Errorf("table %s is not monitored with conditions", table)
+	}
+	return found, nil
+}
+
+// clearCondMonitor forgets the conditions of a monitor
+func (ovs *OvsdbClient) clearCondMonitor(jsonContext interface{}) {
+	if len(ovs.condMonitors) == 0 {
+		return
+	}
+	ovs.monitorsMutex.Lock()
+	defer ovs.monitorsMutex.Unlock()
+	delete(ovs.condMonitors, fmt.Sprint(jsonContext))
+}
+
+// matchesMonitorConditions returns whether a model matches any of the conditions, whose
+// native values are provided, or true if there are none
+func matchesMonitorConditions(table *ovsdb.TableSchema, m model.Model, conditions []ovsdb.Condition, values []interface{}) (bool, error) {
+	if len(conditions) == 0 {
+		return true, nil
+	}
+	info, err := mapper.NewMapperInfo(table, m)
+	if err != nil {
+		return false, err
+	}
+	for i, condition := range conditions {
+		actual, err := info.FieldByColumn(condition.Column)
+		if err != nil {
+			return false, err
+		}
+		matches, err := ovsdb.EvaluateCondition(table.Column(condition.Column), condition.Function, actual, values[i])
+		if err != nil {
+			return false, err
+		}
+		if matches {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// monitorStreaming performs a monitor or monitor_cond request and delivers the initial contents
+// of the monitored tables held by its reply to the cache in batches of rows as they are read from
+// the connection, see WithStreamingInitialDump. The update notifications are read from the same
+// connection, so they are only handled once the reply is. The cache is syncing from the request
+// until the last batch is delivered, or the request fails
+func (ovs OvsdbClient) monitorStreaming(tcache *cache.TableCache, method string, args []interface{}) error {
+	tcache.StartInitialSync()
+	defer tcache.CompleteInitialSync()
+
+	stream := func(dec *json.Decoder) error {
+		if method == "monitor" {
+			return streamTableUpdates(dec, ovs.streamBatch, tcache)
+		}
+		return streamTableUpdates2(dec, ovs.streamBatch, tcache)
+	}
+	return ovs.call(context.Background(), method, &streamedArgs{args: args, stream: stream}, nil)
+}
+
+// streamTableUpdates delivers the rows of the ovsdb.TableUpdates read from the decoder to the
+// cache with Populate, batchSize rows at a time
+func streamTableUpdates(dec *json.Decoder, batchSize int, tcache *cache.TableCache) error {
+	batch := make(ovsdb.TableUpdates)
+	rows := 0
+	err := decodeTableUpdates(dec, func(table, uuid string, raw json.RawMessage) error {
+		var row ovsdb.RowUpdate
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return err
+		}
+		if batch[table] == nil {
+			batch[table] = make(ovsdb.TableUpdate)
+		}
+		batch[table][uuid] = &row
+		if rows++; rows == batchSize {
+			tcache.Populate(batch)
+			batch = make(ovsdb.TableUpdates)
+			rows = 0
+		}
+		return nil
+	})
+	if rows > 0 {
+		tcache.Populate(batch)
+	}
+	return err
+}
+
+// streamTableUpdates2 delivers the rows of the ovsdb.TableUpdates2 read from the decoder to the
+// cache with Populate2, batchSize rows at a time
+func streamTableUpdates2(dec *json.Decoder, batchSize int, tcache *cache.TableCache) error {
+	batch := make(ovsdb.TableUpdates2)
+	rows := 0
+	err := decodeTableUpdates(dec, func(table, uuid string, raw json.RawMessage) error {
+		var row ovsdb.RowUpdate2
+		if err := json.Unmarshal(raw, &row); err != nil {
+			return err
+		}
+		if batch[table] == nil {
+			batch[table] = make(ovsdb.TableUpdate2)
+		}
+		batch[table][uuid] = &row
+		if rows++; rows == batchSize {
+			tcache.Populate2(batch)
+			batch = make(ovsdb.TableUpdates2)
+			rows = 0
+		}
+		return nil
+	})
+	if rows > 0 {
+		tcache.Populate2(batch)
+	}
+	return err
+}
+
+// decodeTableUpdates reads an object of table updates from the decoder, which maps table names
+// to objects mapping the UUIDs of the rows to their updates, and calls decodeRow with each of
+// the row updates, in the order they are read. The object is read whole even if it is invalid
+// or a row update is rejected, in which case no further row is passed to decodeRow and a
+// rejectedResult error is returned. Other errors are those of the decoder
+func decodeTableUpdates(dec *json.Decoder, decodeRow func(table, uuid string, raw json.RawMessage) error) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		// No table updates
+		return nil
+	}
+	if token != json.Delim('{') {
+		return rejectValue(dec, token, 0, fmt.Errorf("invalid table updates: expected an object, got %v", token))
+	}
+	var rejected error
+	for dec.More() {
+		table, err := stringToken(dec)
+		if err != nil {
+			return err
+		}
+		if token, err = dec.Token(); err != nil {
+			return err
+		}
+		if token != json.Delim('{') {
+			return rejectValue(dec, token, 1, fmt.Errorf("invalid updates of table %s: expected an object, got %v", table, token))
+		}
+		for dec.More() {
+			uuid, err := stringToken(dec)
+			if err != nil {
+				return err
+			}
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+			if rejected != nil {
+				continue
+			}
+			if err := decodeRow(table, uuid, raw); err != nil {
+				rejected = fmt.Errorf("invalid update of row %s of table %s: %w", uuid, table, err)
+			}
+		}
+		// Closing brace of the table
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+	// Closing brace of the table updates
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	if rejected != nil {
+		return &rejectedResult{err: rejected}
+	}
+	return nil
+}
+
+// rejectValue reads the rest of a value from the decoder, of which the provided token was just
+// read within depth open objects and arrays, and returns the rejection as a rejectedResult error
+func rejectValue(dec *json.Decoder, token json.Token, depth int, rejection error) error {
+	if delim, ok := token.(json.Delim); ok && (delim == '{' || delim == '[') {
+		depth++
+	}
+	for depth > 0 {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+	return &rejectedResult{err: rejection}
+}
+
+// stringToken returns the next token of the decoder, which must be a string, e.g: an object key
+func stringToken(dec *json.Decoder) (string, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	s, ok := token.(string)
+	if !ok {
+		return "", fmt.Errorf("invalid table updates: expected a string, got %v", token)
+	}
+	return s, nil
+}
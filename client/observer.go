@@ -0,0 +1,32 @@
+package client
+
+import (
+	"time"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// Observer is notified of the transactions performed by the client, e.g: to collect latency metrics
+// It can be registered with the WithObserver option
+type Observer interface {
+	// TransactStart is called right before the transaction is sent to the server
+	TransactStart(operations []ovsdb.Operation)
+	// TransactEnd is called when the transaction completes. duration is the time elapsed since
+	// TransactStart was called. err is the error returned by Transact or, if the transaction was
+	// executed but some operation failed, the error describing the failure
+	TransactEnd(operations []ovsdb.Operation, result []ovsdb.OperationResult, err error, duration time.Duration)
+}
+
+// observeTransact calls fn to perform the transaction and notifies the observer about it
+func observeTransact(observer Observer, operations []ovsdb.Operation, fn func() ([]ovsdb.OperationResult, error)) ([]ovsdb.OperationResult, error) {
+	observer.TransactStart(operations)
+	start := time.Now()
+	reply, err := fn()
+	duration := time.Since(start)
+	observedErr := err
+	if observedErr == nil {
+		_, observedErr = ovsdb.CheckOperationResults(reply, operations)
+	}
+	observer.TransactEnd(operations, reply, observedErr, duration)
+	return reply, err
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/rpc2"
+	"github.com/cenkalti/rpc2/jsonrpc"
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+)
+
+type testObserver struct {
+	started  [][]ovsdb.Operation
+	ended    [][]ovsdb.Operation
+	results  [][]ovsdb.OperationResult
+	errs     []error
+	duration []time.Duration
+}
+
+func (o *testObserver) TransactStart(operations []ovsdb.Operation) {
+	o.started = append(o.started, operations)
+}
+
+func (o *testObserver) TransactEnd(operations []ovsdb.Operation, result []ovsdb.OperationResult, err error, duration time.Duration) {
+	o.ended = append(o.ended, operations)
+	o.results = append(o.results, result)
+	o.errs = append(o.errs, err)
+	o.duration = append(o.duration, duration)
+}
+
+// newTransactTestClient returns a client connected to a fake server that replies
+// to transact requests with the given results
+func newTransactTestClient(t *testing.T, observer Observer, results []ovsdb.OperationResult) *OvsdbClient {
+	clientConn, serverConn := net.Pipe()
+	server := rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(serverConn))
+	server.Handle("transact", func(_ *rpc2.Client, args []json.RawMessage, reply *[]ovsdb.OperationResult) error {
+		*reply = results
+		return nil
+	})
+	go server.Run()
+	t.Cleanup(func() { server.Close() })
+
+	tcache := apiTestCache(t)
+	ovs := newOvsdbClient()
+	ovs.observer = observer
+	ovs.Schema = *tcache.Mapper().Schema
+	ovs.rpcClient = rpc2.NewClientWithCodec(jsonrpc.NewJSONCodec(clientConn))
+	go ovs.rpcClient.Run()
+	t.Cleanup(func() { ovs.rpcClient.Close() })
+	return ovs
+}
+
+func TestObserverTransact(t *testing.T) {
+	operations := []ovsdb.Operation{
+		{Op: opInsert, Table: "Logical_Switch", Row: ovsdb.Row{"name": "foo"}},
+		{Op: opInsert, Table: "Logical_Switch", Row: ovsdb.Row{"name": "bar"}},
+	}
+	tests := []struct {
+		name    string
+		results []ovsdb.OperationResult
+		err     bool
+	}{
+		{
+			name:    "success",
+			results: []ovsdb.OperationResult{{UUID: ovsdb.UUID{GoUUID: aUUID0}}, {UUID: ovsdb.UUID{GoUUID: aUUID1}}},
+		},
+		{
+			name:    "operation error",
+			results: []ovsdb.OperationResult{{UUID: ovsdb.UUID{GoUUID: aUUID0}}, {Error: "constraint violation"}},
+			err:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("Observer: %s", tt.name), func(t *testing.T) {
+			observer := &testObserver{}
+			ovs := newTransactTestClient(t, observer, tt.results)
+
+			reply, err := ovs.Transact(operations...)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.results, reply)
+
+			assert.Equal(t, [][]ovsdb.Operation{operations}, observer.started)
+			assert.Equal(t, [][]ovsdb.Operation{operations}, observer.ended)
+			assert.Equal(t, [][]ovsdb.OperationResult{tt.results}, observer.results)
+			assert.Len(t, observer.duration, 1)
+			if tt.err {
+				assert.NotNil(t, observer.errs[0])
+			} else {
+				assert.Nil(t, observer.errs[0])
+			}
+		})
+	}
+}
+
+func TestObserverNotCalledOnValidationError(t *testing.T) {
+	observer := &testObserver{}
+	ovs := newTransactTestClient(t, observer, nil)
+	_, err := ovs.Transact(ovsdb.Operation{Op: opInsert, Table: "Unknown"})
+	assert.NotNil(t, err)
+	assert.Empty(t, observer.started)
+	assert.Empty(t, observer.ended)
+}
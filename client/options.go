@@ -0,0 +1,350 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"time"
+
+	"github.com/ovn-org/libovsdb/model"
+)
+
+// Option is used to configure the behavior of the client
+type Option func(o *options) error
+
+type options struct {
+	// schemaVersion is the version of the schema the database model was built against
+	schemaVersion string
+	// schemaVersionWarn makes a schema version mismatch be logged instead of failing
+	schemaVersionWarn bool
+	// unixSocketOwner holds the expected owner of unix sockets, if they have to be checked
+	unixSocketOwner *socketOwner
+	// observer is notified of the transactions performed by the client
+	observer Observer
+	// skipImmutable makes updates silently drop immutable columns instead of failing
+	skipImmutable bool
+	// weakRefWarn, if set, is called with the weak references to rows that are not cached
+	// found in the operations built by the client
+	weakRefWarn func(error)
+	// databases holds the models of the additional databases to connect to
+	databases []*model.DBModel
+	// keepalive, if set, configures the echo requests sent to detect dead connections
+	keepalive *keepalive
+	// requestTimeout, if not zero, bounds the time every RPC waits for its reply
+	requestTimeout time.Duration
+	// maxInflight, if not zero, bounds the number of RPCs waiting for their reply
+	maxInflight int
+	// maxTimeouts, if not zero, is the number of consecutive RPCs that time out after which the
+	// connection is closed
+	maxTimeouts int
+	// resume, if set, is the disconnected client whose cache is reused
+	resume *OvsdbClient
+	// historySize, if not zero, is the number of transactions recorded by the client
+	historySize int
+	// tlsConfig, if set, is used to connect to ssl endpoints instead of the one given to Connect
+	tlsConfig *tls.Config
+	// streamBatch, if not zero, is the number of rows of the initial contents of the monitored
+	// tables delivered to the cache at once
+	streamBatch int
+	// maxMessageSize, if not zero, bounds the size in bytes of the messages received from the server
+	maxMessageSize int
+	// noCache makes the client create no cache for its databases, e.g: for the connections of a
+	// Pool that only send transactions
+	noCache bool
+}
+
+// keepalive holds the configuration of the echo keepalives
+type keepalive struct {
+	interval  time.Duration
+	timeout   time.Duration
+	onFailure func(error)
+}
+
+// Default values of the echo keepalives
+const (
+	defaultKeepaliveInterval = 5 * time.Second
+	defaultKeepaliveTimeout  = 5 * time.Second
+)
+
+// defaultMaxTimeouts is the number of consecutive RPCs that time out after which the connection
+// is closed, unless set with WithMaxTimeouts
+const defaultMaxTimeouts = 3
+
+// socketOwner is the expected owner of a unix socket. A negative id is not checked
+type socketOwner struct {
+	uid int
+	gid int
+}
+
+func newOptions(opts ...Option) (*options, error) {
+	o := &options{maxTimeouts: defaultMaxTimeouts}
+	for _, opt := range opts {
+		if err := opt(o); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// withoutCache makes the client create no cache for its databases. Only the functions that do not
+// use the cache (e.g: Transact) can be called on it
+func withoutCache() Option {
+	return func(o *options) error {
+		o.noCache = true
+		return nil
+	}
+}
+
+// WithSchemaVersion sets the version of the schema the database model was built against.
+// On connection, it is compared with the version of the schema reported by the server and,
+// if they differ, the connection fails with an ErrSchemaVersionMismatch error
+func WithSchemaVersion(version string) Option {
+	return func(o *options) error {
+		o.schemaVersion = version
+		o.schemaVersionWarn = false
+		return nil
+	}
+}
+
+// WithSchemaVersionWarning is like WithSchemaVersion, but a version mismatch
+// is only logged and the connection proceeds
+func WithSchemaVersionWarning(version string) Option {
+	return func(o *options) error {
+		o.schemaVersion = version
+		o.schemaVersionWarn = true
+		return nil
+	}
+}
+
+// WithUnixSocketOwner makes the client verify, before connecting to a unix socket endpoint,
+// that the socket file is owned by the given user and group ids. The connection fails if they
+// do not match. A negative uid or gid skips the corresponding check.
+// It is not supported on Windows
+func WithUnixSocketOwner(uid, gid int) Option {
+	return func(o *options) error {
+		o.unixSocketOwner = &socketOwner{uid: uid, gid: gid}
+		return nil
+	}
+}
+
+// WithObserver registers an Observer that is notified before and after every transaction
+func WithObserver(observer Observer) Option {
+	return func(o *options) error {
+		o.observer = observer
+		return nil
+	}
+}
+
+// WithSkipImmutableColumns makes the update operations built by the client silently drop
+// the columns that are immutable as per the schema. By default, attempting to update an
+// immutable column fails with an ErrImmutableColumn error
+func WithSkipImmutableColumns() Option {
+	return func(o *options) error {
+		o.skipImmutable = true
+		return nil
+	}
+}
+
+// WithWeakReferenceWarning makes the client check the weak references set by the insert, update and
+// mutate operations it builds. The ones that refer to rows that are not in the cache of the referenced
+// table, which the server would silently remove, are reported as ErrDanglingWeakReference errors to
+// the provided function, or logged if it is nil. The operations are built anyway. Named-uuids and
+// references to tables that are not cached are not checked
+func WithWeakReferenceWarning(warn func(error)) Option {
+	return func(o *options) error {
+		if warn == nil {
+			warn = func(err error) { log.Printf("warning: %s", err) }
+		}
+		o.weakRefWarn = warn
+		return nil
+	}
+}
+
+// WithDatabase makes the client also connect to the database of the provided model over the same
+// connection. It gets its own cache, that can be monitored with MonitorDatabase or MonitorAllDatabase
+// and accessed with DatabaseCache and DatabaseAPI. Transact performs the operations on the database
+// they are valid for, TransactDatabase on the given one
+func WithDatabase(db *model.DBModel) Option {
+	return func(o *options) error {
+		if db == nil {
+			return fmt.Errorf("database model cannot be nil")
+		}
+		o.databases = append(o.databases, db)
+		return nil
+	}
+}
+
+// WithKeepalive makes the client send an echo request every interval and expect its reply within
+// timeout. Otherwise, onFailure (if not nil) is called with the error and the connection is closed,
+// so the disconnection is notified to the handlers (e.g: to reconnect). A zero interval or timeout
+// takes a default value of 5 seconds
+func WithKeepalive(interval, timeout time.Duration, onFailure func(error)) Option {
+	return func(o *options) error {
+		if interval < 0 || timeout < 0 {
+			return fmt.Errorf("invalid keepalive interval %s or timeout %s", interval, timeout)
+		}
+		if interval == 0 {
+			interval = defaultKeepaliveInterval
+		}
+		if timeout == 0 {
+			timeout = defaultKeepaliveTimeout
+		}
+		o.keepalive = &keepalive{interval: interval, timeout: timeout, onFailure: onFailure}
+		return nil
+	}
+}
+
+// WithRequestTimeout makes every RPC sent by the client fail with a context.DeadlineExceeded error
+// if its reply is not received within the provided timeout, which includes the time spent waiting
+// for an inflight slot (see WithMaxInflight). The reply of a request that timed out is discarded
+// when it arrives. The request stays pending in the RPC client until then, or until the connection
+// is closed, which only happens on its own after a number of consecutive timeouts, see
+// WithMaxTimeouts. Note that monitor requests get the initial contents of the monitored tables in
+// their reply, so the timeout must account for it. A zero timeout disables it
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(o *options) error {
+		if timeout < 0 {
+			return fmt.Errorf("invalid request timeout %s", timeout)
+		}
+		o.requestTimeout = timeout
+		return nil
+	}
+}
+
+// WithMaxInflight bounds the number of RPCs the client has sent and whose reply has not been
+// received yet. Further RPCs block until one of them gets its reply or the connection is closed.
+// Requests that timed out keep their slot until then, so a server that stopped responding does
+// not get more requests. A zero value removes the bound
+func WithMaxInflight(n int) Option {
+	return func(o *options) error {
+		if n < 0 {
+			return fmt.Errorf("invalid maximum number of inflight requests %d", n)
+		}
+		o.maxInflight = n
+		return nil
+	}
+}
+
+// WithMaxTimeouts sets the number of consecutive RPCs whose context deadline is exceeded, e.g: with
+// WithRequestTimeout, after which the connection is closed, 3 by default. The requests that timed
+// out are pending until their reply arrives, and keep their inflight slot (see WithMaxInflight), so
+// closing the connection of a server that stopped responding fails them and releases their slots.
+// A reply received resets the count, so the requests that timed out in between and never get their
+// reply stay pending until the connection is closed. A zero value never closes the connection: the
+// requests whose reply never arrives stay pending, along with their slots, for as long as it is up
+func WithMaxTimeouts(n int) Option {
+	return func(o *options) error {
+		if n < 0 {
+			return fmt.Errorf("invalid maximum number of timeouts %d", n)
+		}
+		o.maxTimeouts = n
+		return nil
+	}
+}
+
+// WithResume makes the client reuse the cache of a previous client, which must have been
+// disconnected with Disconnect, e.g: to reconnect to a server. Along with the cache, the id
+// of the last transaction received by its monitors is kept, so MonitorCondSince only gets the
+// changes made since then. The event handlers registered on the cache keep receiving its events.
+// If the schema reported by the server or the database model differ from the ones of the
+// previous client, a new cache is used
+func WithResume(previous *OvsdbClient) Option {
+	return func(o *options) error {
+		if previous == nil {
+			return fmt.Errorf("client to resume from cannot be nil")
+		}
+		o.resume = previous
+		return nil
+	}
+}
+
+// WithTransactionHistory makes the client record the last n transactions it performed along with
+// their results, errors and timestamps, e.g: to find out what was sent right before a failure
+// without logging all the traffic. They are returned by TransactionHistory
+func WithTransactionHistory(n int) Option {
+	return func(o *options) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid transaction history size %d", n)
+		}
+		o.historySize = n
+		return nil
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to ssl endpoints, overriding the one
+// provided to Connect. If it does not set a ServerName, the host of the endpoint is used for SNI
+// and to verify the server certificate. A failed handshake is reported as an ErrTLSHandshake
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) error {
+		if config == nil {
+			return fmt.Errorf("TLS configuration cannot be nil")
+		}
+		o.tlsConfig = config
+		return nil
+	}
+}
+
+// WithClientCert makes the client connect to ssl endpoints presenting the certificate and key of
+// the provided PEM files, and verify the server certificate against the CA certificates of caFile
+// instead of the system ones. serverName, if not empty, is sent for SNI and must match the server
+// certificate, otherwise the host of the endpoint is used. The files are read when the option is
+// applied, so Connect fails if they cannot be loaded
+func WithClientCert(certFile, keyFile, caFile, serverName string) Option {
+	return func(o *options) error {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		ca, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("no CA certificate found in %s", caFile)
+		}
+		o.tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			RootCAs:      pool,
+			ServerName:   serverName,
+		}
+		return nil
+	}
+}
+
+// WithStreamingInitialDump makes Monitor, MonitorDatabase and MonitorCond deliver the initial
+// contents of the monitored tables to the cache as the reply is read from the connection, in
+// batches of the provided number of rows, instead of once the whole reply is received, so the
+// events of the first rows are generated early and the reply is never held whole. The update
+// notifications received meanwhile are handled once the reply is read. A server that sends the
+// result of the reply before its id, which identifies the request, makes the reply be read whole
+// before its rows are delivered. The cache is marked as syncing until they are all delivered (see
+// cache.TableCache's StartInitialSync). Meanwhile, List returns an ErrCacheSyncing error along
+// with the rows cached so far and Get returns it instead of ErrNotFound, as do the functions
+// relying on them (e.g: Upsert)
+func WithStreamingInitialDump(batchSize int) Option {
+	return func(o *options) error {
+		if batchSize < 1 {
+			return fmt.Errorf("invalid initial dump batch size %d", batchSize)
+		}
+		o.streamBatch = batchSize
+		return nil
+	}
+}
+
+// WithMaxMessageSize bounds the size in bytes of the messages received from the server, which are
+// otherwise unbounded. The messages are decoded as they are read from the connection, whatever
+// their size. A message exceeding the maximum is not read further: the connection is closed and
+// the requests waiting for their reply, e.g: a monitor request whose reply holds the initial
+// contents of large tables, fail with an ErrMessageTooLarge error
+func WithMaxMessageSize(bytes int) Option {
+	return func(o *options) error {
+		if bytes < 1 {
+			return fmt.Errorf("invalid maximum message size %d", bytes)
+		}
+		o.maxMessageSize = bytes
+		return nil
+	}
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"log"
 	"os"
 	"testing"
@@ -147,7 +148,7 @@ func TestGetSchemasIntegration(t *testing.T) {
 	}
 
 	dbName := "Open_vSwitch"
-	reply, err := ovs.GetSchema(dbName)
+	reply, err := ovs.GetSchema(context.Background(), dbName)
 
 	if err != nil {
 		log.Fatal("GetSchemas error:", err)
@@ -217,6 +218,58 @@ func TestInsertTransactIntegration(t *testing.T) {
 	ovs.Disconnect()
 }
 
+func TestMapValueReplaceTransactIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	SetConfig()
+
+	if bridgeUUID == "" {
+		t.Skip()
+	}
+
+	ovs, err := Connect(cfg.Addr, defDB, nil)
+	if err != nil {
+		t.Fatalf("Failed to Connect. error: %s", err)
+	}
+	defer ovs.Disconnect()
+	err = ovs.MonitorAll(nil)
+	assert.Nil(t, err)
+
+	br := bridgeType{}
+	mutations, err := model.NewMapValueReplaceMutations(&br.ExternalIds, "go", "fast")
+	assert.Nil(t, err)
+	mutateOp, err := ovs.Where(&bridgeType{Name: bridgeName}).Mutate(&br, mutations...)
+	assert.Nil(t, err)
+
+	selectOp := ovsdb.Operation{
+		Op:      "select",
+		Table:   "Bridge",
+		Where:   []ovsdb.Condition{ovsdb.NewCondition("name", ovsdb.ConditionEqual, bridgeName)},
+		Columns: []string{"external_ids"},
+	}
+	operations := append(mutateOp, selectOp)
+	reply, err := ovs.Transact(operations...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operationErrs, err := ovsdb.CheckOperationResults(reply, operations)
+	if err != nil {
+		for _, oe := range operationErrs {
+			t.Error(oe)
+		}
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, reply[0].Count)
+	if assert.Len(t, reply[1].Rows, 1) {
+		externalIds, ok := reply[1].Rows[0]["external_ids"].(ovsdb.OvsMap)
+		assert.True(t, ok)
+		assert.Equal(t, "fast", externalIds.GoMap["go"])
+		assert.Equal(t, "made-for-each-other", externalIds.GoMap["docker"])
+	}
+}
+
 func TestDeleteTransactIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// Pool is a set of connections to the same OVSDB server, for clients whose write throughput
+// is limited by a single connection, over which the server handles transactions one at a time.
+// The first connection holds the monitors, the cache and the locks and serves every method of
+// the embedded OvsdbClient but the ones of Pool, while transactions are sent over the connections
+// in turn. The other connections have no cache.
+//
+// OVSDB locks belong to the connection that took them, so the transactions holding an assert
+// operation (e.g: built with Assert or AssertLock) are always sent over the first connection,
+// the one Lock and Steal are called on.
+//
+// OVSDB does not order transactions sent over different connections: a transaction may be
+// committed before one sent earlier, and the updates of the monitors may show them in that
+// order. Operations that depend on each other (e.g: an insert and the mutation referencing
+// the inserted row) must be sent in the same transaction
+type Pool struct {
+	// next is the index of the connection used by the next transaction
+	next    uint64
+	clients []*OvsdbClient
+	*OvsdbClient
+}
+
+// NewPool connects size times to the endpoints, as Connect does with the given options, and
+// returns the Pool of those connections
+func NewPool(endpoints string, size int, database *model.DBModel, tlsConfig *tls.Config, opts ...Option) (*Pool, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("invalid pool size %d", size)
+	}
+	clients := make([]*OvsdbClient, 0, size)
+	for i := 0; i < size; i++ {
+		connOpts := opts
+		if i > 0 {
+			// Only the first connection monitors the databases
+			connOpts = append(append([]Option{}, opts...), withoutCache())
+		}
+		ovs, err := Connect(endpoints, database, tlsConfig, connOpts...)
+		if err != nil {
+			for _, c := range clients {
+				c.Disconnect()
+			}
+			return nil, err
+		}
+		clients = append(clients, ovs)
+	}
+	return newPool(clients), nil
+}
+
+func newPool(clients []*OvsdbClient) *Pool {
+	return &Pool{
+		clients:     clients,
+		OvsdbClient: clients[0],
+	}
+}
+
+// nextClient returns the connection the next transaction, made of the provided operations, is
+// sent over: the first one if they assert a lock, the next one in turn otherwise
+func (p *Pool) nextClient(operation []ovsdb.Operation) *OvsdbClient {
+	for _, op := range operation {
+		if op.Op == ovsdb.OperationAssert {
+			return p.OvsdbClient
+		}
+	}
+	i := atomic.AddUint64(&p.next, 1) - 1
+	return p.clients[i%uint64(len(p.clients))]
+}
+
+// Transact performs the provided Operation's over the next connection of the pool, or the first
+// one if they assert a lock
+// RFC 7047 : transact
+func (p *Pool) Transact(operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+	return p.nextClient(operation).Transact(operation...)
+}
+
+// TransactContext validates and performs the provided Operation's over the next connection of the
+// pool, see OvsdbClient's TransactContext
+func (p *Pool) TransactContext(ctx context.Context, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+	return p.nextClient(operation).TransactContext(ctx, operation...)
+}
+
+// TransactDatabase performs the provided Operation's on the given database over the next
+// connection of the pool
+func (p *Pool) TransactDatabase(dbName string, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+	return p.nextClient(operation).TransactDatabase(dbName, operation...)
+}
+
+// Size returns the number of connections of the pool
+func (p *Pool) Size() int {
+	return len(p.clients)
+}
+
+// Disconnect closes all the connections of the pool
+func (p *Pool) Disconnect() {
+	for _, ovs := range p.clients {
+		ovs.Disconnect()
+	}
+}
+
+// Shutdown gracefully closes all the connections of the pool, see OvsdbClient's Shutdown. The
+// transactions of every connection that did not complete before the context was done are
+// returned
+func (p *Pool) Shutdown(ctx context.Context) ([]PendingTransaction, error) {
+	var pending []PendingTransaction
+	var err error
+	for _, ovs := range p.clients {
+		clientPending, clientErr := ovs.Shutdown(ctx)
+		pending = append(pending, clientPending...)
+		if err == nil {
+			err = clientErr
+		}
+	}
+	return pending, err
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// ErrShutdown is returned by the transactions attempted once Shutdown was called
+var ErrShutdown = errors.New("client is shutting down")
+
+// PendingTransaction is a transaction that was sent but had not completed when Shutdown returned
+type PendingTransaction struct {
+	Database   string
+	Operations []ovsdb.Operation
+}
+
+// pendingTransactions tracks the transactions waiting for their reply so they can be drained
+type pendingTransactions struct {
+	mutex        sync.Mutex
+	next         uint64
+	transactions map[uint64]PendingTransaction
+	// closed is set by Shutdown, after which no transaction is accepted
+	closed bool
+	// drained, if not nil, is closed once the last pending transaction completes
+	drained chan struct{}
+}
+
+func newPendingTransactions() *pendingTransactions {
+	return &pendingTransactions{
+		transactions: make(map[uint64]PendingTransaction),
+	}
+}
+
+// add registers a transaction about to be sent and returns its id, or ErrShutdown
+func (p *pendingTransactions) add(dbName string, operations []ovsdb.Operation) (uint64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.closed {
+		return 0, ErrShutdown
+	}
+	p.next++
+	p.transactions[p.next] = PendingTransaction{Database: dbName, Operations: operations}
+	return p.next, nil
+}
+
+// remove unregisters a completed transaction
+func (p *pendingTransactions) remove(id uint64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.transactions, id)
+	if p.drained != nil && len(p.transactions) == 0 {
+		close(p.drained)
+		p.drained = nil
+	}
+}
+
+// close stops accepting transactions and returns a channel that is closed once the pending
+// ones complete
+func (p *pendingTransactions) close() <-chan struct{} {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.closed = true
+	drained := make(chan struct{})
+	if len(p.transactions) == 0 {
+		close(drained)
+	} else {
+		p.drained = drained
+	}
+	return drained
+}
+
+// list returns the pending transactions in the order they were sent
+func (p *pendingTransactions) list() []PendingTransaction {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	ids := make([]uint64, 0, len(p.transactions))
+	for id := range p.transactions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	result := make([]PendingTransaction, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, p.transactions[id])
+	}
+	return result
+}
+
+// Shutdown gracefully closes the OVSDB connection: new transactions fail with ErrShutdown, the
+// in-flight ones are given until the context is done to complete and the connection is then
+// closed. If the context is done first, the transactions that did not complete are returned
+// along with the context's error, so callers can decide whether to retry them. Their outcome
+// is unknown, as the server may have committed them without the reply being received
+func (ovs OvsdbClient) Shutdown(ctx context.Context) ([]PendingTransaction, error) {
+	drained := ovs.pending.close()
+	var pending []PendingTransaction
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		pending = ovs.pending.list()
+		err = ctx.Err()
+	}
+	ovs.Disconnect()
+	return pending, err
+}
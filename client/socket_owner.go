@@ -0,0 +1,32 @@
+//go:build !windows
+// +build !windows
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// checkSocketOwner verifies that the file at path is a socket owned by the expected user and group
+func checkSocketOwner(path string, owner *socketOwner) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s is not a unix socket", path)
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fmt.Errorf("unable to retrieve the owner of %s", path)
+	}
+	if owner.uid >= 0 && int(stat.Uid) != owner.uid {
+		return fmt.Errorf("unix socket %s is owned by uid %d, expected %d", path, stat.Uid, owner.uid)
+	}
+	if owner.gid >= 0 && int(stat.Gid) != owner.gid {
+		return fmt.Errorf("unix socket %s is owned by gid %d, expected %d", path, stat.Gid, owner.gid)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+//go:build !windows
+// +build !windows
+
+package client
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSocketOwner(t *testing.T) {
+	dir, err := os.MkdirTemp("", "libovsdb")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "db.sock")
+	l, err := net.Listen("unix", path)
+	assert.Nil(t, err)
+	defer l.Close()
+
+	uid, gid := os.Getuid(), os.Getgid()
+	assert.Nil(t, checkSocketOwner(path, &socketOwner{uid: uid, gid: gid}))
+	assert.Nil(t, checkSocketOwner(path, &socketOwner{uid: -1, gid: -1}))
+	assert.NotNil(t, checkSocketOwner(path, &socketOwner{uid: uid + 1, gid: -1}))
+	assert.NotNil(t, checkSocketOwner(path, &socketOwner{uid: -1, gid: gid + 1}))
+
+	// Not a socket
+	file := filepath.Join(dir, "file")
+	assert.Nil(t, os.WriteFile(file, []byte{}, 0600))
+	assert.NotNil(t, checkSocketOwner(file, &socketOwner{uid: uid, gid: gid}))
+
+	// Does not exist
+	assert.NotNil(t, checkSocketOwner(filepath.Join(dir, "none"), &socketOwner{uid: uid, gid: gid}))
+}
@@ -0,0 +1,8 @@
+package client
+
+import "fmt"
+
+// checkSocketOwner is not supported on windows
+func checkSocketOwner(path string, owner *socketOwner) error {
+	return fmt.Errorf("unix socket owner checks are not supported on windows")
+}
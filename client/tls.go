@@ -0,0 +1,52 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+)
+
+// ErrTLSHandshake is returned when the TCP connection to an ssl endpoint was established but
+// the TLS handshake failed, e.g: the server certificate is not signed by a trusted CA, does not
+// match the server name or the server rejected the client certificate. Failing to establish the
+// TCP connection (e.g: connection refused) is not reported as an ErrTLSHandshake. The underlying
+// error can be inspected with errors.As, e.g: for an x509.UnknownAuthorityError
+type ErrTLSHandshake struct {
+	Endpoint string
+	Err      error
+}
+
+func (e *ErrTLSHandshake) Error() string {
+	return fmt.Sprintf("TLS handshake with %s failed: %v", e.Endpoint, e.Err)
+}
+
+func (e *ErrTLSHandshake) Unwrap() error {
+	return e.Err
+}
+
+// dialTLS connects to host and performs the TLS handshake with the provided configuration,
+// so a handshake failure can be told apart from a connection failure. As tls.Dial does, the
+// server name is taken from host if the configuration does not set it
+func dialTLS(host string, config *tls.Config) (net.Conn, error) {
+	conn, err := net.Dial("tcp", host)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = &tls.Config{}
+	}
+	if config.ServerName == "" {
+		serverName := host
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			serverName = hostname
+		}
+		config = config.Clone()
+		config.ServerName = serverName
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, &ErrTLSHandshake{Endpoint: host, Err: err}
+	}
+	return tlsConn, nil
+}
@@ -0,0 +1,203 @@
+package client
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCertificate creates a certificate for name signed by parent, or self-signed if it is nil,
+// and writes it along with its key to dir as name.crt and name.key
+func testCertificate(t *testing.T, dir, name string, parent *tls.Certificate, isCA bool) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		DNSNames:              []string{name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	signer, signerKey := template, interface{}(key)
+	if parent != nil {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	for file, data := range map[string][]byte{name + ".crt": certPEM, name + ".key": keyPEM} {
+		if err := os.WriteFile(filepath.Join(dir, file), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert.Leaf, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// testTLSServer accepts a single connection, performs the TLS handshake and sends the names of
+// the client certificates, if it succeeded, to the returned channel
+func testTLSServer(t *testing.T, config *tls.Config) (string, <-chan []string) {
+	l, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	peers := make(chan []string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tlsConn := conn.(*tls.Conn)
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		var names []string
+		for _, cert := range tlsConn.ConnectionState().PeerCertificates {
+			names = append(names, cert.Subject.CommonName)
+		}
+		peers <- names
+	}()
+	return l.Addr().String(), peers
+}
+
+func TestTLSOptions(t *testing.T) {
+	dir := t.TempDir()
+	ca := testCertificate(t, dir, "ca", nil, true)
+	testCertificate(t, dir, "client", &ca, false)
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	o, err := newOptions(WithClientCert(path("client.crt"), path("client.key"), path("ca.crt"), "ovsdb.example"))
+	assert.Nil(t, err)
+	assert.Len(t, o.tlsConfig.Certificates, 1)
+	assert.NotNil(t, o.tlsConfig.RootCAs)
+	assert.Equal(t, "ovsdb.example", o.tlsConfig.ServerName)
+
+	_, err = newOptions(WithClientCert(path("none.crt"), path("client.key"), path("ca.crt"), ""))
+	assert.NotNil(t, err)
+	_, err = newOptions(WithClientCert(path("client.crt"), path("client.key"), path("none.crt"), ""))
+	assert.NotNil(t, err)
+	// The key is not a certificate
+	_, err = newOptions(WithClientCert(path("client.crt"), path("client.key"), path("ca.key"), ""))
+	assert.NotNil(t, err)
+
+	_, err = newOptions(WithTLSConfig(nil))
+	assert.NotNil(t, err)
+	config := &tls.Config{ServerName: "ovsdb.example"}
+	o, err = newOptions(WithTLSConfig(config))
+	assert.Nil(t, err)
+	assert.Equal(t, config, o.tlsConfig)
+}
+
+func TestDialTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := testCertificate(t, dir, "ca", nil, true)
+	server := testCertificate(t, dir, "ovsdb.example", &ca, false)
+	testCertificate(t, dir, "client", &ca, false)
+	testCertificate(t, dir, "other", nil, true)
+	path := func(name string) string { return filepath.Join(dir, name) }
+	clientConfig := func(caFile, serverName string) *tls.Config {
+		o, err := newOptions(WithClientCert(path("client.crt"), path("client.key"), path(caFile), serverName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return o.tlsConfig
+	}
+	serverConfig := func() *tls.Config {
+		pool := x509.NewCertPool()
+		pool.AddCert(ca.Leaf)
+		return &tls.Config{
+			Certificates: []tls.Certificate{server},
+			ClientCAs:    pool,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+		}
+	}
+
+	t.Run("Dial TLS: client certificate and SNI", func(t *testing.T) {
+		config := serverConfig()
+		sni := make(chan string, 1)
+		config.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			sni <- hello.ServerName
+			return nil, nil
+		}
+		addr, peers := testTLSServer(t, config)
+		conn, err := dialTLS(addr, clientConfig("ca.crt", "ovsdb.example"))
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer conn.Close()
+		assert.Equal(t, "ovsdb.example", <-sni)
+		assert.Equal(t, []string{"client"}, <-peers)
+	})
+
+	t.Run("Dial TLS: unknown CA", func(t *testing.T) {
+		addr, _ := testTLSServer(t, serverConfig())
+		_, err := dialTLS(addr, clientConfig("other.crt", "ovsdb.example"))
+		var handshakeErr *ErrTLSHandshake
+		assert.True(t, errors.As(err, &handshakeErr), err)
+		if handshakeErr != nil {
+			assert.Equal(t, addr, handshakeErr.Endpoint)
+		}
+		var authorityErr x509.UnknownAuthorityError
+		assert.True(t, errors.As(err, &authorityErr), err)
+	})
+
+	t.Run("Dial TLS: server name mismatch", func(t *testing.T) {
+		addr, _ := testTLSServer(t, serverConfig())
+		// Without a server name, the host of the endpoint is verified
+		_, err := dialTLS(addr, clientConfig("ca.crt", ""))
+		var handshakeErr *ErrTLSHandshake
+		assert.True(t, errors.As(err, &handshakeErr), err)
+		var hostnameErr x509.HostnameError
+		assert.True(t, errors.As(err, &hostnameErr), err)
+	})
+
+	t.Run("Dial TLS: connection refused", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := l.Addr().String()
+		l.Close()
+		_, err = Connect("ssl:"+addr, defDB, nil, WithClientCert(path("client.crt"), path("client.key"), path("ca.crt"), "ovsdb.example"))
+		assert.NotNil(t, err)
+		var handshakeErr *ErrTLSHandshake
+		assert.False(t, errors.As(err, &handshakeErr), err)
+		var opErr *net.OpError
+		assert.True(t, errors.As(err, &opErr), err)
+	})
+}
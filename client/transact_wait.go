@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+
+	"github.com/ovn-org/libovsdb/cache"
+	"github.com/ovn-org/libovsdb/mapper"
+	"github.com/ovn-org/libovsdb/model"
+	"github.com/ovn-org/libovsdb/ovsdb"
+)
+
+// expectedRow is the state a cached row is expected to reach once the updates of a transaction
+// are received
+type expectedRow struct {
+	deleted bool
+	// version is the _version of the row after the transaction, if known
+	version string
+}
+
+// TransactAndWait performs the provided Operation's like TransactContext does and then blocks until
+// the cache reflects the transaction, or the context is done, so the changes can be read back from
+// it right away. The cache is otherwise updated when the monitors deliver the updates of the
+// transaction, which the server may send after its reply.
+// The rows a transaction changes are learned from the transaction itself: inserted rows by the
+// UUIDs returned by the server, while a select operation of the _uuid and _version columns is added
+// before every delete, update and mutate, with the same conditions, to get the rows they change.
+// Another one is added after every update and mutate to get the _version the rows they modify have
+// afterwards, with the conditions on the columns they do not change, which the rows may no longer
+// match otherwise, and only the rows selected before are kept. Then, the inserted rows are
+// waited for to be added to the cache, the deleted rows to be removed and the modified rows to have
+// the _version the server returned, which is only checked if their model maps the _version column.
+// The returned results are those of the provided operations, without the added ones. The tables of
+// the operations must be monitored, otherwise the context must be done for it to return
+func (ovs OvsdbClient) TransactAndWait(ctx context.Context, operation ...ovsdb.Operation) ([]ovsdb.OperationResult, error) {
+	schema, err := ovs.schemaForOperations(operation)
+	if err != nil {
+		return nil, err
+	}
+	tcache := ovs.DatabaseCache(schema.Name)
+	operations, positions, selects := waitOperations(tcache, operation)
+
+	// Watch the cache before the transaction so no update is missed
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	handler := &cache.EventHandlerFuncs{
+		AddFunc:    func(string, model.Model) { notify() },
+		UpdateFunc: func(string, model.Model, model.Model) { notify() },
+		DeleteFunc: func(string, model.Model) { notify() },
+	}
+	tcache.AddEventHandler(handler)
+	defer tcache.RemoveEventHandler(handler)
+
+	reply, err := ovs.transactSchema(ctx, schema, operations...)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ovsdb.OperationResult, 0, len(operation))
+	for _, position := range positions {
+		if position < len(reply) {
+			results = append(results, reply[position])
+		}
+	}
+	if len(reply) > len(operations) {
+		// The error of a transaction that could not be committed
+		results = append(results, reply[len(operations):]...)
+	}
+	if _, err := ovsdb.CheckOperationResults(results, operation); err != nil {
+		return results, err
+	}
+
+	expected := expectedRows(operations, reply, selects)
+	for {
+		if cacheReflects(tcache, expected) {
+			return results, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return results, ctx.Err()
+		}
+	}
+}
+
+// waitSelect describes a select added by TransactAndWait to learn the rows changed by an operation
+type waitSelect struct {
+	// deleted tells whether the select precedes a delete, whose rows are expected to be deleted
+	deleted bool
+	// before, for a select that follows an update or a mutate, is the position of the select that
+	// precedes the operation, which got the UUIDs of the rows it changes. It is -1 otherwise
+	before int
+}
+
+// waitOperations returns the operations to send for TransactAndWait to learn the rows changed by
+// the provided ones, along with the position of each of the provided operations among them and
+// the added selects, by position
+func waitOperations(tcache *cache.TableCache, operation []ovsdb.Operation) ([]ovsdb.Operation, []int, map[int]waitSelect) {
+	operations := make([]ovsdb.Operation, 0, len(operation))
+	positions := make([]int, 0, len(operation))
+	selects := make(map[int]waitSelect)
+	selectRows := func(op ovsdb.Operation, where []ovsdb.Condition) ovsdb.Operation {
+		return ovsdb.Operation{
+			Op:      ovsdb.OperationSelect,
+			Table:   op.Table,
+			Where:   where,
+			Columns: []string{"_uuid", "_version"},
+		}
+	}
+	for _, op := range operation {
+		_, cached := tcache.DBModel().Types()[op.Table]
+		changes := cached && (op.Op == ovsdb.OperationUpdate || op.Op == ovsdb.OperationMutate)
+		before := len(operations)
+		if changes || (cached && op.Op == ovsdb.OperationDelete) {
+			selects[before] = waitSelect{deleted: op.Op == ovsdb.OperationDelete, before: -1}
+			operations = append(operations, selectRows(op, op.Where))
+		}
+		positions = append(positions, len(operations))
+		operations = append(operations, op)
+		if changes {
+			selects[len(operations)] = waitSelect{before: before}
+			operations = append(operations, selectRows(op, unchangedConditions(op)))
+		}
+	}
+	return operations, positions, selects
+}
+
+// unchangedConditions returns the conditions of an update or a mutate on the columns it does not
+// change, which the rows it changes still match afterwards, unlike the others
+func unchangedConditions(op ovsdb.Operation) []ovsdb.Condition {
+	changed := make(map[string]bool, len(op.Row)+len(op.Mutations))
+	for column := range op.Row {
+		changed[column] = true
+	}
+	for _, mutation := range op.Mutations {
+		changed[mutation.Column] = true
+	}
+	conditions := make([]ovsdb.Condition, 0, len(op.Where))
+	for _, condition := range op.Where {
+		if !changed[condition.Column] {
+			conditions = append(conditions, condition)
+		}
+	}
+	return conditions
+}
+
+// expectedRows returns, by table and UUID, the state the rows changed by the operations sent by
+// TransactAndWait are expected to reach. The latest operation on a row determines its state
+func expectedRows(operations []ovsdb.Operation, reply []ovsdb.OperationResult, selects map[int]waitSelect) map[string]map[string]expectedRow {
+	expected := make(map[string]map[string]expectedRow)
+	expect := func(table, uuid string, row expectedRow) {
+		if expected[table] == nil {
+			expected[table] = make(map[string]expectedRow)
+		}
+		expected[table][uuid] = row
+	}
+	for i, op := range operations {
+		if i >= len(reply) {
+			break
+		}
+		added, ok := selects[i]
+		switch {
+		case op.Op == ovsdb.OperationInsert:
+			if reply[i].UUID.GoUUID != "" {
+				expect(op.Table, reply[i].UUID.GoUUID, expectedRow{})
+			}
+		case ok && added.deleted:
+			for _, row := range reply[i].Rows {
+				if uuid, ok := row["_uuid"].(ovsdb.UUID); ok {
+					expect(op.Table, uuid.GoUUID, expectedRow{deleted: true})
+				}
+			}
+		case ok && added.before >= 0:
+			// Only the rows selected before the operation were changed by it
+			changed := make(map[string]bool)
+			for _, row := range reply[added.before].Rows {
+				if uuid, ok := row["_uuid"].(ovsdb.UUID); ok {
+					changed[uuid.GoUUID] = true
+				}
+			}
+			for _, row := range reply[i].Rows {
+				uuid, ok := row["_uuid"].(ovsdb.UUID)
+				if !ok || !changed[uuid.GoUUID] {
+					continue
+				}
+				version, _ := row["_version"].(ovsdb.UUID)
+				expect(op.Table, uuid.GoUUID, expectedRow{version: version.GoUUID})
+			}
+		}
+	}
+	return expected
+}
+
+// cacheReflects returns whether the cached rows are in their expected state
+func cacheReflects(tcache *cache.TableCache, expected map[string]map[string]expectedRow) bool {
+	for table, rows := range expected {
+		if _, ok := tcache.DBModel().Types()[table]; !ok {
+			continue
+		}
+		tableCache := tcache.Table(table)
+		tableSchema := tcache.Mapper().Schema.Table(table)
+		for uuid, row := range rows {
+			var cached model.Model
+			if tableCache != nil {
+				cached = tableCache.Row(uuid)
+			}
+			if row.deleted {
+				if cached != nil {
+					return false
+				}
+				continue
+			}
+			if cached == nil {
+				return false
+			}
+			if row.version == "" {
+				continue
+			}
+			info, err := mapper.NewMapperInfo(tableSchema, cached)
+			if err != nil {
+				continue
+			}
+			if version, err := info.FieldByColumn("_version"); err == nil && version != row.version {
+				return false
+			}
+		}
+	}
+	return true
+}
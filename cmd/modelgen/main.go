@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -23,6 +22,8 @@ var (
 	outDirP  = flag.String("o", ".", "Directory where the generated files shall be stored")
 	pkgNameP = flag.String("p", "ovsmodel", "Package name")
 	dryRun   = flag.Bool("d", false, "Dry run")
+
+	optionalPointers = flag.Bool("optional-pointers", false, "Use pointer fields for optional scalar columns (sets of at most one element)")
 )
 
 func writeFile(filename string, src []byte) error {
@@ -59,27 +60,16 @@ func main() {
 		os.Exit(2)
 	}
 
-	schemaFile, err := os.Open(flag.Args()[0])
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer schemaFile.Close()
-
-	schemaBytes, err := ioutil.ReadAll(schemaFile)
+	dbSchema, err := ovsdb.SchemaFromPath(flag.Args()[0])
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	var dbSchema ovsdb.DatabaseSchema
-	if err := json.Unmarshal(schemaBytes, &dbSchema); err != nil {
-		log.Fatal(err)
-	}
-
 	generators := []Generator{}
 	for name, table := range dbSchema.Tables {
-		generators = append(generators, NewTableGenerator(pkgName, name, &table))
+		generators = append(generators, NewTableGenerator(pkgName, name, &table, *optionalPointers))
 	}
-	generators = append(generators, NewDBModelGenerator(pkgName, &dbSchema))
+	generators = append(generators, NewDBModelGenerator(pkgName, dbSchema))
 
 	for _, gen := range generators {
 		code, err := gen.Format()
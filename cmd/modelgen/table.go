@@ -38,7 +38,9 @@ type Field struct {
 }
 
 // NewTableGenerator returns a table code generator
-func NewTableGenerator(pkg string, name string, table *ovsdb.TableSchema) Generator {
+// If optionalPointers is true, optional scalar columns (sets of at most one element)
+// are held in pointer fields instead of slices
+func NewTableGenerator(pkg string, name string, table *ovsdb.TableSchema, optionalPointers bool) Generator {
 	templateData := TableTemplateData{
 		TableName:   name,
 		PackageName: pkg,
@@ -63,9 +65,15 @@ func NewTableGenerator(pkg string, name string, table *ovsdb.TableSchema) Genera
 
 	for _, columnName := range order {
 		columnSchema := table.Columns[columnName]
+		fieldType := FieldType(columnSchema)
+		if optionalPointers {
+			if optionalType := OptionalFieldType(columnSchema); optionalType != "" {
+				fieldType = optionalType
+			}
+		}
 		templateData.Fields = append(templateData.Fields, Field{
 			Name: FieldName(columnName),
-			Type: FieldType(columnSchema),
+			Type: fieldType,
 			Tag:  Tag(columnName),
 		})
 	}
@@ -99,6 +107,15 @@ func FieldType(column *ovsdb.ColumnSchema) string {
 	}
 }
 
+// OptionalFieldType returns the string representation of the pointer type that can hold
+// an optional scalar column (a set of at most one element), or "" if the column is not one
+func OptionalFieldType(column *ovsdb.ColumnSchema) string {
+	if ovsdb.NativeOptionalType(column) == nil {
+		return ""
+	}
+	return fmt.Sprintf("*%s", AtomicType(column.TypeObj.Key.Type))
+}
+
 // BasicType returns the string type of an AtomicType
 func AtomicType(atype string) string {
 	switch atype {
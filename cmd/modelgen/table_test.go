@@ -54,6 +54,7 @@ type test struct {
 		"test",
 		"test",
 		&table,
+		false,
 	)
 
 	for i := 0; i < 3; i++ {
@@ -86,19 +87,67 @@ func TestStructName(t *testing.T) {
 	}
 }
 
-/*
-TODO: Write Test
 func TestFieldType(t *testing.T) {
 	tests := []struct {
-		name string
-		in   *ovsdb.ColumnSchema
-		out  string
+		name     string
+		column   string
+		out      string
+		optional string
+	}{
+		{"String", `{"type": "string"}`, "string", ""},
+		{"Enum", `{"type": {"key": {"type": "string", "enum": ["set", ["a", "b"]]}}}`, "string", ""},
+		{"UUID", `{"type": "uuid"}`, "string", ""},
+		{"Set", `{"type": {"key": "integer", "min": 0, "max": "unlimited"}}`, "[]int", ""},
+		{"OptionalString", `{"type": {"key": "string", "min": 0, "max": 1}}`, "[]string", "*string"},
+		{"OptionalReference", `{"type": {"key": {"type": "uuid", "refTable": "Foo"}, "min": 0, "max": 1}}`, "[]string", "*string"},
+		{"OptionalReal", `{"type": {"key": "real", "min": 0, "max": 1}}`, "[]float64", "*float64"},
+		{"Map", `{"type": {"key": "string", "value": "integer", "min": 0, "max": "unlimited"}}`, "map[string]int", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var column ovsdb.ColumnSchema
+			if err := json.Unmarshal([]byte(tt.column), &column); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.out, FieldType(&column))
+			assert.Equal(t, tt.optional, OptionalFieldType(&column))
+			// Generated types must be the ones the mapper expects
+			assert.Equal(t, ovsdb.NativeType(&column).String(), FieldType(&column))
+			if tt.optional != "" {
+				assert.Equal(t, ovsdb.NativeOptionalType(&column).String(), OptionalFieldType(&column))
+			}
+		})
 	}
-	if got := FieldType(tt.args.column); got != tt.want {
-		t.Errorf("FieldType() = %v, want %v", got, tt.want)
+}
+
+func TestNewTableGeneratorOptionalPointers(t *testing.T) {
+	var table ovsdb.TableSchema
+	err := json.Unmarshal([]byte(`{
+		"columns": {
+			"parent": {"type": {"key": "string", "min": 0, "max": 1}},
+			"tags": {"type": {"key": "integer", "min": 0, "max": "unlimited"}}
+		}
+	}`), &table)
+	if err != nil {
+		t.Fatal(err)
 	}
+
+	expected := `// Code generated by "ovsdb.modelgen"
+// DO NOT EDIT.
+
+package test
+
+// test defines an object in test table
+type test struct {
+	UUID   string  ` + "`" + `ovs:"_uuid"` + "`" + `
+	Parent *string ` + "`" + `ovs:"parent"` + "`" + `
+	Tags   []int   ` + "`" + `ovs:"tags"` + "`" + `
+}
+`
+	b, err := NewTableGenerator("test", "test", &table, true).Format()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, string(b))
 }
-*/
 
 func TestAtomicType(t *testing.T) {
 	tests := []struct {
@@ -1,8 +1,11 @@
 package mapper
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/ovn-org/libovsdb/ovsdb"
 )
@@ -12,17 +15,66 @@ import (
 type MapperInfo struct {
 	// FieldName indexed by column
 	fields map[string]string
-	obj    interface{}
-	table  *ovsdb.TableSchema
+	// Index sequence of the field (as used by reflect's FieldByIndex) indexed by column
+	// Fields of embedded structs have more than one index
+	indexes map[string][]int
+	// Mapped columns that are not mutable (as per the schema)
+	immutable map[string]bool
+	// Table referenced by the mapped columns that hold weak references, indexed by column
+	weak  map[string]string
+	obj   interface{}
+	table *ovsdb.TableSchema
+}
+
+// IsImmutable returns whether a mapped column is immutable, i.e: it cannot be updated
+func (mi *MapperInfo) IsImmutable(column string) bool {
+	return mi.immutable[column]
+}
+
+// ImmutableColumns returns the sorted list of mapped columns that are immutable
+func (mi *MapperInfo) ImmutableColumns() []string {
+	columns := make([]string, 0, len(mi.immutable))
+	for column := range mi.immutable {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// WeakReference returns the table a mapped column references weakly, i.e: its UUIDs (or those of
+// its keys or values) refer to rows of that table and the server removes them when the rows are
+// deleted. It returns false if the column does not hold weak references
+func (mi *MapperInfo) WeakReference(column string) (string, bool) {
+	table, ok := mi.weak[column]
+	return table, ok
+}
+
+// WeakReferenceColumns returns the sorted list of mapped columns that hold weak references
+func (mi *MapperInfo) WeakReferenceColumns() []string {
+	columns := make([]string, 0, len(mi.weak))
+	for column := range mi.weak {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
 }
 
 // FieldByColumn returns the field value that corresponds to a column
 func (mi *MapperInfo) FieldByColumn(column string) (interface{}, error) {
-	fieldName, ok := mi.fields[column]
+	index, ok := mi.indexes[column]
 	if !ok {
 		return nil, fmt.Errorf("column %s not found in orm info", column)
 	}
-	return reflect.ValueOf(mi.obj).Elem().FieldByName(fieldName).Interface(), nil
+	return timeToNative(reflect.ValueOf(mi.obj).Elem().FieldByIndex(index).Interface()), nil
+}
+
+// FieldPtrByColumn returns a pointer to the field that corresponds to a column
+func (mi *MapperInfo) FieldPtrByColumn(column string) (interface{}, error) {
+	index, ok := mi.indexes[column]
+	if !ok {
+		return nil, fmt.Errorf("column %s not found in orm info", column)
+	}
+	return reflect.ValueOf(mi.obj).Elem().FieldByIndex(index).Addr().Interface(), nil
 }
 
 // FieldByColumn returns the field value that corresponds to a column
@@ -37,7 +89,31 @@ func (mi *MapperInfo) SetField(column string, value interface{}) error {
 	if !ok {
 		return fmt.Errorf("column %s not found in orm info", column)
 	}
-	fieldValue := reflect.ValueOf(mi.obj).Elem().FieldByName(fieldName)
+	fieldValue := reflect.ValueOf(mi.obj).Elem().FieldByIndex(mi.indexes[column])
+
+	// Integer columns may be held in time fields
+	if intValue, ok := value.(int); ok && isTimeType(fieldValue.Type()) {
+		fieldValue.Set(reflect.ValueOf(nativeToTime(fieldValue.Type(), intValue)))
+		return nil
+	}
+
+	// Optional scalar columns may be held in a pointer field. In that case, the native
+	// set (of at most one element) is stored behind the pointer
+	if fieldValue.Kind() == reflect.Ptr && reflect.TypeOf(value) == reflect.SliceOf(fieldValue.Type().Elem()) {
+		nativeSet := reflect.ValueOf(value)
+		switch nativeSet.Len() {
+		case 0:
+			fieldValue.Set(reflect.Zero(fieldValue.Type()))
+		case 1:
+			optional := reflect.New(fieldValue.Type().Elem())
+			optional.Elem().Set(nativeSet.Index(0))
+			fieldValue.Set(optional)
+		default:
+			return fmt.Errorf("column %s: native value %v has more than one element and cannot be held by field %s (%s)",
+				column, value, fieldName, fieldValue.Type())
+		}
+		return nil
+	}
 
 	if !fieldValue.Type().AssignableTo(reflect.TypeOf(value)) {
 		return fmt.Errorf("column %s: native value %v (%s) is not assignable to field %s (%s)",
@@ -55,13 +131,16 @@ func (mi *MapperInfo) ColumnByPtr(fieldPtr interface{}) (string, error) {
 	}
 	offset := fieldPtrVal.Pointer() - reflect.ValueOf(mi.obj).Pointer()
 	objType := reflect.TypeOf(mi.obj).Elem()
+	for column, index := range mi.indexes {
+		// An embedded struct and its first field share the offset, so the type is checked too
+		fieldOffset, fieldType := fieldOffsetByIndex(objType, index)
+		if fieldOffset == offset && fieldType == fieldPtrVal.Type().Elem() {
+			return column, nil
+		}
+	}
 	for i := 0; i < objType.NumField(); i++ {
 		if objType.Field(i).Offset == offset {
-			column := objType.Field(i).Tag.Get("ovs")
-			if _, ok := mi.fields[column]; !ok {
-				return "", fmt.Errorf("field does not have orm column information")
-			}
-			return column, nil
+			return "", fmt.Errorf("field does not have orm column information")
 		}
 	}
 	return "", fmt.Errorf("field pminter does not correspond to orm struct")
@@ -100,8 +179,97 @@ OUTER:
 	return validIndexes, nil
 }
 
+// IndexKey holds the values of the columns of an index for which an object has non-default values
+// Two objects hold the same values in all the columns of an index if they have the same Key for it
+type IndexKey struct {
+	Columns []string
+	Key     string
+}
+
+// IndexKeys returns the keys of the indexes (the _uuid column and the indexes defined in the
+// schema) for which the object has non-default values, as returned by getValidIndexes
+func (mi *MapperInfo) IndexKeys() ([]IndexKey, error) {
+	indexes, err := mi.getValidIndexes()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]IndexKey, 0, len(indexes))
+	for _, index := range indexes {
+		values := make([]interface{}, 0, len(index))
+		for _, column := range index {
+			value, err := mi.FieldByColumn(column)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		key, err := json.Marshal(values)
+		if err != nil {
+			return nil, fmt.Errorf("index %v: %w", index, err)
+		}
+		keys = append(keys, IndexKey{Columns: index, Key: string(key)})
+	}
+	return keys, nil
+}
+
+// fieldsCacheKey identifies the field mapping of a type for a table of the schema of a Mapper
+type fieldsCacheKey struct {
+	objType reflect.Type
+	table   string
+}
+
+// fieldMapping holds the mapping between the columns of a table and the fields of a type
+type fieldMapping struct {
+	table     *ovsdb.TableSchema
+	fields    map[string]string
+	indexes   map[string][]int
+	immutable map[string]bool
+	weak      map[string]string
+}
+
 // NewMapperInfo creates a MapperInfo structure around an object based on a given table schema
 func NewMapperInfo(table *ovsdb.TableSchema, obj interface{}) (*MapperInfo, error) {
+	objType, err := mappedType(obj)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := newFieldMapping(table, objType)
+	if err != nil {
+		return nil, err
+	}
+	return mapping.info(obj), nil
+}
+
+// NewMapperInfo is like the package's NewMapperInfo, on the schema of the given table of the Mapper.
+// The mapping between the columns of the table and the fields of the type of the object is
+// computed once and reused by the later calls on the same Mapper, so the schema must not change
+func (m Mapper) NewMapperInfo(tableName string, obj interface{}) (*MapperInfo, error) {
+	objType, err := mappedType(obj)
+	if err != nil {
+		return nil, err
+	}
+	key := fieldsCacheKey{objType: objType, table: tableName}
+	if m.fields != nil {
+		if cached, ok := m.fields.Load(key); ok {
+			return cached.(*fieldMapping).info(obj), nil
+		}
+	}
+	table := m.Schema.Table(tableName)
+	if table == nil {
+		return nil, newErrNoTable(tableName)
+	}
+	mapping, err := newFieldMapping(table, objType)
+	if err != nil {
+		return nil, err
+	}
+	if m.fields != nil {
+		m.fields.Store(key, mapping)
+	}
+	return mapping.info(obj), nil
+}
+
+// mappedType returns the struct type obj points to
+func mappedType(obj interface{}) (reflect.Type, error) {
 	objPtrVal := reflect.ValueOf(obj)
 	if objPtrVal.Type().Kind() != reflect.Ptr {
 		return nil, ovsdb.NewErrWrongType("NewMapperInfo", "pminter to a struct", obj)
@@ -110,44 +278,157 @@ func NewMapperInfo(table *ovsdb.TableSchema, obj interface{}) (*MapperInfo, erro
 	if objVal.Kind() != reflect.Struct {
 		return nil, ovsdb.NewErrWrongType("NewMapperInfo", "pminter to a struct", obj)
 	}
-	objType := objVal.Type()
+	return objVal.Type(), nil
+}
 
+// newFieldMapping computes the mapping between the columns of a table and the fields of a type
+func newFieldMapping(table *ovsdb.TableSchema, objType reflect.Type) (*fieldMapping, error) {
+	fields, indexes, err := newFieldMap(table, objType)
+	if err != nil {
+		return nil, err
+	}
+	immutable := make(map[string]bool)
+	weak := make(map[string]string)
+	for column := range fields {
+		columnSchema := table.Column(column)
+		if columnSchema == nil {
+			continue
+		}
+		if !columnSchema.Mutable() {
+			immutable[column] = true
+		}
+		if refTable, ok := weakReference(columnSchema); ok {
+			weak[column] = refTable
+		}
+	}
+	return &fieldMapping{table: table, fields: fields, indexes: indexes, immutable: immutable, weak: weak}, nil
+}
+
+// info returns the MapperInfo of an object of the mapped type
+func (f *fieldMapping) info(obj interface{}) *MapperInfo {
+	return &MapperInfo{
+		fields:    f.fields,
+		indexes:   f.indexes,
+		immutable: f.immutable,
+		weak:      f.weak,
+		obj:       obj,
+		table:     f.table,
+	}
+}
+
+// newFieldMap returns the field names and index sequences of a struct type indexed by the column
+// they are tagged with. The fields are validated against the table schema
+func newFieldMap(table *ovsdb.TableSchema, objType reflect.Type) (map[string]string, map[string][]int, error) {
 	fields := make(map[string]string, objType.NumField())
+	indexes := make(map[string][]int, objType.NumField())
+	errs := addFields(table, objType, nil, fields, indexes)
+	if len(errs) > 0 {
+		return nil, nil, &ErrMapperColumns{errors: errs}
+	}
+	return fields, indexes, nil
+}
+
+// addFields adds the tagged fields of a struct type, found at the given index sequence, to the
+// field maps. Untagged embedded structs are flattened, their fields being added unless a field of
+// the outer struct is tagged with the same column. All the fields are checked so all errors are
+// reported at once
+func addFields(table *ovsdb.TableSchema, objType reflect.Type, parent []int, fields map[string]string, indexes map[string][]int) []*ErrMapper {
+	var errs []*ErrMapper
+	var embedded []reflect.StructField
 	for i := 0; i < objType.NumField(); i++ {
 		field := objType.Field(i)
-		colName := field.Tag.Get("ovs")
-		if colName == "" {
-			// Untagged fields are ignored
+		tag := field.Tag.Get("ovs")
+		if tag == "" {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				embedded = append(embedded, field)
+			}
+			// Other untagged fields are ignored
 			continue
 		}
-		column := table.Column(colName)
+		// The tag may hold a comma-separated list of alternative column names (e.g: because a
+		// column was renamed between schema versions). The first one that exists in the schema is used
+		colName := tag
+		var column *ovsdb.ColumnSchema
+		for _, alternative := range strings.Split(tag, ",") {
+			if column = table.Column(alternative); column != nil {
+				colName = alternative
+				break
+			}
+		}
 		if column == nil {
-			return nil, &ErrMapper{
+			errs = append(errs, &ErrMapper{
 				objType:   objType.String(),
 				field:     field.Name,
 				fieldType: field.Type.String(),
 				fieldTag:  colName,
 				reason:    "Column does not exist in schema",
-			}
+			})
+			continue
 		}
 
 		// Perform schema-based type checking
 		expType := ovsdb.NativeType(column)
-		if expType != field.Type {
-			return nil, &ErrMapper{
+		optType := ovsdb.NativeOptionalType(column)
+		timeOk := column.Type == ovsdb.TypeInteger && isTimeType(field.Type)
+		if expType != field.Type && (optType == nil || optType != field.Type) && !timeOk {
+			reason := fmt.Sprintf("Wrong type, column expects %s", expType)
+			if optType != nil {
+				reason = fmt.Sprintf("Wrong type, column expects %s or %s", expType, optType)
+			}
+			errs = append(errs, &ErrMapper{
 				objType:   objType.String(),
 				field:     field.Name,
 				fieldType: field.Type.String(),
 				fieldTag:  colName,
-				reason:    fmt.Sprintf("Wrong type, column expects %s", expType),
-			}
+				reason:    reason,
+			})
+			continue
 		}
 		fields[colName] = field.Name
+		indexes[colName] = append(append([]int{}, parent...), field.Index...)
+	}
+	for _, field := range embedded {
+		embeddedFields := make(map[string]string)
+		embeddedIndexes := make(map[string][]int)
+		index := append(append([]int{}, parent...), field.Index...)
+		errs = append(errs, addFields(table, field.Type, index, embeddedFields, embeddedIndexes)...)
+		for column, name := range embeddedFields {
+			if _, ok := fields[column]; !ok {
+				fields[column] = name
+				indexes[column] = embeddedIndexes[column]
+			}
+		}
 	}
+	return errs
+}
 
-	return &MapperInfo{
-		fields: fields,
-		obj:    obj,
-		table:  table,
-	}, nil
+// weakReference returns the table referenced by a column whose key or value is a weak reference
+func weakReference(column *ovsdb.ColumnSchema) (string, bool) {
+	if column.TypeObj == nil {
+		return "", false
+	}
+	for _, baseType := range []*ovsdb.BaseType{column.TypeObj.Key, column.TypeObj.Value} {
+		if baseType == nil || baseType.Type != ovsdb.TypeUUID {
+			continue
+		}
+		refTable, _ := baseType.RefTable()
+		refType, _ := baseType.RefType()
+		if refTable != "" && refType == ovsdb.Weak {
+			return refTable, true
+		}
+	}
+	return "", false
+}
+
+// fieldOffsetByIndex returns the offset, from the beginning of the struct, and the type of the
+// field at the given index sequence
+func fieldOffsetByIndex(objType reflect.Type, index []int) (uintptr, reflect.Type) {
+	var offset uintptr
+	fieldType := objType
+	for _, i := range index {
+		field := fieldType.Field(i)
+		offset += field.Offset
+		fieldType = field.Type
+	}
+	return offset, fieldType
 }
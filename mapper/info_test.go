@@ -3,6 +3,7 @@ package mapper
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/ovn-org/libovsdb/ovsdb"
@@ -72,6 +73,118 @@ func TestNewMapperInfo(t *testing.T) {
 	}
 }
 
+func TestMapperNewMapperInfoFieldsCache(t *testing.T) {
+	type obj struct {
+		AString string `ovs:"aString"`
+		AInt    int    `ovs:"aInteger"`
+	}
+	var table ovsdb.TableSchema
+	if err := json.Unmarshal(sampleTable, &table); err != nil {
+		t.Fatal(err)
+	}
+	var otherTable ovsdb.TableSchema
+	if err := json.Unmarshal([]byte(`{"columns": {"aString": {"type": "string"}}}`), &otherTable); err != nil {
+		t.Fatal(err)
+	}
+	schema := &ovsdb.DatabaseSchema{
+		Name:   "TestDB",
+		Tables: map[string]ovsdb.TableSchema{"TestTable": table, "OtherTable": otherTable},
+	}
+	one := &obj{AString: "one"}
+	other := &obj{AString: "other"}
+
+	// The objects of a type share the field map of a table of the mapper
+	m := NewMapper(schema)
+	oneInfo, err := m.NewMapperInfo("TestTable", one)
+	assert.Nil(t, err)
+	otherInfo, err := m.NewMapperInfo("TestTable", other)
+	assert.Nil(t, err)
+	assert.Equal(t, reflect.ValueOf(oneInfo.fields).Pointer(), reflect.ValueOf(otherInfo.fields).Pointer())
+
+	// The object is not cached
+	value, err := oneInfo.FieldByColumn("aString")
+	assert.Nil(t, err)
+	assert.Equal(t, "one", value)
+	value, err = otherInfo.FieldByColumn("aString")
+	assert.Nil(t, err)
+	assert.Equal(t, "other", value)
+
+	// Another mapper, e.g: of a schema fetched again, computes its own field map
+	otherInfo, err = NewMapper(schema).NewMapperInfo("TestTable", other)
+	assert.Nil(t, err)
+	assert.NotEqual(t, reflect.ValueOf(oneInfo.fields).Pointer(), reflect.ValueOf(otherInfo.fields).Pointer())
+
+	// So does the package's NewMapperInfo on every call
+	otherInfo, err = NewMapperInfo(schema.Table("TestTable"), other)
+	assert.Nil(t, err)
+	assert.NotEqual(t, reflect.ValueOf(oneInfo.fields).Pointer(), reflect.ValueOf(otherInfo.fields).Pointer())
+
+	// Another table computes its own field map, errors are not cached
+	_, err = m.NewMapperInfo("OtherTable", one)
+	assert.NotNil(t, err)
+	_, err = m.NewMapperInfo("OtherTable", one)
+	assert.NotNil(t, err)
+
+	_, err = m.NewMapperInfo("Unknown", one)
+	assert.NotNil(t, err)
+}
+
+func TestNewMapperInfoAliases(t *testing.T) {
+	type obj struct {
+		AString string   `ovs:"aStr,aString"`
+		ASet    []string `ovs:"aSet,aOldSet"`
+	}
+	var table ovsdb.TableSchema
+	if err := json.Unmarshal(sampleTable, &table); err != nil {
+		t.Fatal(err)
+	}
+	o := &obj{AString: "foo", ASet: []string{"bar"}}
+	info, err := NewMapperInfo(&table, o)
+	assert.Nil(t, err)
+
+	value, err := info.FieldByColumn("aString")
+	assert.Nil(t, err)
+	assert.Equal(t, "foo", value)
+	_, err = info.FieldByColumn("aStr")
+	assert.NotNil(t, err)
+	value, err = info.FieldByColumn("aSet")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bar"}, value)
+
+	column, err := info.ColumnByPtr(&o.AString)
+	assert.Nil(t, err)
+	assert.Equal(t, "aString", column)
+
+	type invalid struct {
+		AString string `ovs:"aStr,aStrng"`
+	}
+	_, err = NewMapperInfo(&table, &invalid{})
+	assert.NotNil(t, err)
+}
+
+func TestNewMapperInfoAllErrors(t *testing.T) {
+	var table ovsdb.TableSchema
+	err := json.Unmarshal(sampleTable, &table)
+	assert.Nil(t, err)
+
+	_, err = NewMapperInfo(&table, &struct {
+		Ostring string `ovs:"aString"`
+		Oint    string `ovs:"aInteger"`
+		Oset    []int  `ovs:"aSet"`
+		Ofoo    string `ovs:"foo"`
+	}{})
+	assert.NotNil(t, err)
+	colErr, ok := err.(*ErrMapperColumns)
+	assert.Truef(t, ok, "Error should be an ErrMapperColumns")
+	assert.Len(t, colErr.Errors(), 3)
+	assert.Len(t, colErr.Unwrap(), 3)
+	tags := []string{}
+	for _, e := range colErr.Errors() {
+		tags = append(tags, e.fieldTag)
+	}
+	assert.ElementsMatch(t, []string{"aInteger", "aSet", "foo"}, tags)
+}
+
 func TestMapperInfoSet(t *testing.T) {
 	type obj struct {
 		Ostring string            `ovs:"aString"`
@@ -239,11 +352,18 @@ func TestMapperInfoColByPtr(t *testing.T) {
 
 func TestOrmGetIndex(t *testing.T) {
 	tableSchema := []byte(`{
-      "indexes": [["name"],["composed_1","composed_2"]],
+      "indexes": [["name"],["composed_1","composed_2"],["enabled"]],
       "columns": {
         "name": {
           "type": "string"
         },
+        "enabled": {
+          "type": {
+            "key": "boolean",
+            "min": 0,
+            "max": 1
+          }
+        },
         "composed_1": {
           "type": {
             "key": "string"
@@ -274,6 +394,7 @@ func TestOrmGetIndex(t *testing.T) {
 		Config map[string]string `ovs:"config"`
 		Comp1  string            `ovs:"composed_1"`
 		Comp2  string            `ovs:"composed_2"`
+		Enable []bool            `ovs:"enabled"`
 	}
 	type test struct {
 		name     string
@@ -343,6 +464,22 @@ func TestOrmGetIndex(t *testing.T) {
 			expected: [][]string{{"_uuid"}, {"name"}, {"composed_1", "composed_2"}},
 			err:      false,
 		},
+		{
+			name: "optional boolean explicitly false",
+			obj: &obj{
+				Enable: []bool{false},
+			},
+			expected: [][]string{{"enabled"}},
+			err:      false,
+		},
+		{
+			name: "empty optional boolean",
+			obj: &obj{
+				Enable: []bool{},
+			},
+			expected: [][]string{},
+			err:      false,
+		},
 		{
 			name: "Error: None",
 			obj: &obj{
@@ -368,3 +505,213 @@ func TestOrmGetIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestMapperInfoIndexKeys(t *testing.T) {
+	var table ovsdb.TableSchema
+	err := json.Unmarshal([]byte(`{
+      "indexes": [["name"], ["composed_1", "composed_2"]],
+      "columns": {
+        "name": {"type": "string"},
+        "composed_1": {"type": "string"},
+        "composed_2": {"type": "string"}
+      }
+    }`), &table)
+	assert.Nil(t, err)
+
+	type obj struct {
+		ID     string `ovs:"_uuid"`
+		MyName string `ovs:"name"`
+		Comp1  string `ovs:"composed_1"`
+		Comp2  string `ovs:"composed_2"`
+	}
+	keys := func(o *obj) []IndexKey {
+		info, err := NewMapperInfo(&table, o)
+		assert.Nil(t, err)
+		keys, err := info.IndexKeys()
+		assert.Nil(t, err)
+		return keys
+	}
+
+	assert.Empty(t, keys(&obj{}))
+	one := keys(&obj{ID: aUUID0, MyName: "foo", Comp1: "a", Comp2: "b"})
+	assert.Len(t, one, 3)
+	assert.Equal(t, []string{"_uuid"}, one[0].Columns)
+	assert.Equal(t, []string{"name"}, one[1].Columns)
+	assert.Equal(t, []string{"composed_1", "composed_2"}, one[2].Columns)
+
+	other := keys(&obj{MyName: "foo", Comp1: "a b"})
+	assert.Len(t, other, 1)
+	assert.Equal(t, one[1], other[0], "same name")
+	other = keys(&obj{MyName: "bar", Comp1: "a", Comp2: "b"})
+	assert.Equal(t, one[2], other[1], "same composed index")
+	other = keys(&obj{Comp1: "a b", Comp2: ""})
+	assert.Empty(t, other, "composed index with a default value")
+	other = keys(&obj{Comp1: "a", Comp2: "c"})
+	assert.NotEqual(t, one[2], other[0])
+}
+
+func TestMapperInfoImmutableColumns(t *testing.T) {
+	var table ovsdb.TableSchema
+	err := json.Unmarshal([]byte(`{
+      "columns": {
+        "name": {"type": "string", "mutable": false},
+        "type": {"type": "string", "mutable": false},
+        "config": {"type": {"key": "string", "value": "string"}},
+        "unmapped": {"type": "string", "mutable": false}
+      }
+    }`), &table)
+	assert.Nil(t, err)
+
+	type obj struct {
+		UUID   string            `ovs:"_uuid"`
+		Name   string            `ovs:"name"`
+		Type   string            `ovs:"type"`
+		Config map[string]string `ovs:"config"`
+	}
+
+	info, err := NewMapperInfo(&table, &obj{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"name", "type"}, info.ImmutableColumns())
+	assert.True(t, info.IsImmutable("name"))
+	assert.False(t, info.IsImmutable("config"))
+	assert.False(t, info.IsImmutable("_uuid"))
+	// Only the mapped columns are reported
+	assert.False(t, info.IsImmutable("unmapped"))
+}
+
+func TestMapperInfoFieldPtrByColumn(t *testing.T) {
+	var table ovsdb.TableSchema
+	err := json.Unmarshal(sampleTable, &table)
+	assert.Nil(t, err)
+
+	type obj struct {
+		AString  string `ovs:"aString"`
+		AInteger int    `ovs:"aInteger"`
+	}
+	o := &obj{}
+	info, err := NewMapperInfo(&table, o)
+	assert.Nil(t, err)
+
+	ptr, err := info.FieldPtrByColumn("aInteger")
+	assert.Nil(t, err)
+	assert.Equal(t, &o.AInteger, ptr)
+	*ptr.(*int) = 42
+	assert.Equal(t, 42, o.AInteger)
+	column, err := info.ColumnByPtr(ptr)
+	assert.Nil(t, err)
+	assert.Equal(t, "aInteger", column)
+
+	_, err = info.FieldPtrByColumn("aSet")
+	assert.NotNil(t, err)
+}
+
+type commonColumns struct {
+	AString string            `ovs:"aString"`
+	AMap    map[string]string `ovs:"aMap"`
+}
+
+func TestMapperInfoEmbeddedStruct(t *testing.T) {
+	var table ovsdb.TableSchema
+	err := json.Unmarshal(sampleTable, &table)
+	assert.Nil(t, err)
+
+	type obj struct {
+		commonColumns
+		AInteger int `ovs:"aInteger"`
+	}
+	o := &obj{}
+	info, err := NewMapperInfo(&table, o)
+	assert.Nil(t, err)
+
+	err = info.SetField("aString", "foo")
+	assert.Nil(t, err)
+	assert.Equal(t, "foo", o.AString)
+	err = info.SetField("aMap", map[string]string{"key": "value"})
+	assert.Nil(t, err)
+	err = info.SetField("aInteger", 42)
+	assert.Nil(t, err)
+
+	value, err := info.FieldByColumn("aMap")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"key": "value"}, value)
+	value, err = info.FieldByColumn("aInteger")
+	assert.Nil(t, err)
+	assert.Equal(t, 42, value)
+
+	for column, ptr := range map[string]interface{}{"aString": &o.AString, "aMap": &o.AMap, "aInteger": &o.AInteger} {
+		got, err := info.ColumnByPtr(ptr)
+		assert.Nil(t, err)
+		assert.Equal(t, column, got)
+	}
+	_, err = info.ColumnByPtr(&o.commonColumns)
+	assert.NotNil(t, err)
+
+	t.Run("EmbeddedStruct: outer fields take precedence", func(t *testing.T) {
+		type shadowed struct {
+			commonColumns
+			Name string `ovs:"aString"`
+		}
+		s := &shadowed{}
+		info, err := NewMapperInfo(&table, s)
+		assert.Nil(t, err)
+		err = info.SetField("aString", "foo")
+		assert.Nil(t, err)
+		assert.Equal(t, "foo", s.Name)
+		assert.Equal(t, "", s.AString)
+	})
+
+	t.Run("EmbeddedStruct: errors in embedded fields are reported", func(t *testing.T) {
+		type wrong struct {
+			AInteger int `ovs:"aInteger"`
+		}
+		type bad struct {
+			wrong
+			Other string `ovs:"unknown"`
+		}
+		_, err := NewMapperInfo(&table, &bad{})
+		assert.NotNil(t, err)
+		type badEmbedded struct {
+			AString int `ovs:"aString"`
+		}
+		type outer struct {
+			badEmbedded
+		}
+		_, err = NewMapperInfo(&table, &outer{})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestMapperInfoWeakReferences(t *testing.T) {
+	var table ovsdb.TableSchema
+	err := json.Unmarshal([]byte(`{
+      "columns": {
+        "weakSet": {"type": {"key": {"type": "uuid", "refTable": "Other", "refType": "weak"}, "min": 0, "max": "unlimited"}},
+        "weakMap": {"type": {"key": "string", "value": {"type": "uuid", "refTable": "Another", "refType": "weak"}, "min": 0, "max": "unlimited"}},
+        "strong": {"type": {"key": {"type": "uuid", "refTable": "Other"}}},
+        "plain": {"type": "uuid"},
+        "unmapped": {"type": {"key": {"type": "uuid", "refTable": "Other", "refType": "weak"}}}
+      }
+    }`), &table)
+	assert.Nil(t, err)
+
+	type obj struct {
+		WeakSet []string          `ovs:"weakSet"`
+		WeakMap map[string]string `ovs:"weakMap"`
+		Strong  string            `ovs:"strong"`
+		Plain   string            `ovs:"plain"`
+	}
+	info, err := NewMapperInfo(&table, &obj{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"weakMap", "weakSet"}, info.WeakReferenceColumns())
+
+	refTable, ok := info.WeakReference("weakSet")
+	assert.True(t, ok)
+	assert.Equal(t, "Other", refTable)
+	refTable, ok = info.WeakReference("weakMap")
+	assert.True(t, ok)
+	assert.Equal(t, "Another", refTable)
+	for _, column := range []string{"strong", "plain", "unmapped"} {
+		_, ok := info.WeakReference(column)
+		assert.False(t, ok, column)
+	}
+}
@@ -3,6 +3,9 @@ package mapper
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"sync"
+	"strings"
 
 	"github.com/ovn-org/libovsdb/ovsdb"
 )
@@ -20,6 +23,8 @@ import (
 //  }
 type Mapper struct {
 	Schema *ovsdb.DatabaseSchema
+	// fields holds the field mappings computed by NewMapperInfo, by fieldsCacheKey
+	fields *sync.Map
 }
 
 // ErrMapper describes an error in an Mapper type
@@ -36,6 +41,37 @@ func (e *ErrMapper) Error() string {
 		e.objType, e.field, e.fieldType, e.fieldTag, e.reason)
 }
 
+// ErrMapperColumns aggregates the ErrMapper errors found in the different fields of
+// an object, so that all of them can be reported at once
+type ErrMapperColumns struct {
+	errors []*ErrMapper
+}
+
+func (e *ErrMapperColumns) Error() string {
+	if len(e.errors) == 1 {
+		return e.errors[0].Error()
+	}
+	msgs := make([]string, 0, len(e.errors))
+	for _, err := range e.errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d Mapper Errors: %s", len(e.errors), strings.Join(msgs, "; "))
+}
+
+// Errors returns the individual ErrMapper errors
+func (e *ErrMapperColumns) Errors() []*ErrMapper {
+	return e.errors
+}
+
+// Unwrap returns the individual errors
+func (e *ErrMapperColumns) Unwrap() []error {
+	errs := make([]error, 0, len(e.errors))
+	for _, err := range e.errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
 // ErrNoTable describes a error in the provided table information
 type ErrNoTable struct {
 	table string
@@ -55,6 +91,7 @@ func newErrNoTable(table string) error {
 func NewMapper(schema *ovsdb.DatabaseSchema) *Mapper {
 	return &Mapper{
 		Schema: schema,
+		fields: &sync.Map{},
 	}
 }
 
@@ -76,7 +113,7 @@ func (m Mapper) getData(tableName string, ovsData ovsdb.Row, result interface{})
 		return newErrNoTable(tableName)
 	}
 
-	mapperInfo, err := NewMapperInfo(table, result)
+	mapperInfo, err := m.NewMapperInfo(tableName, result)
 	if err != nil {
 		return err
 	}
@@ -103,18 +140,42 @@ func (m Mapper) getData(tableName string, ovsData ovsdb.Row, result interface{})
 			return err
 		}
 	}
+
+	// The _version column is not part of the schema but is sent if requested (e.g: monitored)
+	if ovsElem, ok := ovsData["_version"]; ok && mapperInfo.hasColumn("_version") {
+		nativeElem, err := ovsdb.OvsToNative(&ovsdb.VersionColumn, ovsElem)
+		if err != nil {
+			return fmt.Errorf("table %s, column _version: failed to extract native element: %s",
+				tableName, err.Error())
+		}
+		if err := mapperInfo.SetField("_version", nativeElem); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// OVSDBMarshaler can be implemented by models (e.g: by generated code) to transform themselves into
+// an ovsdb.Row without the cost of reflection. The returned row must be the one NewRow would return:
+// it holds the columns whose values are not the default ones, in their OVSDB representation
+// (see ovsdb.NativeToOvs)
+type OVSDBMarshaler interface {
+	MarshalOVSDB() (ovsdb.Row, error)
+}
+
 // NewRow transforms an orm struct to a map[string] interface{} that can be used as libovsdb.Row
 // By default, default or null values are skipped. This behaviour can be modified by specifying
 // a list of fields (pointers to fields in the struct) to be added to the row
+// If no fields are specified and the struct implements OVSDBMarshaler, the row it returns is used
 func (m Mapper) NewRow(tableName string, data interface{}, fields ...interface{}) (ovsdb.Row, error) {
 	table := m.Schema.Table(tableName)
 	if table == nil {
 		return nil, newErrNoTable(tableName)
 	}
-	mapperInfo, err := NewMapperInfo(table, data)
+	if marshaler, ok := data.(OVSDBMarshaler); ok && len(fields) == 0 {
+		return marshaler.MarshalOVSDB()
+	}
+	mapperInfo, err := m.NewMapperInfo(tableName, data)
 	if err != nil {
 		return nil, err
 	}
@@ -174,7 +235,7 @@ func (m Mapper) NewEqualityCondition(tableName string, data interface{}, fields
 		return nil, newErrNoTable(tableName)
 	}
 
-	mapperInfo, err := NewMapperInfo(table, data)
+	mapperInfo, err := m.NewMapperInfo(tableName, data)
 	if err != nil {
 		return nil, err
 	}
@@ -233,7 +294,7 @@ func (m Mapper) EqualFields(tableName string, one, other interface{}, fields ...
 		return false, newErrNoTable(tableName)
 	}
 
-	info, err := NewMapperInfo(table, one)
+	info, err := m.NewMapperInfo(tableName, one)
 	if err != nil {
 		return false, err
 	}
@@ -247,14 +308,52 @@ func (m Mapper) EqualFields(tableName string, one, other interface{}, fields ...
 	return m.equalIndexes(table, one, other, indexes...)
 }
 
+// ChangedColumns compares two mapped objects of the same table and returns the sorted list
+// of the columns whose values differ. Only columns mapped by both objects are compared
+func (m Mapper) ChangedColumns(tableName string, one, other interface{}) ([]string, error) {
+	table := m.Schema.Table(tableName)
+	if table == nil {
+		return nil, newErrNoTable(tableName)
+	}
+	oneInfo, err := m.NewMapperInfo(tableName, one)
+	if err != nil {
+		return nil, err
+	}
+	otherInfo, err := m.NewMapperInfo(tableName, other)
+	if err != nil {
+		return nil, err
+	}
+	changed := []string{}
+	for column := range oneInfo.fields {
+		if !otherInfo.hasColumn(column) {
+			continue
+		}
+		oneVal, err := oneInfo.FieldByColumn(column)
+		if err != nil {
+			return nil, err
+		}
+		otherVal, err := otherInfo.FieldByColumn(column)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(oneVal, otherVal) {
+			changed = append(changed, column)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
 // NewCondition returns a ovsdb.Condition based on the model
+// Values of set columns, including optional scalars given as a nil pointer or an empty slice, are
+// encoded as sets: the empty set as ["set",[]] and a set of exactly one element as the element itself
 func (m Mapper) NewCondition(tableName string, data interface{}, field interface{}, function ovsdb.ConditionFunction, value interface{}) (*ovsdb.Condition, error) {
 	table := m.Schema.Table(tableName)
 	if table == nil {
 		return nil, newErrNoTable(tableName)
 	}
 
-	info, err := NewMapperInfo(table, data)
+	info, err := m.NewMapperInfo(tableName, data)
 	if err != nil {
 		return nil, err
 	}
@@ -269,8 +368,9 @@ func (m Mapper) NewCondition(tableName string, data interface{}, field interface
 	if columnSchema == nil {
 		return nil, fmt.Errorf("column %s not found", column)
 	}
+	value = timeToNative(value)
 	if err := ovsdb.ValidateCondition(columnSchema, function, value); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("condition on column %s: %w", column, err)
 	}
 
 	ovsValue, err := ovsdb.NativeToOvs(columnSchema, value)
@@ -292,7 +392,7 @@ func (m Mapper) NewMutation(tableName string, data interface{}, column string, m
 		return nil, newErrNoTable(tableName)
 	}
 
-	mapperInfo, err := NewMapperInfo(table, data)
+	mapperInfo, err := m.NewMapperInfo(tableName, data)
 	if err != nil {
 		return nil, err
 	}
@@ -306,12 +406,13 @@ func (m Mapper) NewMutation(tableName string, data interface{}, column string, m
 	if columnSchema == nil {
 		return nil, fmt.Errorf("column %s not found", column)
 	}
+	value = timeToNative(value)
 	if err := ovsdb.ValidateMutation(columnSchema, mutator, value); err != nil {
 		return nil, err
 	}
 
 	var ovsValue interface{}
-	if mutator == "delete" && columnSchema.Type == ovsdb.TypeMap {
+	if mutator == "delete" && columnSchema.Type == ovsdb.TypeMap && reflect.TypeOf(value).Kind() != reflect.Map {
 		// It's OK to cast the value to a list of elemets because validation has passed
 		ovsSet, err := ovsdb.NewOvsSet(value)
 		if err != nil {
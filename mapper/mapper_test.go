@@ -350,6 +350,191 @@ func TestMapperNewRow(t *testing.T) {
 	}
 }
 
+func TestMapperOptionalPointer(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	if err := json.Unmarshal(testSchema, &schema); err != nil {
+		t.Error(err)
+	}
+	mapper := NewMapper(&schema)
+
+	type obj struct {
+		ASingleSet *string `ovs:"aSingleSet"`
+	}
+
+	t.Run("non-nil pointer round trip", func(t *testing.T) {
+		value := aString
+		row, err := mapper.NewRow("TestTable", &obj{ASingleSet: &value})
+		assert.Nil(t, err)
+		assert.Equal(t, ovsdb.Row(map[string]interface{}{"aSingleSet": testOvsSet(t, []string{aString})}), row)
+
+		// Data comes from the wire either as singleton set or as the bare atom
+		for _, ovsElem := range []interface{}{*testOvsSet(t, []string{aString}), aString} {
+			result := obj{}
+			err = mapper.GetRowData("TestTable", &ovsdb.Row{"aSingleSet": ovsElem}, &result)
+			assert.Nil(t, err)
+			assert.Equal(t, &value, result.ASingleSet)
+		}
+	})
+
+	t.Run("nil pointer round trip", func(t *testing.T) {
+		row, err := mapper.NewRow("TestTable", &obj{})
+		assert.Nil(t, err)
+		assert.Equal(t, ovsdb.Row(map[string]interface{}{}), row)
+
+		value := aString
+		result := obj{ASingleSet: &value}
+		err = mapper.GetRowData("TestTable", &ovsdb.Row{"aSingleSet": *testOvsSet(t, []string{})}, &result)
+		assert.Nil(t, err)
+		assert.Nil(t, result.ASingleSet)
+	})
+
+	t.Run("nil pointer explicitly added to row", func(t *testing.T) {
+		o := obj{}
+		row, err := mapper.NewRow("TestTable", &o, &o.ASingleSet)
+		assert.Nil(t, err)
+		assert.Equal(t, ovsdb.Row(map[string]interface{}{"aSingleSet": testOvsSet(t, []string{})}), row)
+	})
+
+	t.Run("pointers are not valid for unlimited sets", func(t *testing.T) {
+		_, err := mapper.NewRow("TestTable", &struct {
+			ASet *string `ovs:"aSet"`
+		}{})
+		assert.NotNil(t, err)
+	})
+}
+
+type marshalerTestType struct {
+	AString  string            `ovs:"aString"`
+	ASet     []string          `ovs:"aSet"`
+	AUUIDSet []string          `ovs:"aUUIDSet"`
+	AIntSet  []int             `ovs:"aIntSet"`
+	AFloat   float64           `ovs:"aFloat"`
+	AMap     map[string]string `ovs:"aMap"`
+}
+
+// reflectTestType is the same as marshalerTestType without the OVSDBMarshaler implementation
+type reflectTestType marshalerTestType
+
+func (m *marshalerTestType) MarshalOVSDB() (ovsdb.Row, error) {
+	row := make(ovsdb.Row, 6)
+	if m.AString != "" {
+		row["aString"] = m.AString
+	}
+	if len(m.ASet) > 0 {
+		set := make([]interface{}, 0, len(m.ASet))
+		for _, elem := range m.ASet {
+			set = append(set, elem)
+		}
+		row["aSet"] = &ovsdb.OvsSet{GoSet: set}
+	}
+	if len(m.AUUIDSet) > 0 {
+		set := make([]interface{}, 0, len(m.AUUIDSet))
+		for _, elem := range m.AUUIDSet {
+			set = append(set, ovsdb.UUID{GoUUID: elem})
+		}
+		row["aUUIDSet"] = &ovsdb.OvsSet{GoSet: set}
+	}
+	if len(m.AIntSet) > 0 {
+		set := make([]interface{}, 0, len(m.AIntSet))
+		for _, elem := range m.AIntSet {
+			set = append(set, elem)
+		}
+		row["aIntSet"] = &ovsdb.OvsSet{GoSet: set}
+	}
+	if m.AFloat != 0 {
+		row["aFloat"] = m.AFloat
+	}
+	if len(m.AMap) > 0 {
+		goMap := make(map[interface{}]interface{}, len(m.AMap))
+		for k, v := range m.AMap {
+			goMap[k] = v
+		}
+		row["aMap"] = &ovsdb.OvsMap{GoMap: goMap}
+	}
+	return row, nil
+}
+
+func TestMapperNewRowMarshaler(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	if err := json.Unmarshal(testSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	mapper := NewMapper(&schema)
+
+	tests := []struct {
+		name string
+		obj  marshalerTestType
+	}{
+		{
+			name: "empty",
+			obj:  marshalerTestType{},
+		},
+		{
+			name: "full",
+			obj: marshalerTestType{
+				AString:  aString,
+				ASet:     aSet,
+				AUUIDSet: aUUIDSet,
+				AIntSet:  aIntSet,
+				AFloat:   aFloat,
+				AMap:     aMap,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("NewRowMarshaler: %s", tt.name), func(t *testing.T) {
+			obj := tt.obj
+			row, err := mapper.NewRow("TestTable", &obj)
+			assert.Nil(t, err)
+			reflectObj := reflectTestType(tt.obj)
+			expected, err := mapper.NewRow("TestTable", &reflectObj)
+			assert.Nil(t, err)
+			assert.Equal(t, expected, row)
+
+			// Specifying fields uses reflection
+			row, err = mapper.NewRow("TestTable", &obj, &obj.AString)
+			assert.Nil(t, err)
+			assert.Equal(t, ovsdb.Row{"aString": obj.AString}, row)
+		})
+	}
+}
+
+func benchmarkNewRow(b *testing.B, obj interface{}) {
+	var schema ovsdb.DatabaseSchema
+	if err := json.Unmarshal(testSchema, &schema); err != nil {
+		b.Fatal(err)
+	}
+	mapper := NewMapper(&schema)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := mapper.NewRow("TestTable", obj); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewRowReflect(b *testing.B) {
+	benchmarkNewRow(b, &reflectTestType{
+		AString:  aString,
+		ASet:     aSet,
+		AUUIDSet: aUUIDSet,
+		AIntSet:  aIntSet,
+		AFloat:   aFloat,
+		AMap:     aMap,
+	})
+}
+
+func BenchmarkNewRowMarshaler(b *testing.B) {
+	benchmarkNewRow(b, &marshalerTestType{
+		AString:  aString,
+		ASet:     aSet,
+		AUUIDSet: aUUIDSet,
+		AIntSet:  aIntSet,
+		AFloat:   aFloat,
+		AMap:     aMap,
+	})
+}
+
 func TestMapperNewRowFields(t *testing.T) {
 	var schema ovsdb.DatabaseSchema
 	if err := json.Unmarshal(testSchema, &schema); err != nil {
@@ -600,6 +785,112 @@ func TestMapperCondition(t *testing.T) {
 	}
 }
 
+func TestMapperConditionSetEncoding(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal([]byte(`{
+		"name": "TestDB",
+		"tables": {
+			"TestTable": {
+				"columns": {
+					"optional": {"type": {"key": "boolean", "min": 0, "max": 1}},
+					"optional_ref": {"type": {"key": {"type": "uuid"}, "min": 0, "max": 1}},
+					"set": {"type": {"key": "string", "min": 0, "max": "unlimited"}},
+					"refs": {"type": {"key": {"type": "uuid"}, "min": 0, "max": "unlimited"}}
+				}
+			}
+		}
+	}`), &schema)
+	assert.Nil(t, err)
+	mapper := NewMapper(&schema)
+
+	type testType struct {
+		Optional    []bool   `ovs:"optional"`
+		OptionalRef *string  `ovs:"optional_ref"`
+		Set         []string `ovs:"set"`
+		Refs        []string `ovs:"refs"`
+	}
+	obj := &testType{}
+	var unset *string
+	ref := aUUID0
+
+	tests := []struct {
+		name     string
+		field    interface{}
+		value    interface{}
+		expected string
+	}{
+		{
+			name:     "empty optional",
+			field:    &obj.Optional,
+			value:    []bool{},
+			expected: `["optional","==",["set",[]]]`,
+		},
+		{
+			name:     "nil optional",
+			field:    &obj.Optional,
+			value:    []bool(nil),
+			expected: `["optional","==",["set",[]]]`,
+		},
+		{
+			name:     "singleton optional",
+			field:    &obj.Optional,
+			value:    []bool{false},
+			expected: `["optional","==",false]`,
+		},
+		{
+			name:     "unset optional pointer",
+			field:    &obj.OptionalRef,
+			value:    unset,
+			expected: `["optional_ref","==",["set",[]]]`,
+		},
+		{
+			name:     "set optional pointer",
+			field:    &obj.OptionalRef,
+			value:    &ref,
+			expected: `["optional_ref","==",["uuid","` + aUUID0 + `"]]`,
+		},
+		{
+			name:     "empty set",
+			field:    &obj.Set,
+			value:    []string{},
+			expected: `["set","==",["set",[]]]`,
+		},
+		{
+			name:     "singleton set",
+			field:    &obj.Set,
+			value:    []string{"foo"},
+			expected: `["set","==","foo"]`,
+		},
+		{
+			name:     "set of two elements",
+			field:    &obj.Set,
+			value:    []string{"foo", "bar"},
+			expected: `["set","==",["set",["foo","bar"]]]`,
+		},
+		{
+			name:     "empty set of references",
+			field:    &obj.Refs,
+			value:    []string{},
+			expected: `["refs","==",["set",[]]]`,
+		},
+		{
+			name:     "singleton set of references",
+			field:    &obj.Refs,
+			value:    []string{aUUID0},
+			expected: `["refs","==",["uuid","` + aUUID0 + `"]]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("ConditionSetEncoding: %s", tt.name), func(t *testing.T) {
+			cond, err := mapper.NewCondition("TestTable", obj, tt.field, ovsdb.ConditionEqual, tt.value)
+			assert.Nil(t, err)
+			data, err := json.Marshal(cond)
+			assert.Nil(t, err)
+			assert.JSONEq(t, tt.expected, string(data))
+		})
+	}
+}
+
 func TestMapperEqualIndexes(t *testing.T) {
 
 	var testSchema = []byte(`{
@@ -865,6 +1156,75 @@ func TestMapperEqualIndexes(t *testing.T) {
 
 }
 
+func TestMapperChangedColumns(t *testing.T) {
+	var testSchema = []byte(`{
+  "cksum": "223619766 22548",
+  "name": "TestSchema",
+  "tables": {
+    "TestTable": {
+      "columns": {
+        "name": {
+          "type": "string"
+        },
+        "int1": {
+          "type": "integer"
+        },
+        "config": {
+          "type": {
+            "key": "string",
+            "max": "unlimited",
+            "min": 0,
+            "value": "string"
+          }
+        }
+      }
+    }
+  }
+}`)
+	type testType struct {
+		ID     string            `ovs:"_uuid"`
+		MyName string            `ovs:"name"`
+		Config map[string]string `ovs:"config"`
+		Int1   int               `ovs:"int1"`
+	}
+	type partialType struct {
+		ID     string `ovs:"_uuid"`
+		MyName string `ovs:"name"`
+	}
+
+	var schema ovsdb.DatabaseSchema
+	if err := json.Unmarshal(testSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	mapper := NewMapper(&schema)
+
+	obj1 := testType{
+		ID:     aUUID0,
+		MyName: "name1",
+		Config: map[string]string{"foo": "bar"},
+		Int1:   42,
+	}
+	obj2 := obj1
+	changed, err := mapper.ChangedColumns("TestTable", &obj1, &obj2)
+	assert.Nil(t, err)
+	assert.Empty(t, changed)
+
+	obj2.Config = map[string]string{"foo": "baz"}
+	obj2.MyName = "name2"
+	changed, err = mapper.ChangedColumns("TestTable", &obj1, &obj2)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"config", "name"}, changed)
+
+	// Only the columns mapped by both objects are compared
+	partial := partialType{ID: aUUID0, MyName: "name1"}
+	changed, err = mapper.ChangedColumns("TestTable", &partial, &obj2)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"name"}, changed)
+
+	_, err = mapper.ChangedColumns("Unknown", &obj1, &obj2)
+	assert.NotNil(t, err)
+}
+
 func TestMapperMutation(t *testing.T) {
 
 	var testSchema = []byte(`{
@@ -988,6 +1348,15 @@ func TestMapperMutation(t *testing.T) {
 			expected: ovsdb.NewMutation("map", ovsdb.MutateOperationDelete, testOvsSet(t, []string{"foo", "bar"})),
 			err:      false,
 		},
+		{
+			name:     "Delete key-value pairs from map ",
+			column:   "map",
+			obj:      testType{},
+			mutator:  ovsdb.MutateOperationDelete,
+			value:    map[string]string{"foo": "bar"},
+			expected: ovsdb.NewMutation("map", ovsdb.MutateOperationDelete, testOvsMap(t, map[string]string{"foo": "bar"})),
+			err:      false,
+		},
 		{
 			name:     "Insert elements in map ",
 			column:   "map",
@@ -1027,3 +1396,231 @@ func testOvsMap(t *testing.T, set interface{}) *ovsdb.OvsMap {
 	assert.Nil(t, err)
 	return oMap
 }
+
+func TestMapperRealRoundTrip(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	if err := json.Unmarshal(testSchema, &schema); err != nil {
+		t.Error(err)
+	}
+	mapper := NewMapper(&schema)
+
+	type obj struct {
+		AFloat    float64   `ovs:"aFloat"`
+		AFloatSet []float64 `ovs:"aFloatSet"`
+	}
+	for _, value := range []float64{1e9, 0.125, 3, -2.5e-7} {
+		t.Run(fmt.Sprintf("RealRoundTrip: %v", value), func(t *testing.T) {
+			in := &obj{AFloat: value, AFloatSet: []float64{value, 1}}
+			row, err := mapper.NewRow("TestTable", in)
+			assert.Nil(t, err)
+			data, err := json.Marshal(row)
+			assert.Nil(t, err)
+
+			var decoded ovsdb.Row
+			err = json.Unmarshal(data, &decoded)
+			assert.Nil(t, err)
+			out := &obj{}
+			err = mapper.GetRowData("TestTable", &decoded, out)
+			assert.Nil(t, err)
+			assert.Equal(t, in, out)
+
+			mutation, err := mapper.NewMutation("TestTable", in, "aFloat", ovsdb.MutateOperationMultiply, value)
+			assert.Nil(t, err)
+			assert.Equal(t, value, mutation.Value)
+		})
+	}
+}
+
+func TestMapperBooleanRoundTrip(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal([]byte(`{
+		"name": "TestDB",
+		"tables": {
+			"TestTable": {
+				"columns": {
+					"required": {"type": "boolean"},
+					"optional": {"type": {"key": "boolean", "min": 0, "max": 1}}
+				}
+			}
+		}
+	}`), &schema)
+	assert.Nil(t, err)
+	mapper := NewMapper(&schema)
+
+	type sliceObj struct {
+		Required bool   `ovs:"required"`
+		Optional []bool `ovs:"optional"`
+	}
+	type ptrObj struct {
+		Required bool  `ovs:"required"`
+		Optional *bool `ovs:"optional"`
+	}
+
+	t.Run("BooleanRoundTrip: field types", func(t *testing.T) {
+		table := schema.Table("TestTable")
+		_, err := NewMapperInfo(table, &sliceObj{})
+		assert.Nil(t, err)
+		_, err = NewMapperInfo(table, &ptrObj{})
+		assert.Nil(t, err)
+		_, err = NewMapperInfo(table, &struct {
+			Required []bool `ovs:"required"`
+		}{})
+		assert.NotNil(t, err, "a required boolean is not a set")
+		_, err = NewMapperInfo(table, &struct {
+			Required *bool `ovs:"required"`
+		}{})
+		assert.NotNil(t, err, "a required boolean is not optional")
+		_, err = NewMapperInfo(table, &struct {
+			Optional bool `ovs:"optional"`
+		}{})
+		assert.NotNil(t, err, "an optional boolean may be empty")
+	})
+
+	t.Run("BooleanRoundTrip: serialization", func(t *testing.T) {
+		value := true
+		tests := []struct {
+			name     string
+			in       interface{}
+			out      interface{}
+			expected string
+		}{
+			{
+				name:     "required and optional slice",
+				in:       &sliceObj{Required: true, Optional: []bool{false}},
+				out:      &sliceObj{},
+				expected: `{"required":true,"optional":false}`,
+			},
+			{
+				name:     "required and optional pointer",
+				in:       &ptrObj{Required: true, Optional: &value},
+				out:      &ptrObj{},
+				expected: `{"required":true,"optional":true}`,
+			},
+			{
+				name:     "empty optional",
+				in:       &ptrObj{Required: true},
+				out:      &ptrObj{},
+				expected: `{"required":true}`,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				row, err := mapper.NewRow("TestTable", tt.in)
+				assert.Nil(t, err)
+				data, err := json.Marshal(row)
+				assert.Nil(t, err)
+				assert.JSONEq(t, tt.expected, string(data))
+
+				var decoded ovsdb.Row
+				err = json.Unmarshal(data, &decoded)
+				assert.Nil(t, err)
+				err = mapper.GetRowData("TestTable", &decoded, tt.out)
+				assert.Nil(t, err)
+				assert.Equal(t, tt.in, tt.out)
+			})
+		}
+	})
+
+	t.Run("BooleanRoundTrip: false is written when requested", func(t *testing.T) {
+		obj := &ptrObj{}
+		row, err := mapper.NewRow("TestTable", obj, &obj.Required, &obj.Optional)
+		assert.Nil(t, err)
+		data, err := json.Marshal(row)
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"required":false,"optional":["set",[]]}`, string(data))
+	})
+
+	t.Run("BooleanRoundTrip: conditions", func(t *testing.T) {
+		obj := &ptrObj{}
+		cond, err := mapper.NewCondition("TestTable", obj, &obj.Required, ovsdb.ConditionEqual, true)
+		assert.Nil(t, err)
+		assert.Equal(t, true, cond.Value)
+		_, err = mapper.NewCondition("TestTable", obj, &obj.Required, ovsdb.ConditionEqual, []bool{true})
+		assert.NotNil(t, err)
+
+		value := false
+		cond, err = mapper.NewCondition("TestTable", obj, &obj.Optional, ovsdb.ConditionEqual, &value)
+		assert.Nil(t, err)
+		data, err := json.Marshal(cond)
+		assert.Nil(t, err)
+		assert.JSONEq(t, `["optional","==",false]`, string(data))
+	})
+}
+
+func TestMapperIntegerMapRoundTrip(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal([]byte(`{
+		"name": "TestDB",
+		"tables": {
+			"TestTable": {
+				"columns": {
+					"intKeys": {"type": {"key": "integer", "value": "string", "min": 0, "max": "unlimited"}},
+					"intValues": {"type": {"key": "string", "value": "integer", "min": 0, "max": "unlimited"}}
+				}
+			}
+		}
+	}`), &schema)
+	assert.Nil(t, err)
+	mapper := NewMapper(&schema)
+
+	type obj struct {
+		IntKeys   map[int]string `ovs:"intKeys"`
+		IntValues map[string]int `ovs:"intValues"`
+	}
+	in := &obj{
+		IntKeys:   map[int]string{1: "one", 42: "forty-two"},
+		IntValues: map[string]int{"one": 1, "forty-two": 42},
+	}
+	row, err := mapper.NewRow("TestTable", in)
+	assert.Nil(t, err)
+	data, err := json.Marshal(row)
+	assert.Nil(t, err)
+
+	var wire map[string][]interface{}
+	err = json.Unmarshal(data, &wire)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []interface{}{[]interface{}{float64(1), "one"}, []interface{}{float64(42), "forty-two"}}, wire["intKeys"][1])
+
+	var decoded ovsdb.Row
+	err = json.Unmarshal(data, &decoded)
+	assert.Nil(t, err)
+	out := &obj{}
+	err = mapper.GetRowData("TestTable", &decoded, out)
+	assert.Nil(t, err)
+	assert.Equal(t, in, out)
+
+	mutation, err := mapper.NewMutation("TestTable", in, "intKeys", ovsdb.MutateOperationDelete, []int{42})
+	assert.Nil(t, err)
+	data, err = json.Marshal(mutation)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `["intKeys","delete",42]`, string(data))
+}
+
+func TestMapperVersion(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	if err := json.Unmarshal(testSchema, &schema); err != nil {
+		t.Error(err)
+	}
+	mapper := NewMapper(&schema)
+
+	type obj struct {
+		UUID    string `ovs:"_uuid"`
+		Version string `ovs:"_version"`
+		AString string `ovs:"aString"`
+	}
+
+	row := ovsdb.Row{"aString": aString, "_version": ovsdb.UUID{GoUUID: aUUID1}}
+	out := &obj{}
+	err := mapper.GetRowData("TestTable", &row, out)
+	assert.Nil(t, err)
+	assert.Equal(t, &obj{Version: aUUID1, AString: aString}, out)
+
+	// _version is never written
+	newRow, err := mapper.NewRow("TestTable", out)
+	assert.Nil(t, err)
+	assert.Equal(t, ovsdb.Row{"aString": aString}, newRow)
+
+	cond, err := mapper.NewCondition("TestTable", out, &out.Version, ovsdb.ConditionEqual, aUUID1)
+	assert.Nil(t, err)
+	assert.Equal(t, &ovsdb.Condition{Column: "_version", Function: ovsdb.ConditionEqual, Value: ovsdb.UUID{GoUUID: aUUID1}}, cond)
+}
@@ -0,0 +1,45 @@
+package mapper
+
+import (
+	"reflect"
+	"time"
+)
+
+// Integer columns can be held by time.Time and time.Duration fields.
+// A time.Time is stored as milliseconds since the Unix epoch (the zero time.Time is stored as 0)
+// and a time.Duration is stored as milliseconds
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// isTimeType returns whether the type is one of the time types that can hold integer columns
+func isTimeType(t reflect.Type) bool {
+	return t == timeType || t == durationType
+}
+
+// timeToNative returns the integer that represents a time value,
+// other values are returned unchanged
+func timeToNative(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return 0
+		}
+		return int(v.UnixNano() / int64(time.Millisecond))
+	case time.Duration:
+		return int(v / time.Millisecond)
+	}
+	return value
+}
+
+// nativeToTime returns the value of the given time type represented by an integer
+func nativeToTime(t reflect.Type, value int) interface{} {
+	if t == durationType {
+		return time.Duration(value) * time.Millisecond
+	}
+	if value == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(value)*int64(time.Millisecond))
+}
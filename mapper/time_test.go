@@ -0,0 +1,88 @@
+package mapper
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ovn-org/libovsdb/ovsdb"
+	"github.com/stretchr/testify/assert"
+)
+
+var timeTestSchema = []byte(`{
+  "name": "TestSchema",
+  "tables": {
+    "TestTable": {
+      "columns": {
+        "created": {
+          "type": "integer"
+        },
+        "timeout": {
+          "type": "integer"
+        },
+        "name": {
+          "type": "string"
+        }
+      }
+    }
+  }
+}`)
+
+type timeTestType struct {
+	Created time.Time     `ovs:"created"`
+	Timeout time.Duration `ovs:"timeout"`
+}
+
+func TestMapperTimeRoundTrip(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	if err := json.Unmarshal(timeTestSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	mapper := NewMapper(&schema)
+
+	created := time.Date(2021, time.March, 4, 10, 30, 15, 123000000, time.UTC)
+	obj := timeTestType{
+		Created: created,
+		Timeout: 5 * time.Second,
+	}
+	row, err := mapper.NewRow("TestTable", &obj)
+	assert.Nil(t, err)
+	assert.Equal(t, ovsdb.Row{"created": int(created.UnixNano() / int64(time.Millisecond)), "timeout": 5000}, row)
+	assert.Equal(t, 1614853815123, row["created"])
+
+	var result timeTestType
+	err = mapper.GetRowData("TestTable", &row, &result)
+	assert.Nil(t, err)
+	assert.True(t, created.Equal(result.Created), "expected %s, got %s", created, result.Created)
+	assert.Equal(t, 5*time.Second, result.Timeout)
+
+	// Zero values are default values
+	row, err = mapper.NewRow("TestTable", &timeTestType{})
+	assert.Nil(t, err)
+	assert.Equal(t, ovsdb.Row{}, row)
+	zeroRow := ovsdb.Row{"created": 0, "timeout": 0}
+	result = timeTestType{Created: created, Timeout: time.Second}
+	err = mapper.GetRowData("TestTable", &zeroRow, &result)
+	assert.Nil(t, err)
+	assert.Equal(t, timeTestType{}, result)
+
+	// Conditions and mutations accept time values
+	cond, err := mapper.NewCondition("TestTable", &obj, &obj.Created, ovsdb.ConditionGreaterThan, created)
+	assert.Nil(t, err)
+	assert.Equal(t, 1614853815123, cond.Value)
+	mutation, err := mapper.NewMutation("TestTable", &obj, "timeout", ovsdb.MutateOperationAdd, time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, 1000, mutation.Value)
+}
+
+func TestMapperTimeWrongColumn(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	if err := json.Unmarshal(timeTestSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	type wrongType struct {
+		Name time.Time `ovs:"name"`
+	}
+	_, err := NewMapperInfo(schema.Table("TestTable"), &wrongType{})
+	assert.NotNil(t, err)
+}
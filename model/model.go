@@ -3,6 +3,7 @@ package model
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/ovn-org/libovsdb/mapper"
 	"github.com/ovn-org/libovsdb/ovsdb"
@@ -12,6 +13,14 @@ import (
 // to express how data from a specific Database Table shall be translated into structs
 // A Model is a struct with at least one (most likely more) field tagged with the 'ovs' tag
 // The value of 'ovs' field must be a valid column name in the OVS Database
+// It may also be a comma-separated list of alternative column names (e.g: `ovs:"new_name,old_name"`),
+// in which case the first one that exists in the schema is used. This allows a model to be used
+// with different versions of a schema
+// Integer columns can also be held by time.Time fields (milliseconds since the Unix epoch) and
+// time.Duration fields (milliseconds)
+// Columns whose type is a set of at most one element (min 0, max 1), e.g: an optional boolean, can be held
+// by a slice or by a pointer, nil meaning the empty set. Other columns, e.g: a required boolean, are held by
+// the plain type (bool)
 // A field associated with the "_uuid" column mandatory. The rest of the columns are optional
 // The struct may also have non-tagged fields (which will be ignored by the API calls)
 // The Model interface must be implemented by the pointer to such type
@@ -24,6 +33,13 @@ import (
 //}
 type Model interface{}
 
+// Validator can be implemented by models to enforce domain constraints beyond the ones of the schema
+// (e.g: a name matching a regular expression or a VLAN tag in the 1-4094 range). The client API fails to
+// build the operations that would write an invalid model
+type Validator interface {
+	Validate() error
+}
+
 // DBModel is a Database model
 type DBModel struct {
 	name  string
@@ -98,14 +114,7 @@ func NewDBModel(name string, models map[string]Model) (*DBModel, error) {
 		if modelType.Kind() != reflect.Ptr || modelType.Elem().Kind() != reflect.Struct {
 			return nil, fmt.Errorf("model is expected to be a pointer to struct")
 		}
-		hasUUID := false
-		for i := 0; i < modelType.Elem().NumField(); i++ {
-			if field := modelType.Elem().Field(i); field.Tag.Get("ovs") == "_uuid" &&
-				field.Type.Kind() == reflect.String {
-				hasUUID = true
-			}
-		}
-		if !hasUUID {
+		if uuidFieldIndex(modelType.Elem()) == nil {
 			return nil, fmt.Errorf("model is expected to have a string field called uuid")
 		}
 
@@ -117,16 +126,54 @@ func NewDBModel(name string, models map[string]Model) (*DBModel, error) {
 	}, nil
 }
 
+// NewDBModelWithSchema constructs a DBModel named after the provided schema and validates
+// every model against it, so that mismatches are detected when the models are registered
+// rather than when connecting to the server
+func NewDBModelWithSchema(schema *ovsdb.DatabaseSchema, models map[string]Model) (*DBModel, error) {
+	db, err := NewDBModel(schema.Name, models)
+	if err != nil {
+		return nil, err
+	}
+	if errors := db.Validate(schema); len(errors) > 0 {
+		var combined []string
+		for _, err := range errors {
+			combined = append(combined, err.Error())
+		}
+		return nil, fmt.Errorf("database validation error (%d): %s", len(errors),
+			strings.Join(combined, ". "))
+	}
+	return db, nil
+}
+
 func modelSetUUID(model Model, uuid string) error {
 	modelVal := reflect.ValueOf(model).Elem()
-	for i := 0; i < modelVal.NumField(); i++ {
-		if field := modelVal.Type().Field(i); field.Tag.Get("ovs") == "_uuid" &&
-			field.Type.Kind() == reflect.String {
-			modelVal.Field(i).Set(reflect.ValueOf(uuid))
-			return nil
+	index := uuidFieldIndex(modelVal.Type())
+	if index == nil {
+		return fmt.Errorf("model is expected to have a string field mapped to column _uuid")
+	}
+	modelVal.FieldByIndex(index).Set(reflect.ValueOf(uuid))
+	return nil
+}
+
+// uuidFieldIndex returns the index sequence of the string field mapped to column _uuid, which
+// may belong to an untagged embedded struct, or nil if there is none
+func uuidFieldIndex(modelType reflect.Type) []int {
+	var embedded []reflect.StructField
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if field.Tag.Get("ovs") == "_uuid" && field.Type.Kind() == reflect.String {
+			return field.Index
+		}
+		if field.Anonymous && field.Tag.Get("ovs") == "" && field.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, field)
+		}
+	}
+	for _, field := range embedded {
+		if index := uuidFieldIndex(field.Type); index != nil {
+			return append(append([]int{}, field.Index...), index...)
 		}
 	}
-	return fmt.Errorf("model is expected to have a string field mapped to column _uuid")
+	return nil
 }
 
 // Condition is a model-based representation of an OVSDB Condition
@@ -148,3 +195,44 @@ type Mutation struct {
 	// Value to use in the mutation
 	Value interface{}
 }
+
+// NewMapValueReplaceMutations returns the mutations needed to replace the value that
+// a map column holds for a given key. Field must be a pointer to the map field of the model,
+// key and value must match the map's key and value types.
+// The first mutation deletes the key (whatever its current value is) and the second one inserts
+// it back with the new value. Since OVSDB applies the mutations of a mutate operation in order,
+// both must be used, in this order, within the same operation (i.e: in the same call to Mutate)
+// for the replacement to be atomic. If the key is not present, the result is a plain insertion.
+func NewMapValueReplaceMutations(field interface{}, key, value interface{}) ([]Mutation, error) {
+	fieldVal := reflect.ValueOf(field)
+	if fieldVal.Kind() != reflect.Ptr || fieldVal.Elem().Kind() != reflect.Map {
+		return nil, fmt.Errorf("field must be a pointer to a map, got %s", reflect.TypeOf(field))
+	}
+	mapType := fieldVal.Elem().Type()
+	keyVal := reflect.ValueOf(key)
+	if !keyVal.IsValid() || keyVal.Type() != mapType.Key() {
+		return nil, fmt.Errorf("key %v is not of type %s", key, mapType.Key())
+	}
+	valueVal := reflect.ValueOf(value)
+	if !valueVal.IsValid() || valueVal.Type() != mapType.Elem() {
+		return nil, fmt.Errorf("value %v is not of type %s", value, mapType.Elem())
+	}
+
+	keys := reflect.MakeSlice(reflect.SliceOf(mapType.Key()), 0, 1)
+	keys = reflect.Append(keys, keyVal)
+	newMap := reflect.MakeMapWithSize(mapType, 1)
+	newMap.SetMapIndex(keyVal, valueVal)
+
+	return []Mutation{
+		{
+			Field:   field,
+			Mutator: ovsdb.MutateOperationDelete,
+			Value:   keys.Interface(),
+		},
+		{
+			Field:   field,
+			Mutator: ovsdb.MutateOperationInsert,
+			Value:   newMap.Interface(),
+		},
+	}, nil
+}
@@ -23,6 +23,16 @@ type modelInvalid struct {
 	Foo string
 }
 
+type commonColumns struct {
+	UUID        string            `ovs:"_uuid"`
+	ExternalIDs map[string]string `ovs:"external_ids"`
+}
+
+type modelEmbedded struct {
+	commonColumns
+	Foo string `ovs:"bar"`
+}
+
 func TestDBModel(t *testing.T) {
 	type Test struct {
 		name  string
@@ -42,6 +52,11 @@ func TestDBModel(t *testing.T) {
 				"Test_B": &modelB{}},
 			valid: true,
 		},
+		{
+			name:  "valid_embedded",
+			obj:   map[string]Model{"Test_E": &modelEmbedded{}},
+			valid: true,
+		},
 		{
 			name:  "invalid",
 			obj:   map[string]Model{"INVALID": &modelInvalid{}},
@@ -82,7 +97,12 @@ func TestSetUUID(t *testing.T) {
 	err = modelSetUUID(&b, "foo")
 	assert.Nilf(t, err, "Setting UUID should succeed")
 	assert.Equal(t, "foo", b.UID)
-
+	e := modelEmbedded{}
+	err = modelSetUUID(&e, "foo")
+	assert.Nilf(t, err, "Setting UUID should succeed")
+	assert.Equal(t, "foo", e.UUID)
+	err = modelSetUUID(&modelInvalid{}, "foo")
+	assert.NotNil(t, err)
 }
 
 func TestValidate(t *testing.T) {
@@ -334,3 +354,137 @@ func TestValidate(t *testing.T) {
 	}
 
 }
+
+func TestNewDBModelWithSchema(t *testing.T) {
+	var schema ovsdb.DatabaseSchema
+	err := json.Unmarshal([]byte(`{
+	    "name": "TestDB",
+	    "tables": {
+	      "Test_B": {
+	        "columns": {
+	          "bar": { "type": "string" },
+	          "baz": { "type": "string" }
+	        }
+	      }
+	    }
+	}`), &schema)
+	assert.Nil(t, err)
+
+	db, err := NewDBModelWithSchema(&schema, map[string]Model{"Test_B": &modelB{}})
+	assert.Nil(t, err)
+	assert.Equal(t, "TestDB", db.Name())
+	model, err := db.NewModel("Test_B")
+	assert.Nil(t, err)
+	assert.IsType(t, &modelB{}, model)
+
+	// Test_A is not part of the schema
+	_, err = NewDBModelWithSchema(&schema, map[string]Model{"Test_A": &modelA{}, "Test_B": &modelB{}})
+	assert.NotNil(t, err)
+
+	_, err = NewDBModelWithSchema(&schema, map[string]Model{"INVALID": &modelInvalid{}})
+	assert.NotNil(t, err)
+}
+
+func TestNewMapValueReplaceMutations(t *testing.T) {
+	type obj struct {
+		UUID     string         `ovs:"_uuid"`
+		Counters map[string]int `ovs:"counters"`
+		Name     string         `ovs:"name"`
+	}
+	o := obj{}
+
+	// apply processes the mutations in order the way the server would
+	apply := func(m map[string]int, mutations []Mutation) map[string]int {
+		result := make(map[string]int, len(m))
+		for k, v := range m {
+			result[k] = v
+		}
+		for _, mutation := range mutations {
+			switch mutation.Mutator {
+			case ovsdb.MutateOperationDelete:
+				for _, k := range mutation.Value.([]string) {
+					delete(result, k)
+				}
+			case ovsdb.MutateOperationInsert:
+				for k, v := range mutation.Value.(map[string]int) {
+					if _, ok := result[k]; !ok {
+						result[k] = v
+					}
+				}
+			}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		field    interface{}
+		key      interface{}
+		value    interface{}
+		initial  map[string]int
+		expected map[string]int
+		err      bool
+	}{
+		{
+			name:     "replace existing key",
+			field:    &o.Counters,
+			key:      "hits",
+			value:    2,
+			initial:  map[string]int{"hits": 1, "misses": 3},
+			expected: map[string]int{"hits": 2, "misses": 3},
+		},
+		{
+			name:     "missing key is inserted",
+			field:    &o.Counters,
+			key:      "hits",
+			value:    1,
+			initial:  map[string]int{"misses": 3},
+			expected: map[string]int{"hits": 1, "misses": 3},
+		},
+		{
+			name:  "field is not a map",
+			field: &o.Name,
+			key:   "hits",
+			value: 1,
+			err:   true,
+		},
+		{
+			name:  "field is not a pointer",
+			field: o.Counters,
+			key:   "hits",
+			value: 1,
+			err:   true,
+		},
+		{
+			name:  "wrong key type",
+			field: &o.Counters,
+			key:   1,
+			value: 1,
+			err:   true,
+		},
+		{
+			name:  "wrong value type",
+			field: &o.Counters,
+			key:   "hits",
+			value: "one",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("NewMapValueReplaceMutations: %s", tt.name), func(t *testing.T) {
+			mutations, err := NewMapValueReplaceMutations(tt.field, tt.key, tt.value)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Len(t, mutations, 2)
+			assert.Equal(t, ovsdb.MutateOperationDelete, mutations[0].Mutator)
+			assert.Equal(t, ovsdb.MutateOperationInsert, mutations[1].Mutator)
+			for _, mutation := range mutations {
+				assert.Equal(t, tt.field, mutation.Field)
+			}
+			assert.Equal(t, tt.expected, apply(tt.initial, mutations))
+		})
+	}
+}
@@ -24,6 +24,19 @@ func (e *ErrWrongType) Error() string {
 		e.from, e.expected, e.got, reflect.TypeOf(e.got))
 }
 
+// ErrWrongElementType describes a set or map value whose elements (or keys or values)
+// do not have the native type of the elements of the column
+type ErrWrongElementType struct {
+	// Element is the mismatching element of the value: "set element", "map key" or "map value"
+	Element  string
+	Expected reflect.Type
+	Actual   reflect.Type
+}
+
+func (e *ErrWrongElementType) Error() string {
+	return fmt.Sprintf("wrong %s type: expected %s but got %s", e.Element, e.Expected, e.Actual)
+}
+
 // NewErrWrongType creates a new ErrWrongType
 func NewErrWrongType(from, expected string, got interface{}) error {
 	return &ErrWrongType{
@@ -56,6 +69,7 @@ func NativeTypeFromAtomic(basicType string) reflect.Type {
 //OVS Type to Native Type convertions:
 // OVS sets -> go slices
 // OVS uuid -> go strings
+// OVS real -> go float64 (integer-looking JSON numbers are decoded as float64 too)
 // OVS map  -> go map
 // OVS enum -> go native type depending on the type of the enum key
 func NativeType(column *ColumnSchema) reflect.Type {
@@ -76,6 +90,28 @@ func NativeType(column *ColumnSchema) reflect.Type {
 	}
 }
 
+// NativeOptionalType returns the pointer type that can alternatively hold the value of
+// an optional scalar column, that is, a set with min 0 and max 1. A nil pointer represents
+// the empty set and a non-nil pointer represents a set of exactly one element.
+// It returns nil if the column is not an optional scalar
+func NativeOptionalType(column *ColumnSchema) reflect.Type {
+	if column.Type != TypeSet || column.TypeObj.Min() != 0 || column.TypeObj.Max() != 1 {
+		return nil
+	}
+	return reflect.PtrTo(NativeTypeFromAtomic(column.TypeObj.Key.Type))
+}
+
+// optionalToNativeSet converts a pointer holding the value of an optional scalar column
+// into its equivalent native set
+func optionalToNativeSet(column *ColumnSchema, optional interface{}) interface{} {
+	optVal := reflect.ValueOf(optional)
+	nativeSet := reflect.MakeSlice(NativeType(column), 0, 1)
+	if !optVal.IsNil() {
+		nativeSet = reflect.Append(nativeSet, optVal.Elem())
+	}
+	return nativeSet.Interface()
+}
+
 // OvsToNativeAtomic returns the native type of the basic ovs type
 func OvsToNativeAtomic(basicType string, ovsElem interface{}) (interface{}, error) {
 	switch basicType {
@@ -116,6 +152,11 @@ func OvsToNative(column *ColumnSchema, ovsElem interface{}) (interface{}, error)
 		// We need to convert it to the real type os slice
 		var nativeSet reflect.Value
 
+		// Sets created by NativeToOvs are pointers
+		if ovsSet, ok := ovsElem.(*OvsSet); ok {
+			ovsElem = *ovsSet
+		}
+
 		// RFC says that for a set of exactly one, an atomic type an be sent
 		switch ovsSet := ovsElem.(type) {
 		case OvsSet:
@@ -141,6 +182,10 @@ func OvsToNative(column *ColumnSchema, ovsElem interface{}) (interface{}, error)
 
 	case TypeMap:
 		naType := NativeType(column)
+		// Maps created by NativeToOvs are pointers
+		if ovsMap, ok := ovsElem.(*OvsMap); ok {
+			ovsElem = *ovsMap
+		}
 		ovsMap, ok := ovsElem.(OvsMap)
 		if !ok {
 			return nil, NewErrWrongType("OvsToNative", "OvsMap", ovsElem)
@@ -166,11 +211,33 @@ func OvsToNative(column *ColumnSchema, ovsElem interface{}) (interface{}, error)
 }
 
 // NativeToOvs transforms an native type to a ovs type based on the column type information
+// UUID columns (and sets of UUIDs) also accept UUID values (and slices of UUID values) so that rows
+// inserted in the same transaction can be referenced by their named-uuid
 func NativeToOvs(column *ColumnSchema, rawElem interface{}) (interface{}, error) {
 	naType := NativeType(column)
 
+	if isUUIDColumn(column) {
+		switch uuids := rawElem.(type) {
+		case UUID:
+			if column.Type == TypeUUID {
+				return uuids, nil
+			}
+		case []UUID:
+			if column.Type == TypeSet {
+				ovsSlice := make([]interface{}, 0, len(uuids))
+				for _, uuid := range uuids {
+					ovsSlice = append(ovsSlice, uuid)
+				}
+				return &OvsSet{GoSet: ovsSlice}, nil
+			}
+		}
+	}
+
 	if t := reflect.TypeOf(rawElem); t != naType {
-		return nil, NewErrWrongType("NativeToOvs", naType.String(), rawElem)
+		if optType := NativeOptionalType(column); optType == nil || t != optType {
+			return nil, NewErrWrongType("NativeToOvs", naType.String(), rawElem)
+		}
+		rawElem = optionalToNativeSet(column, rawElem)
 	}
 
 	switch column.Type {
@@ -201,16 +268,46 @@ func NativeToOvs(column *ColumnSchema, rawElem interface{}) (interface{}, error)
 		if err != nil {
 			return nil, err
 		}
+		keyIsUUID := column.TypeObj.Key.Type == TypeUUID
+		valueIsUUID := column.TypeObj.Value.Type == TypeUUID
+		if keyIsUUID || valueIsUUID {
+			goMap := make(map[interface{}]interface{}, len(ovsMap.GoMap))
+			for k, v := range ovsMap.GoMap {
+				if keyIsUUID {
+					k = UUID{GoUUID: k.(string)}
+				}
+				if valueIsUUID {
+					v = UUID{GoUUID: v.(string)}
+				}
+				goMap[k] = v
+			}
+			ovsMap.GoMap = goMap
+		}
 		return ovsMap, nil
 	default:
 		panic(fmt.Sprintf("Unknown Type: %v", column.Type))
 	}
 }
 
+// isUUIDColumn returns whether the column is a UUID or a set of UUIDs
+func isUUIDColumn(column *ColumnSchema) bool {
+	return column.Type == TypeUUID || (column.Type == TypeSet && column.TypeObj.Key.Type == TypeUUID)
+}
+
 // IsDefaultValue checks if a provided native element corresponds to the default value of its
-// designated column type
+// designated column type. The default value of a set or map column is the empty one, whatever
+// the bounds of its size: the elements it holds are not checked, so an optional scalar (a set of
+// at most one element) holding a zero value, e.g: []bool{false} or a pointer to false, is set
+// explicitly and is not the default
 func IsDefaultValue(column *ColumnSchema, nativeElem interface{}) bool {
+	value := reflect.ValueOf(nativeElem)
+	// Optional scalars held in pointers are default (i.e: empty) when nil
+	if value.Kind() == reflect.Ptr {
+		return value.IsNil()
+	}
 	switch column.Type {
+	case TypeSet, TypeMap:
+		return !value.IsValid() || value.Len() == 0
 	case TypeEnum:
 		return isDefaultBaseValue(nativeElem, column.TypeObj.Key.Type)
 	default:
@@ -227,17 +324,27 @@ func validateMutationAtomic(atype string, mutator Mutator, value interface{}) er
 
 	switch atype {
 	case TypeUUID, TypeString, TypeBoolean:
-		return fmt.Errorf("atomictype %s does not support mutation", atype)
+		return fmt.Errorf("atomictype %s does not support mutation, %s is only valid for integer and real types", atype, mutator)
 	case TypeReal:
 		switch mutator {
-		case MutateOperationAdd, MutateOperationSubstract, MutateOperationMultiply, MutateOperationDivide:
+		case MutateOperationAdd, MutateOperationSubstract, MutateOperationMultiply:
+			return nil
+		case MutateOperationDivide:
+			if value.(float64) == 0 {
+				return fmt.Errorf("mutator %s does not support a zero divisor", mutator)
+			}
 			return nil
 		default:
 			return fmt.Errorf("wrong mutator for real type %s", mutator)
 		}
 	case TypeInteger:
 		switch mutator {
-		case MutateOperationAdd, MutateOperationSubstract, MutateOperationMultiply, MutateOperationDivide, MutateOperationModulo:
+		case MutateOperationAdd, MutateOperationSubstract, MutateOperationMultiply:
+			return nil
+		case MutateOperationDivide, MutateOperationModulo:
+			if value.(int) == 0 {
+				return fmt.Errorf("mutator %s does not support a zero divisor", mutator)
+			}
 			return nil
 		default:
 			return fmt.Errorf("wrong mutator for integer type: %s", mutator)
@@ -257,6 +364,9 @@ func ValidateMutation(column *ColumnSchema, mutator Mutator, value interface{})
 	case TypeSet:
 		switch mutator {
 		case MutateOperationInsert, MutateOperationDelete:
+			if _, ok := value.([]UUID); ok && isUUIDColumn(column) {
+				return nil
+			}
 			if NativeType(column) != reflect.TypeOf(value) {
 				return NewErrWrongType(fmt.Sprintf("Mutation %s of column %s", mutator, column),
 					NativeType(column).String(), value)
@@ -294,8 +404,126 @@ func ValidateMutation(column *ColumnSchema, mutator Mutator, value interface{})
 	}
 }
 
+// ApplyMutation returns the native value of a column resulting from applying a mutation, whose
+// native value is provided, to its current native value, the way the server applies mutations
+// (RFC7047 5.1). Optional scalars held in pointers are treated as sets. The constraints of the
+// column, e.g: the maximum size of a set, are not checked
+func ApplyMutation(column *ColumnSchema, mutator Mutator, current, value interface{}) (interface{}, error) {
+	if err := ValidateMutation(column, mutator, value); err != nil {
+		return nil, err
+	}
+	current = nativeOptionalToSet(column, current)
+	if reflect.TypeOf(current) != NativeType(column) {
+		return nil, NewErrWrongType("ApplyMutation", NativeType(column).String(), current)
+	}
+	if uuids, ok := value.([]UUID); ok {
+		set := make([]string, 0, len(uuids))
+		for _, uuid := range uuids {
+			set = append(set, uuid.GoUUID)
+		}
+		value = set
+	}
+	currentVal := reflect.ValueOf(current)
+	valueVal := reflect.ValueOf(value)
+
+	switch column.Type {
+	case TypeSet:
+		result := reflect.MakeSlice(NativeType(column), 0, currentVal.Len())
+		switch mutator {
+		case MutateOperationInsert:
+			result = reflect.AppendSlice(result, currentVal)
+			for i := 0; i < valueVal.Len(); i++ {
+				if !setContains(result, valueVal.Index(i)) {
+					result = reflect.Append(result, valueVal.Index(i))
+				}
+			}
+		case MutateOperationDelete:
+			for i := 0; i < currentVal.Len(); i++ {
+				if !setContains(valueVal, currentVal.Index(i)) {
+					result = reflect.Append(result, currentVal.Index(i))
+				}
+			}
+		default:
+			// Arithmetic mutators apply to every element
+			for i := 0; i < currentVal.Len(); i++ {
+				elem := mutateAtomic(currentVal.Index(i).Interface(), mutator, value)
+				result = reflect.Append(result, reflect.ValueOf(elem))
+			}
+		}
+		return result.Interface(), nil
+	case TypeMap:
+		result := reflect.MakeMapWithSize(NativeType(column), currentVal.Len())
+		iter := currentVal.MapRange()
+		for iter.Next() {
+			result.SetMapIndex(iter.Key(), iter.Value())
+		}
+		switch {
+		case mutator == MutateOperationInsert:
+			// Existing keys keep their value
+			iter = valueVal.MapRange()
+			for iter.Next() {
+				if !result.MapIndex(iter.Key()).IsValid() {
+					result.SetMapIndex(iter.Key(), iter.Value())
+				}
+			}
+		case valueVal.Kind() == reflect.Map:
+			// Only the pairs with the same key and value are deleted
+			iter = valueVal.MapRange()
+			for iter.Next() {
+				if elem := result.MapIndex(iter.Key()); elem.IsValid() && elem.Interface() == iter.Value().Interface() {
+					result.SetMapIndex(iter.Key(), reflect.Value{})
+				}
+			}
+		default:
+			for i := 0; i < valueVal.Len(); i++ {
+				result.SetMapIndex(valueVal.Index(i), reflect.Value{})
+			}
+		}
+		return result.Interface(), nil
+	default:
+		return mutateAtomic(current, mutator, value), nil
+	}
+}
+
+// mutateAtomic returns the result of applying an arithmetic mutator to an integer or real value
+func mutateAtomic(current interface{}, mutator Mutator, value interface{}) interface{} {
+	switch c := current.(type) {
+	case int:
+		v := value.(int)
+		switch mutator {
+		case MutateOperationAdd:
+			return c + v
+		case MutateOperationSubstract:
+			return c - v
+		case MutateOperationMultiply:
+			return c * v
+		case MutateOperationDivide:
+			return c / v
+		case MutateOperationModulo:
+			return c % v
+		}
+	case float64:
+		v := value.(float64)
+		switch mutator {
+		case MutateOperationAdd:
+			return c + v
+		case MutateOperationSubstract:
+			return c - v
+		case MutateOperationMultiply:
+			return c * v
+		case MutateOperationDivide:
+			return c / v
+		}
+	}
+	return current
+}
+
 func ValidateCondition(column *ColumnSchema, function ConditionFunction, nativeValue interface{}) error {
-	if NativeType(column) != reflect.TypeOf(nativeValue) {
+	if err := validateElementTypes(column, reflect.TypeOf(nativeValue)); err != nil {
+		return err
+	}
+	if NativeType(column) != reflect.TypeOf(nativeValue) &&
+		(NativeOptionalType(column) == nil || NativeOptionalType(column) != reflect.TypeOf(nativeValue)) {
 		return NewErrWrongType(fmt.Sprintf("Condition for column %s", column),
 			NativeType(column).String(), nativeValue)
 	}
@@ -316,6 +544,148 @@ func ValidateCondition(column *ColumnSchema, function ConditionFunction, nativeV
 	}
 }
 
+// EvaluateCondition returns whether the native value of a column satisfies a condition
+// function against another native value, the way the server evaluates conditions.
+// Optional scalars held in pointers are treated as sets
+func EvaluateCondition(column *ColumnSchema, function ConditionFunction, actual, value interface{}) (bool, error) {
+	actual = nativeOptionalToSet(column, actual)
+	value = nativeOptionalToSet(column, value)
+	if err := ValidateCondition(column, function, value); err != nil {
+		return false, err
+	}
+	if reflect.TypeOf(actual) != NativeType(column) {
+		return false, NewErrWrongType("EvaluateCondition", NativeType(column).String(), actual)
+	}
+	actualVal := reflect.ValueOf(actual)
+	valueVal := reflect.ValueOf(value)
+
+	switch column.Type {
+	case TypeSet:
+		switch function {
+		case ConditionEqual:
+			return setIncludes(actualVal, valueVal) && setIncludes(valueVal, actualVal), nil
+		case ConditionNotEqual:
+			return !(setIncludes(actualVal, valueVal) && setIncludes(valueVal, actualVal)), nil
+		case ConditionIncludes:
+			return setIncludes(actualVal, valueVal), nil
+		case ConditionExcludes:
+			for i := 0; i < valueVal.Len(); i++ {
+				if setContains(actualVal, valueVal.Index(i)) {
+					return false, nil
+				}
+			}
+			return true, nil
+		}
+	case TypeMap:
+		switch function {
+		case ConditionEqual:
+			return mapIncludes(actualVal, valueVal) && mapIncludes(valueVal, actualVal), nil
+		case ConditionNotEqual:
+			return !(mapIncludes(actualVal, valueVal) && mapIncludes(valueVal, actualVal)), nil
+		case ConditionIncludes:
+			return mapIncludes(actualVal, valueVal), nil
+		case ConditionExcludes:
+			iter := valueVal.MapRange()
+			for iter.Next() {
+				elem := actualVal.MapIndex(iter.Key())
+				if elem.IsValid() && elem.Interface() == iter.Value().Interface() {
+					return false, nil
+				}
+			}
+			return true, nil
+		}
+	default:
+		switch function {
+		case ConditionEqual, ConditionIncludes:
+			return actual == value, nil
+		case ConditionNotEqual, ConditionExcludes:
+			return actual != value, nil
+		}
+		var a, v float64
+		switch column.Type {
+		case TypeInteger:
+			a, v = float64(actual.(int)), float64(value.(int))
+		case TypeReal:
+			a, v = actual.(float64), value.(float64)
+		}
+		switch function {
+		case ConditionLessThan:
+			return a < v, nil
+		case ConditionLessThanOrEqual:
+			return a <= v, nil
+		case ConditionGreaterThan:
+			return a > v, nil
+		case ConditionGreaterThanOrEqual:
+			return a >= v, nil
+		}
+	}
+	return false, fmt.Errorf("wrong condition function %s for type: %s", function, column.Type)
+}
+
+// nativeOptionalToSet converts a pointer holding the value of an optional scalar column
+// into its equivalent native set. Other values are returned as they are
+func nativeOptionalToSet(column *ColumnSchema, nativeElem interface{}) interface{} {
+	if optType := NativeOptionalType(column); optType != nil && reflect.TypeOf(nativeElem) == optType {
+		return optionalToNativeSet(column, nativeElem)
+	}
+	return nativeElem
+}
+
+// setContains returns whether a native set holds an element
+func setContains(set, elem reflect.Value) bool {
+	for i := 0; i < set.Len(); i++ {
+		if set.Index(i).Interface() == elem.Interface() {
+			return true
+		}
+	}
+	return false
+}
+
+// setIncludes returns whether a native set holds every element of another one
+func setIncludes(set, other reflect.Value) bool {
+	for i := 0; i < other.Len(); i++ {
+		if !setContains(set, other.Index(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// mapIncludes returns whether a native map holds every key-value pair of another one
+func mapIncludes(m, other reflect.Value) bool {
+	iter := other.MapRange()
+	for iter.Next() {
+		elem := m.MapIndex(iter.Key())
+		if !elem.IsValid() || elem.Interface() != iter.Value().Interface() {
+			return false
+		}
+	}
+	return true
+}
+
+// validateElementTypes returns an ErrWrongElementType error if the type is a slice (or map) but its
+// elements (or keys or values) do not have the native type of the elements of the set (or map) column
+func validateElementTypes(column *ColumnSchema, actual reflect.Type) error {
+	if actual == nil {
+		return nil
+	}
+	expected := NativeType(column)
+	switch {
+	case column.Type == TypeSet && actual.Kind() == reflect.Slice:
+		if actual.Elem() != expected.Elem() {
+			return &ErrWrongElementType{Element: "set element", Expected: expected.Elem(), Actual: actual.Elem()}
+		}
+	case column.Type == TypeMap && actual.Kind() == reflect.Map:
+		if actual.Key() != expected.Key() {
+			return &ErrWrongElementType{Element: "map key", Expected: expected.Key(), Actual: actual.Key()}
+		}
+		if actual.Elem() != expected.Elem() {
+			return &ErrWrongElementType{Element: "map value", Expected: expected.Elem(), Actual: actual.Elem()}
+		}
+	}
+	return nil
+}
+
 func isDefaultBaseValue(elem interface{}, etype ExtendedType) bool {
 	value := reflect.ValueOf(elem)
 	if !value.IsValid() {
@@ -2,6 +2,7 @@ package ovsdb
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -493,6 +494,327 @@ func TestNativeToOvs(t *testing.T) {
 	}
 }
 
+func TestNativeToOvsOptional(t *testing.T) {
+	optionalSchema := []byte(`{"type":{"key": "string", "min": 0, "max": 1}}`)
+	var column ColumnSchema
+	if err := json.Unmarshal(optionalSchema, &column); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, reflect.TypeOf(&aString), NativeOptionalType(&column))
+
+	res, err := NativeToOvs(&column, &aString)
+	assert.Nil(t, err)
+	assert.Equal(t, &OvsSet{GoSet: []interface{}{aString}}, res)
+
+	res, err = NativeToOvs(&column, (*string)(nil))
+	assert.Nil(t, err)
+	assert.Equal(t, &OvsSet{}, res)
+
+	_, err = NativeToOvs(&column, &aInt)
+	assert.NotNil(t, err)
+
+	// Sets with a max different from 1 cannot be held by pointers
+	var setColumn ColumnSchema
+	if err := json.Unmarshal([]byte(`{"type":{"key": "string", "min": 0, "max": "unlimited"}}`), &setColumn); err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, NativeOptionalType(&setColumn))
+	_, err = NativeToOvs(&setColumn, &aString)
+	assert.NotNil(t, err)
+}
+
+func TestNativeToOvsNamedUUID(t *testing.T) {
+	var uuidColumn ColumnSchema
+	if err := json.Unmarshal([]byte(`{"type":"uuid"}`), &uuidColumn); err != nil {
+		t.Fatal(err)
+	}
+	var setColumn ColumnSchema
+	if err := json.Unmarshal([]byte(`{"type":{"key": {"type": "uuid", "refTable": "Foo"}, "min": 0, "max": "unlimited"}}`), &setColumn); err != nil {
+		t.Fatal(err)
+	}
+	var stringSetColumn ColumnSchema
+	if err := json.Unmarshal([]byte(`{"type":{"key": "string", "min": 0, "max": "unlimited"}}`), &stringSetColumn); err != nil {
+		t.Fatal(err)
+	}
+	named := UUID{GoUUID: "namedref"}
+
+	res, err := NativeToOvs(&uuidColumn, named)
+	assert.Nil(t, err)
+	assert.Equal(t, named, res)
+
+	res, err = NativeToOvs(&setColumn, []UUID{named})
+	assert.Nil(t, err)
+	assert.Equal(t, &OvsSet{GoSet: []interface{}{named}}, res)
+	b, err := json.Marshal(res)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `["named-uuid","namedref"]`, string(b))
+
+	assert.Nil(t, ValidateMutation(&setColumn, MutateOperationInsert, []UUID{named}))
+
+	_, err = NativeToOvs(&stringSetColumn, []UUID{named})
+	assert.NotNil(t, err)
+	assert.NotNil(t, ValidateMutation(&stringSetColumn, MutateOperationInsert, []UUID{named}))
+}
+
+func TestSingleElementSetRoundTrip(t *testing.T) {
+	var column ColumnSchema
+	if err := json.Unmarshal([]byte(`{"type":{"key": "string", "min": 0, "max": 1}}`), &column); err != nil {
+		t.Fatal(err)
+	}
+	var uuidColumn ColumnSchema
+	if err := json.Unmarshal([]byte(`{"type":{"key": {"type": "uuid"}, "min": 0, "max": 1}}`), &uuidColumn); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		column   *ColumnSchema
+		wire     string
+		native   interface{}
+		expected string
+	}{
+		{
+			name:     "bare scalar",
+			column:   &column,
+			wire:     `{"col":"foo"}`,
+			native:   []string{"foo"},
+			expected: `{"col":"foo"}`,
+		},
+		{
+			name:     "set notation",
+			column:   &column,
+			wire:     `{"col":["set",["foo"]]}`,
+			native:   []string{"foo"},
+			expected: `{"col":"foo"}`,
+		},
+		{
+			name:     "empty set",
+			column:   &column,
+			wire:     `{"col":["set",[]]}`,
+			native:   []string{},
+			expected: `{"col":["set",[]]}`,
+		},
+		{
+			name:     "bare uuid",
+			column:   &uuidColumn,
+			wire:     fmt.Sprintf(`{"col":["uuid","%s"]}`, aUUID0),
+			native:   []string{aUUID0},
+			expected: fmt.Sprintf(`{"col":["uuid","%s"]}`, aUUID0),
+		},
+		{
+			name:     "uuid set notation",
+			column:   &uuidColumn,
+			wire:     fmt.Sprintf(`{"col":["set",[["uuid","%s"]]]}`, aUUID0),
+			native:   []string{aUUID0},
+			expected: fmt.Sprintf(`{"col":["uuid","%s"]}`, aUUID0),
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("SingleElementSet: %s", test.name), func(t *testing.T) {
+			var row Row
+			err := json.Unmarshal([]byte(test.wire), &row)
+			assert.Nil(t, err)
+
+			native, err := OvsToNative(test.column, row["col"])
+			assert.Nil(t, err)
+			assert.Equal(t, test.native, native)
+
+			ovs, err := NativeToOvs(test.column, native)
+			assert.Nil(t, err)
+
+			// The ovs value must be converted back to the same native value
+			again, err := OvsToNative(test.column, ovs)
+			assert.Nil(t, err)
+			assert.Equal(t, test.native, again)
+
+			data, err := json.Marshal(Row{"col": ovs})
+			assert.Nil(t, err)
+			assert.JSONEq(t, test.expected, string(data))
+		})
+	}
+}
+
+func TestMapRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		column string
+		wire   string
+		native interface{}
+	}{
+		{
+			name:   "integer keys",
+			column: `{"type":{"key":"integer","value":"string","min":0,"max":"unlimited"}}`,
+			wire:   `{"col":["map",[[1,"one"],[42,"forty-two"]]]}`,
+			native: map[int]string{1: "one", 42: "forty-two"},
+		},
+		{
+			name:   "integer values",
+			column: `{"type":{"key":"string","value":"integer","min":0,"max":"unlimited"}}`,
+			wire:   `{"col":["map",[["one",1],["forty-two",42]]]}`,
+			native: map[string]int{"one": 1, "forty-two": 42},
+		},
+		{
+			name:   "integer keys and values",
+			column: `{"type":{"key":{"type":"integer","minInteger":0,"maxInteger":4095},"value":"integer","min":0,"max":"unlimited"}}`,
+			wire:   `{"col":["map",[[10,100],[4095,-1]]]}`,
+			native: map[int]int{10: 100, 4095: -1},
+		},
+		{
+			name:   "boolean values",
+			column: `{"type":{"key":"integer","value":"boolean","min":0,"max":"unlimited"}}`,
+			wire:   `{"col":["map",[[0,true],[1,false]]]}`,
+			native: map[int]bool{0: true, 1: false},
+		},
+		{
+			name:   "uuid keys",
+			column: `{"type":{"key":"uuid","value":"integer","min":0,"max":"unlimited"}}`,
+			wire:   `{"col":["map",[[["uuid","` + aUUID0 + `"],1]]]}`,
+			native: map[string]int{aUUID0: 1},
+		},
+		{
+			name:   "uuid values",
+			column: `{"type":{"key":"integer","value":"uuid","min":0,"max":"unlimited"}}`,
+			wire:   `{"col":["map",[[1,["uuid","` + aUUID0 + `"]]]]}`,
+			native: map[int]string{1: aUUID0},
+		},
+		{
+			name:   "empty",
+			column: `{"type":{"key":"integer","value":"string","min":0,"max":"unlimited"}}`,
+			wire:   `{"col":["map",[]]}`,
+			native: map[int]string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("MapRoundTrip: %s", test.name), func(t *testing.T) {
+			var column ColumnSchema
+			err := json.Unmarshal([]byte(test.column), &column)
+			assert.Nil(t, err)
+			assert.Equal(t, NativeType(&column), reflect.TypeOf(test.native))
+
+			var row Row
+			err = json.Unmarshal([]byte(test.wire), &row)
+			assert.Nil(t, err)
+			native, err := OvsToNative(&column, row["col"])
+			assert.Nil(t, err)
+			assert.Equal(t, test.native, native)
+
+			ovs, err := NativeToOvs(&column, native)
+			assert.Nil(t, err)
+			data, err := json.Marshal(Row{"col": ovs})
+			assert.Nil(t, err)
+			var wire, expected map[string][]interface{}
+			assert.Nil(t, json.Unmarshal(data, &wire))
+			assert.Nil(t, json.Unmarshal([]byte(test.wire), &expected))
+			assert.Equal(t, expected["col"][0], wire["col"][0])
+			assert.ElementsMatch(t, expected["col"][1], wire["col"][1], "the pairs have correctly typed keys and values")
+
+			err = json.Unmarshal(data, &row)
+			assert.Nil(t, err)
+			again, err := OvsToNative(&column, row["col"])
+			assert.Nil(t, err)
+			assert.Equal(t, test.native, again)
+		})
+	}
+
+	t.Run("MapRoundTrip: wrong native type", func(t *testing.T) {
+		var column ColumnSchema
+		err := json.Unmarshal([]byte(`{"type":{"key":"integer","value":"string","min":0,"max":"unlimited"}}`), &column)
+		assert.Nil(t, err)
+		_, err = NativeToOvs(&column, map[string]string{"1": "one"})
+		assert.NotNil(t, err)
+		_, err = OvsToNative(&column, OvsMap{GoMap: map[interface{}]interface{}{"1": "one"}})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRealRoundTrip(t *testing.T) {
+	columns := map[string][]byte{
+		"real":     []byte(`{"type":"real"}`),
+		"real set": []byte(`{"type":{"key":"real","min":0,"max":"unlimited"}}`),
+		"real map": []byte(`{"type":{"key":"string","value":"real","min":0,"max":"unlimited"}}`),
+	}
+	tests := []struct {
+		name   string
+		column string
+		wire   string
+		native interface{}
+	}{
+		{
+			name:   "fractional",
+			column: "real",
+			wire:   `{"col":0.25}`,
+			native: 0.25,
+		},
+		{
+			name:   "integer-looking",
+			column: "real",
+			wire:   `{"col":1000000}`,
+			native: float64(1000000),
+		},
+		{
+			name:   "exponent",
+			column: "real",
+			wire:   `{"col":1e9}`,
+			native: 1e9,
+		},
+		{
+			name:   "negative exponent",
+			column: "real",
+			wire:   `{"col":-1.5e-3}`,
+			native: -1.5e-3,
+		},
+		{
+			name:   "set",
+			column: "real set",
+			wire:   `{"col":["set",[1,2.5,1e9]]}`,
+			native: []float64{1, 2.5, 1e9},
+		},
+		{
+			name:   "map",
+			column: "real map",
+			wire:   `{"col":["map",[["rate",1e9],["burst",0.5]]]}`,
+			native: map[string]float64{"rate": 1e9, "burst": 0.5},
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("RealRoundTrip: %s", test.name), func(t *testing.T) {
+			var column ColumnSchema
+			err := json.Unmarshal(columns[test.column], &column)
+			assert.Nil(t, err)
+			assert.Equal(t, NativeType(&column), reflect.TypeOf(test.native))
+
+			var row Row
+			err = json.Unmarshal([]byte(test.wire), &row)
+			assert.Nil(t, err)
+			native, err := OvsToNative(&column, row["col"])
+			assert.Nil(t, err)
+			assert.Equal(t, test.native, native)
+
+			ovs, err := NativeToOvs(&column, native)
+			assert.Nil(t, err)
+			data, err := json.Marshal(Row{"col": ovs})
+			assert.Nil(t, err)
+			err = json.Unmarshal(data, &row)
+			assert.Nil(t, err)
+			again, err := OvsToNative(&column, row["col"])
+			assert.Nil(t, err)
+			assert.Equal(t, test.native, again)
+		})
+	}
+
+	t.Run("RealRoundTrip: arithmetic mutations", func(t *testing.T) {
+		var column ColumnSchema
+		err := json.Unmarshal(columns["real"], &column)
+		assert.Nil(t, err)
+		for _, mutator := range []Mutator{MutateOperationAdd, MutateOperationSubstract, MutateOperationMultiply, MutateOperationDivide} {
+			assert.Nil(t, ValidateMutation(&column, mutator, 1e9))
+		}
+		assert.NotNil(t, ValidateMutation(&column, MutateOperationModulo, 1e9))
+		data, err := json.Marshal(NewMutation("col", MutateOperationMultiply, 1.5))
+		assert.Nil(t, err)
+		assert.JSONEq(t, `["col","*=",1.5]`, string(data))
+	})
+}
+
 func TestOvsToNativeErr(t *testing.T) {
 	transMaps := getErrTransMaps()
 	for _, trans := range transMaps {
@@ -717,6 +1039,90 @@ func TestIsDefault(t *testing.T) {
 			elem:     "enum1",
 			expected: false,
 		},
+		{
+			name: "nil optional pointer",
+			column: []byte(`{
+					"type":{
+				            "key": "integer",
+				            "min": 0,
+				            "max": 1
+				          }
+					}`),
+			elem:     (*int)(nil),
+			expected: true,
+		},
+		{
+			name: "empty optional boolean",
+			column: []byte(`{
+					"type":{
+				            "key": "boolean",
+				            "min": 0,
+				            "max": 1
+				          }
+					}`),
+			elem:     []bool{},
+			expected: true,
+		},
+		{
+			name: "nil optional boolean",
+			column: []byte(`{
+					"type":{
+				            "key": "boolean",
+				            "min": 0,
+				            "max": 1
+				          }
+					}`),
+			elem:     []bool(nil),
+			expected: true,
+		},
+		{
+			name: "optional boolean explicitly false",
+			column: []byte(`{
+					"type":{
+				            "key": "boolean",
+				            "min": 0,
+				            "max": 1
+				          }
+					}`),
+			elem:     []bool{false},
+			expected: false,
+		},
+		{
+			name: "optional integer explicitly zero",
+			column: []byte(`{
+					"type":{
+				            "key": "integer",
+				            "min": 0,
+				            "max": 1
+				          }
+					}`),
+			elem:     []int{0},
+			expected: false,
+		},
+		{
+			name: "optional boolean pointer to false",
+			column: []byte(`{
+					"type":{
+				            "key": "boolean",
+				            "min": 0,
+				            "max": 1
+				          }
+					}`),
+			elem:     new(bool),
+			expected: false,
+		},
+		{
+			name: "non-nil optional pointer to zero value",
+			column: []byte(`{
+					"type":{
+				            "key": "integer",
+				            "min": 0,
+				            "max": 1
+				          }
+					}`),
+			elem:     new(int),
+			expected: false,
+		},
 	}
 	for _, test := range tests {
 		t.Run(fmt.Sprintf("IsDefault: %s", test.name), func(t *testing.T) {
@@ -800,6 +1206,40 @@ func TestMutationValidation(t *testing.T) {
 			value:    4.0,
 			valid:    true,
 		},
+		{
+			name:     "integer zero divisor",
+			column:   []byte(`{"type":"integer"}`),
+			mutators: []Mutator{MutateOperationDivide, MutateOperationModulo},
+			value:    0,
+			valid:    false,
+		},
+		{
+			name:     "integer zero",
+			column:   []byte(`{"type":"integer"}`),
+			mutators: []Mutator{MutateOperationAdd, MutateOperationSubstract, MutateOperationMultiply},
+			value:    0,
+			valid:    true,
+		},
+		{
+			name:     "real zero divisor",
+			column:   []byte(`{"type":"real"}`),
+			mutators: []Mutator{MutateOperationDivide},
+			value:    0.0,
+			valid:    false,
+		},
+		{
+			name: "integer set zero divisor",
+			column: []byte(`{
+				   "type": {
+				     "key": "integer",
+				     "max": "unlimited",
+				     "min": 0
+				   }
+				 }`),
+			mutators: []Mutator{MutateOperationDivide, MutateOperationModulo},
+			value:    0,
+			valid:    false,
+		},
 		{
 			name:     "real-%/",
 			column:   []byte(`{"type":"real"}`),
@@ -1065,3 +1505,309 @@ func TestConditionValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestConditionValidationElementTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		column   []byte
+		value    interface{}
+		element  string
+		expected reflect.Type
+		actual   reflect.Type
+	}{
+		{
+			name:     "set of integers with strings",
+			column:   []byte(`{"type":{"key":"integer","min":0,"max":"unlimited"}}`),
+			value:    []string{"foo"},
+			element:  "set element",
+			expected: reflect.TypeOf(0),
+			actual:   reflect.TypeOf(""),
+		},
+		{
+			name:     "map with wrong key type",
+			column:   []byte(`{"type":{"key":"string","value":"integer","min":0,"max":"unlimited"}}`),
+			value:    map[int]int{1: 1},
+			element:  "map key",
+			expected: reflect.TypeOf(""),
+			actual:   reflect.TypeOf(0),
+		},
+		{
+			name:     "map with wrong value type",
+			column:   []byte(`{"type":{"key":"string","value":"integer","min":0,"max":"unlimited"}}`),
+			value:    map[string]string{"foo": "bar"},
+			element:  "map value",
+			expected: reflect.TypeOf(0),
+			actual:   reflect.TypeOf(""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("ConditionValidationElementTypes: %s", tt.name), func(t *testing.T) {
+			var column ColumnSchema
+			err := json.Unmarshal(tt.column, &column)
+			assert.Nil(t, err)
+			err = ValidateCondition(&column, ConditionIncludes, tt.value)
+			var elemErr *ErrWrongElementType
+			if assert.True(t, errors.As(err, &elemErr), "expected ErrWrongElementType, got %v", err) {
+				assert.Equal(t, tt.element, elemErr.Element)
+				assert.Equal(t, tt.expected, elemErr.Expected)
+				assert.Equal(t, tt.actual, elemErr.Actual)
+			}
+		})
+	}
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	one := "foo"
+	tests := []struct {
+		name     string
+		column   []byte
+		function ConditionFunction
+		actual   interface{}
+		value    interface{}
+		expected bool
+		err      bool
+	}{
+		{
+			name:     "string equal",
+			column:   []byte(`{"type":"string"}`),
+			function: ConditionEqual,
+			actual:   "foo",
+			value:    "foo",
+			expected: true,
+		},
+		{
+			name:     "string excludes",
+			column:   []byte(`{"type":"string"}`),
+			function: ConditionExcludes,
+			actual:   "foo",
+			value:    "foo",
+			expected: false,
+		},
+		{
+			name:     "string greater than",
+			column:   []byte(`{"type":"string"}`),
+			function: ConditionGreaterThan,
+			actual:   "foo",
+			value:    "bar",
+			err:      true,
+		},
+		{
+			name:     "integer less than or equal",
+			column:   []byte(`{"type":"integer"}`),
+			function: ConditionLessThanOrEqual,
+			actual:   42,
+			value:    42,
+			expected: true,
+		},
+		{
+			name:     "real greater than",
+			column:   []byte(`{"type":"real"}`),
+			function: ConditionGreaterThan,
+			actual:   1.5,
+			value:    2.0,
+			expected: false,
+		},
+		{
+			name:     "set equal in a different order",
+			column:   []byte(`{"type":{"key":"string","min":0,"max":"unlimited"}}`),
+			function: ConditionEqual,
+			actual:   []string{"foo", "bar"},
+			value:    []string{"bar", "foo"},
+			expected: true,
+		},
+		{
+			name:     "set includes",
+			column:   []byte(`{"type":{"key":"string","min":0,"max":"unlimited"}}`),
+			function: ConditionIncludes,
+			actual:   []string{"foo", "bar"},
+			value:    []string{"bar", "baz"},
+			expected: false,
+		},
+		{
+			name:     "set excludes",
+			column:   []byte(`{"type":{"key":"string","min":0,"max":"unlimited"}}`),
+			function: ConditionExcludes,
+			actual:   []string{"foo", "bar"},
+			value:    []string{"baz"},
+			expected: true,
+		},
+		{
+			name:     "optional scalar equal",
+			column:   []byte(`{"type":{"key":"string","min":0,"max":1}}`),
+			function: ConditionEqual,
+			actual:   &one,
+			value:    []string{"foo"},
+			expected: true,
+		},
+		{
+			name:     "map includes",
+			column:   []byte(`{"type":{"key":"string","value":"string","min":0,"max":"unlimited"}}`),
+			function: ConditionIncludes,
+			actual:   map[string]string{"foo": "bar", "baz": "quux"},
+			value:    map[string]string{"foo": "bar"},
+			expected: true,
+		},
+		{
+			name:     "map excludes",
+			column:   []byte(`{"type":{"key":"string","value":"string","min":0,"max":"unlimited"}}`),
+			function: ConditionExcludes,
+			actual:   map[string]string{"foo": "bar"},
+			value:    map[string]string{"foo": "baz"},
+			expected: true,
+		},
+		{
+			name:     "wrong value type",
+			column:   []byte(`{"type":"integer"}`),
+			function: ConditionEqual,
+			actual:   42,
+			value:    "foo",
+			err:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("EvaluateCondition: %s", tt.name), func(t *testing.T) {
+			var column ColumnSchema
+			err := json.Unmarshal(tt.column, &column)
+			assert.Nil(t, err)
+			matches, err := EvaluateCondition(&column, tt.function, tt.actual, tt.value)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, matches)
+		})
+	}
+}
+
+func TestApplyMutation(t *testing.T) {
+	one := 1
+	tests := []struct {
+		name     string
+		column   []byte
+		mutator  Mutator
+		current  interface{}
+		value    interface{}
+		expected interface{}
+		err      bool
+	}{
+		{
+			name:     "integer add",
+			column:   []byte(`{"type":"integer"}`),
+			mutator:  MutateOperationAdd,
+			current:  40,
+			value:    2,
+			expected: 42,
+		},
+		{
+			name:     "integer modulo",
+			column:   []byte(`{"type":"integer"}`),
+			mutator:  MutateOperationModulo,
+			current:  42,
+			value:    5,
+			expected: 2,
+		},
+		{
+			name:     "real divide",
+			column:   []byte(`{"type":"real"}`),
+			mutator:  MutateOperationDivide,
+			current:  3.0,
+			value:    2.0,
+			expected: 1.5,
+		},
+		{
+			name:    "integer divide by zero",
+			column:  []byte(`{"type":"integer"}`),
+			mutator: MutateOperationDivide,
+			current: 42,
+			value:   0,
+			err:     true,
+		},
+		{
+			name:     "set multiply",
+			column:   []byte(`{"type":{"key":"integer","min":0,"max":"unlimited"}}`),
+			mutator:  MutateOperationMultiply,
+			current:  []int{1, 2},
+			value:    3,
+			expected: []int{3, 6},
+		},
+		{
+			name:     "set insert",
+			column:   []byte(`{"type":{"key":"string","min":0,"max":"unlimited"}}`),
+			mutator:  MutateOperationInsert,
+			current:  []string{"foo", "bar"},
+			value:    []string{"bar", "baz"},
+			expected: []string{"foo", "bar", "baz"},
+		},
+		{
+			name:     "set delete",
+			column:   []byte(`{"type":{"key":"string","min":0,"max":"unlimited"}}`),
+			mutator:  MutateOperationDelete,
+			current:  []string{"foo", "bar"},
+			value:    []string{"bar", "baz"},
+			expected: []string{"foo"},
+		},
+		{
+			name:     "set insert of uuids",
+			column:   []byte(`{"type":{"key":{"type":"uuid"},"min":0,"max":"unlimited"}}`),
+			mutator:  MutateOperationInsert,
+			current:  []string{aUUID0},
+			value:    []UUID{{GoUUID: aUUID1}},
+			expected: []string{aUUID0, aUUID1},
+		},
+		{
+			name:     "optional scalar add",
+			column:   []byte(`{"type":{"key":"integer","min":0,"max":1}}`),
+			mutator:  MutateOperationAdd,
+			current:  &one,
+			value:    1,
+			expected: []int{2},
+		},
+		{
+			name:     "map insert keeps existing keys",
+			column:   []byte(`{"type":{"key":"string","value":"string","min":0,"max":"unlimited"}}`),
+			mutator:  MutateOperationInsert,
+			current:  map[string]string{"foo": "bar"},
+			value:    map[string]string{"foo": "baz", "baz": "quux"},
+			expected: map[string]string{"foo": "bar", "baz": "quux"},
+		},
+		{
+			name:     "map delete pairs",
+			column:   []byte(`{"type":{"key":"string","value":"string","min":0,"max":"unlimited"}}`),
+			mutator:  MutateOperationDelete,
+			current:  map[string]string{"foo": "bar", "baz": "quux"},
+			value:    map[string]string{"foo": "baz", "baz": "quux"},
+			expected: map[string]string{"foo": "bar"},
+		},
+		{
+			name:     "map delete keys",
+			column:   []byte(`{"type":{"key":"string","value":"string","min":0,"max":"unlimited"}}`),
+			mutator:  MutateOperationDelete,
+			current:  map[string]string{"foo": "bar", "baz": "quux"},
+			value:    []string{"foo"},
+			expected: map[string]string{"baz": "quux"},
+		},
+		{
+			name:    "wrong current type",
+			column:  []byte(`{"type":"integer"}`),
+			mutator: MutateOperationAdd,
+			current: "foo",
+			value:   1,
+			err:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("ApplyMutation: %s", tt.name), func(t *testing.T) {
+			var column ColumnSchema
+			err := json.Unmarshal(tt.column, &column)
+			assert.Nil(t, err)
+			result, err := ApplyMutation(&column, tt.mutator, tt.current, tt.value)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
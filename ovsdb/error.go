@@ -44,6 +44,9 @@ func errorFromResult(op *Operation, r OperationResult) OperationError {
 	case aborted:
 		return &Aborted{r.Details, op}
 	case notOwner:
+		if op != nil && op.Op == "assert" && op.Lock != nil {
+			return &ErrLockNotHeld{*op.Lock, r.Details, op}
+		}
 		return &NotOwner{r.Details, op}
 	default:
 		return &Error{r.Error, r.Details, op}
@@ -56,6 +59,7 @@ func errorFromResult(op *Operation, r OperationResult) OperationError {
 // return nil, error.
 // Finally, in the case where one or more of the operations in the transaction
 // failed, we return []OperationErrors, error
+// In both cases, the error is a *TransactError describing the failure
 // Within []OperationErrors, the OperationErrors.Index() corresponds to the same index in
 // the original Operations struct. You may also perform type assertions against
 // the error so the caller can decide how best to handle it
@@ -65,23 +69,77 @@ func CheckOperationResults(result []OperationResult, ops []Operation) ([]Operati
 		return nil, fmt.Errorf("ovsdb transaction error. %d operations submitted but only %d results recieved", len(ops), len(result))
 	}
 	var errs []OperationError
+	var transactErr *TransactError
 	for i, op := range result {
 		// RFC 7047: if all of the operations succeed, but the results cannot
 		// be committed, then "result" will have one more element than "params",
 		// with the additional element being an <error>.
 		if i >= len(ops) {
-			return errs, errorFromResult(nil, op)
+			err := errorFromResult(nil, op)
+			if err == nil {
+				break
+			}
+			return errs, newTransactError(-1, nil, op, err, len(ops))
 		}
 		if err := errorFromResult(&ops[i], op); err != nil {
 			errs = append(errs, err)
+			if transactErr == nil {
+				transactErr = newTransactError(i, &ops[i], op, err, len(ops))
+			}
 		}
 	}
 	if len(errs) > 0 {
-		return errs, fmt.Errorf("%d ovsdb operations failed", len(errs))
+		return errs, transactErr
 	}
 	return nil, nil
 }
 
+// TransactError describes a failed transaction. Transactions are atomic, so when it is returned
+// none of the operations have been committed
+type TransactError struct {
+	// Index is the index of the first failed operation, or -1 if all the operations succeeded
+	// but the transaction could not be committed
+	Index int
+	// Operation is the first failed operation, or nil if the transaction could not be committed
+	Operation *Operation
+	// ErrorName and Details are the error and the details reported by the server
+	ErrorName string
+	Details   string
+	// NotExecuted holds the indexes of the operations that were not executed because of the failure
+	NotExecuted []int
+	err         OperationError
+}
+
+func newTransactError(index int, op *Operation, result OperationResult, err OperationError, numOps int) *TransactError {
+	transactErr := &TransactError{
+		Index:     index,
+		Operation: op,
+		ErrorName: result.Error,
+		Details:   result.Details,
+		err:       err,
+	}
+	if index >= 0 {
+		for i := index + 1; i < numOps; i++ {
+			transactErr.NotExecuted = append(transactErr.NotExecuted, i)
+		}
+	}
+	return transactErr
+}
+
+// Error implements the error interface
+func (e *TransactError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("ovsdb transaction could not be committed: %s", e.err)
+	}
+	return fmt.Sprintf("ovsdb transaction failed: operation %d (%s) failed: %s. %d operations not executed",
+		e.Index, e.Operation.Op, e.err, len(e.NotExecuted))
+}
+
+// Unwrap returns the error of the failed operation (e.g: a *ConstraintViolation)
+func (e *TransactError) Unwrap() error {
+	return e.err
+}
+
 // OperationError represents an error that occurred as part of an
 // OVSDB Operation
 type OperationError interface {
@@ -310,6 +368,28 @@ func (e *NotOwner) Operation() *Operation {
 	return e.operation
 }
 
+// ErrLockNotHeld is the error of an assert operation on a lock that the client does not own.
+// The server reports it as a "not owner" error (RFC 7047: 5.2.9)
+type ErrLockNotHeld struct {
+	Lock      string
+	details   string
+	operation *Operation
+}
+
+// Error implements the error interface
+func (e *ErrLockNotHeld) Error() string {
+	msg := fmt.Sprintf("lock %s not held", e.Lock)
+	if e.details != "" {
+		msg += ": " + e.details
+	}
+	return msg
+}
+
+// Operation implements the OperationError interface
+func (e *ErrLockNotHeld) Operation() *Operation {
+	return e.operation
+}
+
 // Error is a generic OVSDB Error type that implements the
 // OperationError and error interfaces
 type Error struct {
@@ -1,6 +1,7 @@
 package ovsdb
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -8,6 +9,7 @@ import (
 )
 
 func TestErrorFromResult(t *testing.T) {
+	lockName := "leader"
 	type args struct {
 		op *Operation
 		r  OperationResult
@@ -72,6 +74,11 @@ func TestErrorFromResult(t *testing.T) {
 			args{nil, OperationResult{Error: notOwner}},
 			&NotOwner{},
 		},
+		{
+			"lock not held",
+			args{&Operation{Op: "assert", Lock: &lockName}, OperationResult{Error: notOwner}},
+			&ErrLockNotHeld{},
+		},
 		{
 			"generic error",
 			args{nil, OperationResult{Error: "foo"}},
@@ -134,3 +141,38 @@ func TestCheckOperationResults(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckOperationResultsTransactError(t *testing.T) {
+	ops := []Operation{{Op: "insert", Table: "Foo"}, {Op: "insert", Table: "Foo"}, {Op: "mutate", Table: "Bar"}, {Op: "delete", Table: "Baz"}}
+	result := []OperationResult{
+		{UUID: UUID{GoUUID: "2f77b348-9768-4866-b761-89d5177ecda0"}},
+		{Error: constraintViolation, Details: "Transaction causes multiple rows in \"Foo\" table to have identical values (foo) for index on column \"name\"."},
+		{},
+		{},
+	}
+	errs, err := CheckOperationResults(result, ops)
+	assert.Len(t, errs, 1)
+	transactErr, ok := err.(*TransactError)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 1, transactErr.Index)
+	assert.Equal(t, &ops[1], transactErr.Operation)
+	assert.Equal(t, constraintViolation, transactErr.ErrorName)
+	assert.Equal(t, result[1].Details, transactErr.Details)
+	assert.Equal(t, []int{2, 3}, transactErr.NotExecuted)
+	var cv *ConstraintViolation
+	assert.True(t, errors.As(err, &cv))
+
+	// Commit failure
+	result = []OperationResult{{}, {}, {}, {}, {Error: ioError, Details: "disk full"}}
+	_, err = CheckOperationResults(result, ops)
+	transactErr, ok = err.(*TransactError)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, -1, transactErr.Index)
+	assert.Nil(t, transactErr.Operation)
+	assert.Equal(t, ioError, transactErr.ErrorName)
+	assert.Empty(t, transactErr.NotExecuted)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 )
 
 // OvsMap is the JSON map structure used for OVSDB
@@ -22,10 +23,16 @@ func (o OvsMap) MarshalJSON() ([]byte, error) {
 	if len(o.GoMap) > 0 {
 		var ovsMap, innerMap []interface{}
 		ovsMap = append(ovsMap, "map")
-		for key, val := range o.GoMap {
+		keys := make([]interface{}, 0, len(o.GoMap))
+		for key := range o.GoMap {
+			keys = append(keys, key)
+		}
+		// Sorting the pairs makes the encoding stable, e.g: to compare or log operations
+		sort.Slice(keys, func(i, j int) bool { return lessAtom(keys[i], keys[j]) })
+		for _, key := range keys {
 			var mapSeg []interface{}
 			mapSeg = append(mapSeg, key)
-			mapSeg = append(mapSeg, val)
+			mapSeg = append(mapSeg, o.GoMap[key])
 			innerMap = append(innerMap, mapSeg)
 		}
 		ovsMap = append(ovsMap, innerMap)
@@ -35,17 +42,37 @@ func (o OvsMap) MarshalJSON() ([]byte, error) {
 }
 
 // UnmarshalJSON unmarshalls an OVSDB style Map from a byte array
+// Keys and values keep the type of their JSON representation (e.g: numbers are float64) except
+// for UUIDs, so they have to be converted to the native types of the column (see OvsToNative)
 func (o *OvsMap) UnmarshalJSON(b []byte) (err error) {
 	var oMap []interface{}
 	o.GoMap = make(map[interface{}]interface{})
-	if err := json.Unmarshal(b, &oMap); err == nil && len(oMap) > 1 {
-		innerSlice := oMap[1].([]interface{})
-		for _, val := range innerSlice {
-			f := val.([]interface{})
-			o.GoMap[f[0]] = f[1]
+	if err := json.Unmarshal(b, &oMap); err != nil {
+		return err
+	}
+	if len(oMap) != 2 || oMap[0] != "map" {
+		return &json.UnmarshalTypeError{Value: string(b), Type: reflect.TypeOf(*o)}
+	}
+	innerSlice, ok := oMap[1].([]interface{})
+	if !ok {
+		return &json.UnmarshalTypeError{Value: string(b), Type: reflect.TypeOf(*o)}
+	}
+	for _, val := range innerSlice {
+		pair, ok := val.([]interface{})
+		if !ok || len(pair) != 2 {
+			return &json.UnmarshalTypeError{Value: string(b), Type: reflect.TypeOf(*o)}
 		}
+		key, err := ovsSliceToGoNotation(pair[0])
+		if err != nil {
+			return err
+		}
+		value, err := ovsSliceToGoNotation(pair[1])
+		if err != nil {
+			return err
+		}
+		o.GoMap[key] = value
 	}
-	return err
+	return nil
 }
 
 // NewOvsMap will return an OVSDB style map from a provided Golang Map
@@ -62,3 +89,30 @@ func NewOvsMap(goMap interface{}) (*OvsMap, error) {
 	}
 	return &OvsMap{genMap}, nil
 }
+
+// lessAtom orders the atoms used as map keys, which are all of the same type
+func lessAtom(one, other interface{}) bool {
+	switch v := one.(type) {
+	case string:
+		if o, ok := other.(string); ok {
+			return v < o
+		}
+	case int:
+		if o, ok := other.(int); ok {
+			return v < o
+		}
+	case float64:
+		if o, ok := other.(float64); ok {
+			return v < o
+		}
+	case bool:
+		if o, ok := other.(bool); ok {
+			return !v && o
+		}
+	case UUID:
+		if o, ok := other.(UUID); ok {
+			return v.GoUUID < o.GoUUID
+		}
+	}
+	return fmt.Sprint(one) < fmt.Sprint(other)
+}
@@ -60,3 +60,22 @@ func BenchmarkMapUnmarshalJSON5(b *testing.B) {
 func BenchmarkMapUnmarshalJSON8(b *testing.B) {
 	benchmarkMapUnmarshalJSON([]byte(`[ "map", [["foo","bar"],["baz", "quuz"],["foofoo", "foobar"],["foobaz", "fooquuz"], ["barfoo", "barbar"],["barbaz", "barquux"],["bazfoo", "bazbar"], ["bazbaz", "bazquux"]]]`), b)
 }
+
+func TestMapUnmarshalJSON(t *testing.T) {
+	var m OvsMap
+	if err := json.Unmarshal([]byte(`["map",[[1,"one"],[["uuid","2f77b348-9768-4866-b761-89d5177ecda0"],["named-uuid","foo"]]]]`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.GoMap[float64(1)] != "one" {
+		t.Error("Expected an integer-looking key, got: ", m.GoMap)
+	}
+	if m.GoMap[UUID{GoUUID: "2f77b348-9768-4866-b761-89d5177ecda0"}] != (UUID{GoUUID: "foo"}) {
+		t.Error("Expected UUID keys and values, got: ", m.GoMap)
+	}
+
+	for _, invalid := range []string{`"map"`, `["set",[]]`, `["map","foo"]`, `["map",[["foo"]]]`, `["map",[1]]`} {
+		if err := json.Unmarshal([]byte(invalid), &m); err == nil {
+			t.Error("Expected an error unmarshalling: ", invalid)
+		}
+	}
+}
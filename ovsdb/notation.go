@@ -2,6 +2,7 @@ package ovsdb
 
 import (
 	"encoding/json"
+	"fmt"
 )
 
 const (
@@ -32,13 +33,30 @@ type Operation struct {
 	Comment   *string     `json:"comment,omitempty"`
 	Lock      *string     `json:"lock,omitempty"`
 	UUIDName  string      `json:"uuid-name,omitempty"`
+	// UUID is the real UUID of the row inserted by an insert operation. It is not defined by
+	// RFC7047 and is only accepted by the servers that support it
+	UUID string `json:"uuid,omitempty"`
+	// ExplicitTimeout makes a wait operation send its timeout even if it is zero, so it fails
+	// right away instead of waiting indefinitely
+	ExplicitTimeout bool `json:"-"`
 }
 
 // MarshalJSON marshalls 'Operation' to a byte array
 // For 'select' operations, we dont omit the 'Where' field
 // to allow selecting all rows of a table
+// For 'wait' operations, we dont omit the 'Where' and 'Rows' fields either, as an
+// empty list of rows is meaningful, nor the 'Timeout' field if 'ExplicitTimeout' is set
+// For operations that do not refer to any table (e.g: 'assert'), the 'Table' field is omitted
 func (o Operation) MarshalJSON() ([]byte, error) {
 	type OpAlias Operation
+	if isTablelessOperation(o) {
+		return json.Marshal(&struct {
+			Table string `json:"table,omitempty"`
+			OpAlias
+		}{
+			OpAlias: (OpAlias)(o),
+		})
+	}
 	switch o.Op {
 	case "select":
 		where := o.Where
@@ -52,6 +70,30 @@ func (o Operation) MarshalJSON() ([]byte, error) {
 			Where:   where,
 			OpAlias: (OpAlias)(o),
 		})
+	case OperationWait:
+		where := o.Where
+		if where == nil {
+			where = make([]Condition, 0)
+		}
+		rows := o.Rows
+		if rows == nil {
+			rows = make([]Row, 0)
+		}
+		var timeout *int
+		if o.Timeout != 0 || o.ExplicitTimeout {
+			timeout = &o.Timeout
+		}
+		return json.Marshal(&struct {
+			Where   []Condition `json:"where"`
+			Rows    []Row       `json:"rows"`
+			Timeout *int        `json:"timeout,omitempty"`
+			OpAlias
+		}{
+			Where:   where,
+			Rows:    rows,
+			Timeout: timeout,
+			OpAlias: (OpAlias)(o),
+		})
 	default:
 		return json.Marshal(&struct {
 			OpAlias
@@ -61,6 +103,29 @@ func (o Operation) MarshalJSON() ([]byte, error) {
 	}
 }
 
+// Operations is a list of operations, as sent in a transaction
+type Operations []Operation
+
+// PrettyJSON returns the indented JSON encoding of the operations, as sent to the server:
+// sets, maps and UUIDs are in their wire form, the columns of rows are sorted by name and
+// the pairs of maps by key, so the same operations are always encoded the same way
+func (ops Operations) PrettyJSON() ([]byte, error) {
+	if ops == nil {
+		ops = Operations{}
+	}
+	return json.MarshalIndent(ops, "", "  ")
+}
+
+// String returns the indented JSON encoding of the operations (see PrettyJSON), e.g: to log
+// a transaction that failed
+func (ops Operations) String() string {
+	b, err := ops.PrettyJSON()
+	if err != nil {
+		return fmt.Sprintf("invalid operations: %v", err)
+	}
+	return string(b)
+}
+
 // MonitorRequests represents a group of monitor requests according to RFC7047
 // We cannot use MonitorRequests by inlining the MonitorRequest Map structure till GoLang issue #6213 makes it.
 // The only option is to go with raw map[string]interface{} option :-( that sucks !
@@ -75,6 +140,21 @@ type MonitorRequest struct {
 	Select  *MonitorSelect `json:"select,omitempty"`
 }
 
+// MonitorCondRequest represents a monitor_cond request according to ovsdb-server(7).
+// Only the rows that match any of the conditions of Where are monitored, or all of them if
+// there are none
+type MonitorCondRequest struct {
+	Columns []string       `json:"columns,omitempty"`
+	Where   []Condition    `json:"where,omitempty"`
+	Select  *MonitorSelect `json:"select,omitempty"`
+}
+
+// MonitorCondChangeRequest represents the change of the conditions of a table in a
+// monitor_cond_change request according to ovsdb-server(7)
+type MonitorCondChangeRequest struct {
+	Where []Condition `json:"where"`
+}
+
 // OvsdbError is an OVS Error Condition
 type OvsdbError struct {
 	Error   string `json:"error"`
@@ -1,6 +1,7 @@
 package ovsdb
 
 import (
+	"bytes"
 	"encoding/json"
 	"log"
 	"testing"
@@ -35,6 +36,78 @@ func TestOpRowSerialization(t *testing.T) {
 	}
 }
 
+func TestOpAssertSerialization(t *testing.T) {
+	lock := "leader"
+	operation := Operation{
+		Op:   "assert",
+		Lock: &lock,
+	}
+	str, err := json.Marshal(operation)
+	if err != nil {
+		log.Fatal("serialization error:", err)
+	}
+	expected := `{"op":"assert","lock":"leader"}`
+	if string(str) != expected {
+		t.Error("Expected: ", expected, "Got", string(str))
+	}
+}
+
+func TestOpWaitSerialization(t *testing.T) {
+	operation := Operation{
+		Op:              "wait",
+		Table:           "Bridge",
+		Columns:         []string{"_uuid"},
+		Until:           "==",
+		ExplicitTimeout: true,
+	}
+	str, err := json.Marshal(operation)
+	if err != nil {
+		log.Fatal("serialization error:", err)
+	}
+	expected := `{"where":[],"rows":[],"timeout":0,"op":"wait","table":"Bridge","columns":["_uuid"],"until":"=="}`
+	if string(str) != expected {
+		t.Error("Expected: ", expected, "Got", string(str))
+	}
+
+	// Without ExplicitTimeout, a zero timeout is omitted and the server waits indefinitely
+	operation.ExplicitTimeout = false
+	str, err = json.Marshal(operation)
+	if err != nil {
+		log.Fatal("serialization error:", err)
+	}
+	expected = `{"where":[],"rows":[],"op":"wait","table":"Bridge","columns":["_uuid"],"until":"=="}`
+	if string(str) != expected {
+		t.Error("Expected: ", expected, "Got", string(str))
+	}
+
+	operation.Timeout = 100
+	str, err = json.Marshal(operation)
+	if err != nil {
+		log.Fatal("serialization error:", err)
+	}
+	expected = `{"where":[],"rows":[],"timeout":100,"op":"wait","table":"Bridge","columns":["_uuid"],"until":"=="}`
+	if string(str) != expected {
+		t.Error("Expected: ", expected, "Got", string(str))
+	}
+}
+
+func TestOpInsertUUIDSerialization(t *testing.T) {
+	operation := Operation{
+		Op:    "insert",
+		Table: "Bridge",
+		Row:   Row{"name": "br0"},
+		UUID:  "2f77b348-9768-4866-b761-89d5177ecda0",
+	}
+	str, err := json.Marshal(operation)
+	if err != nil {
+		log.Fatal("serialization error:", err)
+	}
+	expected := `{"op":"insert","table":"Bridge","row":{"name":"br0"},"uuid":"2f77b348-9768-4866-b761-89d5177ecda0"}`
+	if string(str) != expected {
+		t.Error("Expected: ", expected, "Got", string(str))
+	}
+}
+
 func TestOpRowsSerialization(t *testing.T) {
 	operation := Operation{
 		Op:    "insert",
@@ -90,6 +163,30 @@ func TestValidateOvsSet(t *testing.T) {
 	}
 }
 
+func TestUnmarshalOvsSet(t *testing.T) {
+	var oSet OvsSet
+	if err := json.Unmarshal([]byte(`"foo"`), &oSet); err != nil {
+		t.Error("Error unmarshalling single element OvsSet", err)
+	}
+	if len(oSet.GoSet) != 1 || oSet.GoSet[0] != "foo" {
+		t.Error("Expected: [foo] Got", oSet.GoSet)
+	}
+	oSet = OvsSet{}
+	if err := json.Unmarshal([]byte(`["set",["foo"]]`), &oSet); err != nil {
+		t.Error("Error unmarshalling OvsSet", err)
+	}
+	if len(oSet.GoSet) != 1 || oSet.GoSet[0] != "foo" {
+		t.Error("Expected: [foo] Got", oSet.GoSet)
+	}
+	// Negative condition test
+	for _, data := range []string{`[]`, `["set"]`, `["set","foo"]`, `["foo",["bar"]]`} {
+		oSet = OvsSet{}
+		if err := json.Unmarshal([]byte(data), &oSet); err == nil {
+			t.Error("OvsSet unmarshalling must fail for", data)
+		}
+	}
+}
+
 func TestValidateOvsMap(t *testing.T) {
 	myMap := make(map[int]string)
 	myMap[1] = "hello"
@@ -172,3 +269,124 @@ func TestNewMutation(t *testing.T) {
 		t.Error("mutation is not correctly formatted")
 	}
 }
+
+func TestOperationsPrettyJSON(t *testing.T) {
+	externalIDs, err := NewOvsMap(map[string]string{"owner": "foo", "env": "prod", "zone": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ports, err := NewOvsSet([]UUID{{GoUUID: "2f77b348-9768-4866-b761-89d5177ecda0"}, {GoUUID: "lsp0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock := "leader"
+	ops := Operations{
+		{Op: OperationAssert, Lock: &lock},
+		{
+			Op:       OperationInsert,
+			Table:    "Logical_Switch",
+			Row:      Row{"name": "ls0", "external_ids": externalIDs, "ports": ports},
+			UUIDName: "ls0",
+		},
+		{
+			Op:        OperationMutate,
+			Table:     "Logical_Switch",
+			Mutations: []Mutation{*NewMutation("ports", MutateOperationInsert, UUID{GoUUID: "lsp0"})},
+			Where:     []Condition{NewCondition("_uuid", ConditionEqual, UUID{GoUUID: "2f77b348-9768-4866-b761-89d5177ecda0"})},
+		},
+	}
+	expected := `[
+  {
+    "op": "assert",
+    "lock": "leader"
+  },
+  {
+    "op": "insert",
+    "table": "Logical_Switch",
+    "row": {
+      "external_ids": [
+        "map",
+        [
+          [
+            "env",
+            "prod"
+          ],
+          [
+            "owner",
+            "foo"
+          ],
+          [
+            "zone",
+            "a"
+          ]
+        ]
+      ],
+      "name": "ls0",
+      "ports": [
+        "set",
+        [
+          [
+            "uuid",
+            "2f77b348-9768-4866-b761-89d5177ecda0"
+          ],
+          [
+            "named-uuid",
+            "lsp0"
+          ]
+        ]
+      ]
+    },
+    "uuid-name": "ls0"
+  },
+  {
+    "op": "mutate",
+    "table": "Logical_Switch",
+    "mutations": [
+      [
+        "ports",
+        "insert",
+        [
+          "named-uuid",
+          "lsp0"
+        ]
+      ]
+    ],
+    "where": [
+      [
+        "_uuid",
+        "==",
+        [
+          "uuid",
+          "2f77b348-9768-4866-b761-89d5177ecda0"
+        ]
+      ]
+    ]
+  }
+]`
+	// The encoding is stable
+	for i := 0; i < 10; i++ {
+		if s := ops.String(); s != expected {
+			t.Fatal("Expected: ", expected, "Got", s)
+		}
+	}
+	// It is the indented wire encoding
+	compact, err := json.Marshal([]Operation(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pretty, err := ops.PrettyJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, pretty); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(compact) {
+		t.Error("Expected: ", string(compact), "Got", buf.String())
+	}
+
+	if s := Operations(nil).String(); s != "[]" {
+		t.Error("Expected an empty list, got: ", s)
+	}
+}
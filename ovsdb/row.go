@@ -3,6 +3,8 @@ package ovsdb
 import "encoding/json"
 
 // Row is a table Row according to RFC7047
+// Only the columns it holds are part of it: a column that is absent (e.g: one that is not changed by
+// an update2 modify) is not the same as a column holding an empty value
 type Row map[string]interface{}
 
 // UnmarshalJSON unmarshalls a byte array to an OVSDB Row
@@ -1,5 +1,10 @@
 package ovsdb
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 // NewEchoArgs creates a new set of arguments for an echo RPC
 func NewEchoArgs() []interface{} {
 	return []interface{}{"libovsdb echo"}
@@ -34,6 +39,21 @@ func NewMonitorArgs(database string, value interface{}, requests map[string]Moni
 	return []interface{}{database, value, requests}
 }
 
+// NewMonitorCondArgs creates a new set of arguments for a monitor_cond RPC
+func NewMonitorCondArgs(database string, value interface{}, requests map[string]MonitorCondRequest) []interface{} {
+	return []interface{}{database, value, requests}
+}
+
+// NewMonitorCondChangeArgs creates a new set of arguments for a monitor_cond_change RPC
+func NewMonitorCondChangeArgs(value, newValue interface{}, requests map[string][]MonitorCondChangeRequest) []interface{} {
+	return []interface{}{value, newValue, requests}
+}
+
+// NewMonitorCondSinceArgs creates a new set of arguments for a monitor_cond_since RPC
+func NewMonitorCondSinceArgs(database string, value interface{}, requests map[string]MonitorCondRequest, lastTransactionID string) []interface{} {
+	return []interface{}{database, value, requests, lastTransactionID}
+}
+
 // NewMonitorCancelArgs creates a new set of arguments for a monitor_cancel RPC
 func NewMonitorCancelArgs(value interface{}) []interface{} {
 	return []interface{}{value}
@@ -44,6 +64,47 @@ func NewLockArgs(id interface{}) []interface{} {
 	return []interface{}{id}
 }
 
+// LockResult is the result of a lock or steal RPC
+type LockResult struct {
+	Locked bool `json:"locked"`
+}
+
+// ZeroTransactionID is the transaction id to send in a monitor_cond_since request when no
+// update has been received yet, so the server sends the full contents of the tables
+const ZeroTransactionID = "00000000-0000-0000-0000-000000000000"
+
+// MonitorCondSinceReply is the result of a monitor_cond_since RPC according to ovsdb-server(7).
+// If Found is false, the server could not find the requested transaction and Updates holds the
+// full contents of the monitored tables instead of the changes since that transaction
+type MonitorCondSinceReply struct {
+	Found             bool
+	LastTransactionID string
+	Updates           TableUpdates2
+}
+
+// MarshalJSON marshalls a MonitorCondSinceReply to the 3-element array sent by the server
+func (r MonitorCondSinceReply) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{r.Found, r.LastTransactionID, r.Updates})
+}
+
+// UnmarshalJSON unmarshalls the 3-element array sent by the server to a MonitorCondSinceReply
+func (r *MonitorCondSinceReply) UnmarshalJSON(b []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return fmt.Errorf("monitor_cond_since reply requires exactly 3 elements, got %d", len(raw))
+	}
+	if err := json.Unmarshal(raw[0], &r.Found); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &r.LastTransactionID); err != nil {
+		return err
+	}
+	return json.Unmarshal(raw[2], &r.Updates)
+}
+
 // NotificationHandler is the interface that must be implemented to receive notifcations
 type NotificationHandler interface {
 	// RFC 7047 section 4.1.6 Update Notification
@@ -60,3 +121,9 @@ type NotificationHandler interface {
 
 	Disconnected()
 }
+
+// Update2NotificationHandler can be implemented by a NotificationHandler to also
+// receive the update2 notifications described in ovsdb-server(7)
+type Update2NotificationHandler interface {
+	Update2(context interface{}, tableUpdates TableUpdates2)
+}
@@ -85,3 +85,65 @@ func TestNewLockArgs(t *testing.T) {
 		t.Error("Expected: ", expected, " Got: ", string(argString))
 	}
 }
+
+func TestNewMonitorCondArgs(t *testing.T) {
+	requests := map[string]MonitorCondRequest{
+		"Bridge": {
+			Columns: []string{"name"},
+			Where:   []Condition{NewCondition("name", ConditionEqual, "br-int")},
+		},
+	}
+	args := NewMonitorCondArgs("Open_vSwitch", "ctx", requests)
+	argString, _ := json.Marshal(args)
+	expected := `["Open_vSwitch","ctx",{"Bridge":{"columns":["name"],"where":[["name","==","br-int"]]}}]`
+	if string(argString) != expected {
+		t.Error("Expected: ", expected, " Got: ", string(argString))
+	}
+}
+
+func TestNewMonitorCondChangeArgs(t *testing.T) {
+	requests := map[string][]MonitorCondChangeRequest{
+		"Bridge": {{Where: []Condition{}}},
+	}
+	args := NewMonitorCondChangeArgs("ctx", "ctx", requests)
+	argString, _ := json.Marshal(args)
+	expected := `["ctx","ctx",{"Bridge":[{"where":[]}]}]`
+	if string(argString) != expected {
+		t.Error("Expected: ", expected, " Got: ", string(argString))
+	}
+}
+
+func TestNewMonitorCondSinceArgs(t *testing.T) {
+	requests := map[string]MonitorCondRequest{
+		"Bridge": {Columns: []string{"name"}},
+	}
+	args := NewMonitorCondSinceArgs("Open_vSwitch", "ctx", requests, ZeroTransactionID)
+	argString, _ := json.Marshal(args)
+	expected := `["Open_vSwitch","ctx",{"Bridge":{"columns":["name"]}},"00000000-0000-0000-0000-000000000000"]`
+	if string(argString) != expected {
+		t.Error("Expected: ", expected, " Got: ", string(argString))
+	}
+}
+
+func TestMonitorCondSinceReply(t *testing.T) {
+	raw := `[true,"txn",{"Bridge":{"uuid":{"insert":{"name":"br-int"}}}}]`
+	var reply MonitorCondSinceReply
+	if err := json.Unmarshal([]byte(raw), &reply); err != nil {
+		t.Fatal(err)
+	}
+	if !reply.Found || reply.LastTransactionID != "txn" {
+		t.Error("Unexpected reply: ", reply)
+	}
+	row := reply.Updates["Bridge"]["uuid"]
+	if row == nil || row.Insert == nil || (*row.Insert)["name"] != "br-int" {
+		t.Error("Unexpected updates: ", reply.Updates)
+	}
+	marshalled, _ := json.Marshal(reply)
+	if string(marshalled) != raw {
+		t.Error("Expected: ", raw, " Got: ", string(marshalled))
+	}
+
+	if err := json.Unmarshal([]byte(`[true,"txn"]`), &reply); err == nil {
+		t.Error("Expected an error for a reply with missing elements")
+	}
+}
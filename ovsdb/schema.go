@@ -22,6 +22,12 @@ var UUIDColumn = ColumnSchema{
 	Type: TypeUUID,
 }
 
+// VersionColumn is a static column that represents the _version column, common to all tables
+// Its value changes on every modification of the row, so it can be used for optimistic concurrency
+var VersionColumn = ColumnSchema{
+	Type: TypeUUID,
+}
+
 // Table returns a TableSchema Schema for a given table and column name
 func (schema DatabaseSchema) Table(tableName string) *TableSchema {
 	if table, ok := schema.Tables[tableName]; ok {
@@ -48,22 +54,78 @@ func (schema DatabaseSchema) Print(w io.Writer) {
 
 // SchemaFromFile returns a DatabaseSchema from a file
 func SchemaFromFile(f *os.File) (*DatabaseSchema, error) {
-	data, err := ioutil.ReadAll(f)
+	return SchemaFromReader(f)
+}
+
+// SchemaFromPath returns a DatabaseSchema from the schema file (e.g: vswitch.ovsschema)
+// found at the given path
+func SchemaFromPath(path string) (*DatabaseSchema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	schema, err := SchemaFromReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return schema, nil
+}
+
+// SchemaFromReader returns a DatabaseSchema from a reader providing its JSON representation
+// The schema must contain a name, a version and at least one table with at least one column
+func SchemaFromReader(r io.Reader) (*DatabaseSchema, error) {
+	data, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
 	var schema DatabaseSchema
 	err = json.Unmarshal(data, &schema)
 	if err != nil {
+		if syntaxErr, ok := err.(*json.SyntaxError); ok {
+			return nil, fmt.Errorf("malformed schema at offset %d: %v", syntaxErr.Offset, err)
+		}
+		return nil, fmt.Errorf("malformed schema: %v", err)
+	}
+	if err := schema.validate(); err != nil {
 		return nil, err
 	}
-
 	return &schema, nil
 }
 
+// validate checks that the schema contains the required members
+func (schema DatabaseSchema) validate() error {
+	if schema.Name == "" {
+		return fmt.Errorf("invalid schema: missing name")
+	}
+	if schema.Version == "" {
+		return fmt.Errorf("invalid schema %s: missing version", schema.Name)
+	}
+	if len(schema.Tables) == 0 {
+		return fmt.Errorf("invalid schema %s: missing tables", schema.Name)
+	}
+	for name, table := range schema.Tables {
+		if len(table.Columns) == 0 {
+			return fmt.Errorf("invalid schema %s: table %s has no columns", schema.Name, name)
+		}
+		for _, index := range table.Indexes {
+			for _, column := range index {
+				if table.Column(column) == nil {
+					return fmt.Errorf("invalid schema %s: index of table %s refers to unknown column %s",
+						schema.Name, name, column)
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // ValidateOperations performs basic validation for operations against a DatabaseSchema
 func (schema DatabaseSchema) ValidateOperations(operations ...Operation) bool {
 	for _, op := range operations {
+		if isTablelessOperation(op) {
+			continue
+		}
 		table, ok := schema.Tables[op.Table]
 		if ok {
 			for column := range op.Row {
@@ -96,6 +158,15 @@ func (schema DatabaseSchema) ValidateOperations(operations ...Operation) bool {
 	return true
 }
 
+// isTablelessOperation returns whether the operation does not refer to any table
+func isTablelessOperation(op Operation) bool {
+	switch op.Op {
+	case "assert", "comment", "commit", "abort":
+		return op.Table == ""
+	}
+	return false
+}
+
 // TableSchema is a table schema according to RFC7047
 type TableSchema struct {
 	Columns map[string]*ColumnSchema `json:"columns"`
@@ -107,6 +178,9 @@ func (t TableSchema) Column(columnName string) *ColumnSchema {
 	if columnName == "_uuid" {
 		return &UUIDColumn
 	}
+	if columnName == "_version" {
+		return &VersionColumn
+	}
 	if column, ok := t.Columns[columnName]; ok {
 		return column
 	}
@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -404,6 +407,13 @@ func TestTable(t *testing.T) {
 		column := table.Column("_uuid")
 		assert.NotNil(t, column)
 	})
+	t.Run("GetColumn_version", func(t *testing.T) {
+		table := schema.Table("test")
+		assert.NotNil(t, table)
+		column := table.Column("_version")
+		assert.NotNil(t, column)
+		assert.Equal(t, TypeUUID, column.Type)
+	})
 }
 
 func TestBaseTypeMarshalUnmarshalJSON(t *testing.T) {
@@ -1104,3 +1114,83 @@ func TestColumnSchema_String(t *testing.T) {
 		})
 	}
 }
+
+func TestSchemaFromReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{
+			name: "valid",
+			schema: `{"name": "TestDB", "version": "1.0.0",
+			  "tables": {"Test": {"columns": {"foo": {"type": "string"}}, "indexes": [["foo"]]}}}`,
+		},
+		{
+			name:   "malformed",
+			schema: `{"name": "TestDB",`,
+			err:    "malformed schema",
+		},
+		{
+			name:   "wrong column type",
+			schema: `{"name": "TestDB", "version": "1.0.0", "tables": {"Test": {"columns": {"foo": {"type": "bar"}}}}}`,
+			err:    "malformed schema",
+		},
+		{
+			name:   "missing name",
+			schema: `{"version": "1.0.0", "tables": {"Test": {"columns": {"foo": {"type": "string"}}}}}`,
+			err:    "missing name",
+		},
+		{
+			name:   "missing version",
+			schema: `{"name": "TestDB", "tables": {"Test": {"columns": {"foo": {"type": "string"}}}}}`,
+			err:    "missing version",
+		},
+		{
+			name:   "missing tables",
+			schema: `{"name": "TestDB", "version": "1.0.0"}`,
+			err:    "missing tables",
+		},
+		{
+			name:   "table without columns",
+			schema: `{"name": "TestDB", "version": "1.0.0", "tables": {"Test": {}}}`,
+			err:    "table Test has no columns",
+		},
+		{
+			name: "index of unknown column",
+			schema: `{"name": "TestDB", "version": "1.0.0",
+			  "tables": {"Test": {"columns": {"foo": {"type": "string"}}, "indexes": [["bar"]]}}}`,
+			err: "unknown column bar",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("SchemaFromReader: %s", tt.name), func(t *testing.T) {
+			schema, err := SchemaFromReader(strings.NewReader(tt.schema))
+			if tt.err != "" {
+				assert.NotNil(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, "TestDB", schema.Name)
+			assert.NotNil(t, schema.Table("Test"))
+		})
+	}
+}
+
+func TestSchemaFromPath(t *testing.T) {
+	dir, err := os.MkdirTemp("", "libovsdb")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.ovsschema")
+	err = os.WriteFile(path, []byte(`{"name": "TestDB", "version": "1.0.0",
+	  "tables": {"Test": {"columns": {"foo": {"type": "string"}}}}}`), 0600)
+	assert.Nil(t, err)
+	schema, err := SchemaFromPath(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0.0", schema.Version)
+
+	_, err = SchemaFromPath(filepath.Join(dir, "missing.ovsschema"))
+	assert.NotNil(t, err)
+}
@@ -40,6 +40,8 @@ func NewOvsSet(obj interface{}) (*OvsSet, error) {
 }
 
 // MarshalJSON wil marshal an OVSDB style Set in to a JSON byte array
+// Sets with exactly one element are marshalled as the bare element, which is
+// the form expected for columns whose maximum number of elements is 1
 func (o OvsSet) MarshalJSON() ([]byte, error) {
 	switch l := len(o.GoSet); {
 	case l == 1:
@@ -73,13 +75,20 @@ func (o *OvsSet) UnmarshalJSON(b []byte) (err error) {
 		oSet = inter.([]interface{})
 		// it's a single uuid object
 		if len(oSet) == 2 && (oSet[0] == "uuid" || oSet[0] == "named-uuid") {
-			return addToSet(o, UUID{GoUUID: oSet[1].(string)})
+			var uuid UUID
+			if err := json.Unmarshal(b, &uuid); err != nil {
+				return err
+			}
+			return addToSet(o, uuid)
 		}
-		if oSet[0] != "set" {
+		if len(oSet) != 2 || oSet[0] != "set" {
 			// it is a slice, but is not a set
 			return &json.UnmarshalTypeError{Value: reflect.ValueOf(inter).String(), Type: reflect.TypeOf(*o)}
 		}
-		innerSet := oSet[1].([]interface{})
+		innerSet, ok := oSet[1].([]interface{})
+		if !ok {
+			return &json.UnmarshalTypeError{Value: reflect.ValueOf(inter).String(), Type: reflect.TypeOf(*o)}
+		}
 		for _, val := range innerSet {
 			err := addToSet(o, val)
 			if err != nil {
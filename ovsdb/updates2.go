@@ -0,0 +1,58 @@
+package ovsdb
+
+import "encoding/json"
+
+// TableUpdates2 is an object that maps from a table name to a
+// TableUpdate2
+type TableUpdates2 map[string]TableUpdate2
+
+// TableUpdate2 is an object that maps from the row's UUID to a
+// RowUpdate2
+type TableUpdate2 map[string]*RowUpdate2
+
+// RowUpdate2 represents a row update according to the update2 notification
+// described in ovsdb-server(7). Exactly one of its members is set
+// Modify only contains the columns that changed. For sets and maps, it contains
+// the difference between the old and the new value rather than the new value
+type RowUpdate2 struct {
+	Initial *Row `json:"initial,omitempty"`
+	Insert  *Row `json:"insert,omitempty"`
+	Modify  *Row `json:"modify,omitempty"`
+	Delete  *Row `json:"delete,omitempty"`
+}
+
+// UnmarshalJSON unmarshalls a byte array to a RowUpdate2
+// A "delete" member is always null, so an empty Row is used to signal its presence
+func (r *RowUpdate2) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	unmarshalRow := func(data json.RawMessage) (*Row, error) {
+		row := NewRow()
+		if string(data) == "null" {
+			return &row, nil
+		}
+		if err := json.Unmarshal(data, &row); err != nil {
+			return nil, err
+		}
+		return &row, nil
+	}
+	var err error
+	for key, data := range raw {
+		switch key {
+		case "initial":
+			r.Initial, err = unmarshalRow(data)
+		case "insert":
+			r.Insert, err = unmarshalRow(data)
+		case "modify":
+			r.Modify, err = unmarshalRow(data)
+		case "delete":
+			r.Delete, err = unmarshalRow(data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
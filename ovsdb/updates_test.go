@@ -1,6 +1,7 @@
 package ovsdb
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -166,3 +167,19 @@ func TestRowUpdateDelete(t *testing.T) {
 	assert.False(t, u2.Delete())
 	assert.True(t, u3.Delete())
 }
+
+func TestRowUpdate2Unmarshal(t *testing.T) {
+	var updates TableUpdates2
+	err := json.Unmarshal([]byte(`{"Bridge": {
+		"a": {"initial": {"name": "br0"}},
+		"b": {"insert": {"name": "br1"}},
+		"c": {"modify": {"name": "br2"}},
+		"d": {"delete": null}}}`), &updates)
+	assert.Nil(t, err)
+	assert.Equal(t, TableUpdates2{"Bridge": {
+		"a": {Initial: &Row{"name": "br0"}},
+		"b": {Insert: &Row{"name": "br1"}},
+		"c": {Modify: &Row{"name": "br2"}},
+		"d": {Delete: &Row{}},
+	}}, updates)
+}
@@ -11,26 +11,104 @@ type UUID struct {
 	GoUUID string `json:"uuid"`
 }
 
+const (
+	uuidWireForm      = "uuid"
+	namedUUIDWireForm = "named-uuid"
+)
+
+// IsNamed returns whether the UUID is a named-uuid, i.e: whether it is
+// encoded as ["named-uuid", <name>] rather than ["uuid", <uuid>]
+func (u UUID) IsNamed() bool {
+	return u.validateUUID() != nil
+}
+
 // MarshalJSON will marshal an OVSDB style UUID to a JSON encoded byte array
 func (u UUID) MarshalJSON() ([]byte, error) {
 	var uuidSlice []string
-	err := u.validateUUID()
-	if err == nil {
-		uuidSlice = []string{"uuid", u.GoUUID}
+	if u.IsNamed() {
+		uuidSlice = []string{namedUUIDWireForm, u.GoUUID}
 	} else {
-		uuidSlice = []string{"named-uuid", u.GoUUID}
+		uuidSlice = []string{uuidWireForm, u.GoUUID}
 	}
 
 	return json.Marshal(uuidSlice)
 }
 
 // UnmarshalJSON will unmarshal a JSON encoded byte array to a OVSDB style UUID
+// Both the ["uuid", <uuid>] and ["named-uuid", <name>] forms are accepted, as well
+// as a plain string. The form is not kept: use WireUUID to encode it back the same way
 func (u *UUID) UnmarshalJSON(b []byte) (err error) {
+	uuid, _, err := unmarshalUUID(b)
+	if err != nil {
+		return err
+	}
+	*u = UUID{GoUUID: uuid}
+	return nil
+}
+
+// WireUUID is a UUID that records the form it was decoded from, so it is encoded back the
+// same way even if it does not match the one inferred from the value (e.g: a named-uuid
+// that looks like a UUID)
+type WireUUID struct {
+	UUID
+	Named bool
+}
+
+// IsNamed returns whether the UUID is encoded as a named-uuid
+func (w WireUUID) IsNamed() bool {
+	return w.Named
+}
+
+// MarshalJSON will marshal the UUID to a JSON encoded byte array, in the form it was decoded from
+func (w WireUUID) MarshalJSON() ([]byte, error) {
+	form := uuidWireForm
+	if w.Named {
+		form = namedUUIDWireForm
+	}
+	return json.Marshal([]string{form, w.GoUUID})
+}
+
+// UnmarshalJSON will unmarshal a JSON encoded byte array to a UUID, recording its form. A plain
+// string is decoded in the form inferred from its value
+func (w *WireUUID) UnmarshalJSON(b []byte) error {
+	uuid, form, err := unmarshalUUID(b)
+	if err != nil {
+		return err
+	}
+	w.UUID = UUID{GoUUID: uuid}
+	if form == "" {
+		w.Named = w.UUID.IsNamed()
+	} else {
+		w.Named = form == namedUUIDWireForm
+	}
+	return nil
+}
+
+// unmarshalUUID decodes a UUID in any of its forms and returns its value and its form, which
+// is empty for a plain string
+func unmarshalUUID(b []byte) (string, string, error) {
+	var plain string
+	if err := json.Unmarshal(b, &plain); err == nil {
+		return plain, "", nil
+	}
 	var ovsUUID []string
-	if err := json.Unmarshal(b, &ovsUUID); err == nil {
-		u.GoUUID = ovsUUID[1]
+	if err := json.Unmarshal(b, &ovsUUID); err != nil {
+		return "", "", err
+	}
+	if len(ovsUUID) != 2 {
+		return "", "", fmt.Errorf("invalid uuid %s: expected 2 elements, got %d", string(b), len(ovsUUID))
+	}
+	switch ovsUUID[0] {
+	case uuidWireForm, namedUUIDWireForm:
+		return ovsUUID[1], ovsUUID[0], nil
+	default:
+		return "", "", fmt.Errorf("invalid uuid %s: unknown form %q", string(b), ovsUUID[0])
 	}
-	return err
+}
+
+// IsValidUUID returns whether the provided string is a valid (non-named) UUID
+func IsValidUUID(uuid string) bool {
+	return UUID{GoUUID: uuid}.validateUUID() == nil
 }
 
 func (u UUID) validateUUID() error {
@@ -46,3 +124,76 @@ func (u UUID) validateUUID() error {
 
 	return nil
 }
+
+// ValidateNamedUUIDs checks that every named-uuid referenced by the operations (i.e: every UUID
+// that is not a valid UUID) is declared as the uuid-name of an insert operation of the same transaction
+func ValidateNamedUUIDs(operations ...Operation) error {
+	declared := make(map[string]bool)
+	for _, op := range operations {
+		if op.Op == "insert" && op.UUIDName != "" {
+			declared[op.UUIDName] = true
+		}
+	}
+	var check func(value interface{}) error
+	check = func(value interface{}) error {
+		switch v := value.(type) {
+		case UUID:
+			if !IsValidUUID(v.GoUUID) && !declared[v.GoUUID] {
+				return fmt.Errorf("named-uuid %q is not declared by any insert operation", v.GoUUID)
+			}
+		case *UUID:
+			if v != nil {
+				return check(*v)
+			}
+		case OvsSet:
+			for _, elem := range v.GoSet {
+				if err := check(elem); err != nil {
+					return err
+				}
+			}
+		case *OvsSet:
+			if v != nil {
+				return check(*v)
+			}
+		case OvsMap:
+			for key, elem := range v.GoMap {
+				if err := check(key); err != nil {
+					return err
+				}
+				if err := check(elem); err != nil {
+					return err
+				}
+			}
+		case *OvsMap:
+			if v != nil {
+				return check(*v)
+			}
+		}
+		return nil
+	}
+	for _, op := range operations {
+		for _, value := range op.Row {
+			if err := check(value); err != nil {
+				return err
+			}
+		}
+		for _, row := range op.Rows {
+			for _, value := range row {
+				if err := check(value); err != nil {
+					return err
+				}
+			}
+		}
+		for _, mutation := range op.Mutations {
+			if err := check(mutation.Value); err != nil {
+				return err
+			}
+		}
+		for _, condition := range op.Where {
+			if err := check(condition.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package ovsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateNamedUUIDs(t *testing.T) {
+	realUUID := "2f77b348-9768-4866-b761-89d5177ecda0"
+	insert := Operation{Op: "insert", Table: "Foo", UUIDName: "newfoo"}
+	tests := []struct {
+		name       string
+		operations []Operation
+		err        bool
+	}{
+		{
+			name: "mutation references declared named-uuid",
+			operations: []Operation{insert, {
+				Op:        "mutate",
+				Table:     "Bar",
+				Mutations: []Mutation{{Column: "foos", Mutator: MutateOperationInsert, Value: &OvsSet{GoSet: []interface{}{UUID{GoUUID: "newfoo"}}}}},
+				Where:     []Condition{{Column: "_uuid", Function: ConditionEqual, Value: UUID{GoUUID: realUUID}}},
+			}},
+		},
+		{
+			name: "row references declared named-uuid",
+			operations: []Operation{insert, {
+				Op:    "insert",
+				Table: "Bar",
+				Row:   Row{"foo": UUID{GoUUID: "newfoo"}},
+			}},
+		},
+		{
+			name: "mutation references undeclared named-uuid",
+			operations: []Operation{insert, {
+				Op:        "mutate",
+				Table:     "Bar",
+				Mutations: []Mutation{{Column: "foos", Mutator: MutateOperationInsert, Value: &OvsSet{GoSet: []interface{}{UUID{GoUUID: "otherfoo"}}}}},
+			}},
+			err: true,
+		},
+		{
+			name: "map references undeclared named-uuid",
+			operations: []Operation{{
+				Op:    "update",
+				Table: "Bar",
+				Row:   Row{"foo_map": OvsMap{GoMap: map[interface{}]interface{}{"key": UUID{GoUUID: "newfoo"}}}},
+			}},
+			err: true,
+		},
+		{
+			name: "condition references undeclared named-uuid",
+			operations: []Operation{{
+				Op:    "delete",
+				Table: "Bar",
+				Where: []Condition{{Column: "foo", Function: ConditionEqual, Value: UUID{GoUUID: "newfoo"}}},
+			}},
+			err: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("ValidateNamedUUIDs: %s", tt.name), func(t *testing.T) {
+			err := ValidateNamedUUIDs(tt.operations...)
+			if tt.err {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestUUIDUnmarshalJSON(t *testing.T) {
+	realUUID := "2f77b348-9768-4866-b761-89d5177ecda0"
+	tests := []struct {
+		name     string
+		json     string
+		expected string
+		named    bool
+		wire     string
+		err      bool
+	}{
+		{
+			name:     "uuid",
+			json:     `["uuid","` + realUUID + `"]`,
+			expected: realUUID,
+			wire:     `["uuid","` + realUUID + `"]`,
+		},
+		{
+			name:     "named-uuid",
+			json:     `["named-uuid","myfoo"]`,
+			expected: "myfoo",
+			named:    true,
+			wire:     `["named-uuid","myfoo"]`,
+		},
+		{
+			name:     "named-uuid that looks like a uuid",
+			json:     `["named-uuid","` + realUUID + `"]`,
+			expected: realUUID,
+			named:    true,
+			wire:     `["named-uuid","` + realUUID + `"]`,
+		},
+		{
+			name:     "uuid that does not look like a uuid",
+			json:     `["uuid","myfoo"]`,
+			expected: "myfoo",
+			wire:     `["uuid","myfoo"]`,
+		},
+		{
+			name:     "plain string",
+			json:     `"` + realUUID + `"`,
+			expected: realUUID,
+			wire:     `["uuid","` + realUUID + `"]`,
+		},
+		{
+			name:     "plain string named-uuid",
+			json:     `"myfoo"`,
+			expected: "myfoo",
+			named:    true,
+			wire:     `["named-uuid","myfoo"]`,
+		},
+		{
+			name: "unknown form",
+			json: `["foo","` + realUUID + `"]`,
+			err:  true,
+		},
+		{
+			name: "wrong length",
+			json: `["uuid"]`,
+			err:  true,
+		},
+		{
+			name: "wrong type",
+			json: `42`,
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("UUIDUnmarshalJSON: %s", tt.name), func(t *testing.T) {
+			var uuid UUID
+			err := json.Unmarshal([]byte(tt.json), &uuid)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			// The form is inferred from the value
+			assert.Equal(t, UUID{tt.expected}, uuid)
+			assert.Equal(t, uuid.validateUUID() != nil, uuid.IsNamed())
+		})
+		t.Run(fmt.Sprintf("WireUUIDUnmarshalJSON: %s", tt.name), func(t *testing.T) {
+			var uuid WireUUID
+			err := json.Unmarshal([]byte(tt.json), &uuid)
+			if tt.err {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, UUID{tt.expected}, uuid.UUID)
+			assert.Equal(t, tt.named, uuid.IsNamed())
+			wire, err := json.Marshal(uuid)
+			assert.Nil(t, err)
+			assert.JSONEq(t, tt.wire, string(wire))
+		})
+	}
+}
+
+func TestUUIDSetUnmarshalJSON(t *testing.T) {
+	realUUID := "2f77b348-9768-4866-b761-89d5177ecda0"
+	var set OvsSet
+	err := json.Unmarshal([]byte(`["named-uuid","`+realUUID+`"]`), &set)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{UUID{realUUID}}, set.GoSet)
+
+	err = json.Unmarshal([]byte(`["named-uuid",42]`), &set)
+	assert.NotNil(t, err)
+}